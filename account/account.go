@@ -55,6 +55,18 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 			}}
 		}
 
+		if endpointErrs := account.BidderEndpoints.Validate(nil); len(endpointErrs) > 0 {
+			return nil, []error{&errortypes.MalformedAcct{
+				Message: fmt.Sprintf("The prebid-server account config bidder endpoints for account id \"%s\" are malformed. Please reach out to the prebid server host.", accountID),
+			}}
+		}
+
+		if aliasErrs := account.BidderAliases.Validate(nil); len(aliasErrs) > 0 {
+			return nil, []error{&errortypes.MalformedAcct{
+				Message: fmt.Sprintf("The prebid-server account config bidder aliases for account id \"%s\" are malformed. Please reach out to the prebid server host.", accountID),
+			}}
+		}
+
 		// Fill in ID if needed, so it can be left out of account definition
 		if len(account.ID) == 0 {
 			account.ID = accountID
@@ -70,6 +82,13 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 		return nil, errs
 	}
 
+	setDefaultIPMasking(account)
+
+	return account, nil
+}
+
+// setDefaultIPMasking falls back to the default masking of the IP addresses when the account masking is invalid
+func setDefaultIPMasking(account *config.Account) {
 	if ipV6Err := account.Privacy.IPv6Config.Validate(nil); len(ipV6Err) > 0 {
 		account.Privacy.IPv6Config.AnonKeepBits = iputil.IPv6DefaultMaskingBitSize
 	}
@@ -77,8 +96,6 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 	if ipV4Err := account.Privacy.IPv4Config.Validate(nil); len(ipV4Err) > 0 {
 		account.Privacy.IPv4Config.AnonKeepBits = iputil.IPv4DefaultMaskingBitSize
 	}
-
-	return account, nil
 }
 
 // TCF2Enforcements maps enforcement algo string values to their integer representation and is
@@ -27,6 +27,8 @@ var mockAccountData = map[string]json.RawMessage{
 	"valid_acct_dsa":            json.RawMessage(`{"disabled":false, "privacy": {"dsa": {"default": "` + validDSA + `"}}}`),
 	"invalid_acct_dsa":          json.RawMessage(`{"disabled":false, "privacy": {"dsa": {"default": "` + invalidDSA + `"}}}`),
 	"invalid_acct_ipv6_ipv4":    json.RawMessage(`{"disabled":false, "privacy": {"ipv6": {"anon_keep_bits": -32}, "ipv4": {"anon_keep_bits": -16}}}`),
+	"invalid_acct_endpoint":     json.RawMessage(`{"disabled":false, "bidder_endpoints": {"appnexus": {"endpoint": "{{.Region}}.example.com"}}}`),
+	"invalid_acct_alias":        json.RawMessage(`{"disabled":false, "bidder_aliases": {"appnexus": {"endpoint": "https://example.com"}}}`),
 	"disabled_acct":             json.RawMessage(`{"disabled":true}`),
 	"malformed_acct":            json.RawMessage(`{"disabled":"invalid type"}`),
 	"gdpr_channel_enabled_acct": json.RawMessage(`{"disabled":false,"gdpr":{"channel_enabled":{"amp":true}}}`),
@@ -92,6 +94,8 @@ func TestGetAccount(t *testing.T) {
 
 		{accountID: "invalid_acct_ipv6_ipv4", required: true, disabled: false, err: nil, wantDefaultIP: true},
 		{accountID: "invalid_acct_dsa", required: false, disabled: false, err: &errortypes.MalformedAcct{}},
+		{accountID: "invalid_acct_endpoint", required: false, disabled: false, err: &errortypes.MalformedAcct{}},
+		{accountID: "invalid_acct_alias", required: false, disabled: false, err: &errortypes.MalformedAcct{}},
 
 		// pubID given and matches a host account explicitly disabled (Disabled: true on account json)
 		{accountID: "disabled_acct", required: false, disabled: false, err: &errortypes.AccountDisabled{}},
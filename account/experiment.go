@@ -0,0 +1,147 @@
+package account
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+)
+
+// ApplyExperiment assigns the request to one of the arms of the account experiment, then applies the arm patches
+// over a copy of the account config and over the ext.prebid of the request. The arm is written to
+// ext.prebid.experiment.arm. It returns the account to use for the request and the arm, which is nil when the
+// account runs no experiment.
+func ApplyExperiment(account *config.Account, req *openrtb_ext.RequestWrapper) (*config.Account, *openrtb_ext.ExperimentArm, error) {
+	if account == nil || !account.Experiments.Enabled {
+		return account, nil, nil
+	}
+
+	arm := selectExperimentArm(account.Experiments, req)
+	if arm == nil {
+		return account, nil, nil
+	}
+	experimentArm := &openrtb_ext.ExperimentArm{Experiment: account.Experiments.Name, Name: arm.Name}
+
+	patchedAccount, err := patchAccount(account, arm.Account)
+	if err != nil {
+		return account, nil, fmt.Errorf("experiment %s arm %s: account patch: %v", experimentArm.Experiment, arm.Name, err)
+	}
+	if err := patchRequestPrebid(req, arm.Request, experimentArm); err != nil {
+		return account, nil, fmt.Errorf("experiment %s arm %s: request patch: %v", experimentArm.Experiment, arm.Name, err)
+	}
+
+	return patchedAccount, experimentArm, nil
+}
+
+// selectExperimentArm deterministically maps the assignment key of the request to an arm, in proportion of the arm
+// weights. Requests with the same key are always assigned to the same arm as long as the arms don't change.
+func selectExperimentArm(experiments config.AccountExperiments, req *openrtb_ext.RequestWrapper) *config.AccountExperimentArm {
+	totalWeight := 0
+	for _, arm := range experiments.Arms {
+		if arm.Weight > 0 {
+			totalWeight += arm.Weight
+		}
+	}
+	if totalWeight == 0 {
+		return nil
+	}
+
+	key := req.ID
+	if experiments.AssignBy != config.ExperimentAssignByRequest && req.User != nil && req.User.ID != "" {
+		key = req.User.ID
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(experiments.Name))
+	hash.Write([]byte(key))
+	bucket := int(hash.Sum32() % uint32(totalWeight))
+
+	for i := range experiments.Arms {
+		if experiments.Arms[i].Weight <= 0 {
+			continue
+		}
+		if bucket < experiments.Arms[i].Weight {
+			return &experiments.Arms[i]
+		}
+		bucket -= experiments.Arms[i].Weight
+	}
+	return nil
+}
+
+// patchAccount returns a copy of the account with the merge patch applied, or the account itself without a patch
+func patchAccount(account *config.Account, patch json.RawMessage) (*config.Account, error) {
+	if len(patch) == 0 {
+		return account, nil
+	}
+
+	accountJSON, err := jsonutil.Marshal(account)
+	if err != nil {
+		return nil, err
+	}
+	patchedJSON, err := jsonpatch.MergePatch(accountJSON, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	patchedAccount := &config.Account{}
+	if err := jsonutil.UnmarshalValid(patchedJSON, patchedAccount); err != nil {
+		return nil, err
+	}
+	if err := config.UnpackDSADefault(patchedAccount.Privacy.DSA); err != nil {
+		return nil, err
+	}
+	if endpointErrs := patchedAccount.BidderEndpoints.Validate(nil); len(endpointErrs) > 0 {
+		return nil, fmt.Errorf("bidder endpoints: %v", endpointErrs[0])
+	}
+	if aliasErrs := patchedAccount.BidderAliases.Validate(nil); len(aliasErrs) > 0 {
+		return nil, fmt.Errorf("bidder aliases: %v", aliasErrs[0])
+	}
+	// the account id can't be changed by an experiment
+	patchedAccount.ID = account.ID
+	setDerivedConfig(patchedAccount)
+	setDefaultIPMasking(patchedAccount)
+
+	return patchedAccount, nil
+}
+
+// patchRequestPrebid applies the merge patch over the ext.prebid of the request and records the experiment arm
+func patchRequestPrebid(req *openrtb_ext.RequestWrapper, patch json.RawMessage, arm *openrtb_ext.ExperimentArm) error {
+	reqExt, err := req.GetRequestExt()
+	if err != nil {
+		return err
+	}
+
+	prebid := reqExt.GetPrebid()
+	if len(patch) > 0 {
+		prebidJSON := []byte("{}")
+		if prebid != nil {
+			if prebidJSON, err = jsonutil.Marshal(prebid); err != nil {
+				return err
+			}
+		}
+		patchedJSON, err := jsonpatch.MergePatch(prebidJSON, patch)
+		if err != nil {
+			return err
+		}
+		prebid = &openrtb_ext.ExtRequestPrebid{}
+		if err := jsonutil.UnmarshalValid(patchedJSON, prebid); err != nil {
+			return err
+		}
+	}
+
+	if prebid == nil {
+		prebid = &openrtb_ext.ExtRequestPrebid{}
+	}
+	experiment := openrtb_ext.Experiment{}
+	if prebid.Experiment != nil {
+		experiment = *prebid.Experiment
+	}
+	experiment.Arm = arm
+	prebid.Experiment = &experiment
+	reqExt.SetPrebid(prebid)
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package account
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/iputil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyExperiment(t *testing.T) {
+	testCases := []struct {
+		name              string
+		experiments       config.AccountExperiments
+		request           *openrtb2.BidRequest
+		expectedArm       *openrtb_ext.ExperimentArm
+		expectedBidLimit  int
+		expectedReqExt    string
+		expectedErrSubstr string
+	}{
+		{
+			name:             "disabled",
+			experiments:      config.AccountExperiments{Arms: []config.AccountExperimentArm{{Name: "test", Weight: 1, Account: json.RawMessage(`{"default_bid_limit":3}`)}}},
+			request:          &openrtb2.BidRequest{ID: "req1"},
+			expectedBidLimit: 1,
+		},
+		{
+			name:             "no-weight",
+			experiments:      config.AccountExperiments{Enabled: true, Name: "exp", Arms: []config.AccountExperimentArm{{Name: "test", Account: json.RawMessage(`{"default_bid_limit":3}`)}}},
+			request:          &openrtb2.BidRequest{ID: "req1"},
+			expectedBidLimit: 1,
+		},
+		{
+			name: "account-and-request-patches",
+			experiments: config.AccountExperiments{Enabled: true, Name: "exp", Arms: []config.AccountExperimentArm{
+				{Name: "test", Weight: 1, Account: json.RawMessage(`{"default_bid_limit":3}`), Request: json.RawMessage(`{"debug":true}`)},
+			}},
+			request:          &openrtb2.BidRequest{ID: "req1", Ext: json.RawMessage(`{"prebid":{"experiment":{"adscert":{"enabled":true}}}}`)},
+			expectedArm:      &openrtb_ext.ExperimentArm{Experiment: "exp", Name: "test"},
+			expectedBidLimit: 3,
+			expectedReqExt:   `{"prebid":{"debug":true,"experiment":{"adscert":{"enabled":true},"arm":{"experiment":"exp","name":"test"}}}}`,
+		},
+		{
+			name: "no-patches",
+			experiments: config.AccountExperiments{Enabled: true, Name: "exp", Arms: []config.AccountExperimentArm{
+				{Name: "control", Weight: 1},
+			}},
+			request:          &openrtb2.BidRequest{ID: "req1"},
+			expectedArm:      &openrtb_ext.ExperimentArm{Experiment: "exp", Name: "control"},
+			expectedBidLimit: 1,
+			expectedReqExt:   `{"prebid":{"experiment":{"arm":{"experiment":"exp","name":"control"}}}}`,
+		},
+		{
+			name: "malformed-account-patch",
+			experiments: config.AccountExperiments{Enabled: true, Name: "exp", Arms: []config.AccountExperimentArm{
+				{Name: "test", Weight: 1, Account: json.RawMessage(`{"default_bid_limit":"three"}`)},
+			}},
+			request:           &openrtb2.BidRequest{ID: "req1"},
+			expectedBidLimit:  1,
+			expectedErrSubstr: "experiment exp arm test: account patch",
+		},
+		{
+			name: "invalid-bidder-endpoint-patch",
+			experiments: config.AccountExperiments{Enabled: true, Name: "exp", Arms: []config.AccountExperimentArm{
+				{Name: "test", Weight: 1, Account: json.RawMessage(`{"bidder_endpoints":{"appnexus":{"endpoint":"{{.Region}}.example.com"}}}`)},
+			}},
+			request:           &openrtb2.BidRequest{ID: "req1"},
+			expectedBidLimit:  1,
+			expectedErrSubstr: "experiment exp arm test: account patch: bidder endpoints",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			account := &config.Account{ID: "pub", DefaultBidLimit: 1, Experiments: test.experiments}
+			req := &openrtb_ext.RequestWrapper{BidRequest: test.request}
+
+			gotAccount, gotArm, err := ApplyExperiment(account, req)
+			if test.expectedErrSubstr != "" {
+				assert.ErrorContains(t, err, test.expectedErrSubstr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedArm, gotArm)
+			assert.Equal(t, "pub", gotAccount.ID)
+			assert.Equal(t, test.expectedBidLimit, gotAccount.DefaultBidLimit)
+			assert.Equal(t, 1, account.DefaultBidLimit, "the account config should not be modified")
+
+			require.NoError(t, req.RebuildRequest())
+			if test.expectedReqExt != "" {
+				assert.JSONEq(t, test.expectedReqExt, string(req.Ext))
+			} else {
+				assert.Empty(t, req.Ext)
+			}
+		})
+	}
+}
+
+func TestSelectExperimentArm(t *testing.T) {
+	experiments := config.AccountExperiments{
+		Enabled: true,
+		Name:    "exp",
+		Arms: []config.AccountExperimentArm{
+			{Name: "control", Weight: 50},
+			{Name: "disabled", Weight: 0},
+			{Name: "test", Weight: 50},
+		},
+	}
+
+	t.Run("same-user-same-arm", func(t *testing.T) {
+		first := selectExperimentArm(experiments, &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "req1", User: &openrtb2.User{ID: "user1"}}})
+		for _, reqID := range []string{"req2", "req3", "req4"} {
+			arm := selectExperimentArm(experiments, &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: reqID, User: &openrtb2.User{ID: "user1"}}})
+			assert.Equal(t, first.Name, arm.Name)
+		}
+	})
+
+	t.Run("weighted-split", func(t *testing.T) {
+		counts := map[string]int{}
+		for i := 0; i < 1000; i++ {
+			reqID := string(rune('a'+i%26)) + string(rune('a'+i/26%26)) + string(rune('a'+i/676))
+			arm := selectExperimentArm(experiments, &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: reqID}})
+			counts[arm.Name]++
+		}
+		assert.Zero(t, counts["disabled"])
+		assert.InDelta(t, 500, counts["control"], 100)
+		assert.InDelta(t, 500, counts["test"], 100)
+	})
+
+	t.Run("assign-by-request", func(t *testing.T) {
+		byRequest := experiments
+		byRequest.AssignBy = config.ExperimentAssignByRequest
+		armUser1 := selectExperimentArm(byRequest, &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "req1", User: &openrtb2.User{ID: "user1"}}})
+		armUser2 := selectExperimentArm(byRequest, &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "req1", User: &openrtb2.User{ID: "user2"}}})
+		assert.Equal(t, armUser1.Name, armUser2.Name)
+	})
+}
+
+func TestPatchAccountIPMasking(t *testing.T) {
+	account := &config.Account{ID: "pub"}
+
+	patchedAccount, err := patchAccount(account, json.RawMessage(`{"privacy":{"ipv4":{"anon_keep_bits":40},"ipv6":{"anon_keep_bits":200}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, iputil.IPv4DefaultMaskingBitSize, patchedAccount.Privacy.IPv4Config.AnonKeepBits)
+	assert.Equal(t, iputil.IPv6DefaultMaskingBitSize, patchedAccount.Privacy.IPv6Config.AnonKeepBits)
+}
@@ -0,0 +1,305 @@
+package genericortb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"text/template"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/errortypes"
+	"github.com/prebid/prebid-server/v2/macros"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+const openRTBVersion25 = "2.5"
+
+type adapter struct {
+	endpoint      *template.Template
+	headers       http.Header
+	convertDown   bool
+	splitImps     bool
+	bidTypes      []string
+	paramsMapping []config.GenericORTBParamMapping
+}
+
+// Builder builds a new instance of the generic OpenRTB adapter for the given bidder with the given config.
+// The bidder is usually an alias of genericortb declared by a bidder info file.
+func Builder(bidderName openrtb_ext.BidderName, config config.Adapter, server config.Server) (adapters.Bidder, error) {
+	template, err := template.New("endpointTemplate").Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse endpoint url template: %v", err)
+	}
+
+	bidder := &adapter{
+		endpoint: template,
+		headers:  http.Header{},
+		bidTypes: defaultBidTypes,
+	}
+	bidder.headers.Set("Content-Type", "application/json;charset=utf-8")
+	bidder.headers.Set("Accept", "application/json")
+
+	if config.OpenRTB != nil && config.OpenRTB.Version != "" {
+		bidder.headers.Set("X-Openrtb-Version", config.OpenRTB.Version)
+		bidder.convertDown = config.OpenRTB.Version == openRTBVersion25
+	}
+
+	if genericORTB := config.GenericORTB; genericORTB != nil {
+		for name, value := range genericORTB.Headers {
+			bidder.headers.Set(name, value)
+		}
+		bidder.splitImps = genericORTB.SplitImps
+		if len(genericORTB.BidTypes) > 0 {
+			bidder.bidTypes = genericORTB.BidTypes
+		}
+		if bidder.paramsMapping, err = genericORTB.ParseParamsMapping(); err != nil {
+			return nil, err
+		}
+	}
+
+	return bidder, nil
+}
+
+var defaultBidTypes = []string{config.GenericORTBBidTypeMType, config.GenericORTBBidTypeExt, config.GenericORTBBidTypeImp}
+
+func (a *adapter) MakeRequests(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	impGroups := [][]openrtb2.Imp{request.Imp}
+	if a.splitImps {
+		impGroups = make([][]openrtb2.Imp, 0, len(request.Imp))
+		for _, imp := range request.Imp {
+			impGroups = append(impGroups, []openrtb2.Imp{imp})
+		}
+	}
+
+	var requests []*adapters.RequestData
+	var errs []error
+	for _, imps := range impGroups {
+		requestData, err := a.makeRequest(*request, imps)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		requests = append(requests, requestData)
+	}
+	return requests, errs
+}
+
+func (a *adapter) makeRequest(request openrtb2.BidRequest, imps []openrtb2.Imp) (*adapters.RequestData, error) {
+	request.Imp = imps
+
+	impParams := make([]map[string]json.RawMessage, len(imps))
+	for i := range imps {
+		params, err := getBidderParams(&imps[i])
+		if err != nil {
+			return nil, err
+		}
+		impParams[i] = params
+	}
+
+	body, err := a.marshalRequest(&request)
+	if err != nil {
+		return nil, err
+	}
+
+	// request and endpoint params are taken from the first imp, like a bidder sending a single request would
+	endpointParams := macros.EndpointTemplateParams{}
+	for _, mapping := range a.paramsMapping {
+		for i, params := range impParams {
+			value, ok := params[mapping.Param]
+			if !ok || (i > 0 && mapping.Target != config.GenericORTBTargetImp) {
+				continue
+			}
+			switch mapping.Target {
+			case config.GenericORTBTargetImp:
+				body, err = jsonparser.Set(body, value, append([]string{"imp", fmt.Sprintf("[%d]", i)}, mapping.Path...)...)
+			case config.GenericORTBTargetRequest:
+				body, err = jsonparser.Set(body, value, mapping.Path...)
+			case config.GenericORTBTargetEndpoint:
+				reflect.ValueOf(&endpointParams).Elem().FieldByName(mapping.Path[0]).SetString(paramToString(value))
+			}
+			if err != nil {
+				return nil, &errortypes.BadInput{Message: fmt.Sprintf("unable to map param %s: %v", mapping.Param, err)}
+			}
+		}
+	}
+
+	uri, err := macros.ResolveMacros(a.endpoint, endpointParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &adapters.RequestData{
+		Method:  http.MethodPost,
+		Uri:     uri,
+		Body:    body,
+		Headers: a.getHeaders(&request),
+		ImpIDs:  openrtb_ext.GetImpIDs(imps),
+	}, nil
+}
+
+// marshalRequest marshals the request in the OpenRTB version of the bidder. The request is converted down on a
+// copy, as the conversion modifies objects shared with the other bidders.
+func (a *adapter) marshalRequest(request *openrtb2.BidRequest) ([]byte, error) {
+	body, err := json.Marshal(request)
+	if err != nil || !a.convertDown {
+		return body, err
+	}
+
+	requestCopy := &openrtb2.BidRequest{}
+	if err := json.Unmarshal(body, requestCopy); err != nil {
+		return nil, err
+	}
+	requestWrapper := &openrtb_ext.RequestWrapper{BidRequest: requestCopy}
+	if err := openrtb_ext.ConvertDownTo25(requestWrapper); err != nil {
+		return nil, err
+	}
+	if err := requestWrapper.RebuildRequest(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(requestWrapper.BidRequest)
+}
+
+func (a *adapter) getHeaders(request *openrtb2.BidRequest) http.Header {
+	headers := a.headers.Clone()
+	if request.Device != nil {
+		if len(request.Device.UA) > 0 {
+			headers.Set("User-Agent", request.Device.UA)
+		}
+		if len(request.Device.IPv6) > 0 {
+			headers.Add("X-Forwarded-For", request.Device.IPv6)
+		}
+		if len(request.Device.IP) > 0 {
+			headers.Add("X-Forwarded-For", request.Device.IP)
+		}
+	}
+	return headers
+}
+
+func getBidderParams(imp *openrtb2.Imp) (map[string]json.RawMessage, error) {
+	var bidderExt adapters.ExtImpBidder
+	if err := json.Unmarshal(imp.Ext, &bidderExt); err != nil {
+		return nil, &errortypes.BadInput{
+			Message: fmt.Sprintf("imp %s: ext.bidder not provided", imp.ID),
+		}
+	}
+	var params map[string]json.RawMessage
+	if len(bidderExt.Bidder) > 0 {
+		if err := json.Unmarshal(bidderExt.Bidder, &params); err != nil {
+			return nil, &errortypes.BadInput{
+				Message: fmt.Sprintf("imp %s: invalid ext.bidder", imp.ID),
+			}
+		}
+	}
+	return params, nil
+}
+
+// paramToString returns JSON strings unquoted and other JSON values as they are
+func paramToString(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+	return string(value)
+}
+
+func (a *adapter) MakeBids(request *openrtb2.BidRequest, requestData *adapters.RequestData, responseData *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	if adapters.IsResponseStatusCodeNoContent(responseData) {
+		return nil, nil
+	}
+
+	if err := adapters.CheckResponseStatusCodeForErrors(responseData); err != nil {
+		return nil, []error{err}
+	}
+
+	var response openrtb2.BidResponse
+	if err := json.Unmarshal(responseData.Body, &response); err != nil {
+		return nil, []error{&errortypes.BadServerResponse{
+			Message: fmt.Sprintf("Bad server response: %v", err),
+		}}
+	}
+
+	bidResponse := adapters.NewBidderResponseWithBidsCapacity(len(request.Imp))
+	if response.Cur != "" {
+		bidResponse.Currency = response.Cur
+	}
+
+	var errs []error
+	for _, seatBid := range response.SeatBid {
+		for i := range seatBid.Bid {
+			bid := &seatBid.Bid[i]
+			bidType, err := a.getBidType(bid, request.Imp)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			bidResponse.Bids = append(bidResponse.Bids, &adapters.TypedBid{
+				Bid:     bid,
+				BidType: bidType,
+			})
+		}
+	}
+	return bidResponse, errs
+}
+
+// getBidType tries the bid type rules of the bidder in order until one of them finds the media type of the bid
+func (a *adapter) getBidType(bid *openrtb2.Bid, imps []openrtb2.Imp) (openrtb_ext.BidType, error) {
+	for _, rule := range a.bidTypes {
+		switch rule {
+		case config.GenericORTBBidTypeMType:
+			switch bid.MType {
+			case openrtb2.MarkupBanner:
+				return openrtb_ext.BidTypeBanner, nil
+			case openrtb2.MarkupVideo:
+				return openrtb_ext.BidTypeVideo, nil
+			case openrtb2.MarkupAudio:
+				return openrtb_ext.BidTypeAudio, nil
+			case openrtb2.MarkupNative:
+				return openrtb_ext.BidTypeNative, nil
+			}
+		case config.GenericORTBBidTypeExt:
+			if bidType, err := jsonparser.GetString(bid.Ext, "prebid", "type"); err == nil {
+				if parsedBidType, err := openrtb_ext.ParseBidType(bidType); err == nil {
+					return parsedBidType, nil
+				}
+			}
+		case config.GenericORTBBidTypeImp:
+			if bidType, ok := getImpMediaType(bid.ImpID, imps); ok {
+				return bidType, nil
+			}
+		}
+	}
+	return "", &errortypes.BadServerResponse{
+		Message: fmt.Sprintf("Unable to find the media type of bid %s for imp %s", bid.ID, bid.ImpID),
+	}
+}
+
+// getImpMediaType returns the media type of the imp if it has a single one
+func getImpMediaType(impID string, imps []openrtb2.Imp) (openrtb_ext.BidType, bool) {
+	for _, imp := range imps {
+		if imp.ID != impID {
+			continue
+		}
+		var mediaTypes []openrtb_ext.BidType
+		if imp.Banner != nil {
+			mediaTypes = append(mediaTypes, openrtb_ext.BidTypeBanner)
+		}
+		if imp.Video != nil {
+			mediaTypes = append(mediaTypes, openrtb_ext.BidTypeVideo)
+		}
+		if imp.Audio != nil {
+			mediaTypes = append(mediaTypes, openrtb_ext.BidTypeAudio)
+		}
+		if imp.Native != nil {
+			mediaTypes = append(mediaTypes, openrtb_ext.BidTypeNative)
+		}
+		if len(mediaTypes) == 1 {
+			return mediaTypes[0], true
+		}
+		return "", false
+	}
+	return "", false
+}
@@ -0,0 +1,162 @@
+package genericortb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/adapters/adapterstest"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJsonSamples(t *testing.T) {
+	bidder, buildErr := Builder(openrtb_ext.BidderGenericORTB, config.Adapter{
+		Endpoint: "http://{{.Host}}.example.com/openrtb?pub={{.PublisherID}}",
+		OpenRTB:  &config.OpenRTBInfo{Version: "2.5"},
+		GenericORTB: &config.GenericORTB{
+			Headers:   map[string]string{"X-Api-Key": "key"},
+			SplitImps: true,
+			ParamsMapping: map[string]string{
+				"region":      "endpoint.Host",
+				"publisherId": "endpoint.PublisherID",
+				"placementId": "imp.tagid",
+				"siteId":      "request.site.id",
+			},
+		}}, config.Server{ExternalUrl: "http://hosturl.com", GvlID: 1, DataCenter: "2"})
+
+	if buildErr != nil {
+		t.Fatalf("Builder returned unexpected error %v", buildErr)
+	}
+
+	adapterstest.RunJSONBidderTest(t, "genericortbtest", bidder)
+}
+
+func TestBuilderErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config config.Adapter
+	}{
+		{
+			name:   "malformed-endpoint",
+			config: config.Adapter{Endpoint: "{{Malformed}}"},
+		},
+		{
+			name:   "unknown-target",
+			config: config.Adapter{Endpoint: "http://example.com", GenericORTB: &config.GenericORTB{ParamsMapping: map[string]string{"id": "user.id"}}},
+		},
+		{
+			name:   "missing-path",
+			config: config.Adapter{Endpoint: "http://example.com", GenericORTB: &config.GenericORTB{ParamsMapping: map[string]string{"id": "imp"}}},
+		},
+		{
+			name:   "unknown-endpoint-macro",
+			config: config.Adapter{Endpoint: "http://example.com", GenericORTB: &config.GenericORTB{ParamsMapping: map[string]string{"id": "endpoint.Unknown"}}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			_, buildErr := Builder(openrtb_ext.BidderGenericORTB, test.config, config.Server{})
+			assert.Error(t, buildErr)
+		})
+	}
+}
+
+func TestMakeRequestsSingleRequest(t *testing.T) {
+	bidder, buildErr := Builder(openrtb_ext.BidderGenericORTB, config.Adapter{
+		Endpoint: "http://example.com/openrtb",
+		OpenRTB:  &config.OpenRTBInfo{Version: "2.6"},
+		GenericORTB: &config.GenericORTB{
+			ParamsMapping: map[string]string{"placementId": "imp.ext.placement"},
+		}}, config.Server{})
+	assert.NoError(t, buildErr)
+
+	request := &openrtb2.BidRequest{
+		ID: "req",
+		Imp: []openrtb2.Imp{
+			{ID: "imp1", Banner: &openrtb2.Banner{}, Ext: json.RawMessage(`{"bidder":{"placementId":"p1"}}`)},
+			{ID: "imp2", Banner: &openrtb2.Banner{}, Ext: json.RawMessage(`{"bidder":{"placementId":"p2"}}`)},
+		},
+		Regs: &openrtb2.Regs{GDPR: openrtb2.Int8Ptr(1)},
+	}
+
+	requests, errs := bidder.MakeRequests(request, &adapters.ExtraRequestInfo{})
+	assert.Empty(t, errs)
+	if assert.Len(t, requests, 1, "imps should be sent in a single request") {
+		assert.Equal(t, []string{"imp1", "imp2"}, requests[0].ImpIDs)
+		assert.Equal(t, "2.6", requests[0].Headers.Get("X-Openrtb-Version"))
+		assert.JSONEq(t, `{"id":"req","imp":[`+
+			`{"id":"imp1","banner":{},"ext":{"bidder":{"placementId":"p1"},"placement":"p1"}},`+
+			`{"id":"imp2","banner":{},"ext":{"bidder":{"placementId":"p2"},"placement":"p2"}}],`+
+			`"regs":{"gdpr":1}}`, string(requests[0].Body), "a 2.6 request should not be converted down")
+	}
+	assert.Equal(t, openrtb2.Int8Ptr(1), request.Regs.GDPR, "the request of the bidder should not be modified")
+}
+
+func TestGetBidType(t *testing.T) {
+	imps := []openrtb2.Imp{
+		{ID: "banner", Banner: &openrtb2.Banner{}},
+		{ID: "multiformat", Banner: &openrtb2.Banner{}, Video: &openrtb2.Video{}},
+	}
+
+	testCases := []struct {
+		name        string
+		bidTypes    []string
+		bid         openrtb2.Bid
+		expected    openrtb_ext.BidType
+		expectedErr bool
+	}{
+		{
+			name:     "mtype",
+			bidTypes: defaultBidTypes,
+			bid:      openrtb2.Bid{ImpID: "multiformat", MType: openrtb2.MarkupVideo},
+			expected: openrtb_ext.BidTypeVideo,
+		},
+		{
+			name:     "ext-prebid-type",
+			bidTypes: defaultBidTypes,
+			bid:      openrtb2.Bid{ImpID: "multiformat", Ext: json.RawMessage(`{"prebid":{"type":"native"}}`)},
+			expected: openrtb_ext.BidTypeNative,
+		},
+		{
+			name:     "imp",
+			bidTypes: defaultBidTypes,
+			bid:      openrtb2.Bid{ImpID: "banner"},
+			expected: openrtb_ext.BidTypeBanner,
+		},
+		{
+			name:     "rules-order",
+			bidTypes: []string{config.GenericORTBBidTypeImp, config.GenericORTBBidTypeMType},
+			bid:      openrtb2.Bid{ImpID: "banner", MType: openrtb2.MarkupVideo},
+			expected: openrtb_ext.BidTypeBanner,
+		},
+		{
+			name:        "rule-not-configured",
+			bidTypes:    []string{config.GenericORTBBidTypeImp},
+			bid:         openrtb2.Bid{ImpID: "multiformat", MType: openrtb2.MarkupVideo},
+			expectedErr: true,
+		},
+		{
+			name:        "multiformat-imp",
+			bidTypes:    defaultBidTypes,
+			bid:         openrtb2.Bid{ImpID: "multiformat"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			a := &adapter{bidTypes: test.bidTypes}
+			bidType, err := a.getBidType(&test.bid, imps)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, bidType)
+			}
+		})
+	}
+}
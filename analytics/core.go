@@ -31,6 +31,12 @@ type AuctionObject struct {
 	HookExecutionOutcome []hookexecution.StageOutcome
 	SeatNonBid           []openrtb_ext.SeatNonBid
 	RequestWrapper       *openrtb_ext.RequestWrapper
+	// LateBids are the bids received after the auction returned early, which are not part of the response
+	LateBids []openrtb2.SeatBid
+	// ShadowBids are the bids of the shadow bidders of the account, which did not compete in the auction
+	ShadowBids []openrtb2.SeatBid
+	// ExperimentArm is the arm of the account experiment the request was assigned to, nil if none
+	ExperimentArm *openrtb_ext.ExperimentArm
 }
 
 // Loggable object of a transaction at /openrtb2/amp endpoint
@@ -44,6 +50,12 @@ type AmpObject struct {
 	HookExecutionOutcome []hookexecution.StageOutcome
 	SeatNonBid           []openrtb_ext.SeatNonBid
 	RequestWrapper       *openrtb_ext.RequestWrapper
+	// LateBids are the bids received after the auction returned early, which are not part of the response
+	LateBids []openrtb2.SeatBid
+	// ShadowBids are the bids of the shadow bidders of the account, which did not compete in the auction
+	ShadowBids []openrtb2.SeatBid
+	// ExperimentArm is the arm of the account experiment the request was assigned to, nil if none
+	ExperimentArm *openrtb_ext.ExperimentArm
 }
 
 // Loggable object of a transaction at /openrtb2/video endpoint
@@ -56,6 +68,12 @@ type VideoObject struct {
 	StartTime      time.Time
 	SeatNonBid     []openrtb_ext.SeatNonBid
 	RequestWrapper *openrtb_ext.RequestWrapper
+	// LateBids are the bids received after the auction returned early, which are not part of the response
+	LateBids []openrtb2.SeatBid
+	// ShadowBids are the bids of the shadow bidders of the account, which did not compete in the auction
+	ShadowBids []openrtb2.SeatBid
+	// ExperimentArm is the arm of the account experiment the request was assigned to, nil if none
+	ExperimentArm *openrtb_ext.ExperimentArm
 }
 
 // Loggable object of a transaction at /setuid
@@ -0,0 +1,54 @@
+package bidvalidation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+)
+
+const AdomainValidatorName = "adomain"
+
+type adomainParams struct {
+	Blocked []string `json:"blocked"`
+}
+
+// adomainValidator rejects bids of blocked advertiser domains. A blocked domain also blocks its subdomains.
+type adomainValidator struct {
+	blocked []string
+}
+
+func NewAdomainValidator(params json.RawMessage) (BidValidator, error) {
+	var p adomainParams
+	if len(params) > 0 {
+		if err := jsonutil.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+	}
+
+	v := &adomainValidator{blocked: make([]string, 0, len(p.Blocked))}
+	for _, domain := range p.Blocked {
+		v.blocked = append(v.blocked, strings.ToLower(domain))
+	}
+	return v, nil
+}
+
+func (v *adomainValidator) Validate(bid *entities.PbsOrtbBid, imp *openrtb2.Imp) error {
+	for _, adomain := range bid.Bid.ADomain {
+		adomain = strings.ToLower(adomain)
+		for _, blocked := range v.blocked {
+			if adomain == blocked || strings.HasSuffix(adomain, "."+blocked) {
+				return fmt.Errorf("advertiser domain %s is blocked", adomain)
+			}
+		}
+	}
+	return nil
+}
+
+func (v *adomainValidator) NonBidReason() int {
+	return int(openrtb_ext.ResponseRejectedAdvertiserBlocked)
+}
@@ -0,0 +1,64 @@
+package bidvalidation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdomainValidator(t *testing.T) {
+	validator, err := NewAdomainValidator(json.RawMessage(`{"blocked":["Blocked.com"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, int(openrtb_ext.ResponseRejectedAdvertiserBlocked), validator.NonBidReason())
+
+	testCases := []struct {
+		description string
+		adomain     []string
+		expectedErr string
+	}{
+		{
+			description: "no_adomain",
+		},
+		{
+			description: "allowed_adomain",
+			adomain:     []string{"allowed.com", "notblocked.com"},
+		},
+		{
+			description: "blocked_adomain",
+			adomain:     []string{"allowed.com", "blocked.com"},
+			expectedErr: "advertiser domain blocked.com is blocked",
+		},
+		{
+			description: "blocked_adomain_case_insensitive",
+			adomain:     []string{"BLOCKED.com"},
+			expectedErr: "advertiser domain blocked.com is blocked",
+		},
+		{
+			description: "blocked_subdomain",
+			adomain:     []string{"www.blocked.com"},
+			expectedErr: "advertiser domain www.blocked.com is blocked",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			bid := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ADomain: test.adomain}}
+			err := validator.Validate(bid, &openrtb2.Imp{})
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestNewAdomainValidatorInvalidParams(t *testing.T) {
+	_, err := NewAdomainValidator(json.RawMessage(`{"blocked":"blocked.com"}`))
+	assert.Error(t, err)
+}
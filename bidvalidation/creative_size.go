@@ -0,0 +1,44 @@
+package bidvalidation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+const CreativeSizeValidatorName = "creative_size"
+
+// creativeSizeValidator rejects banner bids whose size matches none of the formats of their imp. Bids without
+// a size are not checked.
+type creativeSizeValidator struct{}
+
+func NewCreativeSizeValidator(params json.RawMessage) (BidValidator, error) {
+	return &creativeSizeValidator{}, nil
+}
+
+func (v *creativeSizeValidator) Validate(bid *entities.PbsOrtbBid, imp *openrtb2.Imp) error {
+	if bid.BidType != openrtb_ext.BidTypeBanner || imp.Banner == nil || (bid.Bid.W == 0 && bid.Bid.H == 0) {
+		return nil
+	}
+
+	banner := imp.Banner
+	if len(banner.Format) == 0 && banner.W == nil && banner.H == nil {
+		return nil
+	}
+	for _, format := range banner.Format {
+		if format.W == bid.Bid.W && format.H == bid.Bid.H {
+			return nil
+		}
+	}
+	if banner.W != nil && banner.H != nil && *banner.W == bid.Bid.W && *banner.H == bid.Bid.H {
+		return nil
+	}
+	return fmt.Errorf("creative size %dx%d does not match any format of imp %s", bid.Bid.W, bid.Bid.H, imp.ID)
+}
+
+func (v *creativeSizeValidator) NonBidReason() int {
+	return int(openrtb_ext.ResponseRejectedCreativeSizeNotAllowed)
+}
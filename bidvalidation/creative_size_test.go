@@ -0,0 +1,77 @@
+package bidvalidation
+
+import (
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreativeSizeValidator(t *testing.T) {
+	validator, err := NewCreativeSizeValidator(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int(openrtb_ext.ResponseRejectedCreativeSizeNotAllowed), validator.NonBidReason())
+
+	formatsImp := &openrtb2.Imp{ID: "imp1", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}, {W: 728, H: 90}}}}
+	sizeImp := &openrtb2.Imp{ID: "imp1", Banner: &openrtb2.Banner{W: ptrutil.ToPtr[int64](320), H: ptrutil.ToPtr[int64](50)}}
+
+	testCases := []struct {
+		description string
+		bid         *entities.PbsOrtbBid
+		imp         *openrtb2.Imp
+		expectedErr string
+	}{
+		{
+			description: "matching_format",
+			bid:         &entities.PbsOrtbBid{BidType: openrtb_ext.BidTypeBanner, Bid: &openrtb2.Bid{W: 728, H: 90}},
+			imp:         formatsImp,
+		},
+		{
+			description: "matching_banner_size",
+			bid:         &entities.PbsOrtbBid{BidType: openrtb_ext.BidTypeBanner, Bid: &openrtb2.Bid{W: 320, H: 50}},
+			imp:         sizeImp,
+		},
+		{
+			description: "bid_without_size",
+			bid:         &entities.PbsOrtbBid{BidType: openrtb_ext.BidTypeBanner, Bid: &openrtb2.Bid{}},
+			imp:         formatsImp,
+		},
+		{
+			description: "imp_without_size",
+			bid:         &entities.PbsOrtbBid{BidType: openrtb_ext.BidTypeBanner, Bid: &openrtb2.Bid{W: 300, H: 600}},
+			imp:         &openrtb2.Imp{ID: "imp1", Banner: &openrtb2.Banner{}},
+		},
+		{
+			description: "video_bid",
+			bid:         &entities.PbsOrtbBid{BidType: openrtb_ext.BidTypeVideo, Bid: &openrtb2.Bid{W: 640, H: 480}},
+			imp:         formatsImp,
+		},
+		{
+			description: "size_not_in_formats",
+			bid:         &entities.PbsOrtbBid{BidType: openrtb_ext.BidTypeBanner, Bid: &openrtb2.Bid{W: 300, H: 600}},
+			imp:         formatsImp,
+			expectedErr: "creative size 300x600 does not match any format of imp imp1",
+		},
+		{
+			description: "size_not_banner_size",
+			bid:         &entities.PbsOrtbBid{BidType: openrtb_ext.BidTypeBanner, Bid: &openrtb2.Bid{W: 300, H: 50}},
+			imp:         sizeImp,
+			expectedErr: "creative size 300x50 does not match any format of imp imp1",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			err := validator.Validate(test.bid, test.imp)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedErr)
+			}
+		})
+	}
+}
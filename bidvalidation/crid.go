@@ -0,0 +1,30 @@
+package bidvalidation
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+const CridValidatorName = "crid"
+
+// cridValidator rejects bids without a creative id.
+type cridValidator struct{}
+
+func NewCridValidator(params json.RawMessage) (BidValidator, error) {
+	return &cridValidator{}, nil
+}
+
+func (v *cridValidator) Validate(bid *entities.PbsOrtbBid, imp *openrtb2.Imp) error {
+	if bid.Bid.CrID == "" {
+		return errors.New("creative id is missing")
+	}
+	return nil
+}
+
+func (v *cridValidator) NonBidReason() int {
+	return int(openrtb_ext.ResponseRejectedInvalidCreative)
+}
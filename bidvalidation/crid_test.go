@@ -0,0 +1,20 @@
+package bidvalidation
+
+import (
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCridValidator(t *testing.T) {
+	validator, err := NewCridValidator(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int(openrtb_ext.ResponseRejectedInvalidCreative), validator.NonBidReason())
+
+	assert.NoError(t, validator.Validate(&entities.PbsOrtbBid{Bid: &openrtb2.Bid{CrID: "creative1"}}, &openrtb2.Imp{}))
+	assert.EqualError(t, validator.Validate(&entities.PbsOrtbBid{Bid: &openrtb2.Bid{}}, &openrtb2.Imp{}), "creative id is missing")
+}
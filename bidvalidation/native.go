@@ -0,0 +1,85 @@
+package bidvalidation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	nativeRequests "github.com/prebid/openrtb/v20/native1/request"
+	nativeResponse "github.com/prebid/openrtb/v20/native1/response"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+)
+
+const NativeValidatorName = "native"
+
+// nativeValidator rejects native bids whose markup misses a required asset of the native request, or has
+// an asset which is not in the native request.
+type nativeValidator struct{}
+
+func NewNativeValidator(params json.RawMessage) (BidValidator, error) {
+	return &nativeValidator{}, nil
+}
+
+// nativeWrapper is the root object of the native 1.0 request and response, which wraps the native object.
+type nativeWrapper struct {
+	Native json.RawMessage `json:"native"`
+}
+
+func (v *nativeValidator) Validate(bid *entities.PbsOrtbBid, imp *openrtb2.Imp) error {
+	if bid.BidType != openrtb_ext.BidTypeNative || imp.Native == nil {
+		return nil
+	}
+
+	var nativeRequest nativeRequests.Request
+	if err := jsonutil.Unmarshal(unwrapNative(json.RawMessage(imp.Native.Request)), &nativeRequest); err != nil {
+		// the native request of the imp is validated by the auction endpoint
+		return nil
+	}
+
+	var nativeMarkup nativeResponse.Response
+	if err := jsonutil.Unmarshal(unwrapNative(json.RawMessage(bid.Bid.AdM)), &nativeMarkup); err != nil {
+		return fmt.Errorf("native markup is not valid: %v", err)
+	}
+
+	requestAssets := make(map[int64]nativeRequests.Asset, len(nativeRequest.Assets))
+	for _, asset := range nativeRequest.Assets {
+		requestAssets[asset.ID] = asset
+	}
+
+	responseAssets := make(map[int64]struct{}, len(nativeMarkup.Assets))
+	for _, asset := range nativeMarkup.Assets {
+		if asset.ID == nil {
+			if len(nativeRequest.Assets) > 0 {
+				return errors.New("native markup has an asset without an ID")
+			}
+			continue
+		}
+		if _, ok := requestAssets[*asset.ID]; !ok {
+			return fmt.Errorf("native markup has an asset with ID:%d which is not in the request", *asset.ID)
+		}
+		responseAssets[*asset.ID] = struct{}{}
+	}
+
+	for _, asset := range nativeRequest.Assets {
+		if _, ok := responseAssets[asset.ID]; !ok && asset.Required == 1 {
+			return fmt.Errorf("native markup misses the required asset with ID:%d", asset.ID)
+		}
+	}
+	return nil
+}
+
+func (v *nativeValidator) NonBidReason() int {
+	return int(openrtb_ext.ResponseRejectedInvalidCreative)
+}
+
+// unwrapNative returns the native object of a native 1.0 payload, or the payload itself if it is not wrapped.
+func unwrapNative(payload json.RawMessage) json.RawMessage {
+	var wrapper nativeWrapper
+	if err := jsonutil.Unmarshal(payload, &wrapper); err == nil && len(wrapper.Native) > 0 {
+		return wrapper.Native
+	}
+	return payload
+}
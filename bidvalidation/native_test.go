@@ -0,0 +1,82 @@
+package bidvalidation
+
+import (
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNativeValidator(t *testing.T) {
+	validator, err := NewNativeValidator(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int(openrtb_ext.ResponseRejectedInvalidCreative), validator.NonBidReason())
+
+	nativeRequest := `{"assets":[{"id":1,"required":1,"title":{"len":90}},{"id":2,"img":{"type":3}}]}`
+
+	testCases := []struct {
+		description   string
+		nativeRequest string
+		adm           string
+		expectedErr   string
+	}{
+		{
+			description:   "all_assets",
+			nativeRequest: nativeRequest,
+			adm:           `{"assets":[{"id":1,"title":{"text":"title"}},{"id":2,"img":{"url":"https://example.com/img.png"}}]}`,
+		},
+		{
+			description:   "optional_asset_missing",
+			nativeRequest: nativeRequest,
+			adm:           `{"assets":[{"id":1,"title":{"text":"title"}}]}`,
+		},
+		{
+			description:   "wrapped_native",
+			nativeRequest: `{"native":` + nativeRequest + `}`,
+			adm:           `{"native":{"assets":[{"id":1,"title":{"text":"title"}}]}}`,
+		},
+		{
+			description:   "required_asset_missing",
+			nativeRequest: nativeRequest,
+			adm:           `{"assets":[{"id":2,"img":{"url":"https://example.com/img.png"}}]}`,
+			expectedErr:   "native markup misses the required asset with ID:1",
+		},
+		{
+			description:   "unknown_asset",
+			nativeRequest: nativeRequest,
+			adm:           `{"assets":[{"id":1,"title":{"text":"title"}},{"id":3,"data":{"value":"sponsor"}}]}`,
+			expectedErr:   "native markup has an asset with ID:3 which is not in the request",
+		},
+		{
+			description:   "asset_without_id",
+			nativeRequest: nativeRequest,
+			adm:           `{"assets":[{"title":{"text":"title"}}]}`,
+			expectedErr:   "native markup has an asset without an ID",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			bid := &entities.PbsOrtbBid{BidType: openrtb_ext.BidTypeNative, Bid: &openrtb2.Bid{AdM: test.adm}}
+			imp := &openrtb2.Imp{Native: &openrtb2.Native{Request: test.nativeRequest}}
+			err := validator.Validate(bid, imp)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestNativeValidatorInvalidMarkup(t *testing.T) {
+	validator, err := NewNativeValidator(nil)
+	require.NoError(t, err)
+
+	bid := &entities.PbsOrtbBid{BidType: openrtb_ext.BidTypeNative, Bid: &openrtb2.Bid{AdM: `<div>`}}
+	imp := &openrtb2.Imp{Native: &openrtb2.Native{Request: `{"assets":[]}`}}
+	assert.Error(t, validator.Validate(bid, imp))
+}
@@ -0,0 +1,105 @@
+package bidvalidation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+	"github.com/prebid/prebid-server/v2/util/maputil"
+)
+
+// BidValidator checks a bid returned by a bidder against the imp it was made for.
+type BidValidator interface {
+	// Validate returns an error describing why the bid is invalid, or nil if it is valid.
+	Validate(bid *entities.PbsOrtbBid, imp *openrtb2.Imp) error
+	// NonBidReason returns the seat non bid status code of the bids rejected by the validator.
+	NonBidReason() int
+}
+
+// Builder builds a bid validator from its params.
+type Builder func(params json.RawMessage) (BidValidator, error)
+
+// Registry maps validator names to their builders.
+type Registry map[string]Builder
+
+var (
+	buildersMutex sync.RWMutex
+	builders      = Registry{
+		AdomainValidatorName:      NewAdomainValidator,
+		CreativeSizeValidatorName: NewCreativeSizeValidator,
+		VastValidatorName:         NewVastValidator,
+		NativeValidatorName:       NewNativeValidator,
+		CridValidatorName:         NewCridValidator,
+	}
+)
+
+// Register makes a bid validator available to the host and account configs under the given name. It is meant to be
+// called by hosts before the exchange is built, and replaces any validator already registered under the same name.
+func Register(name string, builder Builder) {
+	buildersMutex.Lock()
+	defer buildersMutex.Unlock()
+	builders[name] = builder
+}
+
+// NewRegistry returns a registry of the built-in validators along with the validators registered by the host.
+func NewRegistry() Registry {
+	buildersMutex.RLock()
+	defer buildersMutex.RUnlock()
+	return maputil.Clone(builders)
+}
+
+// Validator is a bid validator built for an auction.
+type Validator struct {
+	BidValidator
+	Name string
+	// Enforce is true if the invalid bids are rejected, false if they are only reported
+	Enforce bool
+}
+
+// Build returns the validators configured, sorted by name. Validators in skip mode are left out.
+func (r Registry) Build(cfg map[string]config.BidValidator) ([]Validator, []error) {
+	names := make([]string, 0, len(cfg))
+	for name := range cfg {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var validators []Validator
+	var errs []error
+	for _, name := range names {
+		validatorCfg := cfg[name]
+		if validatorCfg.Mode != config.ValidationEnforce && validatorCfg.Mode != config.ValidationWarn {
+			continue
+		}
+
+		builder, ok := r[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown bid validator %s", name))
+			continue
+		}
+
+		params, err := jsonutil.Marshal(validatorCfg.Params)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid params for bid validator %s: %v", name, err))
+			continue
+		}
+
+		bidValidator, err := builder(params)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to build bid validator %s: %v", name, err))
+			continue
+		}
+
+		validators = append(validators, Validator{
+			BidValidator: bidValidator,
+			Name:         name,
+			Enforce:      validatorCfg.Mode == config.ValidationEnforce,
+		})
+	}
+	return validators, errs
+}
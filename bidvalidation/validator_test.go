@@ -0,0 +1,109 @@
+package bidvalidation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeValidator struct{}
+
+func (v *fakeValidator) Validate(bid *entities.PbsOrtbBid, imp *openrtb2.Imp) error {
+	return nil
+}
+
+func (v *fakeValidator) NonBidReason() int {
+	return 0
+}
+
+func TestRegistryBuild(t *testing.T) {
+	registry := NewRegistry()
+	registry["failing"] = func(params json.RawMessage) (BidValidator, error) {
+		return nil, errors.New("bad params")
+	}
+	registry["fake"] = func(params json.RawMessage) (BidValidator, error) {
+		return &fakeValidator{}, nil
+	}
+
+	testCases := []struct {
+		description   string
+		cfg           map[string]config.BidValidator
+		expectedNames []string
+		expectedModes []bool
+		expectedErrs  []error
+	}{
+		{
+			description: "nil_config",
+		},
+		{
+			description: "validators_sorted_by_name",
+			cfg: map[string]config.BidValidator{
+				CridValidatorName:    {Mode: config.ValidationWarn},
+				AdomainValidatorName: {Mode: config.ValidationEnforce, Params: map[string]interface{}{"blocked": []string{"a.com"}}},
+				"fake":               {Mode: config.ValidationEnforce},
+			},
+			expectedNames: []string{AdomainValidatorName, CridValidatorName, "fake"},
+			expectedModes: []bool{true, false, true},
+		},
+		{
+			description: "skipped_validators_left_out",
+			cfg: map[string]config.BidValidator{
+				CridValidatorName: {Mode: config.ValidationSkip},
+				VastValidatorName: {Mode: config.ValidationWarn},
+			},
+			expectedNames: []string{VastValidatorName},
+			expectedModes: []bool{false},
+		},
+		{
+			description: "unknown_validator",
+			cfg: map[string]config.BidValidator{
+				"unknown": {Mode: config.ValidationEnforce},
+			},
+			expectedErrs: []error{errors.New("unknown bid validator unknown")},
+		},
+		{
+			description: "builder_error",
+			cfg: map[string]config.BidValidator{
+				"failing": {Mode: config.ValidationEnforce},
+			},
+			expectedErrs: []error{errors.New("failed to build bid validator failing: bad params")},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			validators, errs := registry.Build(test.cfg)
+
+			var names []string
+			var modes []bool
+			for _, validator := range validators {
+				names = append(names, validator.Name)
+				modes = append(modes, validator.Enforce)
+			}
+			assert.Equal(t, test.expectedNames, names)
+			assert.Equal(t, test.expectedModes, modes)
+			assert.Equal(t, test.expectedErrs, errs)
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("registered", func(params json.RawMessage) (BidValidator, error) {
+		return &fakeValidator{}, nil
+	})
+
+	registry := NewRegistry()
+	assert.Contains(t, registry, "registered")
+	assert.Contains(t, registry, CridValidatorName)
+
+	// registries are independent of each other
+	registry["added"] = func(params json.RawMessage) (BidValidator, error) {
+		return &fakeValidator{}, nil
+	}
+	assert.NotContains(t, NewRegistry(), "added")
+}
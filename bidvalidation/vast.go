@@ -0,0 +1,56 @@
+package bidvalidation
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+const VastValidatorName = "vast"
+
+// vastValidator rejects video bids whose markup is not a well formed VAST document. Bids without markup,
+// which are fetched through their nurl, are not checked.
+type vastValidator struct{}
+
+func NewVastValidator(params json.RawMessage) (BidValidator, error) {
+	return &vastValidator{}, nil
+}
+
+func (v *vastValidator) Validate(bid *entities.PbsOrtbBid, imp *openrtb2.Imp) error {
+	if bid.BidType != openrtb_ext.BidTypeVideo || bid.Bid.AdM == "" {
+		return nil
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(bid.Bid.AdM))
+	rootFound := false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("VAST markup is not valid XML: %v", err)
+		}
+		if start, ok := token.(xml.StartElement); ok && !rootFound {
+			if start.Name.Local != "VAST" {
+				return fmt.Errorf("VAST markup root element is %s", start.Name.Local)
+			}
+			rootFound = true
+		}
+	}
+	if !rootFound {
+		return errors.New("VAST markup has no VAST element")
+	}
+	return nil
+}
+
+func (v *vastValidator) NonBidReason() int {
+	return int(openrtb_ext.ResponseRejectedInvalidCreative)
+}
@@ -0,0 +1,65 @@
+package bidvalidation
+
+import (
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVastValidator(t *testing.T) {
+	validator, err := NewVastValidator(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int(openrtb_ext.ResponseRejectedInvalidCreative), validator.NonBidReason())
+
+	testCases := []struct {
+		description string
+		bidType     openrtb_ext.BidType
+		adm         string
+		expectedErr bool
+	}{
+		{
+			description: "valid_vast",
+			bidType:     openrtb_ext.BidTypeVideo,
+			adm:         `<?xml version="1.0"?><VAST version="4.0"><Ad id="1"><InLine></InLine></Ad></VAST>`,
+		},
+		{
+			description: "no_markup",
+			bidType:     openrtb_ext.BidTypeVideo,
+		},
+		{
+			description: "banner_bid",
+			bidType:     openrtb_ext.BidTypeBanner,
+			adm:         `<div>`,
+		},
+		{
+			description: "malformed_xml",
+			bidType:     openrtb_ext.BidTypeVideo,
+			adm:         `<VAST version="4.0"><Ad></VAST>`,
+			expectedErr: true,
+		},
+		{
+			description: "not_vast_root",
+			bidType:     openrtb_ext.BidTypeVideo,
+			adm:         `<VMAP></VMAP>`,
+			expectedErr: true,
+		},
+		{
+			description: "not_xml",
+			bidType:     openrtb_ext.BidTypeVideo,
+			adm:         `https://example.com/vast.xml`,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			bid := &entities.PbsOrtbBid{BidType: test.bidType, Bid: &openrtb2.Bid{AdM: test.adm}}
+			err := validator.Validate(bid, &openrtb2.Imp{Video: &openrtb2.Video{}})
+			assert.Equal(t, test.expectedErr, err != nil, err)
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package capture
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+)
+
+// Auction is an auction captured with everything needed to replay it offline: the request as received by the
+// exchange, the resolved account, the currency rates and user syncs in effect, and the http calls made to the
+// bidders along with their responses. The request is captured before the privacy policies are enforced, so it
+// holds the raw personal data of the user, ip addresses and device ids included. Credentials are redacted from
+// the headers of the http calls.
+type Auction struct {
+	ID        string                        `json:"id"`
+	Timestamp time.Time                     `json:"timestamp"`
+	Request   json.RawMessage               `json:"request"`
+	Account   json.RawMessage               `json:"account"`
+	Rates     map[string]map[string]float64 `json:"rates,omitempty"`
+	UIDs      UIDs                          `json:"uids,omitempty"`
+	Calls     map[string][]HttpCall         `json:"calls,omitempty"`
+	Response  json.RawMessage               `json:"response,omitempty"`
+}
+
+// HttpCall is an http call made to a bidder. Bodies are kept as bytes as they aren't necessarily JSON.
+type HttpCall struct {
+	Method          string      `json:"method"`
+	Uri             string      `json:"uri"`
+	RequestBody     []byte      `json:"requestbody,omitempty"`
+	RequestHeaders  http.Header `json:"requestheaders,omitempty"`
+	StatusCode      int         `json:"status,omitempty"`
+	ResponseBody    []byte      `json:"responsebody,omitempty"`
+	ResponseHeaders http.Header `json:"responseheaders,omitempty"`
+	Error           string      `json:"error,omitempty"`
+	ErrorCode       int         `json:"errorcode,omitempty"`
+}
+
+// UIDs holds the user sync ids of the auction by syncer key.
+type UIDs map[string]string
+
+// GetUID returns the user sync id of the syncer key.
+func (uids UIDs) GetUID(key string) (string, bool, bool) {
+	uid, ok := uids[key]
+	return uid, ok, ok
+}
+
+// HasAnyLiveSyncs reports whether the auction had any user sync id.
+func (uids UIDs) HasAnyLiveSyncs() bool {
+	return len(uids) > 0
+}
+
+// Capturer decides which auctions are captured and writes them to its sink. Captured auctions are written in the
+// background so the auctions don't wait for the sink: they're buffered and dropped if the buffer is full.
+type Capturer struct {
+	samplingRate float64
+	sink         Sink
+	random       func() float64
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan *Auction
+	done    chan struct{}
+	dropped int64
+}
+
+// NewCapturer returns the capturer configured by the host, or nil if auction capture is disabled.
+func NewCapturer(cfg config.AuctionCapture) *Capturer {
+	if !cfg.Enabled {
+		return nil
+	}
+	glog.Warningf("Auction capture is enabled: %s holds the raw personal data of the captured requests", cfg.File)
+	return newCapturer(cfg.SamplingRate, NewFileSink(cfg.File, cfg.MaxFileBytes, cfg.MaxBackups), cfg.BufferSize)
+}
+
+func newCapturer(samplingRate float64, sink Sink, bufferSize int) *Capturer {
+	c := &Capturer{
+		samplingRate: samplingRate,
+		sink:         sink,
+		random:       rand.Float64,
+		queue:        make(chan *Auction, bufferSize),
+		done:         make(chan struct{}),
+	}
+	go c.write()
+	return c
+}
+
+func (c *Capturer) write() {
+	defer close(c.done)
+	for auction := range c.queue {
+		if dropped := atomic.SwapInt64(&c.dropped, 0); dropped > 0 {
+			glog.Warningf("%d captured auctions were dropped as the auction capture buffer was full", dropped)
+		}
+		if err := c.sink.Write(auction); err != nil {
+			glog.Errorf("Unable to write captured auction %s: %v", auction.ID, err)
+		}
+	}
+}
+
+// Close waits for the buffered auctions to be written and closes the sink. Auctions finished afterwards are
+// dropped.
+func (c *Capturer) Close() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	close(c.queue)
+	c.mu.Unlock()
+	<-c.done
+
+	if closer, ok := c.sink.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			glog.Errorf("Unable to close the auction capture sink: %v", err)
+		}
+	}
+}
+
+// Start returns a recorder for the auction if it's sampled for capture, or nil otherwise. The request is
+// captured as it is when Start is called.
+func (c *Capturer) Start(request *openrtb_ext.RequestWrapper, account *config.Account, rates map[string]map[string]float64, uids UIDs) *Recorder {
+	if c == nil || request == nil || c.random() >= c.samplingRate {
+		return nil
+	}
+
+	if err := request.RebuildRequest(); err != nil {
+		glog.Errorf("Unable to capture auction: %v", err)
+		return nil
+	}
+	requestJSON, err := jsonutil.Marshal(request.BidRequest)
+	if err != nil {
+		glog.Errorf("Unable to capture auction: %v", err)
+		return nil
+	}
+	accountJSON, err := jsonutil.Marshal(account)
+	if err != nil {
+		glog.Errorf("Unable to capture auction: %v", err)
+		return nil
+	}
+
+	return &Recorder{
+		auction: &Auction{
+			ID:        request.ID,
+			Timestamp: time.Now().UTC(),
+			Request:   requestJSON,
+			Account:   accountJSON,
+			Rates:     rates,
+			UIDs:      uids,
+			Calls:     make(map[string][]HttpCall),
+		},
+	}
+}
+
+// Finish captures the auction response and queues the auction recorded by the recorder to be written to the sink.
+func (c *Capturer) Finish(recorder *Recorder, response interface{}) {
+	if c == nil || recorder == nil {
+		return
+	}
+
+	auction := recorder.finish()
+	if auction == nil {
+		return
+	}
+	responseJSON, err := jsonutil.Marshal(response)
+	if err != nil {
+		glog.Errorf("Unable to capture the response of auction %s: %v", auction.ID, err)
+		return
+	}
+	auction.Response = responseJSON
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.queue <- auction:
+	default:
+		atomic.AddInt64(&c.dropped, 1)
+	}
+}
+
+// Recorder collects the http calls of a captured auction. It's safe for concurrent use by the bidders and
+// all of its methods can be called on a nil recorder, which records nothing.
+type Recorder struct {
+	mu       sync.Mutex
+	auction  *Auction
+	finished bool
+}
+
+// RecordCall records an http call made to the bidder. Calls made after the auction finished, such as the calls
+// of bidders which responded after an early return, are not recorded.
+func (r *Recorder) RecordCall(bidder string, call HttpCall) {
+	if r == nil {
+		return
+	}
+
+	call.RequestHeaders = redactHeaders(call.RequestHeaders)
+	call.ResponseHeaders = redactHeaders(call.ResponseHeaders)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.finished {
+		r.auction.Calls[bidder] = append(r.auction.Calls[bidder], call)
+	}
+}
+
+// redactedHeaderValue replaces the values of the headers carrying credentials
+const redactedHeaderValue = "[redacted]"
+
+// credentialHeaders are the headers known to carry credentials, other headers are redacted based on their name
+var credentialHeaders = map[string]struct{}{
+	"Authorization":       {},
+	"Proxy-Authorization": {},
+	"Cookie":              {},
+	"Set-Cookie":          {},
+}
+
+var credentialHeaderNameParts = []string{"auth", "token", "secret", "password", "api-key", "apikey"}
+
+// redactHeaders returns a copy of the headers with the values of the headers carrying credentials redacted, such
+// as the authorization headers set by the adapters.
+func redactHeaders(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+
+	redacted := h.Clone()
+	for name, values := range redacted {
+		if !isCredentialHeader(name) {
+			continue
+		}
+		for i := range values {
+			values[i] = redactedHeaderValue
+		}
+	}
+	return redacted
+}
+
+func isCredentialHeader(name string) bool {
+	if _, ok := credentialHeaders[http.CanonicalHeaderKey(name)]; ok {
+		return true
+	}
+	lowerName := strings.ToLower(name)
+	for _, part := range credentialHeaderNameParts {
+		if strings.Contains(lowerName, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recorder) finish() *Auction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.finished {
+		return nil
+	}
+	r.finished = true
+	return r.auction
+}
@@ -0,0 +1,190 @@
+package capture
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSink struct {
+	auctions []*Auction
+	// written, if set, is waited for before an auction is written
+	written chan struct{}
+}
+
+func (s *fakeSink) Write(auction *Auction) error {
+	if s.written != nil {
+		<-s.written
+	}
+	s.auctions = append(s.auctions, auction)
+	return nil
+}
+
+func newTestCapturer(sink Sink, bufferSize int, random float64) *Capturer {
+	capturer := newCapturer(1, sink, bufferSize)
+	capturer.random = func() float64 { return random }
+	return capturer
+}
+
+func TestNewCapturer(t *testing.T) {
+	assert.Nil(t, NewCapturer(config.AuctionCapture{Enabled: false, SamplingRate: 1, File: "capture.jsonl"}))
+
+	capturer := NewCapturer(config.AuctionCapture{Enabled: true, SamplingRate: 1, File: "capture.jsonl", MaxFileBytes: 1024, BufferSize: 10})
+	if assert.NotNil(t, capturer) {
+		assert.Equal(t, 10, cap(capturer.queue))
+		capturer.Close()
+	}
+}
+
+func TestCapturerSampling(t *testing.T) {
+	testCases := []struct {
+		description      string
+		samplingRate     float64
+		random           float64
+		expectedCaptured bool
+	}{
+		{
+			description:      "sampled",
+			samplingRate:     0.1,
+			random:           0.05,
+			expectedCaptured: true,
+		},
+		{
+			description:      "not_sampled",
+			samplingRate:     0.1,
+			random:           0.1,
+			expectedCaptured: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			capturer := newTestCapturer(&fakeSink{}, 1, test.random)
+			capturer.samplingRate = test.samplingRate
+			defer capturer.Close()
+
+			recorder := capturer.Start(&openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "req1"}}, &config.Account{ID: "acct"}, nil, nil)
+
+			assert.Equal(t, test.expectedCaptured, recorder != nil)
+		})
+	}
+}
+
+func TestCapturerStartFinish(t *testing.T) {
+	sink := &fakeSink{}
+	capturer := newTestCapturer(sink, 10, 0)
+	request := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "req1", Imp: []openrtb2.Imp{{ID: "imp1"}}}}
+	rates := map[string]map[string]float64{"USD": {"EUR": 0.9}}
+	uids := UIDs{"appnexus": "uid1"}
+
+	recorder := capturer.Start(request, &config.Account{ID: "acct"}, rates, uids)
+	// the request is captured as it was when the auction started
+	request.ID = "changed"
+	recorder.RecordCall("appnexus", HttpCall{Method: "POST", Uri: "https://appnexus.com", StatusCode: 204})
+	capturer.Finish(recorder, &openrtb2.BidResponse{ID: "req1"})
+	recorder.RecordCall("rubicon", HttpCall{Method: "POST", Uri: "https://rubicon.com", StatusCode: 204})
+	capturer.Finish(recorder, &openrtb2.BidResponse{ID: "req1"})
+	capturer.Close()
+
+	if assert.Len(t, sink.auctions, 1, "an auction is written once") {
+		auction := sink.auctions[0]
+		assert.Equal(t, "req1", auction.ID)
+		assert.JSONEq(t, `{"id":"req1","imp":[{"id":"imp1"}]}`, string(auction.Request))
+		var account config.Account
+		assert.NoError(t, json.Unmarshal(auction.Account, &account))
+		assert.Equal(t, "acct", account.ID)
+		assert.Equal(t, rates, auction.Rates)
+		assert.Equal(t, uids, auction.UIDs)
+		assert.Equal(t, map[string][]HttpCall{"appnexus": {{Method: "POST", Uri: "https://appnexus.com", StatusCode: 204}}}, auction.Calls, "calls after the auction finished are not recorded")
+		assert.JSONEq(t, `{"id":"req1"}`, string(auction.Response))
+	}
+}
+
+func TestRecordCallRedactsCredentials(t *testing.T) {
+	sink := &fakeSink{}
+	capturer := newTestCapturer(sink, 10, 0)
+	recorder := capturer.Start(&openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "req1"}}, &config.Account{}, nil, nil)
+
+	requestHeaders := http.Header{
+		"Authorization":     []string{"Basic dXNlcjpwYXNz"},
+		"X-Api-Key":         []string{"key"},
+		"X-Access-Token":    []string{"token"},
+		"Content-Type":      []string{"application/json"},
+		"X-Openrtb-Version": []string{"2.6"},
+	}
+	recorder.RecordCall("appnexus", HttpCall{
+		Method:          "POST",
+		Uri:             "https://appnexus.com",
+		RequestHeaders:  requestHeaders,
+		ResponseHeaders: http.Header{"Set-Cookie": []string{"uid=1"}, "Content-Type": []string{"application/json"}},
+	})
+	capturer.Finish(recorder, nil)
+	capturer.Close()
+
+	assert.Equal(t, "Basic dXNlcjpwYXNz", requestHeaders.Get("Authorization"), "the headers of the call must not be updated")
+	if assert.Len(t, sink.auctions, 1) && assert.Len(t, sink.auctions[0].Calls["appnexus"], 1) {
+		call := sink.auctions[0].Calls["appnexus"][0]
+		assert.Equal(t, http.Header{
+			"Authorization":     []string{"[redacted]"},
+			"X-Api-Key":         []string{"[redacted]"},
+			"X-Access-Token":    []string{"[redacted]"},
+			"Content-Type":      []string{"application/json"},
+			"X-Openrtb-Version": []string{"2.6"},
+		}, call.RequestHeaders)
+		assert.Equal(t, http.Header{"Set-Cookie": []string{"[redacted]"}, "Content-Type": []string{"application/json"}}, call.ResponseHeaders)
+	}
+}
+
+func TestCapturerDropsAuctionsWhenBufferIsFull(t *testing.T) {
+	sink := &fakeSink{written: make(chan struct{})}
+	capturer := newTestCapturer(sink, 1, 0)
+	request := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "req1"}}
+
+	// the first auction is taken by the writer, which waits for the sink, and the second one fills the buffer
+	capturer.Finish(capturer.Start(request, &config.Account{}, nil, nil), nil)
+	assert.Eventually(t, func() bool { return len(capturer.queue) == 0 }, time.Second, time.Millisecond)
+	capturer.Finish(capturer.Start(request, &config.Account{}, nil, nil), nil)
+	capturer.Finish(capturer.Start(request, &config.Account{}, nil, nil), nil)
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&capturer.dropped), "the auction finished while the buffer is full is dropped")
+	close(sink.written)
+	capturer.Close()
+	assert.Len(t, sink.auctions, 2, "the buffered auctions are written on close")
+
+	capturer.Finish(capturer.Start(request, &config.Account{}, nil, nil), nil)
+	assert.Len(t, sink.auctions, 2, "auctions finished after close are dropped")
+}
+
+func TestNilCapturerAndRecorder(t *testing.T) {
+	var capturer *Capturer
+	var recorder *Recorder
+
+	assert.Nil(t, capturer.Start(&openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{}}, &config.Account{}, nil, nil))
+	assert.NotPanics(t, func() {
+		recorder.RecordCall("appnexus", HttpCall{})
+		capturer.Finish(recorder, &openrtb2.BidResponse{})
+		capturer.Close()
+	})
+}
+
+func TestUIDs(t *testing.T) {
+	uids := UIDs{"appnexus": "uid1"}
+
+	uid, exists, notExpired := uids.GetUID("appnexus")
+	assert.Equal(t, "uid1", uid)
+	assert.True(t, exists)
+	assert.True(t, notExpired)
+
+	_, exists, _ = uids.GetUID("rubicon")
+	assert.False(t, exists)
+
+	assert.True(t, uids.HasAnyLiveSyncs())
+	assert.False(t, UIDs{}.HasAnyLiveSyncs())
+}
@@ -0,0 +1,52 @@
+package capture
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Replay serves the http calls of a captured auction in place of the bidders. It's safe for concurrent use.
+type Replay struct {
+	mu     sync.Mutex
+	calls  map[string][]HttpCall
+	served map[string][]bool
+}
+
+// NewReplay returns a replay of the http calls of the auction.
+func NewReplay(auction *Auction) *Replay {
+	served := make(map[string][]bool, len(auction.Calls))
+	for bidder, calls := range auction.Calls {
+		served[bidder] = make([]bool, len(calls))
+	}
+	return &Replay{calls: auction.Calls, served: served}
+}
+
+// Call returns the captured call of the bidder with the same method, uri and body as the request. If the request
+// changed since the auction was captured, the first captured call of the bidder not served yet is returned.
+// Each captured call is served once.
+func (r *Replay) Call(bidder, method, uri string, body []byte) (HttpCall, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := r.calls[bidder]
+	served := r.served[bidder]
+	match := -1
+	for i, call := range calls {
+		if served[i] {
+			continue
+		}
+		if call.Method == method && call.Uri == uri && bytes.Equal(call.RequestBody, body) {
+			match = i
+			break
+		}
+		if match == -1 {
+			match = i
+		}
+	}
+	if match == -1 {
+		return HttpCall{}, false
+	}
+
+	served[match] = true
+	return calls[match], true
+}
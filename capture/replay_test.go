@@ -0,0 +1,31 @@
+package capture
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayCall(t *testing.T) {
+	auction := &Auction{Calls: map[string][]HttpCall{
+		"appnexus": {
+			{Method: "POST", Uri: "https://appnexus.com/a", RequestBody: []byte("a"), StatusCode: 200},
+			{Method: "POST", Uri: "https://appnexus.com/b", RequestBody: []byte("b"), StatusCode: 204},
+		},
+	}}
+	replay := NewReplay(auction)
+
+	call, ok := replay.Call("appnexus", "POST", "https://appnexus.com/b", []byte("b"))
+	assert.True(t, ok)
+	assert.Equal(t, "https://appnexus.com/b", call.Uri, "the call matching the request is served")
+
+	call, ok = replay.Call("appnexus", "POST", "https://appnexus.com/b", []byte("changed"))
+	assert.True(t, ok)
+	assert.Equal(t, "https://appnexus.com/a", call.Uri, "the first call not served yet is served if no call matches")
+
+	_, ok = replay.Call("appnexus", "POST", "https://appnexus.com/a", []byte("a"))
+	assert.False(t, ok, "calls are served once")
+
+	_, ok = replay.Call("rubicon", "POST", "https://rubicon.com", nil)
+	assert.False(t, ok)
+}
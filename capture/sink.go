@@ -0,0 +1,133 @@
+package capture
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+)
+
+// Sink stores captured auctions.
+type Sink interface {
+	Write(auction *Auction) error
+}
+
+// FileSink appends captured auctions to a file, one JSON object per line. The file is created on the first write,
+// readable by its owner only as captured auctions hold personal data. Once the file would grow over maxBytes it's
+// rotated: it's renamed to path.1, the previous path.1 to path.2 and so on, and only maxBackups rotated files are
+// kept.
+type FileSink struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewFileSink returns a sink writing to the file at path. The file isn't rotated if maxBytes is 0.
+func NewFileSink(path string, maxBytes int64, maxBackups int) *FileSink {
+	return &FileSink{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+}
+
+func (s *FileSink) Write(auction *Auction) error {
+	line, err := jsonutil.Marshal(auction)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	if s.maxBytes > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate moves the current file to the first backup, shifting the other backups and removing the oldest one, and
+// opens a new file.
+func (s *FileSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return err
+	}
+
+	if s.maxBackups == 0 {
+		if err := os.Remove(s.path); err != nil {
+			return err
+		}
+		return s.open()
+	}
+	if err := os.Remove(s.backupPath(s.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := s.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(s.backupPath(i), s.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *FileSink) backupPath(i int) string {
+	return s.path + "." + strconv.Itoa(i)
+}
+
+// Close closes the file of the sink.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Read reads the auctions captured by a FileSink.
+func Read(r io.Reader) ([]*Auction, error) {
+	var auctions []*Auction
+	decoder := json.NewDecoder(r)
+	for {
+		auction := &Auction{}
+		if err := decoder.Decode(auction); err == io.EOF {
+			return auctions, nil
+		} else if err != nil {
+			return nil, err
+		}
+		auctions = append(auctions, auction)
+	}
+}
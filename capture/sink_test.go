@@ -0,0 +1,121 @@
+package capture
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	sink := NewFileSink(path, 0, 0)
+
+	first := &Auction{ID: "req1", Request: json.RawMessage(`{"id":"req1"}`), Calls: map[string][]HttpCall{
+		"appnexus": {{Method: "POST", Uri: "https://appnexus.com", RequestBody: []byte(`{"id":"req1"}`), StatusCode: 200, ResponseBody: []byte{0x1f, 0x8b}}},
+	}}
+	second := &Auction{ID: "req2", Request: json.RawMessage(`{"id":"req2"}`)}
+	assert.NoError(t, sink.Write(first))
+	assert.NoError(t, sink.Write(second))
+	assert.NoError(t, sink.Close())
+
+	info, err := os.Stat(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "captured auctions hold personal data")
+	}
+
+	file, err := os.Open(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer file.Close()
+	auctions, err := Read(file)
+
+	assert.NoError(t, err)
+	if assert.Len(t, auctions, 2) {
+		assert.Equal(t, "req1", auctions[0].ID)
+		assert.Equal(t, first.Calls, auctions[0].Calls, "bodies which aren't JSON are kept as is")
+		assert.Equal(t, "req2", auctions[1].ID)
+	}
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	readIDs := func(t *testing.T, path string) []string {
+		file, err := os.Open(path)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		defer file.Close()
+		auctions, err := Read(file)
+		assert.NoError(t, err)
+		ids := make([]string, 0, len(auctions))
+		for _, auction := range auctions {
+			ids = append(ids, auction.ID)
+		}
+		return ids
+	}
+
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	line, err := json.Marshal(&Auction{ID: "req1"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	// two auctions fit in a file
+	sink := NewFileSink(path, int64(2*(len(line)+1)), 2)
+	for _, id := range []string{"req1", "req2", "req3", "req4", "req5", "req6", "req7"} {
+		assert.NoError(t, sink.Write(&Auction{ID: id}))
+	}
+	assert.NoError(t, sink.Close())
+
+	assert.Equal(t, []string{"req7"}, readIDs(t, path))
+	assert.Equal(t, []string{"req5", "req6"}, readIDs(t, path+".1"))
+	assert.Equal(t, []string{"req3", "req4"}, readIDs(t, path+".2"))
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err), "only max backups rotated files are kept")
+
+	info, err := os.Stat(path + ".1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "rotated files keep their permissions")
+	}
+
+	// the size of an existing file is taken into account
+	sink = NewFileSink(path, int64(2*(len(line)+1)), 2)
+	assert.NoError(t, sink.Write(&Auction{ID: "req8"}))
+	assert.NoError(t, sink.Write(&Auction{ID: "req9"}))
+	assert.NoError(t, sink.Close())
+	assert.Equal(t, []string{"req9"}, readIDs(t, path))
+	assert.Equal(t, []string{"req7", "req8"}, readIDs(t, path+".1"))
+}
+
+func TestFileSinkRotationWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	sink := NewFileSink(path, 1, 0)
+
+	assert.NoError(t, sink.Write(&Auction{ID: "req1"}))
+	assert.NoError(t, sink.Write(&Auction{ID: "req2"}))
+	assert.NoError(t, sink.Close())
+
+	content, err := os.ReadFile(path)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(content), `"req2"`)
+		assert.NotContains(t, string(content), `"req1"`)
+	}
+	_, err = os.Stat(path + ".1")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileSinkInvalidPath(t *testing.T) {
+	sink := NewFileSink(filepath.Join(t.TempDir(), "missing", "capture.jsonl"), 0, 0)
+
+	assert.Error(t, sink.Write(&Auction{ID: "req1"}))
+}
+
+func TestReadInvalid(t *testing.T) {
+	auctions, err := Read(strings.NewReader(`{"id":"req1"}` + "\n" + `{"id":`))
+
+	assert.Error(t, err)
+	assert.Nil(t, auctions)
+}
@@ -42,6 +42,11 @@ type Account struct {
 	DefaultBidLimit         int                                         `mapstructure:"default_bid_limit" json:"default_bid_limit"`
 	BidAdjustments          *openrtb_ext.ExtRequestPrebidBidAdjustments `mapstructure:"bidadjustments" json:"bidadjustments"`
 	Privacy                 AccountPrivacy                              `mapstructure:"privacy" json:"privacy"`
+	Auction                 AccountAuction                              `mapstructure:"auction" json:"auction"`
+	ShadowBidders           []string                                    `mapstructure:"shadow_bidders" json:"shadow_bidders"`
+	Experiments             AccountExperiments                          `mapstructure:"experiments" json:"experiments"`
+	BidderAliases           AccountBidderAliases                        `mapstructure:"bidder_aliases" json:"bidder_aliases,omitempty"`
+	BidderEndpoints         AccountBidderEndpoints                      `mapstructure:"bidder_endpoints" json:"bidder_endpoints,omitempty"`
 }
 
 // CookieSync represents the account-level defaults for the cookie sync endpoint.
@@ -309,6 +314,181 @@ func (a *AccountChannel) GetByChannelType(channelType ChannelType) *bool {
 	return channelEnabled
 }
 
+// AuctionPricingMode defines how the clearing price of a winning bid is determined
+type AuctionPricingMode string
+
+const (
+	// AuctionPricingFirstPrice makes the winning bid pay its own price
+	AuctionPricingFirstPrice AuctionPricingMode = "first_price"
+	// AuctionPricingSecondPrice makes the winning bid pay the second highest price plus an increment
+	AuctionPricingSecondPrice AuctionPricingMode = "second_price"
+	// AuctionPricingSoftFloor makes the winning bid above the floor pay the second highest price plus an increment,
+	// but not less than the floor, and the winning bid below the floor pay its own price
+	AuctionPricingSoftFloor AuctionPricingMode = "soft_floor"
+)
+
+// AccountAuction represents account-specific auction configuration
+type AccountAuction struct {
+	Pricing           AccountAuctionPricing           `mapstructure:"pricing" json:"pricing"`
+	EarlyReturn       AccountAuctionEarlyReturn       `mapstructure:"early_return" json:"early_return"`
+	MediaTypePriority AccountAuctionMediaTypePriority `mapstructure:"media_type_priority" json:"media_type_priority"`
+}
+
+// AccountAuctionMediaTypePriority favours some media types when selecting the winning bid of multi-format imps.
+// It's overridden by ext.prebid.targeting.mediatypepriority of the request.
+type AccountAuctionMediaTypePriority struct {
+	// Priority lists the media types from the most to the least favoured. Media types which are not listed
+	// only win when no listed media type was bid.
+	Priority []string `mapstructure:"priority" json:"priority"`
+	// Bias lets a less favoured media type win when its price is more than bias times the price of the favoured
+	// one. A zero bias always favours media types in priority order.
+	Bias float64 `mapstructure:"bias" json:"bias"`
+}
+
+func (mtp *AccountAuctionMediaTypePriority) validate(errs []error) []error {
+	for _, mediaType := range mtp.Priority {
+		if _, err := openrtb_ext.ParseBidType(mediaType); err != nil {
+			errs = append(errs, fmt.Errorf(`account_defaults.auction.media_type_priority.priority contains an invalid media type "%s"`, mediaType))
+		}
+	}
+	if mtp.Bias != 0 && mtp.Bias < 1 {
+		errs = append(errs, fmt.Errorf(`account_defaults.auction.media_type_priority.bias should be 0 or greater than or equal to 1`))
+	}
+	return errs
+}
+
+// AccountAuctionEarlyReturn makes the auction return before all bidders responded once the bids received are
+// good enough. Bidders which did not respond yet keep bidding in the background and their bids are passed to
+// the analytics modules.
+type AccountAuctionEarlyReturn struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// DealBidders makes the auction return once all of these bidders, when called, responded
+	DealBidders []string `mapstructure:"deal_bidders" json:"deal_bidders"`
+	// TargetCPM makes the auction return once every imp received a bid with at least this price
+	TargetCPM float64 `mapstructure:"target_cpm" json:"target_cpm"`
+}
+
+func (er *AccountAuctionEarlyReturn) validate(errs []error) []error {
+	if er.TargetCPM < 0 {
+		errs = append(errs, fmt.Errorf(`account_defaults.auction.early_return.target_cpm should be greater than or equal to 0`))
+	}
+	return errs
+}
+
+// AccountAuctionPricing represents the clearing price configuration of the auction
+type AccountAuctionPricing struct {
+	Mode AuctionPricingMode `mapstructure:"mode" json:"mode"`
+	// Increment is added to the second highest price when computing the clearing price
+	Increment float64 `mapstructure:"increment" json:"increment"`
+}
+
+func (p *AccountAuctionPricing) validate(errs []error) []error {
+	switch p.Mode {
+	case "", AuctionPricingFirstPrice, AuctionPricingSecondPrice, AuctionPricingSoftFloor:
+	default:
+		errs = append(errs, fmt.Errorf(`account_defaults.auction.pricing.mode must be one of "%s", "%s" or "%s"`, AuctionPricingFirstPrice, AuctionPricingSecondPrice, AuctionPricingSoftFloor))
+	}
+
+	if p.Increment < 0 {
+		errs = append(errs, fmt.Errorf(`account_defaults.auction.pricing.increment should be greater than or equal to 0`))
+	}
+
+	return errs
+}
+
+// ExperimentAssignment identifies the request field used to assign requests to the arms of an experiment
+type ExperimentAssignment string
+
+const (
+	// ExperimentAssignByUser assigns the requests of the same user.id to the same arm, falling back to the request id
+	ExperimentAssignByUser ExperimentAssignment = "user"
+	// ExperimentAssignByRequest assigns requests to arms by their request id
+	ExperimentAssignByRequest ExperimentAssignment = "request"
+)
+
+// AccountExperiments represents an A/B experiment splitting the traffic of the account across weighted arms.
+// Each arm patches the account config and the ext.prebid of the request, so that results can be compared per arm.
+type AccountExperiments struct {
+	Enabled  bool                   `mapstructure:"enabled" json:"enabled"`
+	Name     string                 `mapstructure:"name" json:"name"`
+	AssignBy ExperimentAssignment   `mapstructure:"assign_by" json:"assign_by"`
+	Arms     []AccountExperimentArm `mapstructure:"arms" json:"arms"`
+}
+
+// AccountExperimentArm represents an arm of an account experiment
+type AccountExperimentArm struct {
+	Name string `mapstructure:"name" json:"name"`
+	// Weight is the share of the traffic assigned to the arm, relative to the weights of the other arms
+	Weight int `mapstructure:"weight" json:"weight"`
+	// Account is a JSON merge patch applied over the account config
+	Account json.RawMessage `mapstructure:"account" json:"account,omitempty"`
+	// Request is a JSON merge patch applied over the ext.prebid of the request
+	Request json.RawMessage `mapstructure:"request" json:"request,omitempty"`
+}
+
+func (ex *AccountExperiments) validate(errs []error) []error {
+	switch ex.AssignBy {
+	case "", ExperimentAssignByUser, ExperimentAssignByRequest:
+	default:
+		errs = append(errs, fmt.Errorf(`account_defaults.experiments.assign_by must be one of "%s" or "%s"`, ExperimentAssignByUser, ExperimentAssignByRequest))
+	}
+
+	for i, arm := range ex.Arms {
+		if arm.Weight < 0 {
+			errs = append(errs, fmt.Errorf(`account_defaults.experiments.arms[%d].weight should be greater than or equal to 0`, i))
+		}
+	}
+
+	return errs
+}
+
+// AccountBidderEndpoints maps bidder names to the endpoint overrides of the account
+type AccountBidderEndpoints map[string]AccountBidderEndpoint
+
+// AccountBidderEndpoint overrides the host config of a bidder for the account, e.g. to call a regional or a dedicated
+// host of the bidder. Empty fields keep the host config.
+type AccountBidderEndpoint struct {
+	Endpoint         string            `mapstructure:"endpoint" json:"endpoint,omitempty"`
+	ExtraAdapterInfo string            `mapstructure:"extra_info" json:"extra_info,omitempty"`
+	Headers          map[string]string `mapstructure:"headers" json:"headers,omitempty"`
+}
+
+// Validate checks the endpoints the same way as the host endpoints of the bidders are checked at startup
+func (be AccountBidderEndpoints) Validate(errs []error) []error {
+	for bidder, override := range be {
+		if override.Endpoint != "" {
+			errs = validateAdapterEndpoint(override.Endpoint, bidder, errs)
+		}
+	}
+	return errs
+}
+
+// AccountBidderAliases maps the bidders an account declares on its own to their config. They are aliases of the
+// genericortb adapter, which the host enables to let accounts declare them.
+type AccountBidderAliases map[string]AccountBidderAlias
+
+// AccountBidderAlias configures a bidder declared by an account, which accepts plain OpenRTB requests
+type AccountBidderAlias struct {
+	Endpoint       string       `mapstructure:"endpoint" json:"endpoint"`
+	OpenRTBVersion string       `mapstructure:"openrtb_version" json:"openrtb_version,omitempty"`
+	GenericORTB    *GenericORTB `mapstructure:"genericortb" json:"genericortb,omitempty"`
+}
+
+// Validate checks the aliases the same way as the genericortb aliases of the host are checked at startup
+func (ba AccountBidderAliases) Validate(errs []error) []error {
+	for alias, aliasConfig := range ba {
+		if _, isCoreBidder := openrtb_ext.NormalizeBidderName(alias); isCoreBidder {
+			errs = append(errs, fmt.Errorf("bidder_aliases.%s must not be the name of a bidder", alias))
+			continue
+		}
+		errs = validateAdapterEndpoint(aliasConfig.Endpoint, alias, errs)
+		if err := validateGenericORTB(aliasConfig.GenericORTB, alias); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 // AccountHooks represents account-specific hooks configuration
 type AccountHooks struct {
 	Modules       AccountModules    `mapstructure:"modules" json:"modules"`
@@ -339,6 +519,17 @@ type AccountPrivacy struct {
 	IPv6Config      IPv6             `mapstructure:"ipv6" json:"ipv6"`
 	IPv4Config      IPv4             `mapstructure:"ipv4" json:"ipv4"`
 	PrivacySandbox  PrivacySandbox   `mapstructure:"privacysandbox" json:"privacysandbox"`
+	// DataPolicy restricts the fields of the request sent to each bidder, on top of the host policy of the bidder
+	DataPolicy map[string]DataPolicy `mapstructure:"data_policy" json:"data_policy,omitempty"`
+}
+
+func (p *AccountPrivacy) validate(errs []error) []error {
+	for bidder, policy := range p.DataPolicy {
+		if err := policy.validate("account_defaults.privacy.data_policy."+bidder, ""); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
 }
 
 type PrivacySandbox struct {
@@ -1014,3 +1014,224 @@ func TestIPMaskingValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestAccountAuctionPricingValidate(t *testing.T) {
+	tests := []struct {
+		description string
+		pricing     AccountAuctionPricing
+		want        []error
+	}{
+		{
+			description: "empty",
+			pricing:     AccountAuctionPricing{},
+		},
+		{
+			description: "second_price",
+			pricing:     AccountAuctionPricing{Mode: AuctionPricingSecondPrice, Increment: 0.01},
+		},
+		{
+			description: "soft_floor",
+			pricing:     AccountAuctionPricing{Mode: AuctionPricingSoftFloor},
+		},
+		{
+			description: "invalid_mode",
+			pricing:     AccountAuctionPricing{Mode: "third_price"},
+			want:        []error{errors.New(`account_defaults.auction.pricing.mode must be one of "first_price", "second_price" or "soft_floor"`)},
+		},
+		{
+			description: "negative_increment",
+			pricing:     AccountAuctionPricing{Mode: AuctionPricingSecondPrice, Increment: -0.01},
+			want:        []error{errors.New("account_defaults.auction.pricing.increment should be greater than or equal to 0")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			var errs []error
+			got := tt.pricing.validate(errs)
+			assert.ElementsMatch(t, got, tt.want)
+		})
+	}
+}
+
+func TestAccountAuctionEarlyReturnValidate(t *testing.T) {
+	tests := []struct {
+		description string
+		earlyReturn AccountAuctionEarlyReturn
+		want        []error
+	}{
+		{
+			description: "empty",
+			earlyReturn: AccountAuctionEarlyReturn{},
+		},
+		{
+			description: "valid",
+			earlyReturn: AccountAuctionEarlyReturn{Enabled: true, DealBidders: []string{"appnexus"}, TargetCPM: 5},
+		},
+		{
+			description: "negative_target_cpm",
+			earlyReturn: AccountAuctionEarlyReturn{Enabled: true, TargetCPM: -1},
+			want:        []error{errors.New("account_defaults.auction.early_return.target_cpm should be greater than or equal to 0")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			var errs []error
+			got := tt.earlyReturn.validate(errs)
+			assert.ElementsMatch(t, got, tt.want)
+		})
+	}
+}
+
+func TestAccountAuctionMediaTypePriorityValidate(t *testing.T) {
+	tests := []struct {
+		description       string
+		mediaTypePriority AccountAuctionMediaTypePriority
+		want              []error
+	}{
+		{
+			description:       "empty",
+			mediaTypePriority: AccountAuctionMediaTypePriority{},
+		},
+		{
+			description:       "valid",
+			mediaTypePriority: AccountAuctionMediaTypePriority{Priority: []string{"video", "banner"}, Bias: 1.2},
+		},
+		{
+			description:       "invalid_media_type",
+			mediaTypePriority: AccountAuctionMediaTypePriority{Priority: []string{"video", "display"}},
+			want:              []error{errors.New(`account_defaults.auction.media_type_priority.priority contains an invalid media type "display"`)},
+		},
+		{
+			description:       "bias_below_one",
+			mediaTypePriority: AccountAuctionMediaTypePriority{Priority: []string{"video"}, Bias: 0.5},
+			want:              []error{errors.New("account_defaults.auction.media_type_priority.bias should be 0 or greater than or equal to 1")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			var errs []error
+			got := tt.mediaTypePriority.validate(errs)
+			assert.ElementsMatch(t, got, tt.want)
+		})
+	}
+}
+
+func TestAccountExperimentsValidate(t *testing.T) {
+	tests := []struct {
+		description string
+		experiments AccountExperiments
+		want        []error
+	}{
+		{
+			description: "empty",
+			experiments: AccountExperiments{},
+		},
+		{
+			description: "valid",
+			experiments: AccountExperiments{Enabled: true, Name: "floors", AssignBy: ExperimentAssignByUser, Arms: []AccountExperimentArm{{Name: "control", Weight: 90}, {Name: "test", Weight: 10}}},
+		},
+		{
+			description: "invalid_assign_by",
+			experiments: AccountExperiments{Enabled: true, AssignBy: "device"},
+			want:        []error{errors.New(`account_defaults.experiments.assign_by must be one of "user" or "request"`)},
+		},
+		{
+			description: "negative_weight",
+			experiments: AccountExperiments{Enabled: true, Arms: []AccountExperimentArm{{Name: "control", Weight: 1}, {Name: "test", Weight: -1}}},
+			want:        []error{errors.New("account_defaults.experiments.arms[1].weight should be greater than or equal to 0")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			var errs []error
+			got := tt.experiments.validate(errs)
+			assert.ElementsMatch(t, got, tt.want)
+		})
+	}
+}
+
+func TestAccountBidderEndpointsValidate(t *testing.T) {
+	tests := []struct {
+		description string
+		endpoints   AccountBidderEndpoints
+		want        []error
+	}{
+		{
+			description: "no_endpoints",
+			endpoints:   nil,
+		},
+		{
+			description: "headers_only",
+			endpoints:   AccountBidderEndpoints{"appnexus": {Headers: map[string]string{"X-Dedicated": "1"}}},
+		},
+		{
+			description: "valid_endpoint_with_macros",
+			endpoints:   AccountBidderEndpoints{"appnexus": {Endpoint: "https://{{.Host}}.example.com/bid?account={{.AccountID}}"}},
+		},
+		{
+			description: "unknown_macro",
+			endpoints:   AccountBidderEndpoints{"appnexus": {Endpoint: "https://{{.Region}}.example.com"}},
+			want:        []error{errors.New(`Unable to resolve endpoint: https://{{.Region}}.example.com for adapter: appnexus. template: endpointTemplate:1:10: executing "endpointTemplate" at <.Region>: can't evaluate field Region in type macros.EndpointTemplateParams`)},
+		},
+		{
+			description: "invalid_url",
+			endpoints:   AccountBidderEndpoints{"appnexus": {Endpoint: "not a url"}},
+			want:        []error{errors.New("The endpoint: not a url for appnexus is not a valid URL")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.endpoints.Validate(nil))
+		})
+	}
+}
+
+func TestAccountBidderAliasesValidate(t *testing.T) {
+	tests := []struct {
+		description string
+		aliases     AccountBidderAliases
+		want        []error
+	}{
+		{
+			description: "no_aliases",
+			aliases:     nil,
+		},
+		{
+			description: "valid_alias",
+			aliases: AccountBidderAliases{"mybidder": {
+				Endpoint:    "https://{{.Host}}.example.com/bid",
+				GenericORTB: &GenericORTB{ParamsMapping: map[string]string{"host": "endpoint.Host"}},
+			}},
+		},
+		{
+			description: "core_bidder_name",
+			aliases:     AccountBidderAliases{"appnexus": {Endpoint: "https://example.com/bid"}},
+			want:        []error{errors.New("bidder_aliases.appnexus must not be the name of a bidder")},
+		},
+		{
+			description: "missing_endpoint",
+			aliases:     AccountBidderAliases{"mybidder": {}},
+			want: []error{errors.New("There's no default endpoint available for mybidder. Calls to this bidder/exchange will fail. " +
+				"Please set adapters.mybidder.endpoint in your app config")},
+		},
+		{
+			description: "invalid_params_mapping",
+			aliases: AccountBidderAliases{"mybidder": {
+				Endpoint:    "https://example.com/bid",
+				GenericORTB: &GenericORTB{ParamsMapping: map[string]string{"userId": "user.id"}},
+			}},
+			want: []error{errors.New("genericOrtb.paramsMapping.userId must start with imp., request. or endpoint., got user.id for adapter: mybidder")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.aliases.Validate(nil))
+		})
+	}
+}
@@ -10,4 +10,8 @@ type Adapter struct {
 	// needed for Facebook
 	PlatformID string
 	AppSecret  string
+
+	// needed for GenericORTB
+	OpenRTB     *OpenRTBInfo
+	GenericORTB *GenericORTB
 }
@@ -1,18 +1,23 @@
 package config
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"text/template"
 
 	"github.com/prebid/prebid-server/v2/macros"
 	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/requestsigner"
 
 	validator "github.com/asaskevich/govalidator"
+	"golang.org/x/text/currency"
 	"gopkg.in/yaml.v3"
 )
 
@@ -45,6 +50,21 @@ type BidderInfo struct {
 	// EndpointCompression determines, if set, the type of compression the bid request will undergo before being sent to the corresponding bid server
 	EndpointCompression string       `yaml:"endpointCompression" mapstructure:"endpointCompression"`
 	OpenRTB             *OpenRTBInfo `yaml:"openrtb" mapstructure:"openrtb"`
+	// CircuitBreaker stops sending requests to the bidder while it keeps failing
+	CircuitBreaker *CircuitBreaker `yaml:"circuitBreaker" mapstructure:"circuitBreaker"`
+	// ResponseCache reuses the bids of a recent identical request instead of calling the bidder again
+	ResponseCache *ResponseCache `yaml:"responseCache" mapstructure:"responseCache"`
+	// Currencies lists the currencies the bidder accepts bid floors and bids in, in order of preference. When
+	// set, requests in other currencies are converted to the first accepted currency before being sent.
+	Currencies []string `yaml:"currencies" mapstructure:"currencies"`
+	// HTTPClient gives the bidder a dedicated connection pool instead of the shared host client
+	HTTPClient *BidderHTTPClient `yaml:"httpClient" mapstructure:"httpClient"`
+	// GenericORTB configures bidders served by the genericortb adapter, usually declared as its aliases
+	GenericORTB *GenericORTB `yaml:"genericOrtb" mapstructure:"genericOrtb"`
+	// DataPolicy restricts the fields of the request sent to the bidder
+	DataPolicy *DataPolicy `yaml:"dataPolicy" mapstructure:"dataPolicy"`
+	// RequestSigning signs the requests sent to the bidder with one of the registered request signers
+	RequestSigning *RequestSigning `yaml:"requestSigning" mapstructure:"requestSigning"`
 }
 
 type aliasNillableFields struct {
@@ -100,6 +120,114 @@ type OpenRTBInfo struct {
 	GPPSupported bool   `yaml:"gpp-supported" mapstructure:"gpp-supported"`
 }
 
+// CircuitBreaker specifies when requests to a bidder are short-circuited after failing too often. A circuit opens
+// when the share of failed requests (timeouts, connection errors and 5xx responses) within a window reaches the
+// error rate. After the open duration a limited number of probe requests are let through (half-open) and the circuit
+// closes again once all of them succeed. Zero values fall back to the defaults documented on each field.
+type CircuitBreaker struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// PerHost tracks a separate circuit for every endpoint host the bidder sends requests to
+	PerHost bool `yaml:"perHost" mapstructure:"perHost"`
+	// WindowSeconds is the duration of the window the error rate is calculated over. Defaults to 10.
+	WindowSeconds int `yaml:"windowSeconds" mapstructure:"windowSeconds"`
+	// MinRequests is the number of requests within a window needed before the circuit may open. Defaults to 20.
+	MinRequests int `yaml:"minRequests" mapstructure:"minRequests"`
+	// ErrorRate is the share of failed requests, between 0 and 1, which opens the circuit. Defaults to 0.5.
+	ErrorRate float64 `yaml:"errorRate" mapstructure:"errorRate"`
+	// OpenSeconds is how long the circuit stays open before probing the bidder. Defaults to 30.
+	OpenSeconds int `yaml:"openSeconds" mapstructure:"openSeconds"`
+	// HalfOpenRequests is the number of probe requests which must succeed to close the circuit. Defaults to 3.
+	HalfOpenRequests int `yaml:"halfOpenRequests" mapstructure:"halfOpenRequests"`
+}
+
+// BidderHTTPClient specifies the settings of the dedicated http client of a bidder, so that a bidder with slow TLS
+// handshakes or large responses can't exhaust the connections shared by all other bidders. Zero values fall back
+// to the settings of the host http client.
+type BidderHTTPClient struct {
+	MaxConnsPerHost        int `yaml:"maxConnectionsPerHost" mapstructure:"maxConnectionsPerHost"`
+	MaxIdleConns           int `yaml:"maxIdleConnections" mapstructure:"maxIdleConnections"`
+	MaxIdleConnsPerHost    int `yaml:"maxIdleConnectionsPerHost" mapstructure:"maxIdleConnectionsPerHost"`
+	IdleConnTimeoutSeconds int `yaml:"idleConnectionTimeoutSeconds" mapstructure:"idleConnectionTimeoutSeconds"`
+	// TLSHandshakeTimeoutMilliseconds limits the duration of the TLS handshake with the bidder
+	TLSHandshakeTimeoutMilliseconds int `yaml:"tlsHandshakeTimeoutMilliseconds" mapstructure:"tlsHandshakeTimeoutMilliseconds"`
+	// TLSMinVersion is the minimum TLS version accepted from the bidder, one of 1.0, 1.1, 1.2 or 1.3
+	TLSMinVersion string `yaml:"tlsMinVersion" mapstructure:"tlsMinVersion"`
+	// HTTP2 enables or disables HTTP/2 with the bidder. When not set HTTP/2 is only used if the host client does.
+	HTTP2 *bool `yaml:"http2" mapstructure:"http2"`
+	// MaxResponseBytes is the maximum size of a bidder response body, larger responses are discarded
+	MaxResponseBytes int64 `yaml:"maxResponseBytes" mapstructure:"maxResponseBytes"`
+}
+
+// Request signer types available to the bidders
+const (
+	RequestSigningHMAC = requestsigner.TypeHMAC
+	RequestSigningJWT  = requestsigner.TypeJWT
+)
+
+// RequestSigning specifies how the requests sent to a bidder are signed
+type RequestSigning = requestsigner.Config
+
+// Generic ORTB bid type rules, which read the media type of a bid
+const (
+	// GenericORTBBidTypeMType reads the media type from bid.mtype
+	GenericORTBBidTypeMType = "mtype"
+	// GenericORTBBidTypeExt reads the media type from bid.ext.prebid.type
+	GenericORTBBidTypeExt = "ext.prebid.type"
+	// GenericORTBBidTypeImp reads the media type from the imp of the bid, when the imp has a single media type
+	GenericORTBBidTypeImp = "imp"
+)
+
+// Generic ORTB params mapping targets, which are the first element of the location a bidder param is written to
+const (
+	GenericORTBTargetImp      = "imp"
+	GenericORTBTargetRequest  = "request"
+	GenericORTBTargetEndpoint = "endpoint"
+)
+
+// GenericORTBParamMapping is an entry of the genericOrtb params mapping, with its location split into its target
+// and the path within the target. The path of the endpoint target is the name of the endpoint macro.
+type GenericORTBParamMapping struct {
+	Param  string
+	Target string
+	Path   []string
+}
+
+// GenericORTB specifies how the genericortb adapter talks to a bidder which accepts plain OpenRTB requests, so
+// that adding such a bidder only takes a bidder info file. The OpenRTB version sent to the bidder is the
+// openrtb.version of the bidder info, 2.5 requests being converted down from 2.6.
+type GenericORTB struct {
+	// Headers are added to every request sent to the bidder
+	Headers map[string]string `yaml:"headers" mapstructure:"headers" json:"headers,omitempty"`
+	// SplitImps sends a request per imp instead of a single request with all the imps
+	SplitImps bool `yaml:"splitImps" mapstructure:"splitImps" json:"splitImps,omitempty"`
+	// BidTypes lists the rules tried in order to find the media type of a bid, among "mtype", "ext.prebid.type"
+	// and "imp". Defaults to all of them in this order.
+	BidTypes []string `yaml:"bidTypes" mapstructure:"bidTypes" json:"bidTypes,omitempty"`
+	// ParamsMapping maps the bidder params, as defined by the params JSON schema of the bidder, to the location
+	// they are written to: "imp.<path>" in the imp, "request.<path>" in the request or "endpoint.<macro>" in the
+	// endpoint template params, e.g. "endpoint.PublisherID".
+	ParamsMapping map[string]string `yaml:"paramsMapping" mapstructure:"paramsMapping" json:"paramsMapping,omitempty"`
+}
+
+// TLSVersions maps the TLS versions accepted by BidderHTTPClient.TLSMinVersion to their crypto/tls values.
+var TLSVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ResponseCache specifies how long the bids of a bidder are reused for identical requests. Requests are considered
+// identical when they only differ by their id, tmax and source.tid, which is typical of refreshing ad slots.
+// Only bidders whose bids do not depend on the request id should opt in.
+type ResponseCache struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// TTLMilliseconds is how long bids are reused for. Defaults to 1000.
+	TTLMilliseconds int `yaml:"ttlMilliseconds" mapstructure:"ttlMilliseconds"`
+	// MaxEntries is the maximum number of requests bids are kept for. Defaults to 10000.
+	MaxEntries int `yaml:"maxEntries" mapstructure:"maxEntries"`
+}
+
 // Syncer specifies the user sync settings for a bidder. This struct is shared by the account config,
 // so it needs to have both yaml and mapstructure mappings.
 type Syncer struct {
@@ -332,6 +460,27 @@ func processBidderAliases(aliasNillableFieldsByBidder map[string]aliasNillableFi
 		if aliasBidderInfo.OpenRTB == nil {
 			aliasBidderInfo.OpenRTB = parentBidderInfo.OpenRTB
 		}
+		if aliasBidderInfo.CircuitBreaker == nil {
+			aliasBidderInfo.CircuitBreaker = parentBidderInfo.CircuitBreaker
+		}
+		if aliasBidderInfo.ResponseCache == nil {
+			aliasBidderInfo.ResponseCache = parentBidderInfo.ResponseCache
+		}
+		if aliasBidderInfo.Currencies == nil {
+			aliasBidderInfo.Currencies = parentBidderInfo.Currencies
+		}
+		if aliasBidderInfo.HTTPClient == nil {
+			aliasBidderInfo.HTTPClient = parentBidderInfo.HTTPClient
+		}
+		if aliasBidderInfo.GenericORTB == nil {
+			aliasBidderInfo.GenericORTB = parentBidderInfo.GenericORTB
+		}
+		if aliasBidderInfo.DataPolicy == nil {
+			aliasBidderInfo.DataPolicy = parentBidderInfo.DataPolicy
+		}
+		if aliasBidderInfo.RequestSigning == nil {
+			aliasBidderInfo.RequestSigning = parentBidderInfo.RequestSigning
+		}
 		if aliasBidderInfo.PlatformID == "" {
 			aliasBidderInfo.PlatformID = parentBidderInfo.PlatformID
 		}
@@ -457,6 +606,29 @@ func validateInfo(bidder BidderInfo, infos BidderInfos, bidderName string) error
 			return err
 		}
 	}
+	if err := validateCircuitBreaker(bidder.CircuitBreaker, bidderName); err != nil {
+		return err
+	}
+	if err := validateResponseCache(bidder.ResponseCache, bidderName); err != nil {
+		return err
+	}
+	if err := validateCurrencies(bidder.Currencies, bidderName); err != nil {
+		return err
+	}
+	if err := validateHTTPClient(bidder.HTTPClient, bidderName); err != nil {
+		return err
+	}
+	if err := validateGenericORTB(bidder.GenericORTB, bidderName); err != nil {
+		return err
+	}
+	if bidder.DataPolicy != nil {
+		if err := bidder.DataPolicy.validate("dataPolicy", " for adapter: "+bidderName); err != nil {
+			return err
+		}
+	}
+	if err := validateRequestSigning(bidder.RequestSigning, bidderName); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -551,6 +723,126 @@ func validateCapabilities(info *CapabilitiesInfo, bidderName string) error {
 	return nil
 }
 
+func validateCircuitBreaker(info *CircuitBreaker, bidderName string) error {
+	if info == nil {
+		return nil
+	}
+
+	if info.WindowSeconds < 0 || info.MinRequests < 0 || info.OpenSeconds < 0 || info.HalfOpenRequests < 0 {
+		return fmt.Errorf("circuitBreaker.windowSeconds, minRequests, openSeconds and halfOpenRequests must not be negative for adapter: %s", bidderName)
+	}
+
+	if info.ErrorRate < 0 || info.ErrorRate > 1 {
+		return fmt.Errorf("circuitBreaker.errorRate must be between 0 and 1 for adapter: %s", bidderName)
+	}
+
+	return nil
+}
+
+func validateResponseCache(info *ResponseCache, bidderName string) error {
+	if info == nil {
+		return nil
+	}
+
+	if info.TTLMilliseconds < 0 || info.MaxEntries < 0 {
+		return fmt.Errorf("responseCache.ttlMilliseconds and maxEntries must not be negative for adapter: %s", bidderName)
+	}
+
+	return nil
+}
+
+func validateCurrencies(currencies []string, bidderName string) error {
+	for _, cur := range currencies {
+		if _, err := currency.ParseISO(cur); err != nil {
+			return fmt.Errorf("currencies must contain ISO 4217 currency codes for adapter: %s, got %s", bidderName, cur)
+		}
+	}
+	return nil
+}
+
+func validateHTTPClient(info *BidderHTTPClient, bidderName string) error {
+	if info == nil {
+		return nil
+	}
+
+	if info.MaxConnsPerHost < 0 || info.MaxIdleConns < 0 || info.MaxIdleConnsPerHost < 0 || info.IdleConnTimeoutSeconds < 0 || info.TLSHandshakeTimeoutMilliseconds < 0 || info.MaxResponseBytes < 0 {
+		return fmt.Errorf("httpClient connection limits, timeouts and maxResponseBytes must not be negative for adapter: %s", bidderName)
+	}
+
+	if _, ok := TLSVersions[info.TLSMinVersion]; info.TLSMinVersion != "" && !ok {
+		return fmt.Errorf("httpClient.tlsMinVersion must be one of 1.0, 1.1, 1.2 or 1.3 for adapter: %s, got %s", bidderName, info.TLSMinVersion)
+	}
+
+	return nil
+}
+
+func validateRequestSigning(info *RequestSigning, bidderName string) error {
+	if info == nil {
+		return nil
+	}
+	if info.Type == "" {
+		return fmt.Errorf("requestSigning.type must be set for adapter: %s", bidderName)
+	}
+	// request signers registered by the host may not use a key file
+	if info.KeyFile == "" && (info.Type == RequestSigningHMAC || info.Type == RequestSigningJWT) {
+		return fmt.Errorf("requestSigning.keyFile must be set for %s signers for adapter: %s", info.Type, bidderName)
+	}
+	if info.KeyReloadSeconds < 0 {
+		return fmt.Errorf("requestSigning.keyReloadSeconds must be greater than or equal to 0 for adapter: %s, got %d", bidderName, info.KeyReloadSeconds)
+	}
+	if info.TokenTTLSeconds < 0 {
+		return fmt.Errorf("requestSigning.tokenTTLSeconds must be greater than or equal to 0 for adapter: %s, got %d", bidderName, info.TokenTTLSeconds)
+	}
+	// build the signer so that an unknown type or an unreadable key file fail at startup rather than every request
+	if _, err := requestsigner.NewRequestSigner(info); err != nil {
+		return fmt.Errorf("requestSigning is invalid for adapter: %s: %v", bidderName, err)
+	}
+	return nil
+}
+
+func validateGenericORTB(info *GenericORTB, bidderName string) error {
+	if info == nil {
+		return nil
+	}
+
+	for _, bidType := range info.BidTypes {
+		if bidType != GenericORTBBidTypeMType && bidType != GenericORTBBidTypeExt && bidType != GenericORTBBidTypeImp {
+			return fmt.Errorf("genericOrtb.bidTypes must contain only %s, %s or %s for adapter: %s, got %s", GenericORTBBidTypeMType, GenericORTBBidTypeExt, GenericORTBBidTypeImp, bidderName, bidType)
+		}
+	}
+
+	if _, err := info.ParseParamsMapping(); err != nil {
+		return fmt.Errorf("%v for adapter: %s", err, bidderName)
+	}
+
+	return nil
+}
+
+// ParseParamsMapping validates the params mapping and returns its entries sorted by param, so that nested
+// locations are written in the same order on every request.
+func (info *GenericORTB) ParseParamsMapping() ([]GenericORTBParamMapping, error) {
+	mappings := make([]GenericORTBParamMapping, 0, len(info.ParamsMapping))
+	endpointParams := reflect.TypeOf(macros.EndpointTemplateParams{})
+	for param, location := range info.ParamsMapping {
+		path := strings.Split(location, ".")
+		if len(path) < 2 || path[1] == "" {
+			return nil, fmt.Errorf("genericOrtb.paramsMapping.%s must start with imp., request. or endpoint., got %s", param, location)
+		}
+		switch path[0] {
+		case GenericORTBTargetImp, GenericORTBTargetRequest:
+		case GenericORTBTargetEndpoint:
+			if _, ok := endpointParams.FieldByName(path[1]); !ok || len(path) > 2 {
+				return nil, fmt.Errorf("genericOrtb.paramsMapping.%s refers to an unknown endpoint macro, got %s", param, location)
+			}
+		default:
+			return nil, fmt.Errorf("genericOrtb.paramsMapping.%s must start with imp., request. or endpoint., got %s", param, location)
+		}
+		mappings = append(mappings, GenericORTBParamMapping{Param: param, Target: path[0], Path: path[1:]})
+	}
+	sort.Slice(mappings, func(i, j int) bool { return mappings[i].Param < mappings[j].Param })
+	return mappings, nil
+}
+
 func validatePlatformInfo(info *PlatformInfo) error {
 	if len(info.MediaTypes) == 0 {
 		return errors.New("at least one media type needs to be specified")
@@ -646,6 +938,27 @@ func applyBidderInfoConfigOverrides(configBidderInfos nillableFieldBidderInfos,
 		if configBidderInfo.bidderInfo.OpenRTB != nil {
 			mergedBidderInfo.OpenRTB = configBidderInfo.bidderInfo.OpenRTB
 		}
+		if configBidderInfo.bidderInfo.CircuitBreaker != nil {
+			mergedBidderInfo.CircuitBreaker = configBidderInfo.bidderInfo.CircuitBreaker
+		}
+		if configBidderInfo.bidderInfo.ResponseCache != nil {
+			mergedBidderInfo.ResponseCache = configBidderInfo.bidderInfo.ResponseCache
+		}
+		if configBidderInfo.bidderInfo.Currencies != nil {
+			mergedBidderInfo.Currencies = configBidderInfo.bidderInfo.Currencies
+		}
+		if configBidderInfo.bidderInfo.HTTPClient != nil {
+			mergedBidderInfo.HTTPClient = configBidderInfo.bidderInfo.HTTPClient
+		}
+		if configBidderInfo.bidderInfo.GenericORTB != nil {
+			mergedBidderInfo.GenericORTB = configBidderInfo.bidderInfo.GenericORTB
+		}
+		if configBidderInfo.bidderInfo.DataPolicy != nil {
+			mergedBidderInfo.DataPolicy = configBidderInfo.bidderInfo.DataPolicy
+		}
+		if configBidderInfo.bidderInfo.RequestSigning != nil {
+			mergedBidderInfo.RequestSigning = configBidderInfo.bidderInfo.RequestSigning
+		}
 
 		mergedBidderInfos[string(normalizedBidderName)] = mergedBidderInfo
 	}
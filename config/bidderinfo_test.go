@@ -2,12 +2,16 @@ package config
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/requestsigner"
+	"github.com/prebid/prebid-server/v2/util/timeutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -1860,3 +1864,236 @@ func TestReadFullYamlBidderConfig(t *testing.T) {
 	}
 	assert.Equalf(t, expectedBidderInfo, actualBidderInfo, "Bidder info objects aren't matching")
 }
+
+func TestValidateCircuitBreaker(t *testing.T) {
+	testCases := []struct {
+		name        string
+		info        *CircuitBreaker
+		expectedErr error
+	}{
+		{
+			name: "nil",
+			info: nil,
+		},
+		{
+			name: "valid",
+			info: &CircuitBreaker{Enabled: true, WindowSeconds: 10, MinRequests: 20, ErrorRate: 0.5, OpenSeconds: 30, HalfOpenRequests: 3},
+		},
+		{
+			name:        "negative-window",
+			info:        &CircuitBreaker{Enabled: true, WindowSeconds: -1},
+			expectedErr: errors.New("circuitBreaker.windowSeconds, minRequests, openSeconds and halfOpenRequests must not be negative for adapter: bidderA"),
+		},
+		{
+			name:        "error-rate-above-one",
+			info:        &CircuitBreaker{Enabled: true, ErrorRate: 1.5},
+			expectedErr: errors.New("circuitBreaker.errorRate must be between 0 and 1 for adapter: bidderA"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedErr, validateCircuitBreaker(test.info, "bidderA"))
+		})
+	}
+}
+
+func TestValidateResponseCache(t *testing.T) {
+	testCases := []struct {
+		name        string
+		info        *ResponseCache
+		expectedErr error
+	}{
+		{
+			name: "nil",
+			info: nil,
+		},
+		{
+			name: "valid",
+			info: &ResponseCache{Enabled: true, TTLMilliseconds: 1000, MaxEntries: 10000},
+		},
+		{
+			name:        "negative-ttl",
+			info:        &ResponseCache{Enabled: true, TTLMilliseconds: -1},
+			expectedErr: errors.New("responseCache.ttlMilliseconds and maxEntries must not be negative for adapter: bidderA"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedErr, validateResponseCache(test.info, "bidderA"))
+		})
+	}
+}
+
+func TestValidateCurrencies(t *testing.T) {
+	testCases := []struct {
+		name        string
+		currencies  []string
+		expectedErr error
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name:       "valid",
+			currencies: []string{"EUR", "JPY"},
+		},
+		{
+			name:        "invalid",
+			currencies:  []string{"EUR", "EURO"},
+			expectedErr: errors.New("currencies must contain ISO 4217 currency codes for adapter: bidderA, got EURO"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedErr, validateCurrencies(test.currencies, "bidderA"))
+		})
+	}
+}
+
+func TestValidateHTTPClient(t *testing.T) {
+	testCases := []struct {
+		name        string
+		info        *BidderHTTPClient
+		expectedErr error
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "valid",
+			info: &BidderHTTPClient{MaxConnsPerHost: 10, IdleConnTimeoutSeconds: 30, TLSMinVersion: "1.2", MaxResponseBytes: 1 << 20},
+		},
+		{
+			name:        "negative",
+			info:        &BidderHTTPClient{MaxResponseBytes: -1},
+			expectedErr: errors.New("httpClient connection limits, timeouts and maxResponseBytes must not be negative for adapter: bidderA"),
+		},
+		{
+			name:        "invalid_tls_version",
+			info:        &BidderHTTPClient{TLSMinVersion: "1.4"},
+			expectedErr: errors.New("httpClient.tlsMinVersion must be one of 1.0, 1.1, 1.2 or 1.3 for adapter: bidderA, got 1.4"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedErr, validateHTTPClient(test.info, "bidderA"))
+		})
+	}
+}
+
+func TestValidateGenericORTB(t *testing.T) {
+	testCases := []struct {
+		name        string
+		info        *GenericORTB
+		expectedErr error
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "valid",
+			info: &GenericORTB{
+				BidTypes:      []string{"mtype", "ext.prebid.type", "imp"},
+				ParamsMapping: map[string]string{"placementId": "imp.tagid", "siteId": "request.site.id", "publisherId": "endpoint.PublisherID"},
+			},
+		},
+		{
+			name:        "invalid_bid_type",
+			info:        &GenericORTB{BidTypes: []string{"mtype", "adm"}},
+			expectedErr: errors.New("genericOrtb.bidTypes must contain only mtype, ext.prebid.type or imp for adapter: bidderA, got adm"),
+		},
+		{
+			name:        "invalid_location",
+			info:        &GenericORTB{ParamsMapping: map[string]string{"userId": "user.id"}},
+			expectedErr: errors.New("genericOrtb.paramsMapping.userId must start with imp., request. or endpoint., got user.id for adapter: bidderA"),
+		},
+		{
+			name:        "empty_path",
+			info:        &GenericORTB{ParamsMapping: map[string]string{"placementId": "imp."}},
+			expectedErr: errors.New("genericOrtb.paramsMapping.placementId must start with imp., request. or endpoint., got imp. for adapter: bidderA"),
+		},
+		{
+			name:        "unknown_endpoint_macro",
+			info:        &GenericORTB{ParamsMapping: map[string]string{"region": "endpoint.Region"}},
+			expectedErr: errors.New("genericOrtb.paramsMapping.region refers to an unknown endpoint macro, got endpoint.Region for adapter: bidderA"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedErr, validateGenericORTB(test.info, "bidderA"))
+		})
+	}
+}
+
+func TestValidateRequestSigning(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "bidderA")
+	require.NoError(t, os.WriteFile(keyFile, []byte("2024-01:secret"), 0600))
+	requestsigner.Register("custom", func(cfg requestsigner.Config, clock timeutil.Time) (requestsigner.RequestSigner, error) {
+		if cfg.Params["region"] == "" {
+			return nil, errors.New("region must be set")
+		}
+		return nil, nil
+	})
+
+	testCases := []struct {
+		name        string
+		info        *RequestSigning
+		expectedErr error
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "valid",
+			info: &RequestSigning{Type: RequestSigningJWT, KeyFile: keyFile, KeyReloadSeconds: 30, TokenTTLSeconds: 120},
+		},
+		{
+			name:        "missing_type",
+			info:        &RequestSigning{KeyFile: keyFile},
+			expectedErr: errors.New("requestSigning.type must be set for adapter: bidderA"),
+		},
+		{
+			name:        "missing_key_file",
+			info:        &RequestSigning{Type: RequestSigningHMAC},
+			expectedErr: errors.New("requestSigning.keyFile must be set for hmac-sha256 signers for adapter: bidderA"),
+		},
+		{
+			name: "registered_signer_without_key_file",
+			info: &RequestSigning{Type: "custom", Params: map[string]string{"region": "eu"}},
+		},
+		{
+			name:        "registered_signer_failing_to_build",
+			info:        &RequestSigning{Type: "custom"},
+			expectedErr: errors.New("requestSigning is invalid for adapter: bidderA: region must be set"),
+		},
+		{
+			name:        "unknown_type",
+			info:        &RequestSigning{Type: "rsa", KeyFile: keyFile},
+			expectedErr: errors.New("requestSigning is invalid for adapter: bidderA: unknown request signer type: rsa"),
+		},
+		{
+			name:        "negative_reload",
+			info:        &RequestSigning{Type: RequestSigningHMAC, KeyFile: keyFile, KeyReloadSeconds: -1},
+			expectedErr: errors.New("requestSigning.keyReloadSeconds must be greater than or equal to 0 for adapter: bidderA, got -1"),
+		},
+		{
+			name:        "negative_ttl",
+			info:        &RequestSigning{Type: RequestSigningJWT, KeyFile: keyFile, TokenTTLSeconds: -60},
+			expectedErr: errors.New("requestSigning.tokenTTLSeconds must be greater than or equal to 0 for adapter: bidderA, got -60"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedErr, validateRequestSigning(test.info, "bidderA"))
+		})
+	}
+
+	err := validateRequestSigning(&RequestSigning{Type: RequestSigningHMAC, KeyFile: filepath.Join(t.TempDir(), "missing")}, "bidderA")
+	assert.ErrorContains(t, err, "requestSigning is invalid for adapter: bidderA: ", "an unreadable key file should fail at startup")
+}
@@ -103,6 +103,12 @@ type Configuration struct {
 	Hooks       Hooks       `mapstructure:"hooks"`
 	Validations Validations `mapstructure:"validations"`
 	PriceFloors PriceFloors `mapstructure:"price_floors"`
+	// AdaptiveBidderTimeouts cuts requests to chronically slow bidders short based on their observed latency
+	AdaptiveBidderTimeouts AdaptiveBidderTimeouts `mapstructure:"adaptive_bidder_timeouts"`
+	// TrafficShaping skips calls to bidders which are unlikely to bid on a request
+	TrafficShaping TrafficShaping `mapstructure:"traffic_shaping"`
+	// AuctionCapture records a sample of auctions to a file so they can be replayed offline
+	AuctionCapture AuctionCapture `mapstructure:"auction_capture"`
 }
 
 type Admin struct {
@@ -133,6 +139,10 @@ type HTTPClient struct {
 func (cfg *Configuration) validate(v *viper.Viper) []error {
 	var errs []error
 	errs = cfg.AuctionTimeouts.validate(errs)
+	errs = cfg.AdaptiveBidderTimeouts.validate(errs)
+	errs = cfg.TrafficShaping.validate(errs)
+	errs = cfg.AuctionCapture.validate(errs)
+	errs = cfg.Validations.validate(errs)
 	errs = cfg.StoredRequests.validate(errs)
 	if cfg.StoredRequestsTimeout <= 0 {
 		errs = append(errs, fmt.Errorf("cfg.stored_requests_timeout_ms must be > 0. Got %d", cfg.StoredRequestsTimeout))
@@ -150,6 +160,13 @@ func (cfg *Configuration) validate(v *viper.Viper) []error {
 	errs = cfg.Debug.validate(errs)
 	errs = cfg.ExtCacheURL.validate(errs)
 	errs = cfg.AccountDefaults.PriceFloors.validate(errs)
+	errs = cfg.AccountDefaults.Auction.Pricing.validate(errs)
+	errs = cfg.AccountDefaults.Auction.EarlyReturn.validate(errs)
+	errs = cfg.AccountDefaults.Auction.MediaTypePriority.validate(errs)
+	errs = cfg.AccountDefaults.Experiments.validate(errs)
+	errs = cfg.AccountDefaults.Privacy.validate(errs)
+	errs = cfg.AccountDefaults.BidderEndpoints.Validate(errs)
+	errs = cfg.AccountDefaults.BidderAliases.Validate(errs)
 	if cfg.AccountDefaults.Disabled {
 		glog.Warning(`With account_defaults.disabled=true, host-defined accounts must exist and have "disabled":false. All other requests will be rejected.`)
 	}
@@ -692,6 +709,16 @@ type Validations struct {
 	SecureMarkup          string `mapstructure:"secure_markup" json:"secure_markup"`
 	MaxCreativeWidth      int64  `mapstructure:"max_creative_width" json:"max_creative_width"`
 	MaxCreativeHeight     int64  `mapstructure:"max_creative_height" json:"max_creative_height"`
+	// Validators configures the bid validators by name. Account validators replace the host validators of the same name.
+	Validators map[string]BidValidator `mapstructure:"validators" json:"validators"`
+}
+
+// BidValidator configures a bid validator of the bidvalidation registry.
+type BidValidator struct {
+	// Mode is either enforce, to reject invalid bids, warn, to only report them, or skip
+	Mode string `mapstructure:"mode" json:"mode"`
+	// Params holds the settings specific to the validator
+	Params map[string]interface{} `mapstructure:"params" json:"params"`
 }
 
 const (
@@ -706,6 +733,33 @@ func (host *Validations) SetBannerCreativeMaxSize(account Validations) {
 	}
 }
 
+// MergeValidators returns the host validators with the account validators of the same name replacing them.
+func (host *Validations) MergeValidators(account Validations) map[string]BidValidator {
+	if len(account.Validators) == 0 {
+		return host.Validators
+	}
+
+	merged := make(map[string]BidValidator, len(host.Validators)+len(account.Validators))
+	for name, validator := range host.Validators {
+		merged[name] = validator
+	}
+	for name, validator := range account.Validators {
+		merged[name] = validator
+	}
+	return merged
+}
+
+func (cfg *Validations) validate(errs []error) []error {
+	for name, validator := range cfg.Validators {
+		switch validator.Mode {
+		case ValidationEnforce, ValidationWarn, ValidationSkip:
+		default:
+			errs = append(errs, fmt.Errorf("validations.validators.%s.mode must be one of %s, %s or %s. Got %s", name, ValidationEnforce, ValidationWarn, ValidationSkip, validator.Mode))
+		}
+	}
+	return errs
+}
+
 func (cfg *TimeoutNotification) validate(errs []error) []error {
 	if cfg.SamplingRate < 0.0 || cfg.SamplingRate > 1.0 {
 		errs = append(errs, fmt.Errorf("debug.timeout_notification.sampling_rate must be positive and not greater than 1.0. Got %f", cfg.SamplingRate))
@@ -1168,6 +1222,10 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("account_defaults.price_floors.fetch.max_age_sec", 86400)
 	v.SetDefault("account_defaults.price_floors.fetch.period_sec", 3600)
 	v.SetDefault("account_defaults.price_floors.fetch.max_schema_dims", 0)
+	v.SetDefault("account_defaults.auction.pricing.mode", AuctionPricingFirstPrice)
+	v.SetDefault("account_defaults.auction.pricing.increment", 0.01)
+	v.SetDefault("account_defaults.auction.early_return.enabled", false)
+	v.SetDefault("account_defaults.auction.early_return.target_cpm", 0)
 	v.SetDefault("account_defaults.privacy.privacysandbox.topicsdomain", "")
 	v.SetDefault("account_defaults.privacy.privacysandbox.cookiedeprecation.enabled", false)
 	v.SetDefault("account_defaults.privacy.privacysandbox.cookiedeprecation.ttl_sec", 604800)
@@ -1210,6 +1268,24 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("tmax_adjustments.bidder_network_latency_buffer_ms", 0)
 	v.SetDefault("tmax_adjustments.pbs_response_preparation_duration_ms", 0)
 
+	v.SetDefault("adaptive_bidder_timeouts.enabled", false)
+	v.SetDefault("adaptive_bidder_timeouts.percentile", 0.9)
+	v.SetDefault("adaptive_bidder_timeouts.min_samples", 50)
+	v.SetDefault("adaptive_bidder_timeouts.window_seconds", 60)
+	v.SetDefault("adaptive_bidder_timeouts.slow_bidder_budget_ratio", 0.5)
+	v.SetDefault("adaptive_bidder_timeouts.min_timeout_ms", 50)
+	v.SetDefault("traffic_shaping.enabled", false)
+	v.SetDefault("traffic_shaping.window_seconds", 3600)
+	v.SetDefault("traffic_shaping.min_requests", 1000)
+	v.SetDefault("traffic_shaping.min_bid_rate", 0.01)
+	v.SetDefault("traffic_shaping.min_pass_rate", 0.05)
+	v.SetDefault("auction_capture.enabled", false)
+	v.SetDefault("auction_capture.sampling_rate", 0.001)
+	v.SetDefault("auction_capture.file", "")
+	v.SetDefault("auction_capture.max_file_bytes", 104857600)
+	v.SetDefault("auction_capture.max_backups", 5)
+	v.SetDefault("auction_capture.buffer_size", 1000)
+
 	/* IPv4
 	/*  Site Local: 10.0.0.0/8, 172.16.0.0/12, 192.168.0.0/16
 	/*  Link Local: 169.254.0.0/16
@@ -1351,3 +1427,121 @@ type TmaxAdjustments struct {
 	// PBS won't send a request to the bidder if the bidder tmax calculated is less than the BidderResponseDurationMin value
 	BidderResponseDurationMin uint `mapstructure:"bidder_response_duration_min_ms"`
 }
+
+// AdaptiveBidderTimeouts configures per bidder timeouts based on the latency observed for each bidder.
+// A bidder is considered slow when the configured percentile of its latency exceeds its slow bidder budget,
+// a share of the time left for the bidder request. Slow bidders are cut at that budget while all other
+// bidders keep the full time left.
+type AdaptiveBidderTimeouts struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Percentile of the bidder latency, between 0 and 1, compared to the slow bidder budget
+	Percentile float64 `mapstructure:"percentile"`
+	// MinSamples is the number of latency samples needed before the timeout of a bidder is adapted
+	MinSamples int `mapstructure:"min_samples"`
+	// WindowSeconds is the duration latency samples are kept for. Samples of the previous window are kept
+	// as well, so the latency histograms cover between one and two windows.
+	WindowSeconds int `mapstructure:"window_seconds"`
+	// SlowBidderBudgetRatio is the share of the time left for the bidder request, between 0 and 1, slow bidders get
+	SlowBidderBudgetRatio float64 `mapstructure:"slow_bidder_budget_ratio"`
+	// MinTimeout is the minimum timeout in milliseconds a slow bidder gets
+	MinTimeout int `mapstructure:"min_timeout_ms"`
+}
+
+func (cfg *AdaptiveBidderTimeouts) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.Percentile <= 0 || cfg.Percentile > 1 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.percentile must be > 0 and <= 1. Got %f", cfg.Percentile))
+	}
+	if cfg.MinSamples <= 0 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.min_samples must be > 0. Got %d", cfg.MinSamples))
+	}
+	if cfg.WindowSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.window_seconds must be > 0. Got %d", cfg.WindowSeconds))
+	}
+	if cfg.SlowBidderBudgetRatio <= 0 || cfg.SlowBidderBudgetRatio > 1 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.slow_bidder_budget_ratio must be > 0 and <= 1. Got %f", cfg.SlowBidderBudgetRatio))
+	}
+	if cfg.MinTimeout < 0 {
+		errs = append(errs, fmt.Errorf("adaptive_bidder_timeouts.min_timeout_ms must be >= 0. Got %d", cfg.MinTimeout))
+	}
+	return errs
+}
+
+// TrafficShaping configures the suppression of calls to bidders which rarely bid. Bid rates are learned for
+// each bidder per account, country, device type and media types of the request. Calls to a bidder whose bid
+// rate is below MinBidRate are let through with a probability proportional to that rate, and never lower
+// than MinPassRate so the bid rate keeps being measured.
+type TrafficShaping struct {
+	Enabled bool `mapstructure:"enabled"`
+	// WindowSeconds is the duration outcomes of bidder calls are kept for. Outcomes of the previous window
+	// are kept as well, so bid rates cover between one and two windows.
+	WindowSeconds int `mapstructure:"window_seconds"`
+	// MinRequests is the number of calls needed before calls to a bidder are suppressed
+	MinRequests int `mapstructure:"min_requests"`
+	// MinBidRate is the bid rate, between 0 and 1, under which calls to a bidder are suppressed
+	MinBidRate float64 `mapstructure:"min_bid_rate"`
+	// MinPassRate is the minimum share, between 0 and 1, of calls let through to a suppressed bidder
+	MinPassRate float64 `mapstructure:"min_pass_rate"`
+}
+
+func (cfg *TrafficShaping) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.WindowSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("traffic_shaping.window_seconds must be > 0. Got %d", cfg.WindowSeconds))
+	}
+	if cfg.MinRequests <= 0 {
+		errs = append(errs, fmt.Errorf("traffic_shaping.min_requests must be > 0. Got %d", cfg.MinRequests))
+	}
+	if cfg.MinBidRate <= 0 || cfg.MinBidRate > 1 {
+		errs = append(errs, fmt.Errorf("traffic_shaping.min_bid_rate must be > 0 and <= 1. Got %f", cfg.MinBidRate))
+	}
+	if cfg.MinPassRate <= 0 || cfg.MinPassRate > 1 {
+		errs = append(errs, fmt.Errorf("traffic_shaping.min_pass_rate must be > 0 and <= 1. Got %f", cfg.MinPassRate))
+	}
+	return errs
+}
+
+// AuctionCapture specifies the share of auctions recorded along with the resolved account and the http calls
+// made to the bidders. Captured auctions are appended to File as JSON lines and can be replayed offline.
+// Requests are captured before the privacy policies are enforced, so File and its rotated copies hold raw personal
+// data, such as ip addresses, device ids, user ids and consent strings, and must be protected and retained
+// accordingly.
+type AuctionCapture struct {
+	Enabled bool `mapstructure:"enabled"`
+	// SamplingRate is the share of auctions, between 0 and 1, which are captured
+	SamplingRate float64 `mapstructure:"sampling_rate"`
+	File         string  `mapstructure:"file"`
+	// MaxFileBytes is the size from which File is rotated
+	MaxFileBytes int64 `mapstructure:"max_file_bytes"`
+	// MaxBackups is the number of rotated files kept along with File, the oldest ones are removed
+	MaxBackups int `mapstructure:"max_backups"`
+	// BufferSize is the number of captured auctions waiting to be written to File. Auctions captured while the
+	// buffer is full are dropped, as are the buffered auctions when the server stops.
+	BufferSize int `mapstructure:"buffer_size"`
+}
+
+func (cfg *AuctionCapture) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.SamplingRate <= 0 || cfg.SamplingRate > 1 {
+		errs = append(errs, fmt.Errorf("auction_capture.sampling_rate must be > 0 and <= 1. Got %f", cfg.SamplingRate))
+	}
+	if cfg.File == "" {
+		errs = append(errs, errors.New("auction_capture.file must be set when auction capture is enabled"))
+	}
+	if cfg.MaxFileBytes <= 0 {
+		errs = append(errs, fmt.Errorf("auction_capture.max_file_bytes must be > 0. Got %d", cfg.MaxFileBytes))
+	}
+	if cfg.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("auction_capture.max_backups must be >= 0. Got %d", cfg.MaxBackups))
+	}
+	if cfg.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("auction_capture.buffer_size must be > 0. Got %d", cfg.BufferSize))
+	}
+	return errs
+}
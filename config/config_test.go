@@ -1376,6 +1376,168 @@ func TestValidateDebug(t *testing.T) {
 	assert.NotNil(t, err, "cfg.debug.timeout_notification.sampling_rate should not be allowed to be greater than 1.0, but it was allowed")
 }
 
+func TestValidateAdaptiveBidderTimeouts(t *testing.T) {
+	testCases := []struct {
+		description    string
+		cfg            AdaptiveBidderTimeouts
+		expectedErrors []error
+	}{
+		{
+			description: "disabled",
+			cfg:         AdaptiveBidderTimeouts{Enabled: false},
+		},
+		{
+			description: "valid",
+			cfg:         AdaptiveBidderTimeouts{Enabled: true, Percentile: 0.9, MinSamples: 50, WindowSeconds: 60, SlowBidderBudgetRatio: 0.5, MinTimeout: 50},
+		},
+		{
+			description: "invalid",
+			cfg:         AdaptiveBidderTimeouts{Enabled: true, Percentile: 1.5, MinSamples: 0, WindowSeconds: 0, SlowBidderBudgetRatio: 0, MinTimeout: -1},
+			expectedErrors: []error{
+				errors.New("adaptive_bidder_timeouts.percentile must be > 0 and <= 1. Got 1.500000"),
+				errors.New("adaptive_bidder_timeouts.min_samples must be > 0. Got 0"),
+				errors.New("adaptive_bidder_timeouts.window_seconds must be > 0. Got 0"),
+				errors.New("adaptive_bidder_timeouts.slow_bidder_budget_ratio must be > 0 and <= 1. Got 0.000000"),
+				errors.New("adaptive_bidder_timeouts.min_timeout_ms must be >= 0. Got -1"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			errs := test.cfg.validate(nil)
+			assert.ElementsMatch(t, test.expectedErrors, errs)
+		})
+	}
+}
+
+func TestValidateTrafficShaping(t *testing.T) {
+	testCases := []struct {
+		description    string
+		cfg            TrafficShaping
+		expectedErrors []error
+	}{
+		{
+			description: "disabled",
+			cfg:         TrafficShaping{Enabled: false},
+		},
+		{
+			description: "valid",
+			cfg:         TrafficShaping{Enabled: true, WindowSeconds: 3600, MinRequests: 1000, MinBidRate: 0.01, MinPassRate: 0.05},
+		},
+		{
+			description: "invalid",
+			cfg:         TrafficShaping{Enabled: true, WindowSeconds: 0, MinRequests: 0, MinBidRate: 0, MinPassRate: 1.5},
+			expectedErrors: []error{
+				errors.New("traffic_shaping.window_seconds must be > 0. Got 0"),
+				errors.New("traffic_shaping.min_requests must be > 0. Got 0"),
+				errors.New("traffic_shaping.min_bid_rate must be > 0 and <= 1. Got 0.000000"),
+				errors.New("traffic_shaping.min_pass_rate must be > 0 and <= 1. Got 1.500000"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			errs := test.cfg.validate(nil)
+			assert.ElementsMatch(t, test.expectedErrors, errs)
+		})
+	}
+}
+
+func TestValidateAuctionCapture(t *testing.T) {
+	testCases := []struct {
+		description    string
+		cfg            AuctionCapture
+		expectedErrors []error
+	}{
+		{
+			description: "disabled",
+			cfg:         AuctionCapture{Enabled: false},
+		},
+		{
+			description: "valid",
+			cfg:         AuctionCapture{Enabled: true, SamplingRate: 0.01, File: "/var/log/pbs/capture.jsonl", MaxFileBytes: 1024, MaxBackups: 0, BufferSize: 10},
+		},
+		{
+			description: "invalid",
+			cfg:         AuctionCapture{Enabled: true, SamplingRate: 0, MaxFileBytes: 0, MaxBackups: -1, BufferSize: 0},
+			expectedErrors: []error{
+				errors.New("auction_capture.sampling_rate must be > 0 and <= 1. Got 0.000000"),
+				errors.New("auction_capture.file must be set when auction capture is enabled"),
+				errors.New("auction_capture.max_file_bytes must be > 0. Got 0"),
+				errors.New("auction_capture.max_backups must be >= 0. Got -1"),
+				errors.New("auction_capture.buffer_size must be > 0. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			errs := test.cfg.validate(nil)
+			assert.ElementsMatch(t, test.expectedErrors, errs)
+		})
+	}
+}
+
+func TestValidateValidations(t *testing.T) {
+	testCases := []struct {
+		description    string
+		cfg            Validations
+		expectedErrors []error
+	}{
+		{
+			description: "no_validators",
+			cfg:         Validations{},
+		},
+		{
+			description: "valid_modes",
+			cfg: Validations{Validators: map[string]BidValidator{
+				"adomain": {Mode: ValidationEnforce},
+				"crid":    {Mode: ValidationWarn},
+				"vast":    {Mode: ValidationSkip},
+			}},
+		},
+		{
+			description: "invalid_mode",
+			cfg: Validations{Validators: map[string]BidValidator{
+				"crid": {Mode: "reject"},
+			}},
+			expectedErrors: []error{
+				errors.New("validations.validators.crid.mode must be one of enforce, warn or skip. Got reject"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			errs := test.cfg.validate(nil)
+			assert.ElementsMatch(t, test.expectedErrors, errs)
+		})
+	}
+}
+
+func TestMergeValidators(t *testing.T) {
+	host := Validations{Validators: map[string]BidValidator{
+		"adomain": {Mode: ValidationWarn, Params: map[string]interface{}{"blocked": []interface{}{"host.com"}}},
+		"crid":    {Mode: ValidationEnforce},
+	}}
+
+	assert.Equal(t, host.Validators, host.MergeValidators(Validations{}), "no_account_validators")
+
+	account := Validations{Validators: map[string]BidValidator{
+		"adomain": {Mode: ValidationEnforce, Params: map[string]interface{}{"blocked": []interface{}{"account.com"}}},
+		"vast":    {Mode: ValidationWarn},
+	}}
+	expected := map[string]BidValidator{
+		"adomain": {Mode: ValidationEnforce, Params: map[string]interface{}{"blocked": []interface{}{"account.com"}}},
+		"crid":    {Mode: ValidationEnforce},
+		"vast":    {Mode: ValidationWarn},
+	}
+	assert.Equal(t, expected, host.MergeValidators(account), "account_validators")
+	assert.Len(t, host.Validators, 2, "host validators must not be updated")
+}
+
 func TestValidateAccountsConfigRestrictions(t *testing.T) {
 	cfg, v := newDefaultConfig(t)
 	cfg.Accounts.Files.Enabled = true
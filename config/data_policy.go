@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DataPolicySections are the objects of the request a data policy applies to. The other fields of the request, like
+// the imps and the regs, are needed by every bidder and are never stripped by a policy. The same goes for the privacy
+// signals of the sections, user.consent and user.ext.consent, which are kept even when their parents are removed.
+var DataPolicySections = []string{"app", "device", "dooh", "site", "user"}
+
+// DataPolicy specifies which fields of the request a bidder may receive. Fields are given as dot separated paths
+// from the root of the request, e.g. "device.sua" or "site.content". When Allow is set, only the allowed fields
+// and their children are kept in the policy sections, the parents of an allowed field being kept as far as needed
+// to reach it. Denied fields are removed in any case. EIDSources and SegTaxes, when set, restrict the user.eids and
+// the user.data to the given sources and segment taxonomies.
+type DataPolicy struct {
+	Allow      []string `yaml:"allow" mapstructure:"allow" json:"allow,omitempty"`
+	Deny       []string `yaml:"deny" mapstructure:"deny" json:"deny,omitempty"`
+	EIDSources []string `yaml:"eidSources" mapstructure:"eid_sources" json:"eid_sources,omitempty"`
+	SegTaxes   []int    `yaml:"segtaxes" mapstructure:"segtaxes" json:"segtaxes,omitempty"`
+}
+
+// IsEmpty returns true if the policy lets all the fields through
+func (p *DataPolicy) IsEmpty() bool {
+	return p == nil || (len(p.Allow) == 0 && len(p.Deny) == 0 && len(p.EIDSources) == 0 && len(p.SegTaxes) == 0)
+}
+
+// validate checks that the policy only refers to fields of the policy sections. The owner is appended to the
+// error message to tell which bidder or account the policy belongs to.
+func (p *DataPolicy) validate(path, owner string) error {
+	for _, field := range p.Allow {
+		if !isDataPolicyPath(field) {
+			return fmt.Errorf("%s.allow must contain only fields of %s%s, got %s", path, strings.Join(DataPolicySections, ", "), owner, field)
+		}
+	}
+	for _, field := range p.Deny {
+		if !isDataPolicyPath(field) {
+			return fmt.Errorf("%s.deny must contain only fields of %s%s, got %s", path, strings.Join(DataPolicySections, ", "), owner, field)
+		}
+	}
+	return nil
+}
+
+func isDataPolicyPath(field string) bool {
+	for _, section := range DataPolicySections {
+		if field == section {
+			return true
+		}
+		if strings.HasPrefix(field, section+".") && !strings.HasSuffix(field, ".") && !strings.Contains(field, "..") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataPolicyValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policy      DataPolicy
+		expectedErr error
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "valid",
+			policy: DataPolicy{Allow: []string{"site", "device.ua", "user.eids"}, Deny: []string{"device.sua", "site.content.title"}, SegTaxes: []int{4}},
+		},
+		{
+			name:        "allow_outside_sections",
+			policy:      DataPolicy{Allow: []string{"regs.coppa"}},
+			expectedErr: errors.New("dataPolicy.allow must contain only fields of app, device, dooh, site, user for adapter: bidderA, got regs.coppa"),
+		},
+		{
+			name:        "deny_malformed_path",
+			policy:      DataPolicy{Deny: []string{"device..sua"}},
+			expectedErr: errors.New("dataPolicy.deny must contain only fields of app, device, dooh, site, user for adapter: bidderA, got device..sua"),
+		},
+		{
+			name:        "deny_section_prefix",
+			policy:      DataPolicy{Deny: []string{"users"}},
+			expectedErr: errors.New("dataPolicy.deny must contain only fields of app, device, dooh, site, user for adapter: bidderA, got users"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedErr, test.policy.validate("dataPolicy", " for adapter: bidderA"))
+		})
+	}
+}
+
+func TestAccountPrivacyValidate(t *testing.T) {
+	privacy := AccountPrivacy{
+		DataPolicy: map[string]DataPolicy{
+			"appnexus": {Deny: []string{"device.sua"}},
+			"rubicon":  {Allow: []string{"imp"}},
+		},
+	}
+
+	errs := privacy.validate(nil)
+	assert.Equal(t, []error{errors.New("account_defaults.privacy.data_policy.rubicon.allow must contain only fields of app, device, dooh, site, user, got imp")}, errs)
+}
@@ -136,10 +136,21 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		RequestStatus: metrics.RequestStatusOK,
 	}
 	activityControl := privacy.ActivityControl{}
+	var lateBids *exchange.LateBids
+	var shadowBids *exchange.ShadowBids
 
 	defer func() {
 		deps.metricsEngine.RecordRequest(labels)
 		deps.metricsEngine.RecordRequestTime(labels, time.Since(start))
+		if lateBids != nil || shadowBids != nil {
+			// analytics are logged once the late and shadow bidders responded
+			go func() {
+				ao.LateBids = lateBids.Wait()
+				ao.ShadowBids = shadowBids.Wait()
+				deps.analytics.LogAmpObject(&ao, activityControl)
+			}()
+			return
+		}
 		deps.analytics.LogAmpObject(&ao, activityControl)
 	}()
 
@@ -170,10 +181,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	}
 
 	if errortypes.ContainsFatalError(errL) {
-		w.WriteHeader(http.StatusBadRequest)
-		for _, err := range errortypes.FatalOnly(errL) {
-			fmt.Fprintf(w, "Invalid request: %s\n", err.Error())
-		}
+		writeErrorResponse(w, hookExecutor, http.StatusBadRequest, invalidRequestBody(errortypes.FatalOnly(errL)))
 		labels.RequestStatus = metrics.RequestStatusBadInput
 		return
 	}
@@ -222,15 +230,23 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 				break
 			}
 		}
-		w.WriteHeader(httpStatus)
+		writeErrorResponse(w, hookExecutor, httpStatus, invalidRequestBody(errortypes.FatalOnly(errL)))
 		labels.RequestStatus = metricsStatus
-		for _, err := range errortypes.FatalOnly(errL) {
-			fmt.Fprintf(w, "Invalid request: %s\n", err.Error())
-		}
 		ao.Errors = append(ao.Errors, acctIDErrs...)
 		return
 	}
 
+	// Assign the request to an arm of the account experiment, which may patch the account and the request
+	experimentAccount, experimentArm, err := accountService.ApplyExperiment(account, reqWrapper)
+	if err != nil {
+		errL = append(errL, &errortypes.Warning{Message: err.Error(), WarningCode: errortypes.ExperimentWarningCode})
+	} else if experimentArm != nil {
+		account = experimentAccount
+		labels.Experiment = experimentArm.Experiment
+		labels.ExperimentArm = experimentArm.Name
+		ao.ExperimentArm = experimentArm
+	}
+
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	if errs := deps.setFieldsImplicitly(r, reqWrapper, account); len(errs) > 0 {
 		errL = append(errL, errs...)
@@ -241,10 +257,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	errL = append(errL, errs...)
 	ao.Errors = append(ao.Errors, errs...)
 	if errortypes.ContainsFatalError(errs) {
-		w.WriteHeader(http.StatusBadRequest)
-		for _, err := range errortypes.FatalOnly(errs) {
-			fmt.Fprintf(w, "Invalid request: %s\n", err.Error())
-		}
+		writeErrorResponse(w, hookExecutor, http.StatusBadRequest, invalidRequestBody(errortypes.FatalOnly(errs)))
 		labels.RequestStatus = metrics.RequestStatusBadInput
 		return
 	}
@@ -286,13 +299,14 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	var response *openrtb2.BidResponse
 	if auctionResponse != nil {
 		response = auctionResponse.BidResponse
+		lateBids = auctionResponse.LateBids
+		shadowBids = auctionResponse.ShadowBids
 	}
 	ao.SeatNonBid = auctionResponse.GetSeatNonBid()
 	ao.AuctionResponse = response
 	rejectErr, isRejectErr := hookexecution.CastRejectErr(err)
 	if err != nil && !isRejectErr {
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, "Critical error while running the auction: %v", err)
+		writeErrorResponse(w, hookExecutor, http.StatusInternalServerError, []byte(fmt.Sprintf("Critical error while running the auction: %v", err)))
 		glog.Errorf("/openrtb2/amp Critical error: %v", err)
 		ao.Status = http.StatusInternalServerError
 		ao.Errors = append(ao.Errors, err)
@@ -302,8 +316,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	// hold auction rebuilds the request wrapper first thing, so there is likely
 	// no work to do here, but added a rebuild just in case this behavior changes.
 	if err := reqWrapper.RebuildRequest(); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, "Critical error while running the auction: %v", err)
+		writeErrorResponse(w, hookExecutor, http.StatusInternalServerError, []byte(fmt.Sprintf("Critical error while running the auction: %v", err)))
 		glog.Errorf("/openrtb2/amp Critical error: %v", err)
 		ao.Status = http.StatusInternalServerError
 		ao.Errors = append(ao.Errors, err)
@@ -366,8 +379,7 @@ func sendAmpResponse(
 					bidExt := &openrtb_ext.ExtBid{}
 					err := jsonutil.Unmarshal(bid.Ext, bidExt)
 					if err != nil {
-						w.WriteHeader(http.StatusInternalServerError)
-						fmt.Fprintf(w, "Critical error while unpacking AMP targets: %v", err)
+						writeErrorResponse(w, hookExecutor, http.StatusInternalServerError, []byte(fmt.Sprintf("Critical error while unpacking AMP targets: %v", err)))
 						glog.Errorf("/openrtb2/amp Critical error unpacking targets: %v", err)
 						ao.Errors = append(ao.Errors, fmt.Errorf("Critical error while unpacking AMP targets: %v", err))
 						ao.Status = http.StatusInternalServerError
@@ -403,19 +415,19 @@ func sendAmpResponse(
 
 	ao.AmpTargetingValues = targets
 
-	// Fixes #231
-	enc := json.NewEncoder(w) // nosemgrep: json-encoder-needs-type
-	enc.SetEscapeHTML(false)
 	// Explicitly set content type to text/plain, which had previously been
 	// the implied behavior from the time the project was launched.
 	// It's unclear why text/plain was chosen or if it was an oversight,
 	// nevertheless we will keep it as such for compatibility reasons.
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 
+	exitpointResponse, status := hookExecutor.ExecuteExitpointStage(ampResponse, http.StatusOK, w)
+	ao.HookExecutionOutcome = hookExecutor.GetOutcomes()
+
 	// If an error happens when encoding the response, there isn't much we can do.
 	// If we've sent _any_ bytes, then Go would have sent the 200 status code first.
 	// That status code can't be un-sent... so the best we can do is log the error.
-	if err := enc.Encode(ampResponse); err != nil {
+	if err := writeResponse(w, status, exitpointResponse); err != nil {
 		labels.RequestStatus = metrics.RequestStatusNetworkErr
 		ao.Errors = append(ao.Errors, fmt.Errorf("/openrtb2/amp Failed to send response: %v", err))
 	}
@@ -1,6 +1,7 @@
 package openrtb2
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"encoding/json"
@@ -186,9 +187,20 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	}
 
 	activityControl := privacy.ActivityControl{}
+	var lateBids *exchange.LateBids
+	var shadowBids *exchange.ShadowBids
 	defer func() {
 		deps.metricsEngine.RecordRequest(labels)
 		deps.metricsEngine.RecordRequestTime(labels, time.Since(start))
+		if lateBids != nil || shadowBids != nil {
+			// analytics are logged once the late and shadow bidders responded
+			go func() {
+				ao.LateBids = lateBids.Wait()
+				ao.ShadowBids = shadowBids.Wait()
+				deps.analytics.LogAuctionObject(&ao, activityControl)
+			}()
+			return
+		}
 		deps.analytics.LogAuctionObject(&ao, activityControl)
 	}()
 
@@ -196,7 +208,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	setBrowsingTopicsHeader(w, r)
 
 	req, impExtInfoMap, storedAuctionResponses, storedBidResponses, bidderImpReplaceImp, account, errL := deps.parseRequest(r, &labels, hookExecutor)
-	if errortypes.ContainsFatalError(errL) && writeError(errL, w, &labels) {
+	if errortypes.ContainsFatalError(errL) && writeError(errL, w, hookExecutor, &labels) {
 		return
 	}
 
@@ -239,7 +251,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	err := deps.setIntegrationType(req, account)
 	if err != nil {
 		errL = append(errL, err)
-		writeError(errL, w, &labels)
+		writeError(errL, w, hookExecutor, &labels)
 		return
 	}
 	secGPC := r.Header.Get("Sec-GPC")
@@ -273,21 +285,25 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	}()
 	ao.RequestWrapper = req
 	ao.Account = account
+	if labels.Experiment != "" {
+		ao.ExperimentArm = &openrtb_ext.ExperimentArm{Experiment: labels.Experiment, Name: labels.ExperimentArm}
+	}
 	var response *openrtb2.BidResponse
 	if auctionResponse != nil {
 		response = auctionResponse.BidResponse
+		lateBids = auctionResponse.LateBids
+		shadowBids = auctionResponse.ShadowBids
 	}
 	ao.Response = response
 	ao.SeatNonBid = auctionResponse.GetSeatNonBid()
 	rejectErr, isRejectErr := hookexecution.CastRejectErr(err)
 	if err != nil && !isRejectErr {
 		if errortypes.ReadCode(err) == errortypes.BadInputErrorCode {
-			writeError([]error{err}, w, &labels)
+			writeError([]error{err}, w, hookExecutor, &labels)
 			return
 		}
 		labels.RequestStatus = metrics.RequestStatusErr
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, "Critical error while running the auction: %v", err)
+		writeErrorResponse(w, hookExecutor, http.StatusInternalServerError, []byte(fmt.Sprintf("Critical error while running the auction: %v", err)))
 		glog.Errorf("/openrtb2/auction Critical error: %v", err)
 		ao.Status = http.StatusInternalServerError
 		ao.Errors = append(ao.Errors, err)
@@ -380,16 +396,15 @@ func sendAuctionResponse(
 		}
 	}
 
-	// Fixes #231
-	enc := json.NewEncoder(w)
-	enc.SetEscapeHTML(false)
-
 	w.Header().Set("Content-Type", "application/json")
 
+	exitpointResponse, status := hookExecutor.ExecuteExitpointStage(response, http.StatusOK, w)
+	ao.HookExecutionOutcome = hookExecutor.GetOutcomes()
+
 	// If an error happens when encoding the response, there isn't much we can do.
 	// If we've sent _any_ bytes, then Go would have sent the 200 status code first.
 	// That status code can't be un-sent... so the best we can do is log the error.
-	if err := enc.Encode(response); err != nil {
+	if err := writeResponse(w, status, exitpointResponse); err != nil {
 		labels.RequestStatus = metrics.RequestStatusNetworkErr
 		ao.Errors = append(ao.Errors, fmt.Errorf("/openrtb2/auction Failed to send response: %v", err))
 	}
@@ -397,6 +412,32 @@ func sendAuctionResponse(
 	return labels, ao
 }
 
+// writeResponse sends the response returned by the exitpoint stage to the client.
+// A []byte response is written as is, any other response is encoded to JSON.
+func writeResponse(w http.ResponseWriter, status int, response any) error {
+	w.WriteHeader(status)
+
+	if body, ok := response.([]byte); ok {
+		_, err := w.Write(body)
+		return err
+	}
+
+	// Fixes #231
+	enc := json.NewEncoder(w) // nosemgrep: json-encoder-needs-type
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(response)
+}
+
+// writeErrorResponse runs the exitpoint stage over an error response before sending it to the client,
+// so the hooks see every response, not only the bid responses.
+func writeErrorResponse(w http.ResponseWriter, hookExecutor hookexecution.HookStageExecutor, status int, body []byte) {
+	exitpointResponse, exitpointStatus := hookExecutor.ExecuteExitpointStage(body, status, w)
+	if err := writeResponse(w, exitpointStatus, exitpointResponse); err != nil {
+		glog.Errorf("Failed to send error response: %v", err)
+	}
+}
+
 // setBrowsingTopicsHeader always set the Observe-Browsing-Topics header to a value of ?1 if the Sec-Browsing-Topics is present in request
 func setBrowsingTopicsHeader(w http.ResponseWriter, r *http.Request) {
 	if value := r.Header.Get(secBrowsingTopics); value != "" {
@@ -543,6 +584,16 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 		return
 	}
 
+	// Assign the request to an arm of the account experiment, which may patch the account and the request
+	experimentAccount, experimentArm, err := accountService.ApplyExperiment(account, req)
+	if err != nil {
+		errs = append(errs, &errortypes.Warning{Message: err.Error(), WarningCode: errortypes.ExperimentWarningCode})
+	} else if experimentArm != nil {
+		account = experimentAccount
+		labels.Experiment = experimentArm.Experiment
+		labels.ExperimentArm = experimentArm.Name
+	}
+
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	if errsL := deps.setFieldsImplicitly(httpRequest, req, account); len(errsL) > 0 {
 		errs = append(errs, errsL...)
@@ -1180,6 +1231,30 @@ func validateVideo(video *openrtb2.Video, impIndex int) error {
 		return fmt.Errorf("request.imp[%d].video.maxbitrate must be a positive number", impIndex)
 	}
 
+	return validateVideoPod(video, impIndex)
+}
+
+// validateVideoPod validates the OpenRTB 2.6 ad pod fields of a video imp.
+func validateVideoPod(video *openrtb2.Video, impIndex int) error {
+	if video.PodDur < 0 {
+		return fmt.Errorf("request.imp[%d].video.poddur must be a positive number", impIndex)
+	}
+	if video.MaxSeq < 0 {
+		return fmt.Errorf("request.imp[%d].video.maxseq must be a positive number", impIndex)
+	}
+	if video.SlotInPod < adcom1.SlotPosLast || video.SlotInPod > adcom1.SlotPosFirstOrLast {
+		return fmt.Errorf("request.imp[%d].video.slotinpod must be -1, 0, 1 or 2", impIndex)
+	}
+	if len(video.RqdDurs) > 0 {
+		if video.MinDuration > 0 || video.MaxDuration > 0 {
+			return fmt.Errorf("request.imp[%d].video.rqddurs must not be used along with minduration or maxduration", impIndex)
+		}
+		for _, dur := range video.RqdDurs {
+			if dur <= 0 {
+				return fmt.Errorf("request.imp[%d].video.rqddurs must contain positive numbers", impIndex)
+			}
+		}
+	}
 	return nil
 }
 
@@ -1733,6 +1808,23 @@ func validateTargeting(t *openrtb_ext.ExtRequestTargeting) error {
 				return err
 			}
 		}
+		if t.MediaTypePriority != nil {
+			if err := validateMediaTypePriority(t.MediaTypePriority); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateMediaTypePriority(mtp *openrtb_ext.ExtMediaTypePriority) error {
+	for _, mediaType := range mtp.Priority {
+		if _, err := openrtb_ext.ParseBidType(string(mediaType)); err != nil {
+			return fmt.Errorf("Media type priority error: invalid media type %s", mediaType)
+		}
+	}
+	if mtp.Bias != 0 && mtp.Bias < 1 {
+		return errors.New("Media type priority error: bias must be 0 or greater than or equal to 1")
 	}
 	return nil
 }
@@ -2055,9 +2147,42 @@ func (deps *endpointDeps) setFieldsImplicitly(httpReq *http.Request, r *openrtb_
 	setAuctionTypeImplicitly(r)
 
 	errs := setSecBrowsingTopicsImplicitly(httpReq, r, account)
+	if err := deps.setAccountAliasesImplicitly(r, account); err != nil {
+		errs = append(errs, err)
+	}
 	return errs
 }
 
+// setAccountAliasesImplicitly adds the bidders the account declares to the request aliases, as aliases of the
+// genericortb adapter, if the host enabled it. The aliases of the request take precedence.
+func (deps *endpointDeps) setAccountAliasesImplicitly(r *openrtb_ext.RequestWrapper, account *config.Account) error {
+	if account == nil || len(account.BidderAliases) == 0 {
+		return nil
+	}
+	if _, ok := deps.bidderMap[string(openrtb_ext.BidderGenericORTB)]; !ok {
+		return nil
+	}
+
+	reqExt, err := r.GetRequestExt()
+	if err != nil {
+		return err
+	}
+	prebid := reqExt.GetPrebid()
+	if prebid == nil {
+		prebid = &openrtb_ext.ExtRequestPrebid{}
+	}
+	aliases := make(map[string]string, len(account.BidderAliases)+len(prebid.Aliases))
+	for alias := range account.BidderAliases {
+		aliases[alias] = string(openrtb_ext.BidderGenericORTB)
+	}
+	for alias, bidder := range prebid.Aliases {
+		aliases[alias] = bidder
+	}
+	prebid.Aliases = aliases
+	reqExt.SetPrebid(prebid)
+	return nil
+}
+
 // setDeviceImplicitly uses implicit info from httpReq to populate bidReq.Device
 func setDeviceImplicitly(httpReq *http.Request, r *openrtb_ext.RequestWrapper, ipValidtor iputil.IPValidator) {
 	setIPImplicitly(httpReq, r, ipValidtor)
@@ -2420,7 +2545,7 @@ func setDoNotTrackImplicitly(httpReq *http.Request, r *openrtb_ext.RequestWrappe
 }
 
 // Write(return) errors to the client, if any. Returns true if errors were found.
-func writeError(errs []error, w http.ResponseWriter, labels *metrics.Labels) bool {
+func writeError(errs []error, w http.ResponseWriter, hookExecutor hookexecution.HookStageExecutor, labels *metrics.Labels) bool {
 	var rc bool = false
 	if len(errs) > 0 {
 		httpStatus := http.StatusBadRequest
@@ -2437,16 +2562,22 @@ func writeError(errs []error, w http.ResponseWriter, labels *metrics.Labels) boo
 				break
 			}
 		}
-		w.WriteHeader(httpStatus)
 		labels.RequestStatus = metricsStatus
-		for _, err := range errs {
-			fmt.Fprintf(w, "Invalid request: %s\n", err.Error())
-		}
+		writeErrorResponse(w, hookExecutor, httpStatus, invalidRequestBody(errs))
 		rc = true
 	}
 	return rc
 }
 
+// invalidRequestBody lists the errors of a rejected request, one per line.
+func invalidRequestBody(errs []error) []byte {
+	var body bytes.Buffer
+	for _, err := range errs {
+		fmt.Fprintf(&body, "Invalid request: %s\n", err.Error())
+	}
+	return body.Bytes()
+}
+
 // Returns the account ID for the request
 func getAccountID(pub *openrtb2.Publisher) string {
 	if pub != nil {
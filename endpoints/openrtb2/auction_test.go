@@ -2150,6 +2150,35 @@ func TestValidateTargeting(t *testing.T) {
 			},
 			expectedError: errors.New("Price granularity error: range list must be ordered with increasing \"max\""),
 		},
+		{
+			name: "media type priority correct",
+			givenTargeting: &openrtb_ext.ExtRequestTargeting{
+				MediaTypePriority: &openrtb_ext.ExtMediaTypePriority{
+					Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeBanner},
+					Bias:     1.2,
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "media type priority invalid media type",
+			givenTargeting: &openrtb_ext.ExtRequestTargeting{
+				MediaTypePriority: &openrtb_ext.ExtMediaTypePriority{
+					Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeVideo, "display"},
+				},
+			},
+			expectedError: errors.New("Media type priority error: invalid media type display"),
+		},
+		{
+			name: "media type priority bias below one",
+			givenTargeting: &openrtb_ext.ExtRequestTargeting{
+				MediaTypePriority: &openrtb_ext.ExtMediaTypePriority{
+					Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeVideo},
+					Bias:     0.8,
+				},
+			},
+			expectedError: errors.New("Media type priority error: bias must be 0 or greater than or equal to 1"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -5841,6 +5870,64 @@ func TestSendAuctionResponse_LogsErrors(t *testing.T) {
 	}
 }
 
+func TestSendAuctionResponse_ExitpointStage(t *testing.T) {
+	testCases := []struct {
+		description         string
+		planBuilder         hooks.ExecutionPlanBuilder
+		expectedBody        string
+		expectedStatus      int
+		expectedContentType string
+	}{
+		{
+			description:         "Response encoded to JSON if exitpoint stage not configured",
+			planBuilder:         hooks.EmptyPlanBuilder{},
+			expectedBody:        `{"id":"some-id"}` + "\n",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+		},
+		{
+			description:         "Response replaced by exitpoint hook written as is",
+			planBuilder:         mockPlanBuilder{exitpointPlan: makePlan[hookstage.Exitpoint](mockExitpointHook{})},
+			expectedBody:        "<VAST></VAST>",
+			expectedStatus:      http.StatusAccepted,
+			expectedContentType: "application/xml",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			writer := httptest.NewRecorder()
+			hookExecutor := hookexecution.NewHookExecutor(test.planBuilder, hookexecution.EndpointAuction, &metricsConfig.NilMetricsEngine{})
+			response := &openrtb2.BidResponse{ID: "some-id"}
+
+			_, ao := sendAuctionResponse(writer, hookExecutor, response, &openrtb2.BidRequest{ID: "some-id"}, &config.Account{}, metrics.Labels{}, analytics.AuctionObject{})
+
+			assert.Empty(t, ao.Errors, "Unexpected errors.")
+			assert.Equal(t, test.expectedStatus, writer.Code, "Invalid HTTP response status.")
+			assert.Equal(t, test.expectedBody, writer.Body.String(), "Invalid response body.")
+			assert.Equal(t, test.expectedContentType, writer.Header().Get("Content-Type"), "Invalid response content type.")
+		})
+	}
+}
+
+type mockExitpointHook struct{}
+
+func (m mockExitpointHook) HandleExitpointHook(
+	_ context.Context,
+	_ hookstage.ModuleInvocationContext,
+	_ hookstage.ExitpointPayload,
+) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+	changeSet := hookstage.ChangeSet[hookstage.ExitpointPayload]{}
+	changeSet.AddMutation(func(payload hookstage.ExitpointPayload) (hookstage.ExitpointPayload, error) {
+		payload.Headers.Set("Content-Type", "application/xml")
+		payload.StatusCode = http.StatusAccepted
+		payload.Response = []byte("<VAST></VAST>")
+		return payload, nil
+	}, hookstage.MutationUpdate, "response")
+
+	return hookstage.HookResult[hookstage.ExitpointPayload]{ChangeSet: changeSet}, nil
+}
+
 func TestParseRequestMultiBid(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -6619,6 +6706,62 @@ func TestSetSecBrowsingTopicsImplicitly(t *testing.T) {
 	}
 }
 
+func TestSetAccountAliasesImplicitly(t *testing.T) {
+	account := &config.Account{BidderAliases: config.AccountBidderAliases{
+		"mybidder":    {Endpoint: "https://mybidder.example.com"},
+		"otherbidder": {Endpoint: "https://otherbidder.example.com"},
+	}}
+
+	testCases := []struct {
+		name            string
+		bidderMap       map[string]openrtb_ext.BidderName
+		requestExt      json.RawMessage
+		account         *config.Account
+		expectedAliases map[string]string
+	}{
+		{
+			name:            "account-aliases-added",
+			bidderMap:       map[string]openrtb_ext.BidderName{"genericortb": openrtb_ext.BidderGenericORTB},
+			account:         account,
+			expectedAliases: map[string]string{"mybidder": "genericortb", "otherbidder": "genericortb"},
+		},
+		{
+			name:            "request-aliases-take-precedence",
+			bidderMap:       map[string]openrtb_ext.BidderName{"genericortb": openrtb_ext.BidderGenericORTB},
+			requestExt:      json.RawMessage(`{"prebid":{"aliases":{"mybidder":"appnexus","requestalias":"rubicon"}}}`),
+			account:         account,
+			expectedAliases: map[string]string{"mybidder": "appnexus", "otherbidder": "genericortb", "requestalias": "rubicon"},
+		},
+		{
+			name:      "genericortb-disabled",
+			bidderMap: map[string]openrtb_ext.BidderName{"appnexus": openrtb_ext.BidderAppnexus},
+			account:   account,
+		},
+		{
+			name:      "no-account-aliases",
+			bidderMap: map[string]openrtb_ext.BidderName{"genericortb": openrtb_ext.BidderGenericORTB},
+			account:   &config.Account{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			deps := &endpointDeps{bidderMap: test.bidderMap}
+			req := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Ext: test.requestExt}}
+
+			assert.NoError(t, deps.setAccountAliasesImplicitly(req, test.account))
+
+			reqExt, err := req.GetRequestExt()
+			assert.NoError(t, err)
+			var aliases map[string]string
+			if prebid := reqExt.GetPrebid(); prebid != nil {
+				aliases = prebid.Aliases
+			}
+			assert.Equal(t, test.expectedAliases, aliases)
+		})
+	}
+}
+
 func sortUserData(user *openrtb2.User) {
 	if user != nil {
 		sort.Slice(user.Data, func(i, j int) bool {
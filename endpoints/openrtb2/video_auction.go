@@ -57,6 +57,7 @@ func NewVideoEndpoint(
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
 	cache prebid_cache_client.Client,
+	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
 	tmaxAdjustments *exchange.TmaxAdjustmentsPreprocessed,
 ) (httprouter.Handle, error) {
 
@@ -91,7 +92,7 @@ func NewVideoEndpoint(
 		videoEndpointRegexp,
 		ipValidator,
 		empty_fetcher.EmptyFetcher{},
-		hooks.EmptyPlanBuilder{},
+		hookExecutionPlanBuilder,
 		tmaxAdjustments,
 		openrtb_ext.NormalizeBidderName}).VideoAuctionEndpoint), nil
 }
@@ -151,6 +152,10 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	debugLog.DebugEnabledOrOverridden = debugLog.Enabled || debugLog.DebugOverride
 
 	activityControl := privacy.ActivityControl{}
+	var lateBids *exchange.LateBids
+	var shadowBids *exchange.ShadowBids
+	// the video endpoint only runs the exitpoint stage, for the error responses too
+	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointVideo, deps.metricsEngine)
 
 	defer func() {
 		if len(debugLog.CacheKey) > 0 && vo.VideoResponse == nil {
@@ -161,6 +166,15 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		}
 		deps.metricsEngine.RecordRequest(labels)
 		deps.metricsEngine.RecordRequestTime(labels, time.Since(start))
+		if lateBids != nil || shadowBids != nil {
+			// analytics are logged once the late and shadow bidders responded
+			go func() {
+				vo.LateBids = lateBids.Wait()
+				vo.ShadowBids = shadowBids.Wait()
+				deps.analytics.LogVideoObject(&vo, activityControl)
+			}()
+			return
+		}
 		deps.analytics.LogVideoObject(&vo, activityControl)
 	}()
 
@@ -173,7 +187,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 	requestJson, err := io.ReadAll(lr)
 	if err != nil {
-		handleError(&labels, w, []error{err}, &vo, &debugLog)
+		handleError(&labels, w, hookExecutor, []error{err}, &vo, &debugLog)
 		return
 	}
 
@@ -192,27 +206,27 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 
 	if err != nil {
 		if deps.cfg.VideoStoredRequestRequired {
-			handleError(&labels, w, []error{err}, &vo, &debugLog)
+			handleError(&labels, w, hookExecutor, []error{err}, &vo, &debugLog)
 			return
 		}
 	} else {
 		storedRequest, errs := deps.loadStoredVideoRequest(context.Background(), storedRequestId)
 		if len(errs) > 0 {
-			handleError(&labels, w, errs, &vo, &debugLog)
+			handleError(&labels, w, hookExecutor, errs, &vo, &debugLog)
 			return
 		}
 
 		//merge incoming req with stored video req
 		resolvedRequest, err = jsonpatch.MergePatch(storedRequest, requestJson)
 		if err != nil {
-			handleError(&labels, w, []error{err}, &vo, &debugLog)
+			handleError(&labels, w, hookExecutor, []error{err}, &vo, &debugLog)
 			return
 		}
 	}
 	//unmarshal and validate combined result
 	videoBidReq, errL, podErrors := deps.parseVideoRequest(resolvedRequest, r.Header)
 	if len(errL) > 0 {
-		handleError(&labels, w, errL, &vo, &debugLog)
+		handleError(&labels, w, hookExecutor, errL, &vo, &debugLog)
 		return
 	}
 
@@ -222,7 +236,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	if deps.defaultRequest {
 		if err := jsonutil.UnmarshalValid(deps.defReqJSON, bidReq); err != nil {
 			err = fmt.Errorf("Invalid JSON in Default Request Settings: %s", err)
-			handleError(&labels, w, []error{err}, &vo, &debugLog)
+			handleError(&labels, w, hookExecutor, []error{err}, &vo, &debugLog)
 			return
 		}
 	}
@@ -250,7 +264,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		}
 		err := fmt.Errorf("all pods are incorrect: %s", strings.Join(resPodErr, "; "))
 		errL = append(errL, err)
-		handleError(&labels, w, errL, &vo, &debugLog)
+		handleError(&labels, w, hookExecutor, errL, &vo, &debugLog)
 		return
 	}
 
@@ -261,7 +275,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	bidReqWrapper := &openrtb_ext.RequestWrapper{BidRequest: bidReq}
 
 	if err := ortb.SetDefaults(bidReqWrapper); err != nil {
-		handleError(&labels, w, errL, &vo, &debugLog)
+		handleError(&labels, w, hookExecutor, errL, &vo, &debugLog)
 		return
 	}
 
@@ -294,10 +308,21 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	// Look up account now that we have resolved the pubID value
 	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID, deps.metricsEngine)
 	if len(acctIDErrs) > 0 {
-		handleError(&labels, w, acctIDErrs, &vo, &debugLog)
+		handleError(&labels, w, hookExecutor, acctIDErrs, &vo, &debugLog)
 		return
 	}
 
+	// Assign the request to an arm of the account experiment, which may patch the account and the request
+	experimentAccount, experimentArm, err := accountService.ApplyExperiment(account, bidReqWrapper)
+	if err != nil {
+		errL = append(errL, &errortypes.Warning{Message: err.Error(), WarningCode: errortypes.ExperimentWarningCode})
+	} else if experimentArm != nil {
+		account = experimentAccount
+		labels.Experiment = experimentArm.Experiment
+		labels.ExperimentArm = experimentArm.Name
+		vo.ExperimentArm = experimentArm
+	}
+
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	if errs := deps.setFieldsImplicitly(r, bidReqWrapper, account); len(errs) > 0 {
 		errL = append(errL, errs...)
@@ -306,11 +331,13 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	errs := deps.validateRequest(account, r, bidReqWrapper, false, false, nil, false)
 	errL = append(errL, errs...)
 	if errortypes.ContainsFatalError(errL) {
-		handleError(&labels, w, errL, &vo, &debugLog)
+		handleError(&labels, w, hookExecutor, errL, &vo, &debugLog)
 		return
 	}
 
 	activityControl = privacy.NewActivityControl(&account.Privacy)
+	hookExecutor.SetActivityControl(activityControl)
+	hookExecutor.SetAccount(account)
 
 	warnings := errortypes.WarningOnly(errL)
 
@@ -340,12 +367,14 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	var response *openrtb2.BidResponse
 	if auctionResponse != nil {
 		response = auctionResponse.BidResponse
+		lateBids = auctionResponse.LateBids
+		shadowBids = auctionResponse.ShadowBids
 	}
 	vo.Response = response
 	vo.SeatNonBid = auctionResponse.GetSeatNonBid()
 	if err != nil {
 		errL := []error{err}
-		handleError(&labels, w, errL, &vo, &debugLog)
+		handleError(&labels, w, hookExecutor, errL, &vo, &debugLog)
 		return
 	}
 
@@ -353,7 +382,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	bidResp, err := buildVideoResponse(response, podErrors)
 	if err != nil {
 		errL := []error{err}
-		handleError(&labels, w, errL, &vo, &debugLog)
+		handleError(&labels, w, hookExecutor, errL, &vo, &debugLog)
 		return
 	}
 	if bidReq.Test == 1 {
@@ -381,14 +410,22 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 
 	vo.VideoResponse = bidResp
 
-	resp, err := jsonutil.Marshal(bidResp)
-	if err != nil {
-		errL := []error{err}
-		handleError(&labels, w, errL, &vo, &debugLog)
-		return
+	w.Header().Set("Content-Type", "application/json")
+
+	exitpointResponse, status := hookExecutor.ExecuteExitpointStage(bidResp, http.StatusOK, w)
+
+	resp, ok := exitpointResponse.([]byte)
+	if !ok {
+		resp, err = jsonutil.Marshal(exitpointResponse)
+		if err != nil {
+			errL := []error{err}
+			// the exitpoint stage already ran over this response
+			handleError(&labels, w, hookexecution.EmptyHookExecutor{}, errL, &vo, &debugLog)
+			return
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	w.Write(resp)
 }
 
@@ -399,7 +436,7 @@ func cleanupVideoBidRequest(videoReq *openrtb_ext.BidRequestVideo, podErrors []P
 	return videoReq
 }
 
-func handleError(labels *metrics.Labels, w http.ResponseWriter, errL []error, vo *analytics.VideoObject, debugLog *exchange.DebugLog) {
+func handleError(labels *metrics.Labels, w http.ResponseWriter, hookExecutor hookexecution.HookStageExecutor, errL []error, vo *analytics.VideoObject, debugLog *exchange.DebugLog) {
 	if debugLog != nil && debugLog.DebugEnabledOrOverridden {
 		if rawUUID, err := uuid.NewV4(); err == nil {
 			debugLog.CacheKey = rawUUID.String()
@@ -426,9 +463,8 @@ func handleError(labels *metrics.Labels, w http.ResponseWriter, errL []error, vo
 		}
 		errors = fmt.Sprintf("%s %s", errors, er.Error())
 	}
-	w.WriteHeader(status)
 	vo.Status = status
-	fmt.Fprintf(w, "Critical error while running the video endpoint: %v", errors)
+	writeErrorResponse(w, hookExecutor, status, []byte(fmt.Sprintf("Critical error while running the video endpoint: %v", errors)))
 	glog.Errorf("/openrtb2/video Critical error: %v", errors)
 	vo.Errors = append(vo.Errors, errL...)
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/prebid/prebid-server/v2/errortypes"
 	"github.com/prebid/prebid-server/v2/exchange"
 	"github.com/prebid/prebid-server/v2/hooks"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
 	"github.com/prebid/prebid-server/v2/metrics"
 	metricsConfig "github.com/prebid/prebid-server/v2/metrics/config"
 	"github.com/prebid/prebid-server/v2/openrtb_ext"
@@ -865,7 +866,7 @@ func TestHandleError(t *testing.T) {
 		}
 
 		recorder := httptest.NewRecorder()
-		handleError(&labels, recorder, tt.giveErrors, &vo, nil)
+		handleError(&labels, recorder, hookexecution.EmptyHookExecutor{}, tt.giveErrors, &vo, nil)
 
 		assert.Equal(t, tt.wantMetricsStatus, labels.RequestStatus, tt.description)
 		assert.Equal(t, tt.wantCode, recorder.Code, tt.description)
@@ -1014,7 +1015,7 @@ func TestHandleErrorDebugLog(t *testing.T) {
 		DebugOverride:            false,
 		DebugEnabledOrOverridden: true,
 	}
-	handleError(&labels, recorder, []error{err1, err2}, &vo, &debugLog)
+	handleError(&labels, recorder, hookexecution.EmptyHookExecutor{}, []error{err1, err2}, &vo, &debugLog)
 
 	assert.Equal(t, metrics.RequestStatusErr, labels.RequestStatus, "labels.RequestStatus should indicate an error")
 	assert.Equal(t, 500, recorder.Code, "Error status should be written to writer")
@@ -17,6 +17,7 @@ const (
 	TmaxTimeoutErrorCode
 	FailedToMarshalErrorCode
 	FailedToUnmarshalErrorCode
+	BidderCircuitOpenErrorCode
 )
 
 // Defines numeric codes for well-known warnings.
@@ -34,6 +35,8 @@ const (
 	InvalidBidResponseDSAWarningCode
 	SecCookieDeprecationLenWarningCode
 	SecBrowsingTopicsWarningCode
+	BidValidationWarningCode
+	ExperimentWarningCode
 )
 
 // Coder provides an error or warning code with severity.
@@ -39,6 +39,26 @@ func (err *TmaxTimeout) Severity() Severity {
 	return SeverityFatal
 }
 
+// BidderCircuitOpen should be used to flag that a request was not sent to a bidder because its circuit breaker
+// is open after too many failed requests.
+//
+// BidderCircuitOpen will not be written to the app log, since the failures which opened the circuit already were.
+type BidderCircuitOpen struct {
+	Message string
+}
+
+func (err *BidderCircuitOpen) Error() string {
+	return err.Message
+}
+
+func (err *BidderCircuitOpen) Code() int {
+	return BidderCircuitOpenErrorCode
+}
+
+func (err *BidderCircuitOpen) Severity() Severity {
+	return SeverityFatal
+}
+
 // BadInput should be used when returning errors which are caused by bad input.
 // It should _not_ be used if the error is a server-side issue (e.g. failed to send the external request).
 //
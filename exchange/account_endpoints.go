@@ -0,0 +1,195 @@
+package exchange
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+// accountAdapters builds the adapters of the bidders an account points at its own endpoints, and of the bidders an
+// account declares as aliases of the genericortb adapter. The adapters are built with the host config of the bidder
+// overridden by the account config, and are cached until the account config of the bidder changes. All other
+// requests keep using the adapters built at startup.
+type accountAdapters struct {
+	builders map[openrtb_ext.BidderName]adapters.Builder
+	infos    config.BidderInfos
+	server   config.Server
+	cache    sync.Map
+}
+
+type accountAdapterKey struct {
+	account string
+	bidder  openrtb_ext.BidderName
+}
+
+// accountAdapter is a cached adapter along with the account config it was built with, either an endpoint override
+// or an alias
+type accountAdapter struct {
+	config any
+	bidder adapters.Bidder
+}
+
+func newAccountAdapters(builders map[openrtb_ext.BidderName]adapters.Builder, infos config.BidderInfos, server config.Server) *accountAdapters {
+	return &accountAdapters{
+		builders: builders,
+		infos:    infos,
+		server:   server,
+	}
+}
+
+// get returns the adapter to use for the bidder request, or nil if the account doesn't configure the bidder. Request
+// aliases of other bidders take precedence over the account aliases. Endpoint overrides are looked up by the bidder
+// name first, so that an account can override a request alias on its own.
+func (aa *accountAdapters) get(account *config.Account, bidderRequest BidderRequest) (adapters.Bidder, error) {
+	if aa == nil {
+		return nil, nil
+	}
+
+	if alias, ok := account.BidderAliases[bidderRequest.BidderName.String()]; ok && bidderRequest.BidderCoreName == openrtb_ext.BidderGenericORTB {
+		bidder, err := aa.load(account.ID, bidderRequest.BidderName, alias, func() (adapters.Bidder, error) {
+			return aa.buildAlias(bidderRequest.BidderName, alias)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: account alias: %v", bidderRequest.BidderName, err)
+		}
+		return bidder, nil
+	}
+
+	if len(account.BidderEndpoints) == 0 {
+		return nil, nil
+	}
+	override, ok := account.BidderEndpoints[bidderRequest.BidderName.String()]
+	if !ok {
+		if override, ok = account.BidderEndpoints[bidderRequest.BidderCoreName.String()]; !ok {
+			return nil, nil
+		}
+	}
+
+	bidder, err := aa.load(account.ID, bidderRequest.BidderName, override, func() (adapters.Bidder, error) {
+		return aa.build(bidderRequest.BidderCoreName, override)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: account endpoint: %v", bidderRequest.BidderName, err)
+	}
+	return bidder, nil
+}
+
+// load returns the cached adapter of the account bidder if it was built with the same config, or builds it
+func (aa *accountAdapters) load(accountID string, bidderName openrtb_ext.BidderName, cfg any, build func() (adapters.Bidder, error)) (adapters.Bidder, error) {
+	key := accountAdapterKey{account: accountID, bidder: bidderName}
+	if cached, ok := aa.cache.Load(key); ok && reflect.DeepEqual(cached.(*accountAdapter).config, cfg) {
+		return cached.(*accountAdapter).bidder, nil
+	}
+
+	bidder, err := build()
+	if err != nil {
+		return nil, err
+	}
+	aa.cache.Store(key, &accountAdapter{config: cfg, bidder: bidder})
+	return bidder, nil
+}
+
+// buildAlias builds the genericortb adapter of a bidder declared by the account
+func (aa *accountAdapters) buildAlias(bidderName openrtb_ext.BidderName, alias config.AccountBidderAlias) (adapters.Bidder, error) {
+	builder, ok := aa.builders[openrtb_ext.BidderGenericORTB]
+	if !ok {
+		return nil, fmt.Errorf("builder not registered")
+	}
+
+	adapterInfo := config.Adapter{Endpoint: alias.Endpoint, GenericORTB: alias.GenericORTB}
+	if alias.OpenRTBVersion != "" {
+		adapterInfo.OpenRTB = &config.OpenRTBInfo{Version: alias.OpenRTBVersion}
+	}
+	bidder, err := builder(bidderName, adapterInfo, aa.server)
+	if err != nil {
+		return nil, err
+	}
+	return adapters.BuildInfoAwareBidder(bidder, aa.infos[string(openrtb_ext.BidderGenericORTB)]), nil
+}
+
+func (aa *accountAdapters) build(bidderName openrtb_ext.BidderName, override config.AccountBidderEndpoint) (adapters.Bidder, error) {
+	info, ok := aa.infos[string(bidderName)]
+	if !ok {
+		return nil, fmt.Errorf("unknown bidder")
+	}
+	builderName := bidderName
+	if len(info.AliasOf) > 0 {
+		parentBidderName, parentBidderFound := openrtb_ext.NormalizeBidderName(info.AliasOf)
+		if !parentBidderFound {
+			return nil, fmt.Errorf("unknown parent bidder: %v", info.AliasOf)
+		}
+		builderName = parentBidderName
+	}
+	builder, ok := aa.builders[builderName]
+	if !ok {
+		return nil, fmt.Errorf("builder not registered")
+	}
+
+	adapterInfo := buildAdapterInfo(info)
+	if override.Endpoint != "" {
+		adapterInfo.Endpoint = override.Endpoint
+	}
+	if override.ExtraAdapterInfo != "" {
+		adapterInfo.ExtraAdapterInfo = override.ExtraAdapterInfo
+	}
+	bidder, err := builder(bidderName, adapterInfo, aa.server)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(override.Headers) > 0 {
+		headers := http.Header{}
+		for name, value := range override.Headers {
+			headers.Set(name, value)
+		}
+		bidder = newEndpointHeadersBidder(bidder, headers)
+	}
+	return adapters.BuildInfoAwareBidder(bidder, info), nil
+}
+
+// endpointHeadersBidder sets the headers of an account endpoint on the requests of the bidder
+type endpointHeadersBidder struct {
+	adapters.Bidder
+	headers http.Header
+}
+
+// endpointHeadersTimeoutBidder is the endpointHeadersBidder of a bidder which supports timeout notifications
+type endpointHeadersTimeoutBidder struct {
+	*endpointHeadersBidder
+	timeoutBidder adapters.TimeoutBidder
+}
+
+// newEndpointHeadersBidder wraps the bidder so that it sets the headers on its requests, keeping its support of
+// timeout notifications
+func newEndpointHeadersBidder(bidder adapters.Bidder, headers http.Header) adapters.Bidder {
+	headersBidder := &endpointHeadersBidder{Bidder: bidder, headers: headers}
+	if timeoutBidder, ok := bidder.(adapters.TimeoutBidder); ok {
+		return &endpointHeadersTimeoutBidder{endpointHeadersBidder: headersBidder, timeoutBidder: timeoutBidder}
+	}
+	return headersBidder
+}
+
+func (b *endpointHeadersTimeoutBidder) MakeTimeoutNotification(req *adapters.RequestData) (*adapters.RequestData, []error) {
+	return b.timeoutBidder.MakeTimeoutNotification(req)
+}
+
+func (b *endpointHeadersBidder) MakeRequests(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	reqData, errs := b.Bidder.MakeRequests(request, reqInfo)
+	for _, data := range reqData {
+		if data.Headers != nil {
+			data.Headers = data.Headers.Clone()
+		} else {
+			data.Headers = http.Header{}
+		}
+		for name, values := range b.headers {
+			data.Headers[name] = values
+		}
+	}
+	return reqData, errs
+}
@@ -0,0 +1,237 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/currency"
+	"github.com/prebid/prebid-server/v2/gdpr"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	metricsConfig "github.com/prebid/prebid-server/v2/metrics/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// endpointBidder returns a single request to the endpoint it was built with
+type endpointBidder struct {
+	endpoint  string
+	extraInfo string
+}
+
+func (b *endpointBidder) MakeRequests(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	return []*adapters.RequestData{{Method: http.MethodPost, Uri: b.endpoint, Headers: http.Header{"Content-Type": []string{"application/json"}}}}, nil
+}
+
+func (b *endpointBidder) MakeBids(request *openrtb2.BidRequest, requestData *adapters.RequestData, responseData *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	return nil, nil
+}
+
+func TestAccountAdaptersGet(t *testing.T) {
+	builds := 0
+	builders := map[openrtb_ext.BidderName]adapters.Builder{
+		"appnexus": func(bidderName openrtb_ext.BidderName, config config.Adapter, server config.Server) (adapters.Bidder, error) {
+			builds++
+			return &endpointBidder{endpoint: config.Endpoint, extraInfo: config.ExtraAdapterInfo}, nil
+		},
+	}
+	infos := config.BidderInfos{
+		"appnexus": {
+			Endpoint:         "https://host.example.com",
+			ExtraAdapterInfo: "host",
+			Capabilities:     &config.CapabilitiesInfo{Site: &config.PlatformInfo{MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}}},
+		},
+		"alias": {AliasOf: "appnexus", Endpoint: "https://alias.example.com"},
+	}
+	builders[openrtb_ext.BidderGenericORTB] = builders["appnexus"]
+	aa := newAccountAdapters(builders, infos, config.Server{})
+
+	t.Run("no-override", func(t *testing.T) {
+		account := &config.Account{ID: "pub", BidderEndpoints: config.AccountBidderEndpoints{"rubicon": {Endpoint: "https://pub.example.com"}}}
+		bidder, err := aa.get(account, BidderRequest{BidderName: "appnexus", BidderCoreName: "appnexus"})
+		assert.NoError(t, err)
+		assert.Nil(t, bidder)
+	})
+
+	t.Run("override-cached-until-changed", func(t *testing.T) {
+		builds = 0
+		account := &config.Account{ID: "pub", BidderEndpoints: config.AccountBidderEndpoints{
+			"appnexus": {Endpoint: "https://pub.example.com", Headers: map[string]string{"x-dedicated": "pub"}},
+		}}
+		bidderRequest := BidderRequest{BidderName: "appnexus", BidderCoreName: "appnexus", BidRequest: &openrtb2.BidRequest{
+			Site: &openrtb2.Site{},
+			Imp:  []openrtb2.Imp{{ID: "imp1", Banner: &openrtb2.Banner{}}},
+		}}
+
+		bidder, err := aa.get(account, bidderRequest)
+		require.NoError(t, err)
+		reqData, _ := bidder.MakeRequests(bidderRequest.BidRequest, &adapters.ExtraRequestInfo{})
+		require.Len(t, reqData, 1)
+		assert.Equal(t, "https://pub.example.com", reqData[0].Uri)
+		assert.Equal(t, "pub", reqData[0].Headers.Get("X-Dedicated"))
+		assert.Equal(t, "application/json", reqData[0].Headers.Get("Content-Type"))
+
+		cachedBidder, err := aa.get(account, bidderRequest)
+		require.NoError(t, err)
+		assert.Same(t, bidder, cachedBidder)
+		assert.Equal(t, 1, builds)
+
+		account.BidderEndpoints = config.AccountBidderEndpoints{"appnexus": {Endpoint: "https://pub-eu.example.com"}}
+		bidder, err = aa.get(account, bidderRequest)
+		require.NoError(t, err)
+		reqData, _ = bidder.MakeRequests(bidderRequest.BidRequest, &adapters.ExtraRequestInfo{})
+		require.Len(t, reqData, 1)
+		assert.Equal(t, "https://pub-eu.example.com", reqData[0].Uri)
+		assert.Equal(t, 2, builds)
+	})
+
+	t.Run("alias-built-with-parent-builder", func(t *testing.T) {
+		account := &config.Account{ID: "pub", BidderEndpoints: config.AccountBidderEndpoints{"alias": {ExtraAdapterInfo: "pub"}}}
+		bidder, err := aa.get(account, BidderRequest{BidderName: "alias", BidderCoreName: "alias"})
+		require.NoError(t, err)
+		infoAware, ok := bidder.(*adapters.InfoAwareBidder)
+		require.True(t, ok, "account adapters should be info aware like the host adapters")
+		assert.Equal(t, &endpointBidder{endpoint: "https://alias.example.com", extraInfo: "pub"}, infoAware.Bidder)
+	})
+
+	t.Run("request-alias-uses-core-override", func(t *testing.T) {
+		account := &config.Account{ID: "pub", BidderEndpoints: config.AccountBidderEndpoints{"appnexus": {Endpoint: "https://pub.example.com"}}}
+		bidder, err := aa.get(account, BidderRequest{BidderName: "requestalias", BidderCoreName: "appnexus"})
+		require.NoError(t, err)
+		assert.NotNil(t, bidder)
+	})
+
+	t.Run("account-alias-built-with-genericortb-builder", func(t *testing.T) {
+		account := &config.Account{ID: "pub", BidderAliases: config.AccountBidderAliases{"mybidder": {Endpoint: "https://mybidder.example.com"}}}
+		bidder, err := aa.get(account, BidderRequest{BidderName: "mybidder", BidderCoreName: openrtb_ext.BidderGenericORTB})
+		require.NoError(t, err)
+		infoAware, ok := bidder.(*adapters.InfoAwareBidder)
+		require.True(t, ok, "account adapters should be info aware like the host adapters")
+		assert.Equal(t, &endpointBidder{endpoint: "https://mybidder.example.com"}, infoAware.Bidder)
+	})
+
+	t.Run("request-alias-takes-precedence-over-account-alias", func(t *testing.T) {
+		account := &config.Account{ID: "pub", BidderAliases: config.AccountBidderAliases{"mybidder": {Endpoint: "https://mybidder.example.com"}}}
+		bidder, err := aa.get(account, BidderRequest{BidderName: "mybidder", BidderCoreName: "appnexus"})
+		assert.NoError(t, err)
+		assert.Nil(t, bidder)
+	})
+
+	t.Run("unknown-bidder", func(t *testing.T) {
+		account := &config.Account{ID: "pub", BidderEndpoints: config.AccountBidderEndpoints{"unknown": {Endpoint: "https://pub.example.com"}}}
+		bidder, err := aa.get(account, BidderRequest{BidderName: "unknown", BidderCoreName: "unknown"})
+		assert.EqualError(t, err, "unknown: account endpoint: unknown bidder")
+		assert.Nil(t, bidder)
+	})
+}
+
+func TestBidderAdapterGetAdapter(t *testing.T) {
+	hostBidder := &endpointBidder{endpoint: "https://host.example.com"}
+	accountBidder := &endpointBidder{endpoint: "https://pub.example.com"}
+	bidder := &bidderAdapter{Bidder: hostBidder}
+
+	assert.Same(t, hostBidder, bidder.getAdapter(BidderRequest{}))
+	assert.Same(t, accountBidder, bidder.getAdapter(BidderRequest{adapter: accountBidder}))
+}
+
+func TestEndpointHeadersBidderTimeoutNotification(t *testing.T) {
+	notifyRequest := adapters.RequestData{Method: http.MethodGet, Uri: "https://pub.example.com/notify"}
+	headers := http.Header{"X-Dedicated": []string{"pub"}}
+
+	timeoutBidder, ok := newEndpointHeadersBidder(&notifyingBidder{notifyRequest: notifyRequest}, headers).(adapters.TimeoutBidder)
+	require.True(t, ok, "the timeout notifications of the bidder should be passed through")
+	toReq, errs := timeoutBidder.MakeTimeoutNotification(&adapters.RequestData{})
+	assert.Empty(t, errs)
+	assert.Equal(t, &notifyRequest, toReq)
+
+	_, ok = newEndpointHeadersBidder(&endpointBidder{}, headers).(adapters.TimeoutBidder)
+	assert.False(t, ok, "bidders without timeout notifications should not get any")
+}
+
+func TestDoRequestNotifiesAccountAdapterOfTimeouts(t *testing.T) {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	cancel()
+
+	notified := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/notify" {
+			close(notified)
+		}
+	}))
+	defer server.Close()
+
+	hostBidder := &notifyingBidder{notifyRequest: adapters.RequestData{Method: http.MethodGet, Uri: server.URL + "/host"}}
+	accountBidder := adapters.BuildInfoAwareBidder(
+		newEndpointHeadersBidder(&notifyingBidder{notifyRequest: adapters.RequestData{Method: http.MethodGet, Uri: server.URL + "/notify"}}, http.Header{}),
+		config.BidderInfo{})
+	bidder := &bidderAdapter{Bidder: hostBidder, Client: server.Client(), me: &metricsConfig.NilMetricsEngine{}}
+
+	bidder.doRequest(ctx, &adapters.RequestData{Method: http.MethodPost, Uri: server.URL}, time.Now(), nil, adaptiveTimeout{}, bidder.getAdapter(BidderRequest{adapter: accountBidder}))
+
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("the account adapter should be notified of the timeout")
+	}
+}
+
+func TestHoldAuctionRecordsNonBidsOfBiddersWithoutAccountAdapter(t *testing.T) {
+	// the host adapter must not be called, the mock fails on any call
+	hostBidder := &mockBidder{}
+	e := exchange{
+		cache: &wellBehavedCache{},
+		me:    &metricsConfig.NilMetricsEngine{},
+		gdprPermsBuilder: fakePermissionsBuilder{
+			permissions: &permissionsMock{allowAllBidders: true},
+		}.Builder,
+		currencyConverter: currency.NewRateConverter(&http.Client{}, "", 24*time.Hour),
+		categoriesFetcher: nilCategoryFetcher{},
+		bidIDGenerator:    &fakeBidIDGenerator{},
+		adapterMap: map[openrtb_ext.BidderName]AdaptedBidder{
+			"appnexus": AdaptBidder(hostBidder, nil, &config.Configuration{}, &metricsConfig.NilMetricsEngine{}, "appnexus", nil, ""),
+		},
+		// the account endpoint of appnexus fails to build as there's no builder for it
+		accountAdapters: newAccountAdapters(map[openrtb_ext.BidderName]adapters.Builder{}, config.BidderInfos{}, config.Server{}),
+	}
+	e.requestSplitter = requestSplitter{me: e.me, gdprPermsBuilder: e.gdprPermsBuilder}
+
+	request := &openrtb2.BidRequest{
+		ID: "req",
+		Imp: []openrtb2.Imp{
+			{ID: "imp1", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}, Ext: json.RawMessage(`{"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`)},
+			{ID: "imp2", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}, Ext: json.RawMessage(`{"prebid":{"bidder":{"appnexus":{"placementId":2}}}}`)},
+		},
+		Site: &openrtb2.Site{Page: "prebid.org"},
+		Ext:  json.RawMessage(`{"prebid":{"returnallbidstatus":true}}`),
+	}
+	auctionRequest := &AuctionRequest{
+		BidRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: request},
+		Account:           config.Account{ID: "pub", BidderEndpoints: config.AccountBidderEndpoints{"appnexus": {Endpoint: "https://pub.example.com"}}},
+		UserSyncs:         &emptyUsersync{},
+		HookExecutor:      &hookexecution.EmptyHookExecutor{},
+		TCF2Config:        gdpr.NewTCF2Config(config.TCF2{}, config.AccountGDPR{}),
+	}
+
+	response, err := e.HoldAuction(context.Background(), auctionRequest, &DebugLog{})
+
+	require.NoError(t, err)
+	assert.Empty(t, response.SeatBid)
+	require.NotNil(t, response.ExtBidResponse)
+	require.NotNil(t, response.ExtBidResponse.Prebid)
+	assert.Equal(t, []openrtb_ext.SeatNonBid{{
+		Seat: "appnexus",
+		NonBid: []openrtb_ext.NonBid{
+			{ImpId: "imp1", StatusCode: int(openrtb_ext.ErrorGeneral)},
+			{ImpId: "imp2", StatusCode: int(openrtb_ext.ErrorGeneral)},
+		},
+	}}, response.ExtBidResponse.Prebid.SeatNonBid)
+	hostBidder.AssertNotCalled(t, "MakeRequests", mock.Anything, mock.Anything)
+}
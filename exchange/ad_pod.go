@@ -0,0 +1,243 @@
+package exchange
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+// adPod groups the video imps of an OpenRTB 2.6 ad pod. Imps sharing a podid form a single pod, and an imp
+// with a pod duration but no podid is a pod on its own.
+type adPod struct {
+	imps map[string]*adPodSlot
+	// categories and adomains hold the values of the bids selected so far, for competitive separation
+	categories map[string]struct{}
+	adomains   map[string]struct{}
+}
+
+// adPodSlot tracks the bids selected for an imp of a pod. A structured imp holds a single bid, whereas
+// a dynamic imp, which has a pod duration, holds bids up to its pod duration and max sequence.
+type adPodSlot struct {
+	video     *openrtb2.Video
+	dynamic   bool
+	bids      int64
+	duration  int64
+	firstUsed bool
+	lastUsed  bool
+}
+
+// buildAdPods returns the ad pods of the request.
+func buildAdPods(imps []openrtb2.Imp) []*adPod {
+	var pods []*adPod
+	podsByID := make(map[string]*adPod)
+	for i := range imps {
+		video := imps[i].Video
+		if video == nil || (video.PodID == "" && video.PodDur == 0) {
+			continue
+		}
+
+		pod, ok := podsByID[video.PodID]
+		if !ok || video.PodID == "" {
+			pod = &adPod{
+				imps:       make(map[string]*adPodSlot),
+				categories: make(map[string]struct{}),
+				adomains:   make(map[string]struct{}),
+			}
+			pods = append(pods, pod)
+			if video.PodID != "" {
+				podsByID[video.PodID] = pod
+			}
+		}
+		pod.imps[imps[i].ID] = &adPodSlot{video: video, dynamic: video.PodDur > 0}
+	}
+	return pods
+}
+
+// assembleAdPods selects the bids filling the ad pods of the request. Bids are considered from the highest
+// price down, and a bid is selected if its duration fits its imp and if neither its categories nor its
+// advertiser domains were already selected in the pod. Bid durations are bucketed to the required durations
+// of their imp, and bids without a duration are never selected. Bids which are not selected are removed and
+// reported as seat non bids.
+func assembleAdPods(imps []openrtb2.Imp, seatBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, preferDeals bool, seatNonBids *nonBids) {
+	pods := buildAdPods(imps)
+	if len(pods) == 0 {
+		return
+	}
+
+	rejected := make(map[*entities.PbsOrtbBid]struct{})
+	for _, pod := range pods {
+		candidates := collectAdPodBids(pod, seatBids)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return isNewWinningBid(candidates[i].bid.Bid, candidates[j].bid.Bid, preferDeals)
+		})
+
+		for _, candidate := range candidates {
+			if reason, ok := pod.add(candidate.bid); !ok {
+				rejected[candidate.bid] = struct{}{}
+				seatNonBids.addBid(candidate.bid, int(reason), candidate.seat)
+			}
+		}
+	}
+
+	if len(rejected) == 0 {
+		return
+	}
+	for _, seatBid := range seatBids {
+		if seatBid == nil {
+			continue
+		}
+		bids := make([]*entities.PbsOrtbBid, 0, len(seatBid.Bids))
+		for _, bid := range seatBid.Bids {
+			if _, ok := rejected[bid]; !ok {
+				bids = append(bids, bid)
+			}
+		}
+		seatBid.Bids = bids
+	}
+}
+
+type adPodBid struct {
+	bid  *entities.PbsOrtbBid
+	seat string
+}
+
+func collectAdPodBids(pod *adPod, seatBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid) []adPodBid {
+	var bids []adPodBid
+	for _, seatBid := range seatBids {
+		if seatBid == nil {
+			continue
+		}
+		for _, bid := range seatBid.Bids {
+			if bid == nil || bid.Bid == nil {
+				continue
+			}
+			if _, ok := pod.imps[bid.Bid.ImpID]; ok {
+				bids = append(bids, adPodBid{bid: bid, seat: seatBid.Seat})
+			}
+		}
+	}
+	return bids
+}
+
+// add selects the bid in the pod if it fits, or returns the reason it was rejected.
+func (pod *adPod) add(bid *entities.PbsOrtbBid) (openrtb_ext.NonBidReason, bool) {
+	slot := pod.imps[bid.Bid.ImpID]
+
+	bidDuration := getBidDuration(bid)
+	if bidDuration <= 0 {
+		return openrtb_ext.ResponseRejectedAdPodNotFitting, false
+	}
+	duration, ok := slot.bucketDuration(bidDuration)
+	if !ok || !slot.fits(duration, bid.Bid.SlotInPod) {
+		return openrtb_ext.ResponseRejectedAdPodNotFitting, false
+	}
+
+	categories := getBidCategories(bid)
+	for _, category := range categories {
+		if _, ok := pod.categories[category]; ok {
+			return openrtb_ext.ResponseRejectedCompetitiveSeparation, false
+		}
+	}
+	for _, adomain := range bid.Bid.ADomain {
+		if _, ok := pod.adomains[strings.ToLower(adomain)]; ok {
+			return openrtb_ext.ResponseRejectedCompetitiveSeparation, false
+		}
+	}
+
+	for _, category := range categories {
+		pod.categories[category] = struct{}{}
+	}
+	for _, adomain := range bid.Bid.ADomain {
+		pod.adomains[strings.ToLower(adomain)] = struct{}{}
+	}
+	slot.use(duration, bid.Bid.SlotInPod)
+
+	if bid.BidVideo == nil {
+		bid.BidVideo = &openrtb_ext.ExtBidPrebidVideo{}
+	}
+	bid.BidVideo.Duration = int(duration)
+	return openrtb_ext.NoBidUnknownError, true
+}
+
+// bucketDuration returns the required duration of the imp the given duration is bucketed to, or false if
+// the duration exceeds the durations allowed by the imp.
+func (slot *adPodSlot) bucketDuration(duration int64) (int64, bool) {
+	if len(slot.video.RqdDurs) > 0 {
+		durationRanges := make([]int, 0, len(slot.video.RqdDurs))
+		for _, rqdDur := range slot.video.RqdDurs {
+			durationRanges = append(durationRanges, int(rqdDur))
+		}
+		bucket, err := findDurationRange(int(duration), durationRanges)
+		if err != nil {
+			return 0, false
+		}
+		return int64(bucket), true
+	}
+	if slot.video.MaxDuration > 0 && duration > slot.video.MaxDuration {
+		return 0, false
+	}
+	return duration, true
+}
+
+// fits reports whether a bid of the given duration and slot position can be added to the imp.
+func (slot *adPodSlot) fits(duration int64, slotInPod adcom1.SlotPositionInPod) bool {
+	if !slot.dynamic {
+		return slot.bids == 0
+	}
+	if slot.video.MaxSeq > 0 && slot.bids >= slot.video.MaxSeq {
+		return false
+	}
+	if slot.duration+duration > slot.video.PodDur {
+		return false
+	}
+	switch slotInPod {
+	case adcom1.SlotPosFirst:
+		return !slot.firstUsed
+	case adcom1.SlotPosLast:
+		return !slot.lastUsed
+	case adcom1.SlotPosFirstOrLast:
+		return !slot.firstUsed || !slot.lastUsed
+	}
+	return true
+}
+
+func (slot *adPodSlot) use(duration int64, slotInPod adcom1.SlotPositionInPod) {
+	slot.bids++
+	slot.duration += duration
+	switch slotInPod {
+	case adcom1.SlotPosFirst:
+		slot.firstUsed = true
+	case adcom1.SlotPosLast:
+		slot.lastUsed = true
+	case adcom1.SlotPosFirstOrLast:
+		if !slot.firstUsed {
+			slot.firstUsed = true
+		} else {
+			slot.lastUsed = true
+		}
+	}
+}
+
+// getBidDuration returns the duration of the bid creative, preferring the OpenRTB 2.6 bid.dur field, or 0 if
+// the bid has no duration.
+func getBidDuration(bid *entities.PbsOrtbBid) int64 {
+	if bid.Bid.Dur > 0 {
+		return bid.Bid.Dur
+	}
+	if bid.BidVideo != nil {
+		return int64(bid.BidVideo.Duration)
+	}
+	return 0
+}
+
+// getBidCategories returns the ad server category of the bid if it was mapped, or its IAB categories.
+func getBidCategories(bid *entities.PbsOrtbBid) []string {
+	if bid.BidVideo != nil && bid.BidVideo.PrimaryCategory != "" {
+		return []string{bid.BidVideo.PrimaryCategory}
+	}
+	return bid.Bid.Cat
+}
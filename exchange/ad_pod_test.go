@@ -0,0 +1,198 @@
+package exchange
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAdPods(t *testing.T) {
+	imps := []openrtb2.Imp{
+		{ID: "banner", Banner: &openrtb2.Banner{}},
+		{ID: "video", Video: &openrtb2.Video{}},
+		{ID: "pod1-slot1", Video: &openrtb2.Video{PodID: "pod1", SlotInPod: adcom1.SlotPosFirst}},
+		{ID: "pod1-slot2", Video: &openrtb2.Video{PodID: "pod1", SlotInPod: adcom1.SlotPosLast}},
+		{ID: "dynamic", Video: &openrtb2.Video{PodDur: 60, MaxSeq: 3}},
+	}
+
+	pods := buildAdPods(imps)
+
+	if assert.Len(t, pods, 2) {
+		assert.Len(t, pods[0].imps, 2)
+		assert.False(t, pods[0].imps["pod1-slot1"].dynamic)
+		assert.False(t, pods[0].imps["pod1-slot2"].dynamic)
+		assert.Len(t, pods[1].imps, 1)
+		assert.True(t, pods[1].imps["dynamic"].dynamic)
+	}
+}
+
+func TestAssembleAdPods(t *testing.T) {
+	testCases := []struct {
+		description         string
+		imps                []openrtb2.Imp
+		bids                map[openrtb_ext.BidderName][]*openrtb2.Bid
+		primaryCategory     string
+		expectedBidIDs      []string
+		expectedDurations   map[string]int
+		expectedNonBidCodes map[string]int
+	}{
+		{
+			description: "no_pods",
+			imps:        []openrtb2.Imp{{ID: "imp1", Video: &openrtb2.Video{}}},
+			bids: map[openrtb_ext.BidderName][]*openrtb2.Bid{
+				"appnexus": {{ID: "bid1", ImpID: "imp1", Price: 1, Dur: 30}, {ID: "bid2", ImpID: "imp1", Price: 2, Dur: 30}},
+			},
+			expectedBidIDs: []string{"bid1", "bid2"},
+		},
+		{
+			description: "dynamic_pod_filled_by_duration",
+			imps:        []openrtb2.Imp{{ID: "imp1", Video: &openrtb2.Video{PodID: "pod1", PodDur: 45, RqdDurs: []int64{15, 30}}}},
+			bids: map[openrtb_ext.BidderName][]*openrtb2.Bid{
+				"appnexus": {{ID: "bid1", ImpID: "imp1", Price: 3, Dur: 25}, {ID: "bid2", ImpID: "imp1", Price: 2, Dur: 20}},
+				"rubicon":  {{ID: "bid3", ImpID: "imp1", Price: 1, Dur: 10}, {ID: "bid4", ImpID: "imp1", Price: 4, Dur: 45}},
+			},
+			expectedBidIDs:    []string{"bid1", "bid3"},
+			expectedDurations: map[string]int{"bid1": 30, "bid3": 15},
+			expectedNonBidCodes: map[string]int{
+				"bid2": int(openrtb_ext.ResponseRejectedAdPodNotFitting),
+				"bid4": int(openrtb_ext.ResponseRejectedAdPodNotFitting),
+			},
+		},
+		{
+			description: "dynamic_pod_max_sequence",
+			imps:        []openrtb2.Imp{{ID: "imp1", Video: &openrtb2.Video{PodDur: 120, MaxSeq: 2, MaxDuration: 30}}},
+			bids: map[openrtb_ext.BidderName][]*openrtb2.Bid{
+				"appnexus": {{ID: "bid1", ImpID: "imp1", Price: 3, Dur: 15}, {ID: "bid2", ImpID: "imp1", Price: 2, Dur: 15}, {ID: "bid3", ImpID: "imp1", Price: 1, Dur: 15}},
+			},
+			expectedBidIDs:      []string{"bid1", "bid2"},
+			expectedDurations:   map[string]int{"bid1": 15, "bid2": 15},
+			expectedNonBidCodes: map[string]int{"bid3": int(openrtb_ext.ResponseRejectedAdPodNotFitting)},
+		},
+		{
+			description: "dynamic_pod_slot_positions",
+			imps:        []openrtb2.Imp{{ID: "imp1", Video: &openrtb2.Video{PodDur: 120}}},
+			bids: map[openrtb_ext.BidderName][]*openrtb2.Bid{
+				"appnexus": {
+					{ID: "bid1", ImpID: "imp1", Price: 4, Dur: 15, SlotInPod: adcom1.SlotPosFirst},
+					{ID: "bid2", ImpID: "imp1", Price: 3, Dur: 15, SlotInPod: adcom1.SlotPosFirst},
+					{ID: "bid3", ImpID: "imp1", Price: 2, Dur: 15, SlotInPod: adcom1.SlotPosFirstOrLast},
+					{ID: "bid4", ImpID: "imp1", Price: 1, Dur: 15, SlotInPod: adcom1.SlotPosLast},
+				},
+			},
+			expectedBidIDs:      []string{"bid1", "bid3"},
+			expectedDurations:   map[string]int{"bid1": 15, "bid3": 15},
+			expectedNonBidCodes: map[string]int{"bid2": int(openrtb_ext.ResponseRejectedAdPodNotFitting), "bid4": int(openrtb_ext.ResponseRejectedAdPodNotFitting)},
+		},
+		{
+			description: "structured_pod_competitive_separation",
+			imps: []openrtb2.Imp{
+				{ID: "slot1", Video: &openrtb2.Video{PodID: "pod1", SlotInPod: adcom1.SlotPosFirst, MaxDuration: 30}},
+				{ID: "slot2", Video: &openrtb2.Video{PodID: "pod1", SlotInPod: adcom1.SlotPosLast, MaxDuration: 30}},
+				{ID: "other", Video: &openrtb2.Video{PodID: "pod2", MaxDuration: 30}},
+			},
+			bids: map[openrtb_ext.BidderName][]*openrtb2.Bid{
+				"appnexus": {
+					{ID: "bid1", ImpID: "slot1", Price: 5, Dur: 30, Cat: []string{"IAB1"}, ADomain: []string{"a.com"}},
+					{ID: "bid2", ImpID: "slot2", Price: 4, Dur: 30, Cat: []string{"IAB1"}, ADomain: []string{"b.com"}},
+					{ID: "bid5", ImpID: "other", Price: 1, Dur: 30, Cat: []string{"IAB1"}, ADomain: []string{"a.com"}},
+				},
+				"rubicon": {
+					{ID: "bid3", ImpID: "slot2", Price: 3, Dur: 30, Cat: []string{"IAB2"}, ADomain: []string{"A.com"}},
+					{ID: "bid4", ImpID: "slot2", Price: 2, Dur: 30, Cat: []string{"IAB3"}, ADomain: []string{"c.com"}},
+				},
+			},
+			expectedBidIDs:    []string{"bid1", "bid4", "bid5"},
+			expectedDurations: map[string]int{"bid1": 30, "bid4": 30, "bid5": 30},
+			expectedNonBidCodes: map[string]int{
+				"bid2": int(openrtb_ext.ResponseRejectedCompetitiveSeparation),
+				"bid3": int(openrtb_ext.ResponseRejectedCompetitiveSeparation),
+			},
+		},
+		{
+			description: "mapped_category_used_for_separation",
+			imps:        []openrtb2.Imp{{ID: "imp1", Video: &openrtb2.Video{PodDur: 60}}},
+			bids: map[openrtb_ext.BidderName][]*openrtb2.Bid{
+				"appnexus": {
+					{ID: "bid1", ImpID: "imp1", Price: 2, Dur: 15, Cat: []string{"IAB1-1"}},
+					{ID: "bid2", ImpID: "imp1", Price: 1, Dur: 15, Cat: []string{"IAB1-2"}},
+				},
+			},
+			primaryCategory:     "news",
+			expectedBidIDs:      []string{"bid1"},
+			expectedDurations:   map[string]int{"bid1": 15},
+			expectedNonBidCodes: map[string]int{"bid2": int(openrtb_ext.ResponseRejectedCompetitiveSeparation)},
+		},
+		{
+			description: "bids_without_duration_rejected",
+			imps:        []openrtb2.Imp{{ID: "imp1", Video: &openrtb2.Video{PodDur: 60}}},
+			bids: map[openrtb_ext.BidderName][]*openrtb2.Bid{
+				"appnexus": {{ID: "bid1", ImpID: "imp1", Price: 3}, {ID: "bid2", ImpID: "imp1", Price: 2}, {ID: "bid3", ImpID: "imp1", Price: 1, Dur: 30}},
+			},
+			expectedBidIDs:    []string{"bid3"},
+			expectedDurations: map[string]int{"bid3": 30},
+			expectedNonBidCodes: map[string]int{
+				"bid1": int(openrtb_ext.ResponseRejectedAdPodNotFitting),
+				"bid2": int(openrtb_ext.ResponseRejectedAdPodNotFitting),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			seatBids := make(map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid)
+			for bidder, bids := range test.bids {
+				seatBid := &entities.PbsOrtbSeatBid{Seat: bidder.String()}
+				for _, bid := range bids {
+					pbsBid := &entities.PbsOrtbBid{Bid: bid, BidType: openrtb_ext.BidTypeVideo}
+					if test.primaryCategory != "" {
+						pbsBid.BidVideo = &openrtb_ext.ExtBidPrebidVideo{PrimaryCategory: test.primaryCategory}
+					}
+					seatBid.Bids = append(seatBid.Bids, pbsBid)
+				}
+				seatBids[bidder] = seatBid
+			}
+			seatNonBids := nonBids{}
+
+			assembleAdPods(test.imps, seatBids, false, &seatNonBids)
+
+			var bidIDs []string
+			durations := make(map[string]int)
+			for _, seatBid := range seatBids {
+				for _, bid := range seatBid.Bids {
+					bidIDs = append(bidIDs, bid.Bid.ID)
+					if bid.BidVideo != nil {
+						durations[bid.Bid.ID] = bid.BidVideo.Duration
+					}
+				}
+			}
+			sort.Strings(bidIDs)
+			assert.Equal(t, test.expectedBidIDs, bidIDs)
+			if test.expectedDurations != nil {
+				assert.Equal(t, test.expectedDurations, durations)
+			}
+
+			// non bids don't carry the bid id, bids are told apart by their price
+			bidIDsByPrice := make(map[float64]string)
+			for _, bids := range test.bids {
+				for _, bid := range bids {
+					bidIDsByPrice[bid.Price] = bid.ID
+				}
+			}
+			nonBidCodes := make(map[string]int)
+			for _, seatNonBid := range seatNonBids.seatNonBidsMap {
+				for _, nonBid := range seatNonBid {
+					nonBidCodes[bidIDsByPrice[nonBid.Ext.Prebid.Bid.Price]] = nonBid.StatusCode
+				}
+			}
+			if test.expectedNonBidCodes == nil {
+				test.expectedNonBidCodes = map[string]int{}
+			}
+			assert.Equal(t, test.expectedNonBidCodes, nonBidCodes)
+		})
+	}
+}
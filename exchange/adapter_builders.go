@@ -89,6 +89,7 @@ import (
 	"github.com/prebid/prebid-server/v2/adapters/frvradn"
 	"github.com/prebid/prebid-server/v2/adapters/gamma"
 	"github.com/prebid/prebid-server/v2/adapters/gamoshi"
+	"github.com/prebid/prebid-server/v2/adapters/genericortb"
 	"github.com/prebid/prebid-server/v2/adapters/globalsun"
 	"github.com/prebid/prebid-server/v2/adapters/gothamads"
 	"github.com/prebid/prebid-server/v2/adapters/grid"
@@ -300,6 +301,7 @@ func newAdapterBuilders() map[openrtb_ext.BidderName]adapters.Builder {
 		openrtb_ext.BidderFRVRAdNetwork:     frvradn.Builder,
 		openrtb_ext.BidderGamma:             gamma.Builder,
 		openrtb_ext.BidderGamoshi:           gamoshi.Builder,
+		openrtb_ext.BidderGenericORTB:       genericortb.Builder,
 		openrtb_ext.BidderGlobalsun:         globalsun.Builder,
 		openrtb_ext.BidderGothamads:         gothamads.Builder,
 		openrtb_ext.BidderGrid:              grid.Builder,
@@ -0,0 +1,170 @@
+package exchange
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/util/timeutil"
+)
+
+const (
+	latencyBucketWidth = 5 * time.Millisecond
+	// latencyBucketCount covers latencies up to 10 seconds, longer ones are counted in an extra overflow bucket
+	latencyBucketCount = 2000
+)
+
+// adaptiveTimeout is the timeout chosen by the latency tracker for the requests of a bidder.
+type adaptiveTimeout struct {
+	timeout time.Duration
+	// cut is true if the timeout is shorter than the time left for the bidder to respond
+	cut bool
+}
+
+type latencyHistogram struct {
+	buckets [latencyBucketCount + 1]int
+	count   int
+}
+
+// latencyTracker keeps a rolling histogram of the latency of a bidder to decide on the timeout of
+// its requests. It is safe for concurrent use.
+type latencyTracker struct {
+	percentile            float64
+	minSamples            int
+	window                time.Duration
+	slowBidderBudgetRatio float64
+	minTimeout            time.Duration
+
+	clock timeutil.Time
+
+	lock        sync.Mutex
+	windowStart time.Time
+	current     latencyHistogram
+	previous    latencyHistogram
+}
+
+func newLatencyTracker(cfg config.AdaptiveBidderTimeouts) *latencyTracker {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	clock := &timeutil.RealTime{}
+	return &latencyTracker{
+		percentile:            cfg.Percentile,
+		minSamples:            cfg.MinSamples,
+		window:                time.Duration(cfg.WindowSeconds) * time.Second,
+		slowBidderBudgetRatio: cfg.SlowBidderBudgetRatio,
+		minTimeout:            time.Duration(cfg.MinTimeout) * time.Millisecond,
+		clock:                 clock,
+		windowStart:           clock.Now(),
+	}
+}
+
+// record adds the latency of a request which completed to the histogram.
+func (lt *latencyTracker) record(latency time.Duration) {
+	if lt == nil {
+		return
+	}
+
+	bucket := int(latency / latencyBucketWidth)
+	if bucket < 0 {
+		bucket = 0
+	} else if bucket > latencyBucketCount {
+		bucket = latencyBucketCount
+	}
+	lt.add(bucket)
+}
+
+// recordTimeout adds a request which timed out, whether cut short by an adapted timeout or not, to the histogram.
+// Its latency is only known to be above its timeout so it is counted in the overflow bucket, which keeps a bidder
+// that keeps timing out above the slow bidder budget.
+func (lt *latencyTracker) recordTimeout() {
+	if lt == nil {
+		return
+	}
+	lt.add(latencyBucketCount)
+}
+
+func (lt *latencyTracker) add(bucket int) {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+
+	lt.rotate()
+	lt.current.buckets[bucket]++
+	lt.current.count++
+}
+
+// timeout returns the timeout of a request given the time left for it. The timeout is cut to the slow bidder
+// budget when the bidder latency percentile exceeds it, in which case cut is true.
+func (lt *latencyTracker) timeout(remaining time.Duration) (timeout time.Duration, cut bool) {
+	if lt == nil {
+		return remaining, false
+	}
+
+	budget := time.Duration(float64(remaining) * lt.slowBidderBudgetRatio)
+	if budget < lt.minTimeout {
+		budget = lt.minTimeout
+	}
+	if budget >= remaining {
+		return remaining, false
+	}
+
+	if latency, ok := lt.percentileLatency(); !ok || latency <= budget {
+		return remaining, false
+	}
+	return budget, true
+}
+
+// getAdaptiveTimeout returns the timeout of the requests sent to a bidder with the given context, which is
+// the time left for the bidder to respond unless the bidder is chronically slow.
+func (lt *latencyTracker) getAdaptiveTimeout(ctx context.Context, tmaxAdjustments *TmaxAdjustmentsPreprocessed) adaptiveTimeout {
+	deadline, ok := ctx.Deadline()
+	if lt == nil || !ok {
+		return adaptiveTimeout{}
+	}
+	timeout, cut := lt.timeout(time.Until(getBidderDeadline(deadline, tmaxAdjustments)))
+	return adaptiveTimeout{timeout: timeout, cut: cut}
+}
+
+// percentileLatency returns the upper bound of the histogram bucket holding the configured percentile,
+// or false if there are not enough samples yet.
+func (lt *latencyTracker) percentileLatency() (time.Duration, bool) {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+
+	lt.rotate()
+	total := lt.current.count + lt.previous.count
+	if total == 0 || total < lt.minSamples {
+		return 0, false
+	}
+
+	target := int(math.Ceil(lt.percentile * float64(total)))
+	seen := 0
+	for i := range lt.current.buckets {
+		seen += lt.current.buckets[i] + lt.previous.buckets[i]
+		if seen >= target {
+			return time.Duration(i+1) * latencyBucketWidth, true
+		}
+	}
+	return time.Duration(latencyBucketCount+1) * latencyBucketWidth, true
+}
+
+// rotate moves the current histogram to the previous one once the window elapsed. It must be called
+// with the lock held.
+func (lt *latencyTracker) rotate() {
+	now := lt.clock.Now()
+	elapsed := now.Sub(lt.windowStart)
+	if elapsed < lt.window {
+		return
+	}
+
+	if elapsed < 2*lt.window {
+		lt.previous = lt.current
+	} else {
+		lt.previous = latencyHistogram{}
+	}
+	lt.current = latencyHistogram{}
+	lt.windowStart = now
+}
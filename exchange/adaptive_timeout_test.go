@@ -0,0 +1,185 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLatencyTracker(clock *fakeClock) *latencyTracker {
+	lt := newLatencyTracker(config.AdaptiveBidderTimeouts{
+		Enabled:               true,
+		Percentile:            0.9,
+		MinSamples:            10,
+		WindowSeconds:         60,
+		SlowBidderBudgetRatio: 0.5,
+		MinTimeout:            50,
+	})
+	lt.clock = clock
+	lt.windowStart = clock.now
+	return lt
+}
+
+func TestNewLatencyTrackerDisabled(t *testing.T) {
+	lt := newLatencyTracker(config.AdaptiveBidderTimeouts{Enabled: false, Percentile: 0.9})
+	assert.Nil(t, lt)
+
+	lt.record(time.Second)
+	timeout, cut := lt.timeout(500 * time.Millisecond)
+	assert.Equal(t, 500*time.Millisecond, timeout)
+	assert.False(t, cut)
+}
+
+func TestLatencyTrackerPercentileLatency(t *testing.T) {
+	testCases := []struct {
+		name            string
+		latencies       []time.Duration
+		expectedLatency time.Duration
+		expectedOK      bool
+	}{
+		{
+			name:       "no-samples",
+			expectedOK: false,
+		},
+		{
+			name:       "too-few-samples",
+			latencies:  repeatLatency(100*time.Millisecond, 9),
+			expectedOK: false,
+		},
+		{
+			name:            "uniform",
+			latencies:       repeatLatency(100*time.Millisecond, 10),
+			expectedLatency: 105 * time.Millisecond,
+			expectedOK:      true,
+		},
+		{
+			name:            "percentile-ignores-outliers",
+			latencies:       append(repeatLatency(100*time.Millisecond, 18), 900*time.Millisecond, 900*time.Millisecond),
+			expectedLatency: 105 * time.Millisecond,
+			expectedOK:      true,
+		},
+		{
+			name:            "slow-tail",
+			latencies:       append(repeatLatency(100*time.Millisecond, 17), 900*time.Millisecond, 900*time.Millisecond, 900*time.Millisecond),
+			expectedLatency: 905 * time.Millisecond,
+			expectedOK:      true,
+		},
+		{
+			name:            "overflow",
+			latencies:       repeatLatency(time.Minute, 10),
+			expectedLatency: (latencyBucketCount + 1) * latencyBucketWidth,
+			expectedOK:      true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			lt := newTestLatencyTracker(&fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+			for _, latency := range test.latencies {
+				lt.record(latency)
+			}
+
+			latency, ok := lt.percentileLatency()
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expectedLatency, latency)
+		})
+	}
+}
+
+func TestLatencyTrackerTimeout(t *testing.T) {
+	testCases := []struct {
+		name            string
+		latency         time.Duration
+		remaining       time.Duration
+		expectedTimeout time.Duration
+		expectedCut     bool
+	}{
+		{
+			name:            "fast-bidder-keeps-full-budget",
+			latency:         100 * time.Millisecond,
+			remaining:       800 * time.Millisecond,
+			expectedTimeout: 800 * time.Millisecond,
+			expectedCut:     false,
+		},
+		{
+			name:            "slow-bidder-is-cut",
+			latency:         600 * time.Millisecond,
+			remaining:       800 * time.Millisecond,
+			expectedTimeout: 400 * time.Millisecond,
+			expectedCut:     true,
+		},
+		{
+			name:            "slow-bidder-gets-min-timeout",
+			latency:         600 * time.Millisecond,
+			remaining:       80 * time.Millisecond,
+			expectedTimeout: 50 * time.Millisecond,
+			expectedCut:     true,
+		},
+		{
+			name:            "min-timeout-above-remaining",
+			latency:         600 * time.Millisecond,
+			remaining:       40 * time.Millisecond,
+			expectedTimeout: 40 * time.Millisecond,
+			expectedCut:     false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			lt := newTestLatencyTracker(&fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+			for _, latency := range repeatLatency(test.latency, 10) {
+				lt.record(latency)
+			}
+
+			timeout, cut := lt.timeout(test.remaining)
+			assert.Equal(t, test.expectedTimeout, timeout)
+			assert.Equal(t, test.expectedCut, cut)
+		})
+	}
+}
+
+func TestLatencyTrackerRotate(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	lt := newTestLatencyTracker(clock)
+	for _, latency := range repeatLatency(600*time.Millisecond, 10) {
+		lt.record(latency)
+	}
+
+	// samples of the previous window are still used
+	clock.now = clock.now.Add(61 * time.Second)
+	latency, ok := lt.percentileLatency()
+	assert.True(t, ok)
+	assert.Equal(t, 605*time.Millisecond, latency)
+
+	// samples older than two windows are dropped
+	clock.now = clock.now.Add(61 * time.Second)
+	_, ok = lt.percentileLatency()
+	assert.False(t, ok)
+}
+
+func TestLatencyTrackerRecordTimeout(t *testing.T) {
+	lt := newTestLatencyTracker(&fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	for _, latency := range repeatLatency(100*time.Millisecond, 8) {
+		lt.record(latency)
+	}
+	lt.recordTimeout()
+	lt.recordTimeout()
+
+	latency, ok := lt.percentileLatency()
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(latencyBucketCount+1)*latencyBucketWidth, latency, "timed out requests are above any recorded latency")
+
+	timeout, cut := lt.timeout(800 * time.Millisecond)
+	assert.Equal(t, 400*time.Millisecond, timeout)
+	assert.True(t, cut)
+}
+
+func repeatLatency(latency time.Duration, count int) []time.Duration {
+	latencies := make([]time.Duration, count)
+	for i := range latencies {
+		latencies[i] = latency
+	}
+	return latencies
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"sort"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	uuid "github.com/gofrs/uuid"
 	"github.com/prebid/openrtb/v20/openrtb2"
 	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/currency"
 	"github.com/prebid/prebid-server/v2/exchange/entities"
 	"github.com/prebid/prebid-server/v2/openrtb_ext"
 	"github.com/prebid/prebid-server/v2/prebid_cache_client"
@@ -23,6 +25,9 @@ const (
 	DebugOverrideHeader string = "x-pbs-debug-override"
 )
 
+// clearingPricePrecision rounds clearing prices to four decimal places
+const clearingPricePrecision = 10000
+
 type DebugLog struct {
 	Enabled       bool
 	CacheType     prebid_cache_client.PayloadType
@@ -148,7 +153,7 @@ func isNewWinningBid(bid, wbid *openrtb2.Bid, preferDeals bool) bool {
 	return bid.Price > wbid.Price
 }
 
-func (a *auction) validateAndUpdateMultiBid(adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, preferDeals bool, accountDefaultBidLimit int) {
+func (a *auction) validateAndUpdateMultiBid(adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, preferDeals bool, accountDefaultBidLimit int, seatNonBids *nonBids) {
 	bidsSnipped := false
 	// sort bids for multibid targeting
 	for _, topBidsPerBidder := range a.allBidsByBidder {
@@ -160,6 +165,7 @@ func (a *auction) validateAndUpdateMultiBid(adapterBids map[openrtb_ext.BidderNa
 			// assert hard limit on bids count per imp, per adapter.
 			if accountDefaultBidLimit != 0 && len(topBids) > accountDefaultBidLimit {
 				for i := accountDefaultBidLimit; i < len(topBids); i++ {
+					seatNonBids.addBid(topBids[i], int(openrtb_ext.ResponseRejectedMultiBidLimitExceeded), bidder.String())
 					topBids[i].Bid = nil
 					topBids[i] = nil
 					bidsSnipped = true
@@ -185,6 +191,191 @@ func (a *auction) validateAndUpdateMultiBid(adapterBids map[openrtb_ext.BidderNa
 	}
 }
 
+// getMediaTypePriority returns the media type priority of the request, or the one of the account if the request
+// has none.
+func getMediaTypePriority(targeting *openrtb_ext.ExtRequestTargeting, account config.AccountAuctionMediaTypePriority) *openrtb_ext.ExtMediaTypePriority {
+	if targeting != nil && targeting.MediaTypePriority != nil {
+		return targeting.MediaTypePriority
+	}
+	if len(account.Priority) == 0 {
+		return nil
+	}
+
+	mtp := &openrtb_ext.ExtMediaTypePriority{Bias: account.Bias}
+	for _, mediaType := range account.Priority {
+		mtp.Priority = append(mtp.Priority, openrtb_ext.BidType(mediaType))
+	}
+	return mtp
+}
+
+// applyMediaTypePriority reselects the winning bid of every imp favouring the media types listed first. The best
+// bid of the most favoured media type wins, unless the best bid of a less favoured media type is priced above
+// bias times its price. Only the winning bids change, the top bids of each bidder are left as is.
+func (a *auction) applyMediaTypePriority(mtp *openrtb_ext.ExtMediaTypePriority, preferDeals bool) {
+	if mtp == nil || len(mtp.Priority) == 0 {
+		return
+	}
+
+	for impID, bidsByBidder := range a.allBidsByBidder {
+		bestByMediaType := make(map[openrtb_ext.BidType]*entities.PbsOrtbBid)
+		for _, bids := range bidsByBidder {
+			for _, bid := range bids {
+				if bid == nil || bid.Bid == nil {
+					continue
+				}
+				if best, ok := bestByMediaType[bid.BidType]; !ok || isNewWinningBid(bid.Bid, best.Bid, preferDeals) {
+					bestByMediaType[bid.BidType] = bid
+				}
+			}
+		}
+
+		var winner *entities.PbsOrtbBid
+		for _, mediaType := range mtp.Priority {
+			bid, ok := bestByMediaType[mediaType]
+			if !ok {
+				continue
+			}
+			if winner == nil {
+				winner = bid
+				continue
+			}
+			if preferDeals && (len(winner.Bid.DealID) > 0) != (len(bid.Bid.DealID) > 0) {
+				// deals win over the favoured media types when deals are preferred
+				if len(bid.Bid.DealID) > 0 {
+					winner = bid
+				}
+				continue
+			}
+			if mtp.Bias > 0 && bid.Bid.Price > winner.Bid.Price*mtp.Bias {
+				winner = bid
+			}
+		}
+
+		if winner != nil {
+			a.winningBids[impID] = winner
+		}
+	}
+}
+
+// applyPricing replaces the price of every non-deal winning bid with the clearing price
+// determined by the account auction pricing mode. Deal bids always pay their own price.
+func (a *auction) applyPricing(pricing config.AccountAuctionPricing, req *openrtb_ext.RequestWrapper, conversions currency.Conversions) {
+	if pricing.Mode != config.AuctionPricingSecondPrice && pricing.Mode != config.AuctionPricingSoftFloor {
+		return
+	}
+
+	var impFloors map[string]float64
+	if pricing.Mode == config.AuctionPricingSoftFloor {
+		impFloors = getImpFloors(req, conversions)
+	}
+
+	for impID, winningBid := range a.winningBids {
+		if winningBid == nil || winningBid.Bid == nil || len(winningBid.Bid.DealID) > 0 {
+			continue
+		}
+
+		bidPrice := winningBid.Bid.Price
+		secondPrice, hasSecondPrice := a.getSecondHighestPrice(impID, winningBid)
+		clearingPrice := getClearingPrice(pricing, bidPrice, secondPrice, hasSecondPrice, impFloors[impID])
+		if clearingPrice >= bidPrice {
+			continue
+		}
+
+		winningBid.Bid.Price = clearingPrice
+		winningBid.BidPricing = &openrtb_ext.ExtBidPrebidPricing{
+			Mode:          string(pricing.Mode),
+			BidPrice:      bidPrice,
+			ClearingPrice: clearingPrice,
+		}
+	}
+}
+
+// getSecondHighestPrice returns the highest price among the bids competing with the winning bid for the imp.
+// The other multibid bids of the winning bidder don't compete with it, so they are left out.
+func (a *auction) getSecondHighestPrice(impID string, winningBid *entities.PbsOrtbBid) (float64, bool) {
+	var secondPrice float64
+	var found bool
+	for _, bids := range a.allBidsByBidder[impID] {
+		if containsBid(bids, winningBid) {
+			continue
+		}
+		for _, bid := range bids {
+			if bid == nil || bid.Bid == nil {
+				continue
+			}
+			if !found || bid.Bid.Price > secondPrice {
+				secondPrice = bid.Bid.Price
+				found = true
+			}
+		}
+	}
+	return secondPrice, found
+}
+
+func containsBid(bids []*entities.PbsOrtbBid, bid *entities.PbsOrtbBid) bool {
+	for _, b := range bids {
+		if b == bid {
+			return true
+		}
+	}
+	return false
+}
+
+// getClearingPrice calculates the price the winning bid pays according to the pricing mode.
+// The clearing price never exceeds the bid price.
+func getClearingPrice(pricing config.AccountAuctionPricing, bidPrice, secondPrice float64, hasSecondPrice bool, floor float64) float64 {
+	var clearingPrice float64
+	switch {
+	case pricing.Mode == config.AuctionPricingSoftFloor && floor > 0:
+		// bids below the soft floor pay their own price
+		if bidPrice < floor {
+			return bidPrice
+		}
+		clearingPrice = floor
+		if hasSecondPrice {
+			clearingPrice = math.Max(floor, secondPrice+pricing.Increment)
+		}
+	default:
+		if !hasSecondPrice {
+			return bidPrice
+		}
+		clearingPrice = secondPrice + pricing.Increment
+	}
+
+	return math.Min(bidPrice, math.Round(clearingPrice*clearingPricePrecision)/clearingPricePrecision)
+}
+
+// getImpFloors returns the floor of each imp converted to the auction currency.
+func getImpFloors(req *openrtb_ext.RequestWrapper, conversions currency.Conversions) map[string]float64 {
+	defaultCurrency := "USD"
+	auctionCurrency := defaultCurrency
+	if len(req.Cur) > 0 {
+		auctionCurrency = req.Cur[0]
+	}
+
+	impFloors := make(map[string]float64, len(req.Imp))
+	for _, imp := range req.Imp {
+		if imp.BidFloor <= 0 {
+			continue
+		}
+
+		floorCurrency := imp.BidFloorCur
+		if floorCurrency == "" {
+			floorCurrency = defaultCurrency
+		}
+
+		rate := 1.0
+		if floorCurrency != auctionCurrency {
+			var err error
+			if rate, err = conversions.GetRate(floorCurrency, auctionCurrency); err != nil {
+				continue
+			}
+		}
+		impFloors[imp.ID] = imp.BidFloor * rate
+	}
+	return impFloors
+}
+
 func (a *auction) setRoundedPrices(targetingData targetData) {
 	roundedPrices := make(map[*entities.PbsOrtbBid]string, 5*len(a.winningBids))
 	for _, topBidsPerImp := range a.allBidsByBidder {
@@ -0,0 +1,99 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/capture"
+	"github.com/prebid/prebid-server/v2/errortypes"
+)
+
+// sendRequest makes the http call of a bidder request. The call is recorded if the auction is captured, and
+// served from the captured auction instead of the bidder if the auction is replayed.
+func (bidder *bidderAdapter) sendRequest(ctx context.Context, bidderRequest BidderRequest, req *adapters.RequestData, bidderRequestStartTime time.Time, tmaxAdjustments *TmaxAdjustmentsPreprocessed) *httpCallInfo {
+	if bidderRequest.replay != nil {
+		return replayRequest(bidderRequest.replay, string(bidderRequest.BidderName), req)
+	}
+
+	httpInfo := bidder.doRequest(ctx, req, bidderRequestStartTime, tmaxAdjustments, bidderRequest.adaptiveTimeout, bidder.getAdapter(bidderRequest))
+	if bidderRequest.recorder != nil {
+		bidderRequest.recorder.RecordCall(string(bidderRequest.BidderName), newCapturedCall(httpInfo))
+	}
+	return httpInfo
+}
+
+func newCapturedCall(httpInfo *httpCallInfo) capture.HttpCall {
+	call := capture.HttpCall{
+		Method:         httpInfo.request.Method,
+		Uri:            httpInfo.request.Uri,
+		RequestBody:    httpInfo.request.Body,
+		RequestHeaders: httpInfo.request.Headers,
+	}
+	if httpInfo.response != nil {
+		call.StatusCode = httpInfo.response.StatusCode
+		call.ResponseBody = httpInfo.response.Body
+		call.ResponseHeaders = httpInfo.response.Headers
+	}
+	if httpInfo.err != nil {
+		call.Error = httpInfo.err.Error()
+		call.ErrorCode = errortypes.ReadCode(httpInfo.err)
+	}
+	return call
+}
+
+// replayRequest returns the captured call matching the request.
+func replayRequest(replay *capture.Replay, bidderName string, req *adapters.RequestData) *httpCallInfo {
+	call, ok := replay.Call(bidderName, req.Method, req.Uri, req.Body)
+	if !ok {
+		return &httpCallInfo{
+			request: req,
+			err:     &errortypes.FailedToRequestBids{Message: fmt.Sprintf("no captured call to %s left to replay", req.Uri)},
+		}
+	}
+
+	httpInfo := &httpCallInfo{request: req}
+	if call.Error != "" {
+		httpInfo.err = newReplayedError(call.Error, call.ErrorCode)
+	}
+	if call.StatusCode != 0 {
+		httpInfo.response = &adapters.ResponseData{
+			StatusCode: call.StatusCode,
+			Body:       call.ResponseBody,
+			Headers:    call.ResponseHeaders,
+		}
+	}
+	return httpInfo
+}
+
+// newReplayedError recreates the error of a captured call, keeping the error types the exchange tells apart.
+func newReplayedError(message string, code int) error {
+	switch code {
+	case errortypes.TimeoutErrorCode:
+		return &errortypes.Timeout{Message: message}
+	case errortypes.TmaxTimeoutErrorCode:
+		return &errortypes.TmaxTimeout{Message: message}
+	case errortypes.BadServerResponseErrorCode:
+		return &errortypes.BadServerResponse{Message: message}
+	case errortypes.BidderCircuitOpenErrorCode:
+		return &errortypes.BidderCircuitOpen{Message: message}
+	}
+	return errors.New(message)
+}
+
+// captureUIDs returns the user sync ids of the auction by syncer key.
+func captureUIDs(bidderToSyncerKey map[string]string, userSyncs IdFetcher) capture.UIDs {
+	if userSyncs == nil {
+		return nil
+	}
+
+	uids := make(capture.UIDs)
+	for _, syncerKey := range bidderToSyncerKey {
+		if uid, exists, notExpired := userSyncs.GetUID(syncerKey); exists && notExpired {
+			uids[syncerKey] = uid
+		}
+	}
+	return uids
+}
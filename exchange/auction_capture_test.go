@@ -0,0 +1,180 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/capture"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/currency"
+	"github.com/prebid/prebid-server/v2/errortypes"
+	"github.com/prebid/prebid-server/v2/experiment/adscert"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	metricsConfig "github.com/prebid/prebid-server/v2/metrics/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/usersync"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendRequestCapture(t *testing.T) {
+	server := httptest.NewServer(mockHandler(200, "getBody", `{"id":"resp"}`))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	capturer := capture.NewCapturer(config.AuctionCapture{Enabled: true, SamplingRate: 1, File: path, MaxFileBytes: 1 << 20, BufferSize: 1})
+	recorder := capturer.Start(&openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "req"}}, &config.Account{}, nil, nil)
+	bidder := &bidderAdapter{me: &metricsConfig.NilMetricsEngine{}, Client: server.Client()}
+	req := &adapters.RequestData{Method: "POST", Uri: server.URL, Body: []byte(`{"id":"req"}`)}
+
+	httpInfo := bidder.sendRequest(context.Background(), BidderRequest{BidderName: "appnexus", recorder: recorder}, req, time.Now(), nil)
+	capturer.Finish(recorder, nil)
+	capturer.Close()
+
+	assert.NoError(t, httpInfo.err)
+	file, err := os.Open(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer file.Close()
+	auctions, err := capture.Read(file)
+	assert.NoError(t, err)
+	if assert.Len(t, auctions, 1) {
+		calls := auctions[0].Calls["appnexus"]
+		if assert.Len(t, calls, 1) {
+			assert.Equal(t, "POST", calls[0].Method)
+			assert.Equal(t, server.URL, calls[0].Uri)
+			assert.Equal(t, []byte(`{"id":"req"}`), calls[0].RequestBody)
+			assert.Equal(t, 200, calls[0].StatusCode)
+			assert.Equal(t, []byte(`{"id":"resp"}`), calls[0].ResponseBody)
+		}
+	}
+}
+
+func TestSendRequestReplay(t *testing.T) {
+	replay := capture.NewReplay(&capture.Auction{Calls: map[string][]capture.HttpCall{
+		"appnexus": {{Method: "POST", Uri: "https://appnexus.com", StatusCode: 200, ResponseBody: []byte(`{"id":"resp"}`), ResponseHeaders: http.Header{"Content-Type": []string{"application/json"}}}},
+	}})
+	// the bidder must not be called when replaying
+	bidder := &bidderAdapter{me: &metricsConfig.NilMetricsEngine{}}
+	req := &adapters.RequestData{Method: "POST", Uri: "https://appnexus.com", Body: []byte(`{"id":"req"}`)}
+
+	httpInfo := bidder.sendRequest(context.Background(), BidderRequest{BidderName: "appnexus", replay: replay}, req, time.Now(), nil)
+
+	assert.NoError(t, httpInfo.err)
+	assert.Same(t, req, httpInfo.request)
+	assert.Equal(t, &adapters.ResponseData{StatusCode: 200, Body: []byte(`{"id":"resp"}`), Headers: http.Header{"Content-Type": []string{"application/json"}}}, httpInfo.response)
+
+	httpInfo = bidder.sendRequest(context.Background(), BidderRequest{BidderName: "appnexus", replay: replay}, req, time.Now(), nil)
+
+	assert.Equal(t, &errortypes.FailedToRequestBids{Message: "no captured call to https://appnexus.com left to replay"}, httpInfo.err)
+}
+
+func TestNewCapturedCallError(t *testing.T) {
+	call := newCapturedCall(&httpCallInfo{
+		request: &adapters.RequestData{Method: "POST", Uri: "https://appnexus.com"},
+		err:     &errortypes.Timeout{Message: "context deadline exceeded"},
+	})
+
+	assert.Equal(t, capture.HttpCall{Method: "POST", Uri: "https://appnexus.com", Error: "context deadline exceeded", ErrorCode: errortypes.TimeoutErrorCode}, call)
+}
+
+func TestNewReplayedError(t *testing.T) {
+	testCases := []struct {
+		description string
+		code        int
+		expectedErr error
+	}{
+		{
+			description: "timeout",
+			code:        errortypes.TimeoutErrorCode,
+			expectedErr: &errortypes.Timeout{Message: "msg"},
+		},
+		{
+			description: "tmax_timeout",
+			code:        errortypes.TmaxTimeoutErrorCode,
+			expectedErr: &errortypes.TmaxTimeout{Message: "msg"},
+		},
+		{
+			description: "bad_server_response",
+			code:        errortypes.BadServerResponseErrorCode,
+			expectedErr: &errortypes.BadServerResponse{Message: "msg"},
+		},
+		{
+			description: "circuit_open",
+			code:        errortypes.BidderCircuitOpenErrorCode,
+			expectedErr: &errortypes.BidderCircuitOpen{Message: "msg"},
+		},
+		{
+			description: "other",
+			code:        errortypes.UnknownErrorCode,
+			expectedErr: errors.New("msg"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expectedErr, newReplayedError("msg", test.code))
+		})
+	}
+}
+
+func TestCaptureUIDs(t *testing.T) {
+	cookie := usersync.NewCookie()
+	cookie.Sync("adnxs", "uid1")
+
+	uids := captureUIDs(map[string]string{"appnexus": "adnxs", "rubicon": "rubicon"}, cookie)
+
+	assert.Equal(t, capture.UIDs{"adnxs": "uid1"}, uids)
+	assert.Nil(t, captureUIDs(map[string]string{"appnexus": "adnxs"}, nil))
+}
+
+func TestRequestBidCapturesResponseCacheHits(t *testing.T) {
+	cfg := &config.Configuration{
+		BidderInfos: config.BidderInfos{
+			string(openrtb_ext.BidderAppnexus): config.BidderInfo{ResponseCache: &config.ResponseCache{Enabled: true}},
+		},
+	}
+	// the bidder must not be called when its response is cached
+	bidderImpl := &goodSingleBidder{}
+	bidder := AdaptBidder(bidderImpl, nil, cfg, &metricsConfig.NilMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "").(*bidderAdapter)
+
+	bidRequest := &openrtb2.BidRequest{ID: "req", Imp: []openrtb2.Imp{{ID: "imp1"}}}
+	key, err := bidderResponseCacheKey(bidRequest)
+	if !assert.NoError(t, err) {
+		return
+	}
+	bidder.responseCache.set(key, []cachedHttpCall{{
+		request:     &adapters.RequestData{Method: "POST", Uri: "https://appnexus.com", Body: []byte(`{"id":"cached"}`)},
+		response:    &adapters.ResponseData{StatusCode: 200, Body: []byte(`{"id":"resp"}`)},
+		bidResponse: &adapters.BidderResponse{Bids: []*adapters.TypedBid{{Bid: &openrtb2.Bid{ID: "bid1", ImpID: "imp1", Price: 1}, BidType: openrtb_ext.BidTypeBanner}}},
+	}})
+
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	capturer := capture.NewCapturer(config.AuctionCapture{Enabled: true, SamplingRate: 1, File: path, MaxFileBytes: 1 << 20, BufferSize: 1})
+	recorder := capturer.Start(&openrtb_ext.RequestWrapper{BidRequest: bidRequest}, &config.Account{}, nil, nil)
+
+	bidderReq := BidderRequest{BidRequest: bidRequest, BidderName: openrtb_ext.BidderAppnexus, recorder: recorder}
+	_, _, errs := bidder.requestBid(context.Background(), bidderReq, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidRequestOptions{}, openrtb_ext.ExtAlternateBidderCodes{}, &hookexecution.EmptyHookExecutor{}, nil)
+	capturer.Finish(recorder, nil)
+	capturer.Close()
+
+	assert.Empty(t, errs)
+	file, err := os.Open(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer file.Close()
+	auctions, err := capture.Read(file)
+	assert.NoError(t, err)
+	if assert.Len(t, auctions, 1) {
+		assert.Equal(t, []capture.HttpCall{{Method: "POST", Uri: "https://appnexus.com", RequestBody: []byte(`{"id":"cached"}`), StatusCode: 200, ResponseBody: []byte(`{"id":"resp"}`)}}, auctions[0].Calls["appnexus"])
+	}
+}
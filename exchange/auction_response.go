@@ -9,6 +9,10 @@ import (
 type AuctionResponse struct {
 	*openrtb2.BidResponse
 	ExtBidResponse *openrtb_ext.ExtBidResponse
+	// LateBids holds the bids of the bidders which did not respond before the auction returned early
+	LateBids *LateBids
+	// ShadowBids holds the bids of the shadow bidders of the account, which did not compete in the auction
+	ShadowBids *ShadowBids
 }
 
 // GetSeatNonBid returns array of seat non-bid if present. nil otherwise
@@ -13,6 +13,7 @@ import (
 
 	"github.com/prebid/openrtb/v20/openrtb2"
 	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/currency"
 	"github.com/prebid/prebid-server/v2/exchange/entities"
 	"github.com/prebid/prebid-server/v2/openrtb_ext"
 	"github.com/prebid/prebid-server/v2/prebid_cache_client"
@@ -659,6 +660,7 @@ func TestValidateAndUpdateMultiBid(t *testing.T) {
 	type want struct {
 		allBidsByBidder map[string]map[openrtb_ext.BidderName][]*entities.PbsOrtbBid
 		adapterBids     map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid
+		seatNonBids     []openrtb_ext.SeatNonBid
 	}
 	tests := []struct {
 		description string
@@ -817,6 +819,20 @@ func TestValidateAndUpdateMultiBid(t *testing.T) {
 						Bids: []*entities.PbsOrtbBid{&bid1p088d, &bid1p123, &bid2p155, &bid2p166},
 					},
 				},
+				seatNonBids: []openrtb_ext.SeatNonBid{
+					{
+						Seat: "appnexus",
+						NonBid: []openrtb_ext.NonBid{
+							{
+								ImpId:      "imp1",
+								StatusCode: int(openrtb_ext.ResponseRejectedMultiBidLimitExceeded),
+								Ext: openrtb_ext.NonBidExt{
+									Prebid: openrtb_ext.ExtResponseNonBidPrebid{Bid: openrtb_ext.NonBidObject{Price: 0.01}},
+								},
+							},
+						},
+					},
+				},
 			},
 		},
 	}
@@ -829,9 +845,11 @@ func TestValidateAndUpdateMultiBid(t *testing.T) {
 				cacheIds:        tt.fields.cacheIds,
 				vastCacheIds:    tt.fields.vastCacheIds,
 			}
-			a.validateAndUpdateMultiBid(tt.args.adapterBids, tt.args.preferDeals, tt.args.accountDefaultBidLimit)
+			seatNonBids := nonBids{}
+			a.validateAndUpdateMultiBid(tt.args.adapterBids, tt.args.preferDeals, tt.args.accountDefaultBidLimit, &seatNonBids)
 			assert.Equal(t, tt.want.allBidsByBidder, tt.fields.allBidsByBidder, tt.description)
 			assert.Equal(t, tt.want.adapterBids, tt.args.adapterBids, tt.description)
+			assert.Equal(t, tt.want.seatNonBids, seatNonBids.get(), tt.description)
 		})
 	}
 }
@@ -875,3 +893,249 @@ func (c *mockCache) PutJson(ctx context.Context, values []prebid_cache_client.Ca
 	c.items = values
 	return []string{"", "", "", "", ""}, nil
 }
+
+func TestApplyPricing(t *testing.T) {
+	type bidSetup struct {
+		impID  string
+		bidder openrtb_ext.BidderName
+		price  float64
+		dealID string
+		winner bool
+	}
+
+	testCases := []struct {
+		name               string
+		pricing            config.AccountAuctionPricing
+		imp                openrtb2.Imp
+		bids               []bidSetup
+		expectedPrice      float64
+		expectedBidPricing *openrtb_ext.ExtBidPrebidPricing
+	}{
+		{
+			name:          "first_price",
+			pricing:       config.AccountAuctionPricing{Mode: config.AuctionPricingFirstPrice, Increment: 0.01},
+			imp:           openrtb2.Imp{ID: "imp1"},
+			bids:          []bidSetup{{"imp1", "appnexus", 5, "", true}, {"imp1", "rubicon", 3, "", false}},
+			expectedPrice: 5,
+		},
+		{
+			name:               "second_price",
+			pricing:            config.AccountAuctionPricing{Mode: config.AuctionPricingSecondPrice, Increment: 0.01},
+			imp:                openrtb2.Imp{ID: "imp1"},
+			bids:               []bidSetup{{"imp1", "appnexus", 5, "", true}, {"imp1", "rubicon", 3, "", false}, {"imp1", "rubicon", 2, "", false}},
+			expectedPrice:      3.01,
+			expectedBidPricing: &openrtb_ext.ExtBidPrebidPricing{Mode: "second_price", BidPrice: 5, ClearingPrice: 3.01},
+		},
+		{
+			name:               "second_price_winner_multibid_left_out",
+			pricing:            config.AccountAuctionPricing{Mode: config.AuctionPricingSecondPrice, Increment: 0.01},
+			imp:                openrtb2.Imp{ID: "imp1"},
+			bids:               []bidSetup{{"imp1", "appnexus", 5, "", true}, {"imp1", "appnexus", 4, "", false}, {"imp1", "rubicon", 3, "", false}},
+			expectedPrice:      3.01,
+			expectedBidPricing: &openrtb_ext.ExtBidPrebidPricing{Mode: "second_price", BidPrice: 5, ClearingPrice: 3.01},
+		},
+		{
+			name:          "second_price_only_winner_multibid",
+			pricing:       config.AccountAuctionPricing{Mode: config.AuctionPricingSecondPrice, Increment: 0.01},
+			imp:           openrtb2.Imp{ID: "imp1"},
+			bids:          []bidSetup{{"imp1", "appnexus", 5, "", true}, {"imp1", "appnexus", 4, "", false}},
+			expectedPrice: 5,
+		},
+		{
+			name:          "second_price_no_competition",
+			pricing:       config.AccountAuctionPricing{Mode: config.AuctionPricingSecondPrice, Increment: 0.01},
+			imp:           openrtb2.Imp{ID: "imp1"},
+			bids:          []bidSetup{{"imp1", "appnexus", 5, "", true}},
+			expectedPrice: 5,
+		},
+		{
+			name:          "second_price_increment_above_winner",
+			pricing:       config.AccountAuctionPricing{Mode: config.AuctionPricingSecondPrice, Increment: 0.5},
+			imp:           openrtb2.Imp{ID: "imp1"},
+			bids:          []bidSetup{{"imp1", "appnexus", 5, "", true}, {"imp1", "rubicon", 5, "", false}},
+			expectedPrice: 5,
+		},
+		{
+			name:          "second_price_deal_pays_own_price",
+			pricing:       config.AccountAuctionPricing{Mode: config.AuctionPricingSecondPrice, Increment: 0.01},
+			imp:           openrtb2.Imp{ID: "imp1"},
+			bids:          []bidSetup{{"imp1", "appnexus", 5, "deal1", true}, {"imp1", "rubicon", 3, "", false}},
+			expectedPrice: 5,
+		},
+		{
+			name:               "soft_floor_second_price_above_floor",
+			pricing:            config.AccountAuctionPricing{Mode: config.AuctionPricingSoftFloor, Increment: 0.01},
+			imp:                openrtb2.Imp{ID: "imp1", BidFloor: 2, BidFloorCur: "USD"},
+			bids:               []bidSetup{{"imp1", "appnexus", 5, "", true}, {"imp1", "rubicon", 3, "", false}},
+			expectedPrice:      3.01,
+			expectedBidPricing: &openrtb_ext.ExtBidPrebidPricing{Mode: "soft_floor", BidPrice: 5, ClearingPrice: 3.01},
+		},
+		{
+			name:               "soft_floor_second_price_below_floor",
+			pricing:            config.AccountAuctionPricing{Mode: config.AuctionPricingSoftFloor, Increment: 0.01},
+			imp:                openrtb2.Imp{ID: "imp1", BidFloor: 4},
+			bids:               []bidSetup{{"imp1", "appnexus", 5, "", true}, {"imp1", "rubicon", 3, "", false}},
+			expectedPrice:      4,
+			expectedBidPricing: &openrtb_ext.ExtBidPrebidPricing{Mode: "soft_floor", BidPrice: 5, ClearingPrice: 4},
+		},
+		{
+			name:               "soft_floor_no_competition",
+			pricing:            config.AccountAuctionPricing{Mode: config.AuctionPricingSoftFloor, Increment: 0.01},
+			imp:                openrtb2.Imp{ID: "imp1", BidFloor: 4},
+			bids:               []bidSetup{{"imp1", "appnexus", 5, "", true}},
+			expectedPrice:      4,
+			expectedBidPricing: &openrtb_ext.ExtBidPrebidPricing{Mode: "soft_floor", BidPrice: 5, ClearingPrice: 4},
+		},
+		{
+			name:          "soft_floor_winner_below_floor",
+			pricing:       config.AccountAuctionPricing{Mode: config.AuctionPricingSoftFloor, Increment: 0.01},
+			imp:           openrtb2.Imp{ID: "imp1", BidFloor: 6},
+			bids:          []bidSetup{{"imp1", "appnexus", 5, "", true}, {"imp1", "rubicon", 3, "", false}},
+			expectedPrice: 5,
+		},
+		{
+			name:               "soft_floor_converted_floor",
+			pricing:            config.AccountAuctionPricing{Mode: config.AuctionPricingSoftFloor},
+			imp:                openrtb2.Imp{ID: "imp1", BidFloor: 2, BidFloorCur: "EUR"},
+			bids:               []bidSetup{{"imp1", "appnexus", 5, "", true}, {"imp1", "rubicon", 3, "", false}},
+			expectedPrice:      4,
+			expectedBidPricing: &openrtb_ext.ExtBidPrebidPricing{Mode: "soft_floor", BidPrice: 5, ClearingPrice: 4},
+		},
+		{
+			name:               "soft_floor_without_floor_acts_as_second_price",
+			pricing:            config.AccountAuctionPricing{Mode: config.AuctionPricingSoftFloor, Increment: 0.01},
+			imp:                openrtb2.Imp{ID: "imp1"},
+			bids:               []bidSetup{{"imp1", "appnexus", 5, "", true}, {"imp1", "rubicon", 3, "", false}},
+			expectedPrice:      3.01,
+			expectedBidPricing: &openrtb_ext.ExtBidPrebidPricing{Mode: "soft_floor", BidPrice: 5, ClearingPrice: 3.01},
+		},
+	}
+
+	conversions := currency.NewRates(map[string]map[string]float64{"EUR": {"USD": 2}})
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			auc := &auction{
+				winningBids:     map[string]*entities.PbsOrtbBid{},
+				allBidsByBidder: map[string]map[openrtb_ext.BidderName][]*entities.PbsOrtbBid{},
+			}
+
+			var winner *entities.PbsOrtbBid
+			for _, b := range test.bids {
+				bid := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ImpID: b.impID, Price: b.price, DealID: b.dealID}}
+				if auc.allBidsByBidder[b.impID] == nil {
+					auc.allBidsByBidder[b.impID] = map[openrtb_ext.BidderName][]*entities.PbsOrtbBid{}
+				}
+				auc.allBidsByBidder[b.impID][b.bidder] = append(auc.allBidsByBidder[b.impID][b.bidder], bid)
+				if b.winner {
+					auc.winningBids[b.impID] = bid
+					winner = bid
+				}
+			}
+
+			req := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Imp: []openrtb2.Imp{test.imp}}}
+			auc.applyPricing(test.pricing, req, conversions)
+
+			assert.Equal(t, test.expectedPrice, winner.Bid.Price)
+			assert.Equal(t, test.expectedBidPricing, winner.BidPricing)
+		})
+	}
+}
+
+func TestApplyMediaTypePriority(t *testing.T) {
+	type bidSetup struct {
+		bidder  openrtb_ext.BidderName
+		id      string
+		price   float64
+		bidType openrtb_ext.BidType
+		dealID  string
+	}
+
+	testCases := []struct {
+		name              string
+		mediaTypePriority *openrtb_ext.ExtMediaTypePriority
+		preferDeals       bool
+		bids              []bidSetup
+		expectedWinner    string
+	}{
+		{
+			name:           "no_priority",
+			bids:           []bidSetup{{"appnexus", "video", 2, openrtb_ext.BidTypeVideo, ""}, {"rubicon", "banner", 3, openrtb_ext.BidTypeBanner, ""}},
+			expectedWinner: "banner",
+		},
+		{
+			name:              "strict_priority",
+			mediaTypePriority: &openrtb_ext.ExtMediaTypePriority{Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeBanner}},
+			bids:              []bidSetup{{"appnexus", "video", 2, openrtb_ext.BidTypeVideo, ""}, {"rubicon", "banner", 30, openrtb_ext.BidTypeBanner, ""}},
+			expectedWinner:    "video",
+		},
+		{
+			name:              "best_bid_of_favoured_media_type",
+			mediaTypePriority: &openrtb_ext.ExtMediaTypePriority{Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeBanner}},
+			bids:              []bidSetup{{"appnexus", "video1", 2, openrtb_ext.BidTypeVideo, ""}, {"rubicon", "video2", 2.5, openrtb_ext.BidTypeVideo, ""}, {"rubicon", "banner", 3, openrtb_ext.BidTypeBanner, ""}},
+			expectedWinner:    "video2",
+		},
+		{
+			name:              "bias_not_exceeded",
+			mediaTypePriority: &openrtb_ext.ExtMediaTypePriority{Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeBanner}, Bias: 1.2},
+			bids:              []bidSetup{{"appnexus", "video", 2, openrtb_ext.BidTypeVideo, ""}, {"rubicon", "banner", 2.4, openrtb_ext.BidTypeBanner, ""}},
+			expectedWinner:    "video",
+		},
+		{
+			name:              "bias_exceeded",
+			mediaTypePriority: &openrtb_ext.ExtMediaTypePriority{Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeBanner}, Bias: 1.2},
+			bids:              []bidSetup{{"appnexus", "video", 2, openrtb_ext.BidTypeVideo, ""}, {"rubicon", "banner", 2.5, openrtb_ext.BidTypeBanner, ""}},
+			expectedWinner:    "banner",
+		},
+		{
+			name:              "unlisted_media_type_never_wins",
+			mediaTypePriority: &openrtb_ext.ExtMediaTypePriority{Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}, Bias: 1.2},
+			bids:              []bidSetup{{"appnexus", "native", 20, openrtb_ext.BidTypeNative, ""}, {"rubicon", "banner", 1, openrtb_ext.BidTypeBanner, ""}},
+			expectedWinner:    "banner",
+		},
+		{
+			name:              "unlisted_media_type_wins_alone",
+			mediaTypePriority: &openrtb_ext.ExtMediaTypePriority{Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}},
+			bids:              []bidSetup{{"appnexus", "native", 20, openrtb_ext.BidTypeNative, ""}},
+			expectedWinner:    "native",
+		},
+		{
+			name:              "preferred_deal_wins_over_favoured_media_type",
+			mediaTypePriority: &openrtb_ext.ExtMediaTypePriority{Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeBanner}},
+			preferDeals:       true,
+			bids:              []bidSetup{{"appnexus", "video", 5, openrtb_ext.BidTypeVideo, ""}, {"rubicon", "banner", 1, openrtb_ext.BidTypeBanner, "deal1"}},
+			expectedWinner:    "banner",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			seatBids := make(map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid)
+			for _, b := range test.bids {
+				if _, ok := seatBids[b.bidder]; !ok {
+					seatBids[b.bidder] = &entities.PbsOrtbSeatBid{Seat: b.bidder.String()}
+				}
+				seatBids[b.bidder].Bids = append(seatBids[b.bidder].Bids, &entities.PbsOrtbBid{
+					Bid:     &openrtb2.Bid{ID: b.id, ImpID: "imp1", Price: b.price, DealID: b.dealID},
+					BidType: b.bidType,
+				})
+			}
+
+			auc := newAuction(seatBids, 1, test.preferDeals)
+			topBidsBefore := len(auc.allBidsByBidder["imp1"])
+			auc.applyMediaTypePriority(test.mediaTypePriority, test.preferDeals)
+
+			assert.Equal(t, test.expectedWinner, auc.winningBids["imp1"].Bid.ID)
+			assert.Len(t, auc.allBidsByBidder["imp1"], topBidsBefore)
+		})
+	}
+}
+
+func TestGetMediaTypePriority(t *testing.T) {
+	account := config.AccountAuctionMediaTypePriority{Priority: []string{"video", "banner"}, Bias: 1.5}
+	requestPriority := &openrtb_ext.ExtMediaTypePriority{Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}}
+
+	assert.Nil(t, getMediaTypePriority(nil, config.AccountAuctionMediaTypePriority{}))
+	assert.Equal(t, &openrtb_ext.ExtMediaTypePriority{Priority: []openrtb_ext.BidType{openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeBanner}, Bias: 1.5}, getMediaTypePriority(&openrtb_ext.ExtRequestTargeting{}, account))
+	assert.Equal(t, requestPriority, getMediaTypePriority(&openrtb_ext.ExtRequestTargeting{MediaTypePriority: requestPriority}, account))
+}
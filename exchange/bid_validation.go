@@ -0,0 +1,123 @@
+package exchange
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/bidvalidation"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/errortypes"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+// bidValidators holds the bid validators of the auctions. The host validators are built once, and the validators of
+// the accounts configuring their own are cached until the account config changes. Validators which can't be built
+// are logged when the set is built and left out.
+type bidValidators struct {
+	registry       bidvalidation.Registry
+	host           config.Validations
+	hostValidators []bidvalidation.Validator
+	cache          sync.Map
+}
+
+// accountBidValidators is a cached set of validators along with the merged config it was built with
+type accountBidValidators struct {
+	config     map[string]config.BidValidator
+	validators []bidvalidation.Validator
+}
+
+func newBidValidators(registry bidvalidation.Registry, host config.Validations) *bidValidators {
+	validators, errs := registry.Build(host.Validators)
+	for _, err := range errs {
+		glog.Warningf("Host bid validators: %v", err)
+	}
+	return &bidValidators{
+		registry:       registry,
+		host:           host,
+		hostValidators: validators,
+	}
+}
+
+// get returns the validators of the account, which are the host validators unless the account configures its own
+func (bv *bidValidators) get(account *config.Account) []bidvalidation.Validator {
+	if bv == nil {
+		return nil
+	}
+	if len(account.Validations.Validators) == 0 {
+		return bv.hostValidators
+	}
+
+	cfg := bv.host.MergeValidators(account.Validations)
+	if cached, ok := bv.cache.Load(account.ID); ok && reflect.DeepEqual(cached.(*accountBidValidators).config, cfg) {
+		return cached.(*accountBidValidators).validators
+	}
+
+	validators, errs := bv.registry.Build(cfg)
+	for _, err := range errs {
+		glog.Warningf("Account %s bid validators: %v", account.ID, err)
+	}
+	bv.cache.Store(account.ID, &accountBidValidators{config: cfg, validators: validators})
+	return validators
+}
+
+// validateBids runs the bid validators over the bids of the auction. Bids failing an enforced validator are
+// removed and reported as seat non bids, while bids failing a validator in warn mode are only reported as
+// warnings.
+func validateBids(validators []bidvalidation.Validator, req *openrtb2.BidRequest, adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, seatNonBids *nonBids) []error {
+	if len(validators) == 0 {
+		return nil
+	}
+
+	imps := make(map[string]*openrtb2.Imp, len(req.Imp))
+	for i := range req.Imp {
+		imps[req.Imp[i].ID] = &req.Imp[i]
+	}
+
+	var errs []error
+	for _, seatBid := range adapterBids {
+		if seatBid == nil {
+			continue
+		}
+		validBids := seatBid.Bids[:0]
+		for _, bid := range seatBid.Bids {
+			if bid == nil || bid.Bid == nil {
+				continue
+			}
+			imp, ok := imps[bid.Bid.ImpID]
+			if !ok {
+				validBids = append(validBids, bid)
+				continue
+			}
+
+			rejected := false
+			for _, validator := range validators {
+				err := validator.Validate(bid, imp)
+				if err == nil {
+					continue
+				}
+				action := "flagged"
+				if validator.Enforce {
+					action = "rejected"
+				}
+				errs = append(errs, &errortypes.Warning{
+					Message:     fmt.Sprintf("%s bid id %s %s by bid validator %s: %s", seatBid.Seat, bid.Bid.ID, action, validator.Name, err.Error()),
+					WarningCode: errortypes.BidValidationWarningCode,
+				})
+				if validator.Enforce {
+					seatNonBids.addBid(bid, validator.NonBidReason(), seatBid.Seat)
+					rejected = true
+					break
+				}
+			}
+			if !rejected {
+				validBids = append(validBids, bid)
+			}
+		}
+		seatBid.Bids = validBids
+	}
+	return errs
+}
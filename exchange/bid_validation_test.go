@@ -0,0 +1,130 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/bidvalidation"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/errortypes"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBids(t *testing.T) {
+	req := &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "imp1", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}},
+		},
+	}
+
+	testCases := []struct {
+		description         string
+		mode                string
+		expectedBidIDs      []string
+		expectedNonBidCodes []int
+		expectedWarnings    int
+	}{
+		{
+			description:         "enforce",
+			mode:                config.ValidationEnforce,
+			expectedBidIDs:      []string{"valid"},
+			expectedNonBidCodes: []int{int(openrtb_ext.ResponseRejectedInvalidCreative)},
+			expectedWarnings:    1,
+		},
+		{
+			description:      "warn",
+			mode:             config.ValidationWarn,
+			expectedBidIDs:   []string{"valid", "nocrid"},
+			expectedWarnings: 1,
+		},
+		{
+			description:    "skip",
+			mode:           config.ValidationSkip,
+			expectedBidIDs: []string{"valid", "nocrid"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			validators, buildErrs := bidvalidation.NewRegistry().Build(map[string]config.BidValidator{
+				bidvalidation.CridValidatorName: {Mode: test.mode},
+			})
+			require.Empty(t, buildErrs)
+
+			adapterBids := map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+				"appnexus": {
+					Seat: "appnexus",
+					Bids: []*entities.PbsOrtbBid{
+						{Bid: &openrtb2.Bid{ID: "valid", ImpID: "imp1", CrID: "creative1"}, BidType: openrtb_ext.BidTypeBanner},
+						{Bid: &openrtb2.Bid{ID: "nocrid", ImpID: "imp1"}, BidType: openrtb_ext.BidTypeBanner},
+					},
+				},
+			}
+			seatNonBids := nonBids{}
+
+			errs := validateBids(validators, req, adapterBids, &seatNonBids)
+
+			var bidIDs []string
+			for _, bid := range adapterBids["appnexus"].Bids {
+				bidIDs = append(bidIDs, bid.Bid.ID)
+			}
+			assert.Equal(t, test.expectedBidIDs, bidIDs)
+
+			var nonBidCodes []int
+			for _, nonBid := range seatNonBids.seatNonBidsMap["appnexus"] {
+				nonBidCodes = append(nonBidCodes, nonBid.StatusCode)
+			}
+			assert.Equal(t, test.expectedNonBidCodes, nonBidCodes)
+
+			assert.Len(t, errs, test.expectedWarnings)
+			for _, err := range errs {
+				assert.Equal(t, errortypes.BidValidationWarningCode, errortypes.ReadCode(err))
+			}
+		})
+	}
+}
+
+func TestBidValidatorsGet(t *testing.T) {
+	var builds int
+	registry := bidvalidation.Registry{
+		"counted": func(params json.RawMessage) (bidvalidation.BidValidator, error) {
+			builds++
+			return bidvalidation.NewCridValidator(params)
+		},
+	}
+	host := config.Validations{Validators: map[string]config.BidValidator{
+		"counted": {Mode: config.ValidationEnforce},
+		"unknown": {Mode: config.ValidationWarn},
+	}}
+
+	bv := newBidValidators(registry, host)
+	require.Equal(t, 1, builds, "the host validators should be built with the exchange")
+
+	hostAccount := &config.Account{ID: "host"}
+	for i := 0; i < 2; i++ {
+		validators := bv.get(hostAccount)
+		require.Len(t, validators, 1, "the unknown validator should be left out")
+		assert.True(t, validators[0].Enforce)
+	}
+	assert.Equal(t, 1, builds, "the host validators should not be rebuilt for each auction")
+
+	account := &config.Account{ID: "account", Validations: config.Validations{Validators: map[string]config.BidValidator{
+		"counted": {Mode: config.ValidationWarn},
+	}}}
+	for i := 0; i < 2; i++ {
+		validators := bv.get(account)
+		require.Len(t, validators, 1)
+		assert.False(t, validators[0].Enforce, "the account validators should replace the host ones")
+	}
+	assert.Equal(t, 2, builds, "the account validators should be cached")
+
+	account.Validations.Validators["counted"] = config.BidValidator{Mode: config.ValidationSkip}
+	assert.Empty(t, bv.get(account), "the account validators should be rebuilt when the account config changes")
+
+	var nilValidators *bidValidators
+	assert.Nil(t, nilValidators.get(account))
+}
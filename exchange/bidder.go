@@ -8,7 +8,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/http/httptrace"
 	"regexp"
@@ -34,6 +33,7 @@ import (
 	"github.com/prebid/prebid-server/v2/errortypes"
 	"github.com/prebid/prebid-server/v2/metrics"
 	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/requestsigner"
 	"github.com/prebid/prebid-server/v2/util/jsonutil"
 	"golang.org/x/net/context/ctxhttp"
 )
@@ -76,12 +76,18 @@ type bidRequestOptions struct {
 
 type extraBidderRespInfo struct {
 	respProcessingStartTime time.Time
+	seatNonBids             nonBids
 }
 
 type extraAuctionResponseInfo struct {
 	fledge                  *openrtb_ext.Fledge
 	bidsFound               bool
 	bidderResponseStartTime time.Time
+	seatNonBids             nonBids
+	// lateBids holds the bids of the bidders which did not respond yet, if the auction returned early
+	lateBids *LateBids
+	// shadowBids holds the bids of the shadow bidders, which are not part of the auction
+	shadowBids *ShadowBids
 }
 
 const ImpIdReqBody = "Stored bid response for impression id: "
@@ -96,6 +102,11 @@ const (
 // The name refers to the "Adapter" architecture pattern, and should not be confused with a Prebid "Adapter"
 // (which is being phased out and replaced by Bidder for OpenRTB auctions)
 func AdaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine, name openrtb_ext.BidderName, debugInfo *config.DebugInfo, endpointCompression string) AdaptedBidder {
+	var maxResponseBytes int64
+	if httpClient := cfg.BidderInfos[string(name)].HTTPClient; httpClient != nil {
+		maxResponseBytes = httpClient.MaxResponseBytes
+	}
+
 	return &bidderAdapter{
 		Bidder:     bidder,
 		BidderName: name,
@@ -106,8 +117,34 @@ func AdaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Config
 			DisableConnMetrics:  cfg.Metrics.Disabled.AdapterConnectionMetrics,
 			DebugInfo:           config.DebugInfo{Allow: parseDebugInfo(debugInfo)},
 			EndpointCompression: endpointCompression,
+			MaxResponseBytes:    maxResponseBytes,
 		},
+		circuitBreaker: newCircuitBreaker(cfg.BidderInfos[string(name)].CircuitBreaker, name, me),
+		latencyTracker: newLatencyTracker(cfg.AdaptiveBidderTimeouts),
+		responseCache:  newBidderResponseCache(cfg.BidderInfos[string(name)].ResponseCache),
+		requestSigner:  newBidderRequestSigner(cfg.BidderInfos[string(name)].RequestSigning, name),
+	}
+}
+
+// newBidderRequestSigner builds the request signer of the bidder, or returns nil if its requests aren't signed. The
+// signers are built when the bidder infos are validated, so a signer only fails to build here if its key file became
+// unreadable since. It then fails the requests of the bidder, so that they aren't sent unsigned.
+func newBidderRequestSigner(cfg *config.RequestSigning, name openrtb_ext.BidderName) requestsigner.RequestSigner {
+	signer, err := requestsigner.NewRequestSigner(cfg)
+	if err != nil {
+		glog.Errorf("%v: failed to build request signer: %v", name, err)
+		return &failedRequestSigner{err: fmt.Errorf("failed to build request signer: %v", err)}
 	}
+	return signer
+}
+
+// failedRequestSigner stands for a request signer which couldn't be built
+type failedRequestSigner struct {
+	err error
+}
+
+func (s *failedRequestSigner) Sign(req *http.Request, body []byte) error {
+	return s.err
 }
 
 func parseDebugInfo(info *config.DebugInfo) bool {
@@ -118,11 +155,15 @@ func parseDebugInfo(info *config.DebugInfo) bool {
 }
 
 type bidderAdapter struct {
-	Bidder     adapters.Bidder
-	BidderName openrtb_ext.BidderName
-	Client     *http.Client
-	me         metrics.MetricsEngine
-	config     bidderAdapterConfig
+	Bidder         adapters.Bidder
+	BidderName     openrtb_ext.BidderName
+	Client         *http.Client
+	me             metrics.MetricsEngine
+	config         bidderAdapterConfig
+	circuitBreaker *circuitBreaker
+	latencyTracker *latencyTracker
+	responseCache  *bidderResponseCache
+	requestSigner  requestsigner.RequestSigner
 }
 
 type bidderAdapterConfig struct {
@@ -130,6 +171,7 @@ type bidderAdapterConfig struct {
 	DisableConnMetrics  bool
 	DebugInfo           config.DebugInfo
 	EndpointCompression string
+	MaxResponseBytes    int64
 }
 
 func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest BidderRequest, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, adsCertSigner adscert.Signer, bidRequestOptions bidRequestOptions, alternateBidderCodes openrtb_ext.ExtAlternateBidderCodes, hookExecutor hookexecution.StageExecutor, ruleToAdjustments openrtb_ext.AdjustmentsByDealID) ([]*entities.PbsOrtbSeatBid, extraBidderRespInfo, []error) {
@@ -152,13 +194,36 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest Bidde
 
 	//check if real request exists for this bidder or it only has stored responses
 	dataLen := 0
+	var (
+		cacheKey    string
+		cachedCalls []cachedHttpCall
+		cacheHit    bool
+	)
 	if len(bidderRequest.BidRequest.Imp) > 0 {
 		// Reducing the amount of time bidders have to compensate for the processing time used by PBS to fetch a stored request (if needed), validate the OpenRTB request and split it into multiple requests sanitized for each bidder
 		// As well as for the time needed by PBS to prepare the auction response
 		if bidRequestOptions.tmaxAdjustments != nil && bidRequestOptions.tmaxAdjustments.IsEnforced {
 			bidderRequest.BidRequest.TMax = getBidderTmax(&bidderTmaxCtx{ctx}, bidderRequest.BidRequest.TMax, *bidRequestOptions.tmaxAdjustments)
 		}
-		reqData, errs = bidder.Bidder.MakeRequests(bidderRequest.BidRequest, reqInfo)
+		// Chronically slow bidders are cut short so they don't hold up the auction, tmax tells them how long they have
+		bidderRequest.adaptiveTimeout = bidder.latencyTracker.getAdaptiveTimeout(ctx, bidRequestOptions.tmaxAdjustments)
+		if bidderRequest.adaptiveTimeout.cut {
+			bidderRequest.BidRequest.TMax = bidderRequest.adaptiveTimeout.timeout.Milliseconds()
+		}
+		cacheKey, cachedCalls, cacheHit = bidder.lookupResponseCache(bidderRequest)
+	}
+	if cacheHit {
+		// reuse the bidder responses of an identical request instead of calling the bidder again
+		dataLen = len(cachedCalls)
+		responseChannel = make(chan *httpCallInfo, dataLen)
+		for _, call := range cachedCalls {
+			httpInfo := &httpCallInfo{request: call.request, response: call.response, bidResponse: call.bidResponse, cached: true}
+			// the cached calls are captured like calls made to the bidder so the auction can be replayed
+			bidderRequest.recorder.RecordCall(string(bidderRequest.BidderName), newCapturedCall(httpInfo))
+			responseChannel <- httpInfo
+		}
+	} else if len(bidderRequest.BidRequest.Imp) > 0 {
+		reqData, errs = bidder.getAdapter(bidderRequest).MakeRequests(bidderRequest.BidRequest, reqInfo)
 
 		if len(reqData) == 0 {
 			// If the adapter failed to generate both requests and errors, this is an error.
@@ -199,11 +264,11 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest Bidde
 		dataLen = len(reqData) + len(bidderRequest.BidderStoredResponses)
 		responseChannel = make(chan *httpCallInfo, dataLen)
 		if len(reqData) == 1 {
-			responseChannel <- bidder.doRequest(ctx, reqData[0], bidRequestOptions.bidderRequestStartTime, bidRequestOptions.tmaxAdjustments)
+			responseChannel <- bidder.sendRequest(ctx, bidderRequest, reqData[0], bidRequestOptions.bidderRequestStartTime, bidRequestOptions.tmaxAdjustments)
 		} else {
 			for _, oneReqData := range reqData {
 				go func(data *adapters.RequestData) {
-					responseChannel <- bidder.doRequest(ctx, data, bidRequestOptions.bidderRequestStartTime, bidRequestOptions.tmaxAdjustments)
+					responseChannel <- bidder.sendRequest(ctx, bidderRequest, data, bidRequestOptions.bidderRequestStartTime, bidRequestOptions.tmaxAdjustments)
 				}(oneReqData) // Method arg avoids a race condition on oneReqData
 			}
 		}
@@ -231,6 +296,10 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest Bidde
 		},
 	}
 
+	// calls are only cached if all of them succeeded
+	cacheable := cacheKey != "" && !cacheHit
+	var callsToCache []cachedHttpCall
+
 	// If the bidder made multiple requests, we still want them to enter as many bids as possible...
 	// even if the timeout occurs sometime halfway through.
 	for i := 0; i < dataLen; i++ {
@@ -258,8 +327,17 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest Bidde
 
 		if httpInfo.err == nil {
 			extraRespInfo.respProcessingStartTime = time.Now()
-			bidResponse, moreErrs := bidder.Bidder.MakeBids(bidderRequest.BidRequest, httpInfo.request, httpInfo.response)
-			errs = append(errs, moreErrs...)
+			bidResponse := httpInfo.bidResponse
+			if !httpInfo.cached {
+				var moreErrs []error
+				bidResponse, moreErrs = bidder.getAdapter(bidderRequest).MakeBids(bidderRequest.BidRequest, httpInfo.request, httpInfo.response)
+				errs = append(errs, moreErrs...)
+				if len(moreErrs) > 0 {
+					cacheable = false
+				} else if cacheable {
+					callsToCache = append(callsToCache, cachedHttpCall{request: httpInfo.request, response: httpInfo.response, bidResponse: copyBidderResponse(bidResponse)})
+				}
+			}
 
 			if bidResponse != nil {
 				reject := hookExecutor.ExecuteRawBidderResponseStage(bidResponse, string(bidder.BidderName))
@@ -280,7 +358,12 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest Bidde
 				// and use it as currency
 				var conversionRate float64
 				var err error
-				for _, bidReqCur := range bidderRequest.BidRequest.Cur {
+				auctionCur := bidderRequest.BidRequest.Cur
+				if len(bidderRequest.auctionCur) > 0 {
+					// the request was sent in a currency negotiated with the bidder
+					auctionCur = bidderRequest.auctionCur
+				}
+				for _, bidReqCur := range auctionCur {
 					if conversionRate, err = conversions.GetRate(bidResponse.Currency, bidReqCur); err == nil {
 						seatBidMap[bidderRequest.BidderName].Currency = bidReqCur
 						break
@@ -360,7 +443,15 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest Bidde
 
 						originalBidCpm := 0.0
 						currencyAfterAdjustments := ""
+						var bidCurrency *openrtb_ext.ExtBidPrebidCurrency
 						if bidResponse.Bids[i].Bid != nil {
+							if len(bidderRequest.auctionCur) > 0 {
+								bidCurrency = &openrtb_ext.ExtBidPrebidCurrency{
+									BidderCurrency: bidResponse.Currency,
+									BidderPrice:    bidResponse.Bids[i].Bid.Price,
+									Rate:           conversionRate,
+								}
+							}
 							originalBidCpm = bidResponse.Bids[i].Bid.Price
 							bidResponse.Bids[i].Bid.Price = bidResponse.Bids[i].Bid.Price * adjustmentFactor * conversionRate
 
@@ -387,6 +478,7 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest Bidde
 							DealPriority:   bidResponse.Bids[i].DealPriority,
 							OriginalBidCPM: originalBidCpm,
 							OriginalBidCur: bidResponse.Currency,
+							BidCurrency:    bidCurrency,
 							AdapterCode:    bidderRequest.BidderCoreName,
 						})
 						seatBidMap[bidderName].Currency = currencyAfterAdjustments
@@ -397,9 +489,19 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest Bidde
 				}
 			}
 		} else {
+			cacheable = false
 			errs = append(errs, httpInfo.err)
+			if _, ok := httpInfo.err.(*errortypes.BidderCircuitOpen); ok {
+				for _, impID := range getRequestImpIDs(httpInfo.request, bidderRequest.BidRequest) {
+					extraRespInfo.seatNonBids.addImp(impID, int(openrtb_ext.ErrorBidderUnreachable), string(bidderRequest.BidderName))
+				}
+			}
 		}
 	}
+	if cacheable {
+		bidder.responseCache.set(cacheKey, callsToCache)
+	}
+
 	seatBids := make([]*entities.PbsOrtbSeatBid, 0, len(seatBidMap))
 	for _, seatBid := range seatBidMap {
 		seatBids = append(seatBids, seatBid)
@@ -408,6 +510,37 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, bidderRequest Bidde
 	return seatBids, extraRespInfo, errs
 }
 
+// lookupResponseCache returns the http calls cached for the request if the bidder response cache is enabled.
+// The returned key is empty if the calls made for the request must not be cached.
+func (bidder *bidderAdapter) lookupResponseCache(bidderRequest BidderRequest) (key string, calls []cachedHttpCall, hit bool) {
+	// requests with stored bid responses mix in responses which are not returned by the bidder, requests sent to
+	// an account endpoint get responses which are not returned by the host endpoint, and replayed requests get
+	// the responses of the captured auction
+	if bidder.responseCache == nil || len(bidderRequest.BidderStoredResponses) > 0 || bidderRequest.adapter != nil || bidderRequest.replay != nil {
+		return "", nil, false
+	}
+
+	key, err := bidderResponseCacheKey(bidderRequest.BidRequest)
+	if err != nil {
+		return "", nil, false
+	}
+
+	if calls, hit = bidder.responseCache.get(key); hit {
+		bidder.me.RecordAdapterBidResponseCacheResult(bidder.BidderName, metrics.CacheHit)
+		return key, calls, true
+	}
+	bidder.me.RecordAdapterBidResponseCacheResult(bidder.BidderName, metrics.CacheMiss)
+	return key, nil, false
+}
+
+// getAdapter returns the adapter the account set for the request, or the adapter built at startup
+func (bidder *bidderAdapter) getAdapter(bidderRequest BidderRequest) adapters.Bidder {
+	if bidderRequest.adapter != nil {
+		return bidderRequest.adapter
+	}
+	return bidder.Bidder
+}
+
 func addNativeTypes(bid *openrtb2.Bid, request *openrtb2.BidRequest) (*nativeResponse.Response, []error) {
 	var errs []error
 	var nativeMarkup nativeResponse.Response
@@ -507,6 +640,8 @@ func makeExt(httpInfo *httpCallInfo) *openrtb_ext.ExtHttpCall {
 		ext.Uri = httpInfo.request.Uri
 		ext.RequestBody = string(httpInfo.request.Body)
 		ext.RequestHeaders = filterHeader(httpInfo.request.Headers)
+		ext.TimeoutMS = httpInfo.timeout.Milliseconds()
+		ext.Cached = httpInfo.cached
 
 		if httpInfo.err == nil && httpInfo.response != nil {
 			ext.ResponseBody = string(httpInfo.response.Body)
@@ -518,12 +653,12 @@ func makeExt(httpInfo *httpCallInfo) *openrtb_ext.ExtHttpCall {
 }
 
 // doRequest makes a request, handles the response, and returns the data needed by the
-// Bidder interface.
-func (bidder *bidderAdapter) doRequest(ctx context.Context, req *adapters.RequestData, bidderRequestStartTime time.Time, tmaxAdjustments *TmaxAdjustmentsPreprocessed) *httpCallInfo {
-	return bidder.doRequestImpl(ctx, req, glog.Warningf, bidderRequestStartTime, tmaxAdjustments)
+// Bidder interface. The adapter which made the request is notified if the request times out.
+func (bidder *bidderAdapter) doRequest(ctx context.Context, req *adapters.RequestData, bidderRequestStartTime time.Time, tmaxAdjustments *TmaxAdjustmentsPreprocessed, timeout adaptiveTimeout, adapter adapters.Bidder) *httpCallInfo {
+	return bidder.doRequestImpl(ctx, req, glog.Warningf, bidderRequestStartTime, tmaxAdjustments, timeout, adapter)
 }
 
-func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.RequestData, logger util.LogMsg, bidderRequestStartTime time.Time, tmaxAdjustments *TmaxAdjustmentsPreprocessed) *httpCallInfo {
+func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.RequestData, logger util.LogMsg, bidderRequestStartTime time.Time, tmaxAdjustments *TmaxAdjustmentsPreprocessed, timeout adaptiveTimeout, adapter adapters.Bidder) *httpCallInfo {
 	requestBody, err := getRequestBody(req, bidder.config.EndpointCompression)
 	if err != nil {
 		return &httpCallInfo{
@@ -539,6 +674,16 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 		}
 	}
 	httpReq.Header = req.Headers
+	if bidder.requestSigner != nil {
+		// the signature is only set on the http request, so that it isn't exposed in the debug output
+		httpReq.Header = req.Headers.Clone()
+		if err := bidder.requestSigner.Sign(httpReq, requestBody.Bytes()); err != nil {
+			return &httpCallInfo{
+				request: req,
+				err:     err,
+			}
+		}
+	}
 
 	// If adapter connection metrics are not disabled, add the client trace
 	// to get complete connection info into our metrics
@@ -557,18 +702,41 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 		}
 	}
 
+	if !bidder.circuitBreaker.allow(req.Uri) {
+		return &httpCallInfo{
+			request: req,
+			err:     &errortypes.BidderCircuitOpen{Message: "request not sent, circuit breaker is open after too many failed requests"},
+		}
+	}
+
+	requestCtx := ctx
+	if timeout.cut {
+		var cancel context.CancelFunc
+		requestCtx, cancel = context.WithTimeout(ctx, timeout.timeout)
+		defer cancel()
+	}
+
 	httpCallStart := time.Now()
-	httpResp, err := ctxhttp.Do(ctx, bidder.Client, httpReq)
+	httpResp, err := ctxhttp.Do(requestCtx, bidder.Client, httpReq)
 	if err != nil {
+		// the auction still had time left when the adaptive timeout cut the request short
+		cutShort := err == context.DeadlineExceeded && timeout.cut && ctx.Err() == nil
+		if err == context.Canceled || cutShort {
+			// neither a canceled auction nor a slow bidder cut short say anything about the health of the bidder
+			bidder.circuitBreaker.release(req.Uri)
+		} else {
+			bidder.circuitBreaker.done(req.Uri, true)
+		}
 		if err == context.DeadlineExceeded {
+			bidder.latencyTracker.recordTimeout()
 			err = &errortypes.Timeout{Message: err.Error()}
-			var corebidder adapters.Bidder = bidder.Bidder
+			corebidder := adapter
 			// The bidder adapter normally stores an info-aware bidder (a bidder wrapper)
 			// rather than the actual bidder. So we need to unpack that first.
 			if b, ok := corebidder.(*adapters.InfoAwareBidder); ok {
 				corebidder = b.Bidder
 			}
-			if tb, ok := corebidder.(adapters.TimeoutBidder); ok {
+			if tb, ok := corebidder.(adapters.TimeoutBidder); ok && !cutShort {
 				// Toss the timeout notification call into a go routine, as we are out of time'
 				// and cannot delay processing. We don't do anything result, as there is not much
 				// we can do about a timeout notification failure. We do not want to get stuck in
@@ -580,17 +748,19 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 		return &httpCallInfo{
 			request: req,
 			err:     err,
+			timeout: timeout.timeout,
 		}
 	}
 
-	respBody, err := io.ReadAll(httpResp.Body)
+	defer httpResp.Body.Close()
+	respBody, err := readResponseBody(httpResp.Body, bidder.config.MaxResponseBytes)
+	bidder.circuitBreaker.done(req.Uri, err != nil || httpResp.StatusCode >= http.StatusInternalServerError)
 	if err != nil {
 		return &httpCallInfo{
 			request: req,
 			err:     err,
 		}
 	}
-	defer httpResp.Body.Close()
 
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 400 {
 		err = &errortypes.BadServerResponse{
@@ -599,6 +769,7 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 	}
 
 	bidder.me.RecordBidderServerResponseTime(time.Since(httpCallStart))
+	bidder.latencyTracker.record(time.Since(httpCallStart))
 	return &httpCallInfo{
 		request: req,
 		response: &adapters.ResponseData{
@@ -606,8 +777,22 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 			Body:       respBody,
 			Headers:    httpResp.Header,
 		},
-		err: err,
+		err:     err,
+		timeout: timeout.timeout,
+	}
+}
+
+// getRequestImpIDs returns the IDs of the imps sent in the request, falling back to all imps of the bid request
+// for bidders which don't report them.
+func getRequestImpIDs(req *adapters.RequestData, bidRequest *openrtb2.BidRequest) []string {
+	if req != nil && len(req.ImpIDs) > 0 {
+		return req.ImpIDs
+	}
+	impIDs := make([]string, 0, len(bidRequest.Imp))
+	for _, imp := range bidRequest.Imp {
+		impIDs = append(impIDs, imp.ID)
 	}
+	return impIDs
 }
 
 func (bidder *bidderAdapter) doTimeoutNotification(timeoutBidder adapters.TimeoutBidder, req *adapters.RequestData, logger util.LogMsg) {
@@ -655,6 +840,11 @@ type httpCallInfo struct {
 	request  *adapters.RequestData
 	response *adapters.ResponseData
 	err      error
+	// timeout is the adaptive timeout chosen for the request, if adaptive bidder timeouts are enabled
+	timeout time.Duration
+	// cached is true if the call was not made but reused from the bidder response cache along with bidResponse
+	cached      bool
+	bidResponse *adapters.BidderResponse
 }
 
 // This function adds an httptrace.ClientTrace object to the context so, if connection with the bidder
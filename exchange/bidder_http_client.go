@@ -0,0 +1,128 @@
+package exchange
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/errortypes"
+	"github.com/prebid/prebid-server/v2/metrics"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+// newBidderHTTPClient returns a dedicated http client for the bidder if its bidder info overrides the host http
+// client settings, otherwise the shared client is returned. The dedicated client starts from a copy of the shared
+// transport, so root certificates and proxy settings are kept, and reports its open connections to metrics.
+func newBidderHTTPClient(client *http.Client, info *config.BidderHTTPClient, name openrtb_ext.BidderName, me metrics.MetricsEngine) *http.Client {
+	if info == nil {
+		return client
+	}
+
+	var transport *http.Transport
+	if sharedTransport, ok := client.Transport.(*http.Transport); ok {
+		transport = sharedTransport.Clone()
+	} else {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	if info.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = info.MaxConnsPerHost
+	}
+	if info.MaxIdleConns > 0 {
+		transport.MaxIdleConns = info.MaxIdleConns
+	}
+	if info.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = info.MaxIdleConnsPerHost
+	}
+	if info.IdleConnTimeoutSeconds > 0 {
+		transport.IdleConnTimeout = time.Duration(info.IdleConnTimeoutSeconds) * time.Second
+	}
+	if info.TLSHandshakeTimeoutMilliseconds > 0 {
+		transport.TLSHandshakeTimeout = time.Duration(info.TLSHandshakeTimeoutMilliseconds) * time.Millisecond
+	}
+	if version, ok := config.TLSVersions[info.TLSMinVersion]; ok {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.MinVersion = version
+	}
+	if info.HTTP2 != nil {
+		if *info.HTTP2 {
+			transport.ForceAttemptHTTP2 = true
+		} else {
+			// a non-nil empty map disables HTTP/2
+			transport.ForceAttemptHTTP2 = false
+			transport.TLSNextProto = make(map[string]func(authority string, c *tls.Conn) http.RoundTripper)
+		}
+	}
+
+	pool := &connectionPool{bidderName: name, me: me}
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	transport.DialContext = pool.trackDial(dial)
+
+	return &http.Client{
+		Transport:     transport,
+		CheckRedirect: client.CheckRedirect,
+		Jar:           client.Jar,
+		Timeout:       client.Timeout,
+	}
+}
+
+// connectionPool counts the open connections of a dedicated bidder http client.
+type connectionPool struct {
+	bidderName openrtb_ext.BidderName
+	me         metrics.MetricsEngine
+	open       int64
+}
+
+func (pool *connectionPool) trackDial(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		pool.me.RecordAdapterOpenConnections(pool.bidderName, int(atomic.AddInt64(&pool.open, 1)))
+		return &trackedConn{Conn: conn, pool: pool}, nil
+	}
+}
+
+type trackedConn struct {
+	net.Conn
+	pool      *connectionPool
+	closeOnce sync.Once
+}
+
+func (conn *trackedConn) Close() error {
+	conn.closeOnce.Do(func() {
+		conn.pool.me.RecordAdapterOpenConnections(conn.pool.bidderName, int(atomic.AddInt64(&conn.pool.open, -1)))
+	})
+	return conn.Conn.Close()
+}
+
+// readResponseBody reads the body of a bidder response, failing if it is larger than maxBytes when set.
+func readResponseBody(body io.Reader, maxBytes int64) ([]byte, error) {
+	if maxBytes <= 0 {
+		return io.ReadAll(body)
+	}
+
+	respBody, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(respBody)) > maxBytes {
+		return nil, &errortypes.BadServerResponse{
+			Message: fmt.Sprintf("Server response body exceeds the maximum size of %d bytes", maxBytes),
+		}
+	}
+	return respBody, nil
+}
@@ -0,0 +1,143 @@
+package exchange
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/errortypes"
+	"github.com/prebid/prebid-server/v2/metrics"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewBidderHTTPClient(t *testing.T) {
+	sharedClient := &http.Client{
+		Transport: &http.Transport{
+			MaxConnsPerHost: 50,
+			MaxIdleConns:    100,
+			TLSClientConfig: &tls.Config{},
+		},
+	}
+
+	t.Run("no_override", func(t *testing.T) {
+		assert.Same(t, sharedClient, newBidderHTTPClient(sharedClient, nil, "appnexus", &metrics.MetricsEngineMock{}))
+	})
+
+	t.Run("override", func(t *testing.T) {
+		info := &config.BidderHTTPClient{
+			MaxConnsPerHost:                 5,
+			IdleConnTimeoutSeconds:          30,
+			TLSHandshakeTimeoutMilliseconds: 500,
+			TLSMinVersion:                   "1.2",
+			HTTP2:                           ptrutil.ToPtr(false),
+		}
+
+		client := newBidderHTTPClient(sharedClient, info, "appnexus", &metrics.MetricsEngineMock{})
+
+		assert.NotSame(t, sharedClient, client)
+		transport := client.Transport.(*http.Transport)
+		assert.Equal(t, 5, transport.MaxConnsPerHost)
+		assert.Equal(t, 100, transport.MaxIdleConns, "settings which are not overridden are kept")
+		assert.Equal(t, 30*time.Second, transport.IdleConnTimeout)
+		assert.Equal(t, 500*time.Millisecond, transport.TLSHandshakeTimeout)
+		assert.Equal(t, uint16(tls.VersionTLS12), transport.TLSClientConfig.MinVersion)
+		assert.False(t, transport.TLSClientConfig.InsecureSkipVerify, "certificates of the bidder are always verified")
+		assert.NotNil(t, transport.TLSNextProto)
+		assert.Empty(t, transport.TLSNextProto)
+
+		sharedTransport := sharedClient.Transport.(*http.Transport)
+		assert.Equal(t, 50, sharedTransport.MaxConnsPerHost, "the shared transport must not be modified")
+		assert.Equal(t, uint16(0), sharedTransport.TLSClientConfig.MinVersion, "the shared tls config must not be modified")
+	})
+
+	t.Run("http2_enabled", func(t *testing.T) {
+		client := newBidderHTTPClient(sharedClient, &config.BidderHTTPClient{HTTP2: ptrutil.ToPtr(true)}, "appnexus", &metrics.MetricsEngineMock{})
+
+		assert.True(t, client.Transport.(*http.Transport).ForceAttemptHTTP2)
+	})
+}
+
+func TestConnectionPoolTrackDial(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterOpenConnections", openrtb_ext.BidderName("appnexus"), mock.Anything).Return()
+
+	pool := &connectionPool{bidderName: "appnexus", me: metricsMock}
+	dial := pool.trackDial(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		client, _ := net.Pipe()
+		return client, nil
+	})
+
+	conn1, err := dial(context.Background(), "tcp", "bidder.com:443")
+	assert.NoError(t, err)
+	conn2, err := dial(context.Background(), "tcp", "bidder.com:443")
+	assert.NoError(t, err)
+	conn1.Close()
+	conn1.Close()
+
+	metricsMock.AssertExpectations(t)
+	metricsMock.AssertNumberOfCalls(t, "RecordAdapterOpenConnections", 3)
+	metricsMock.AssertCalled(t, "RecordAdapterOpenConnections", openrtb_ext.BidderName("appnexus"), 2)
+	assert.Equal(t, int64(1), pool.open, "closing a connection twice must only be counted once")
+
+	conn2.Close()
+	assert.Equal(t, int64(0), pool.open)
+}
+
+func TestConnectionPoolTrackDialError(t *testing.T) {
+	pool := &connectionPool{bidderName: "appnexus", me: &metrics.MetricsEngineMock{}}
+	dial := pool.trackDial(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	conn, err := dial(context.Background(), "tcp", "bidder.com:443")
+
+	assert.Nil(t, conn)
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, int64(0), pool.open)
+}
+
+func TestReadResponseBody(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		maxBytes     int64
+		expectedBody []byte
+		expectedErr  error
+	}{
+		{
+			name:         "no_limit",
+			body:         "0123456789",
+			expectedBody: []byte("0123456789"),
+		},
+		{
+			name:         "within_limit",
+			body:         "0123456789",
+			maxBytes:     10,
+			expectedBody: []byte("0123456789"),
+		},
+		{
+			name:        "exceeds_limit",
+			body:        "0123456789",
+			maxBytes:    9,
+			expectedErr: &errortypes.BadServerResponse{Message: "Server response body exceeds the maximum size of 9 bytes"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			body, err := readResponseBody(strings.NewReader(test.body), test.maxBytes)
+
+			assert.Equal(t, test.expectedBody, body)
+			assert.Equal(t, test.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package exchange
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+	"github.com/prebid/prebid-server/v2/util/ptrutil"
+	"github.com/prebid/prebid-server/v2/util/sliceutil"
+	"github.com/prebid/prebid-server/v2/util/timeutil"
+)
+
+const (
+	defaultBidderResponseCacheTTL        = time.Second
+	defaultBidderResponseCacheMaxEntries = 10000
+)
+
+// bidderResponseCache keeps the bidder responses of recent requests so they can be reused by identical
+// requests. It is safe for concurrent use.
+type bidderResponseCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	clock timeutil.Time
+
+	lock    sync.Mutex
+	entries map[string]bidderResponseCacheEntry
+}
+
+type bidderResponseCacheEntry struct {
+	expiresAt time.Time
+	calls     []cachedHttpCall
+}
+
+// cachedHttpCall is an http call made to a bidder along with the bidder response built from it
+type cachedHttpCall struct {
+	request     *adapters.RequestData
+	response    *adapters.ResponseData
+	bidResponse *adapters.BidderResponse
+}
+
+func newBidderResponseCache(cfg *config.ResponseCache) *bidderResponseCache {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	c := &bidderResponseCache{
+		ttl:        time.Duration(cfg.TTLMilliseconds) * time.Millisecond,
+		maxEntries: cfg.MaxEntries,
+		clock:      &timeutil.RealTime{},
+		entries:    make(map[string]bidderResponseCacheEntry),
+	}
+	if c.ttl <= 0 {
+		c.ttl = defaultBidderResponseCacheTTL
+	}
+	if c.maxEntries <= 0 {
+		c.maxEntries = defaultBidderResponseCacheMaxEntries
+	}
+	return c
+}
+
+// bidderResponseCacheKey returns the hash of the request normalized by dropping the fields which change
+// between otherwise identical requests, the transaction ids of the request and of its imps included.
+func bidderResponseCacheKey(req *openrtb2.BidRequest) (string, error) {
+	normalized := *req
+	normalized.ID = ""
+	normalized.TMax = 0
+	if req.Source != nil {
+		source := *req.Source
+		source.TID = ""
+		normalized.Source = &source
+	}
+	if len(req.Imp) > 0 {
+		normalized.Imp = make([]openrtb2.Imp, len(req.Imp))
+		for i, imp := range req.Imp {
+			if len(imp.Ext) > 0 {
+				// jsonparser deletes in place, the ext of the request must be left untouched
+				imp.Ext = jsonparser.Delete(append(json.RawMessage(nil), imp.Ext...), "tid")
+			}
+			normalized.Imp[i] = imp
+		}
+	}
+
+	body, err := jsonutil.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(body)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// get returns a copy of the http calls cached for the given key, if they did not expire yet.
+func (c *bidderResponseCache) get(key string) ([]cachedHttpCall, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.lock.Lock()
+	entry, ok := c.entries[key]
+	if ok && !c.clock.Now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.lock.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+	return copyCachedHttpCalls(entry.calls), true
+}
+
+// set caches the given http calls, which must not be updated afterwards. Calls are not cached when the
+// cache is full of entries which did not expire yet, or when their bids carry win or billing notice urls
+// as reusing them would notify the bidder of auctions it didn't take part in.
+func (c *bidderResponseCache) set(key string, calls []cachedHttpCall) {
+	if c == nil || hasNoticeURLs(calls) {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.clock.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+	c.entries[key] = bidderResponseCacheEntry{expiresAt: now.Add(c.ttl), calls: calls}
+}
+
+// copyCachedHttpCalls deep copies the bids of the calls as the exchange updates them in place.
+func copyCachedHttpCalls(calls []cachedHttpCall) []cachedHttpCall {
+	copied := make([]cachedHttpCall, 0, len(calls))
+	for _, call := range calls {
+		call.bidResponse = copyBidderResponse(call.bidResponse)
+		copied = append(copied, call)
+	}
+	return copied
+}
+
+func copyBidderResponse(bidResponse *adapters.BidderResponse) *adapters.BidderResponse {
+	if bidResponse == nil {
+		return nil
+	}
+
+	copied := *bidResponse
+	copied.Bids = make([]*adapters.TypedBid, 0, len(bidResponse.Bids))
+	for _, typedBid := range bidResponse.Bids {
+		if typedBid == nil {
+			continue
+		}
+		copiedTypedBid := *typedBid
+		copiedTypedBid.Bid = copyBid(typedBid.Bid)
+		copiedTypedBid.BidMeta = copyBidMeta(typedBid.BidMeta)
+		copiedTypedBid.BidVideo = ptrutil.Clone(typedBid.BidVideo)
+		copied.Bids = append(copied.Bids, &copiedTypedBid)
+	}
+	if bidResponse.FledgeAuctionConfigs != nil {
+		copied.FledgeAuctionConfigs = make([]*openrtb_ext.FledgeAuctionConfig, 0, len(bidResponse.FledgeAuctionConfigs))
+		for _, fledgeConfig := range bidResponse.FledgeAuctionConfigs {
+			if fledgeConfig == nil {
+				continue
+			}
+			copiedConfig := *fledgeConfig
+			copiedConfig.Config = sliceutil.Clone(fledgeConfig.Config)
+			copied.FledgeAuctionConfigs = append(copied.FledgeAuctionConfigs, &copiedConfig)
+		}
+	}
+	return &copied
+}
+
+func copyBid(bid *openrtb2.Bid) *openrtb2.Bid {
+	if bid == nil {
+		return nil
+	}
+
+	copied := *bid
+	copied.ADomain = sliceutil.Clone(bid.ADomain)
+	copied.Cat = sliceutil.Clone(bid.Cat)
+	copied.Attr = sliceutil.Clone(bid.Attr)
+	copied.APIs = sliceutil.Clone(bid.APIs)
+	copied.Ext = sliceutil.Clone(bid.Ext)
+	return &copied
+}
+
+func copyBidMeta(meta *openrtb_ext.ExtBidPrebidMeta) *openrtb_ext.ExtBidPrebidMeta {
+	if meta == nil {
+		return nil
+	}
+
+	copied := *meta
+	copied.AdvertiserDomains = sliceutil.Clone(meta.AdvertiserDomains)
+	copied.DChain = sliceutil.Clone(meta.DChain)
+	copied.RendererData = sliceutil.Clone(meta.RendererData)
+	copied.SecondaryCategoryIDs = sliceutil.Clone(meta.SecondaryCategoryIDs)
+	return &copied
+}
+
+func hasNoticeURLs(calls []cachedHttpCall) bool {
+	for _, call := range calls {
+		if call.bidResponse == nil {
+			continue
+		}
+		for _, typedBid := range call.bidResponse.Bids {
+			if typedBid != nil && typedBid.Bid != nil && (typedBid.Bid.NURL != "" || typedBid.Bid.BURL != "") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBidderResponseCache(t *testing.T) {
+	assert.Nil(t, newBidderResponseCache(nil))
+	assert.Nil(t, newBidderResponseCache(&config.ResponseCache{Enabled: false}))
+
+	c := newBidderResponseCache(&config.ResponseCache{Enabled: true})
+	require.NotNil(t, c)
+	assert.Equal(t, defaultBidderResponseCacheTTL, c.ttl)
+	assert.Equal(t, defaultBidderResponseCacheMaxEntries, c.maxEntries)
+
+	var nilCache *bidderResponseCache
+	nilCache.set("key", nil)
+	_, ok := nilCache.get("key")
+	assert.False(t, ok)
+}
+
+func TestBidderResponseCacheKey(t *testing.T) {
+	base := &openrtb2.BidRequest{
+		ID:     "req1",
+		TMax:   500,
+		Imp:    []openrtb2.Imp{{ID: "imp1"}},
+		Source: &openrtb2.Source{TID: "tid1"},
+		User:   &openrtb2.User{ID: "user1"},
+	}
+	baseKey, err := bidderResponseCacheKey(base)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		req           *openrtb2.BidRequest
+		expectSameKey bool
+	}{
+		{
+			name: "different-id-tmax-and-tid",
+			req: &openrtb2.BidRequest{
+				ID:     "req2",
+				TMax:   300,
+				Imp:    []openrtb2.Imp{{ID: "imp1"}},
+				Source: &openrtb2.Source{TID: "tid2"},
+				User:   &openrtb2.User{ID: "user1"},
+			},
+			expectSameKey: true,
+		},
+		{
+			name: "different-user",
+			req: &openrtb2.BidRequest{
+				ID:     "req1",
+				Imp:    []openrtb2.Imp{{ID: "imp1"}},
+				Source: &openrtb2.Source{TID: "tid1"},
+				User:   &openrtb2.User{ID: "user2"},
+			},
+			expectSameKey: false,
+		},
+		{
+			name: "different-imp",
+			req: &openrtb2.BidRequest{
+				ID:     "req1",
+				Imp:    []openrtb2.Imp{{ID: "imp2"}},
+				Source: &openrtb2.Source{TID: "tid1"},
+				User:   &openrtb2.User{ID: "user1"},
+			},
+			expectSameKey: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := bidderResponseCacheKey(test.req)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectSameKey, key == baseKey)
+		})
+	}
+
+	assert.Equal(t, "req1", base.ID, "request should not be modified")
+	assert.Equal(t, "tid1", base.Source.TID, "request should not be modified")
+}
+
+func TestBidderResponseCacheKeyIgnoresTransactionIDs(t *testing.T) {
+	newRequest := func(tid, impTID1, impTID2 string) *openrtb2.BidRequest {
+		return &openrtb2.BidRequest{
+			ID:     "req",
+			Source: &openrtb2.Source{TID: tid},
+			Imp: []openrtb2.Imp{
+				{ID: "imp1", Ext: json.RawMessage(`{"tid":"` + impTID1 + `","bidder":{"placementId":1}}`)},
+				{ID: "imp2", Ext: json.RawMessage(`{"bidder":{"placementId":2},"tid":"` + impTID2 + `"}`)},
+			},
+		}
+	}
+	first := newRequest("tid1", "imp-tid1", "imp-tid2")
+	second := newRequest("tid2", "imp-tid3", "imp-tid4")
+
+	firstKey, err := bidderResponseCacheKey(first)
+	require.NoError(t, err)
+	secondKey, err := bidderResponseCacheKey(second)
+	require.NoError(t, err)
+	assert.Equal(t, firstKey, secondKey, "requests differing only in their transaction ids should share a key")
+
+	third := newRequest("tid1", "imp-tid1", "imp-tid2")
+	third.Imp[1].Ext = json.RawMessage(`{"bidder":{"placementId":3},"tid":"imp-tid2"}`)
+	thirdKey, err := bidderResponseCacheKey(third)
+	require.NoError(t, err)
+	assert.NotEqual(t, firstKey, thirdKey, "the rest of the imp ext is part of the key")
+
+	assert.JSONEq(t, `{"tid":"imp-tid1","bidder":{"placementId":1}}`, string(first.Imp[0].Ext), "request should not be modified")
+	assert.JSONEq(t, `{"bidder":{"placementId":2},"tid":"imp-tid2"}`, string(first.Imp[1].Ext), "request should not be modified")
+}
+
+func TestBidderResponseCacheGetSet(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := newBidderResponseCache(&config.ResponseCache{Enabled: true, TTLMilliseconds: 1000, MaxEntries: 1})
+	c.clock = clock
+
+	calls := []cachedHttpCall{{
+		request:  &adapters.RequestData{Uri: "http://bidder.com"},
+		response: &adapters.ResponseData{StatusCode: 200},
+		bidResponse: &adapters.BidderResponse{Bids: []*adapters.TypedBid{{
+			Bid:     &openrtb2.Bid{ID: "bid1", Price: 1, ADomain: []string{"advertiser.com"}, Ext: json.RawMessage(`{}`)},
+			BidMeta: &openrtb_ext.ExtBidPrebidMeta{AdvertiserDomains: []string{"advertiser.com"}},
+		}}},
+	}}
+	c.set("key1", calls)
+
+	cached, ok := c.get("key1")
+	require.True(t, ok)
+	require.Len(t, cached, 1)
+	assert.Equal(t, calls[0].request, cached[0].request)
+	assert.Equal(t, calls[0].bidResponse, cached[0].bidResponse)
+
+	// bids returned by the cache can be updated without affecting the cached ones
+	cached[0].bidResponse.Bids[0].Bid.Price = 2
+	cached[0].bidResponse.Bids[0].Bid.ADomain[0] = "updated.com"
+	cached[0].bidResponse.Bids[0].Bid.Ext[0] = '['
+	cached[0].bidResponse.Bids[0].BidMeta.AdvertiserDomains[0] = "updated.com"
+	cached, _ = c.get("key1")
+	assert.Equal(t, 1.0, cached[0].bidResponse.Bids[0].Bid.Price)
+	assert.Equal(t, []string{"advertiser.com"}, cached[0].bidResponse.Bids[0].Bid.ADomain)
+	assert.Equal(t, json.RawMessage(`{}`), cached[0].bidResponse.Bids[0].Bid.Ext)
+	assert.Equal(t, []string{"advertiser.com"}, cached[0].bidResponse.Bids[0].BidMeta.AdvertiserDomains)
+
+	// the cache is full of entries which did not expire yet
+	c.set("key2", calls)
+	_, ok = c.get("key2")
+	assert.False(t, ok)
+
+	clock.now = clock.now.Add(time.Second)
+	_, ok = c.get("key1")
+	assert.False(t, ok, "expired entries should not be returned")
+
+	c.set("key2", calls)
+	_, ok = c.get("key2")
+	assert.True(t, ok)
+}
+
+func TestBidderResponseCacheSetWithNoticeURLs(t *testing.T) {
+	testCases := []struct {
+		name        string
+		bid         *openrtb2.Bid
+		expectCache bool
+	}{
+		{
+			name:        "no-notice-urls",
+			bid:         &openrtb2.Bid{ID: "bid1"},
+			expectCache: true,
+		},
+		{
+			name:        "nurl",
+			bid:         &openrtb2.Bid{ID: "bid1", NURL: "http://bidder.com/win"},
+			expectCache: false,
+		},
+		{
+			name:        "burl",
+			bid:         &openrtb2.Bid{ID: "bid1", BURL: "http://bidder.com/bill"},
+			expectCache: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			c := newBidderResponseCache(&config.ResponseCache{Enabled: true})
+			c.set("key", []cachedHttpCall{{bidResponse: &adapters.BidderResponse{Bids: []*adapters.TypedBid{{Bid: test.bid}}}}})
+
+			_, ok := c.get("key")
+			assert.Equal(t, test.expectCache, ok)
+		})
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/prebid/prebid-server/v2/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // TestSingleBidder makes sure that the following things work if the Bidder needs only one request.
@@ -411,6 +412,67 @@ func TestSetGPCHeader(t *testing.T) {
 	assert.ElementsMatch(t, seatBids[0].HttpCalls, expectedHttpCall)
 }
 
+// bodySigner signs requests with the body they are sent with
+type bodySigner struct{}
+
+func (s *bodySigner) Sign(req *http.Request, body []byte) error {
+	req.Header.Set("X-Signature", fmt.Sprintf("%x", body))
+	return nil
+}
+
+func TestDoRequestWithRequestSigner(t *testing.T) {
+	var receivedSignature string
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedSignature = r.Header.Get("X-Signature")
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	bidder := AdaptBidder(&goodSingleBidder{}, server.Client(), &config.Configuration{}, &metricsConfig.NilMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, Gzip).(*bidderAdapter)
+	bidder.requestSigner = &bodySigner{}
+	req := &adapters.RequestData{
+		Method:  http.MethodPost,
+		Uri:     server.URL,
+		Body:    []byte(`{"id":"req1"}`),
+		Headers: http.Header{"Content-Type": []string{"application/json"}},
+	}
+
+	httpInfo := bidder.doRequest(context.Background(), req, time.Now(), nil, adaptiveTimeout{}, bidder.Bidder)
+
+	assert.NoError(t, httpInfo.err)
+	assert.Equal(t, fmt.Sprintf("%x", receivedBody), receivedSignature, "the signature should cover the compressed body")
+	assert.Equal(t, "gzip", req.Headers.Get("Content-Encoding"))
+	assert.Empty(t, req.Headers.Get("X-Signature"), "the signature should not be exposed in the request data")
+}
+
+func TestDoRequestWithFailedRequestSigner(t *testing.T) {
+	var serverCalled bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverCalled = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := &config.Configuration{
+		BidderInfos: config.BidderInfos{
+			string(openrtb_ext.BidderAppnexus): {RequestSigning: &config.RequestSigning{Type: "rsa", KeyFile: "key"}},
+		},
+	}
+	bidder := AdaptBidder(&goodSingleBidder{}, server.Client(), cfg, &metricsConfig.NilMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "").(*bidderAdapter)
+	req := &adapters.RequestData{
+		Method: http.MethodPost,
+		Uri:    server.URL,
+		Body:   []byte(`{"id":"req1"}`),
+	}
+
+	httpInfo := bidder.doRequest(context.Background(), req, time.Now(), nil, adaptiveTimeout{}, bidder.Bidder)
+
+	assert.EqualError(t, httpInfo.err, "failed to build request signer: unknown request signer type: rsa")
+	assert.False(t, serverCalled, "the request should not be sent unsigned")
+}
+
 func TestSetGPCHeaderNil(t *testing.T) {
 	server := httptest.NewServer(mockHandler(200, "getBody", "responseJson"))
 	defer server.Close()
@@ -562,7 +624,7 @@ func TestBidderTimeout(t *testing.T) {
 	callInfo := bidder.doRequest(ctx, &adapters.RequestData{
 		Method: "POST",
 		Uri:    server.URL,
-	}, time.Now(), tmaxAdjustments)
+	}, time.Now(), tmaxAdjustments, adaptiveTimeout{}, bidder.Bidder)
 	if callInfo.err == nil {
 		t.Errorf("The bidder should report an error if the context has expired already.")
 	}
@@ -581,7 +643,7 @@ func TestInvalidRequest(t *testing.T) {
 	tmaxAdjustments := &TmaxAdjustmentsPreprocessed{}
 	callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{
 		Method: "\"", // force http.NewRequest() to fail
-	}, time.Now(), tmaxAdjustments)
+	}, time.Now(), tmaxAdjustments, adaptiveTimeout{}, bidder.Bidder)
 	if callInfo.err == nil {
 		t.Errorf("bidderAdapter.doRequest should return an error if the request data is malformed.")
 	}
@@ -605,7 +667,7 @@ func TestConnectionClose(t *testing.T) {
 	callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{
 		Method: "POST",
 		Uri:    server.URL,
-	}, time.Now(), tmaxAdjustments)
+	}, time.Now(), tmaxAdjustments, adaptiveTimeout{}, bidder.Bidder)
 	if callInfo.err == nil {
 		t.Errorf("bidderAdapter.doRequest should return an error if the connection closes unexpectedly.")
 	}
@@ -2153,7 +2215,7 @@ func TestCallRecordDNSTime(t *testing.T) {
 	tmaxAdjustments := &TmaxAdjustmentsPreprocessed{}
 
 	// Run test
-	bidder.doRequest(context.Background(), &adapters.RequestData{Method: "POST", Uri: "http://www.example.com/"}, time.Now(), tmaxAdjustments)
+	bidder.doRequest(context.Background(), &adapters.RequestData{Method: "POST", Uri: "http://www.example.com/"}, time.Now(), tmaxAdjustments, adaptiveTimeout{}, bidder.Bidder)
 
 	// Tried one or another, none seem to work without panicking
 	metricsMock.AssertExpectations(t)
@@ -2177,7 +2239,7 @@ func TestCallRecordTLSHandshakeTime(t *testing.T) {
 	tmaxAdjustments := &TmaxAdjustmentsPreprocessed{}
 
 	// Run test
-	bidder.doRequest(context.Background(), &adapters.RequestData{Method: "POST", Uri: "http://www.example.com/"}, time.Now(), tmaxAdjustments)
+	bidder.doRequest(context.Background(), &adapters.RequestData{Method: "POST", Uri: "http://www.example.com/"}, time.Now(), tmaxAdjustments, adaptiveTimeout{}, bidder.Bidder)
 
 	// Tried one or another, none seem to work without panicking
 	metricsMock.AssertExpectations(t)
@@ -2265,7 +2327,7 @@ func TestTimeoutNotificationOn(t *testing.T) {
 		loggerBuffer.WriteString(fmt.Sprintf(fmt.Sprintln(msg), args...))
 	}
 	tmaxAdjustments := &TmaxAdjustmentsPreprocessed{}
-	bidderAdapter.doRequestImpl(ctx, &bidRequest, logger, time.Now(), tmaxAdjustments)
+	bidderAdapter.doRequestImpl(ctx, &bidRequest, logger, time.Now(), tmaxAdjustments, adaptiveTimeout{}, bidderAdapter.Bidder)
 
 	// Wait a little longer than the 205ms mock server sleep.
 	time.Sleep(210 * time.Millisecond)
@@ -3288,7 +3350,7 @@ func TestDoRequestImplWithTmax(t *testing.T) {
 			defer cancelFn()
 		}
 
-		httpCallInfo := bidderAdapter.doRequestImpl(ctx, &bidRequest, logger, requestStartTime, test.tmaxAdjustments)
+		httpCallInfo := bidderAdapter.doRequestImpl(ctx, &bidRequest, logger, requestStartTime, test.tmaxAdjustments, adaptiveTimeout{}, bidderAdapter.Bidder)
 		test.assertFn(httpCallInfo.err)
 	}
 }
@@ -3342,7 +3404,7 @@ func TestDoRequestImplWithTmaxTimeout(t *testing.T) {
 			defer cancelFn()
 		}
 
-		httpCallInfo := bidderAdapter.doRequestImpl(ctx, &bidRequest, logger, requestStartTime, test.tmaxAdjustments)
+		httpCallInfo := bidderAdapter.doRequestImpl(ctx, &bidRequest, logger, requestStartTime, test.tmaxAdjustments, adaptiveTimeout{}, bidderAdapter.Bidder)
 		test.assertFn(httpCallInfo.err)
 	}
 }
@@ -3420,3 +3482,279 @@ func BenchmarkCompressToGZIPOptimized(b *testing.B) {
 		getRequestBody(req, "GZIP")
 	}
 }
+
+func TestRequestBidWithCircuitBreaker(t *testing.T) {
+	var serverCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverCalls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    server.URL,
+			Body:   []byte(`{"id":"req-id"}`),
+			ImpIDs: []string{"imp1"},
+		},
+	}
+	cfg := &config.Configuration{
+		BidderInfos: config.BidderInfos{
+			string(openrtb_ext.BidderAppnexus): config.BidderInfo{
+				CircuitBreaker: &config.CircuitBreaker{Enabled: true, MinRequests: 1},
+			},
+		},
+	}
+	bidder := AdaptBidder(bidderImpl, server.Client(), cfg, &metricsConfig.NilMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "")
+	bidderReq := BidderRequest{
+		BidRequest: &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp1"}, {ID: "imp2"}}},
+		BidderName: openrtb_ext.BidderAppnexus,
+	}
+	bidReqOptions := bidRequestOptions{bidAdjustments: map[string]float64{}}
+
+	// the first failed request opens the circuit
+	_, extraRespInfo, errs := bidder.requestBid(context.Background(), bidderReq, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidReqOptions, openrtb_ext.ExtAlternateBidderCodes{}, &hookexecution.EmptyHookExecutor{}, nil)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.BadServerResponse{}, errs[0])
+	}
+	assert.Empty(t, extraRespInfo.seatNonBids.seatNonBidsMap)
+
+	// the following request is short-circuited and reported as a non bid for the imps it was for
+	_, extraRespInfo, errs = bidder.requestBid(context.Background(), bidderReq, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidReqOptions, openrtb_ext.ExtAlternateBidderCodes{}, &hookexecution.EmptyHookExecutor{}, nil)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.BidderCircuitOpen{}, errs[0])
+	}
+	expectedNonBids := map[string][]openrtb_ext.NonBid{
+		"appnexus": {{ImpId: "imp1", StatusCode: int(openrtb_ext.ErrorBidderUnreachable)}},
+	}
+	assert.Equal(t, expectedNonBids, extraRespInfo.seatNonBids.seatNonBidsMap)
+	assert.Equal(t, 1, serverCalls)
+}
+
+func TestGetRequestImpIDs(t *testing.T) {
+	bidRequest := &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp1"}, {ID: "imp2"}}}
+
+	testCases := []struct {
+		name     string
+		req      *adapters.RequestData
+		expected []string
+	}{
+		{
+			name:     "request-imp-ids",
+			req:      &adapters.RequestData{ImpIDs: []string{"imp2"}},
+			expected: []string{"imp2"},
+		},
+		{
+			name:     "no-request-imp-ids",
+			req:      &adapters.RequestData{},
+			expected: []string{"imp1", "imp2"},
+		},
+		{
+			name:     "nil-request",
+			req:      nil,
+			expected: []string{"imp1", "imp2"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, getRequestImpIDs(test.req, bidRequest))
+		})
+	}
+}
+
+func TestDoRequestImplWithAdaptiveTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		description          string
+		timeout              adaptiveTimeout
+		expectTimeout        bool
+		expectLatencySamples int
+	}{
+		{
+			description:          "fast-bidder-keeps-full-budget",
+			timeout:              adaptiveTimeout{timeout: 2 * time.Second},
+			expectTimeout:        false,
+			expectLatencySamples: 1,
+		},
+		{
+			description:          "slow-bidder-is-cut",
+			timeout:              adaptiveTimeout{timeout: 100 * time.Millisecond, cut: true},
+			expectTimeout:        true,
+			expectLatencySamples: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			metricsMock := &metrics.MetricsEngineMock{}
+			metricsMock.On("RecordOverheadTime", metrics.PreBidder, mock.Anything)
+			metricsMock.On("RecordBidderServerResponseTime", mock.Anything)
+
+			tracker := newLatencyTracker(config.AdaptiveBidderTimeouts{Enabled: true, Percentile: 0.9, MinSamples: 1, WindowSeconds: 60, SlowBidderBudgetRatio: 0.1, MinTimeout: 10})
+
+			bidder := bidderAdapter{
+				me:             metricsMock,
+				Client:         server.Client(),
+				config:         bidderAdapterConfig{DisableConnMetrics: true},
+				latencyTracker: tracker,
+				circuitBreaker: newCircuitBreaker(&config.CircuitBreaker{Enabled: true, MinRequests: 1}, openrtb_ext.BidderAppnexus, &metricsConfig.NilMetricsEngine{}),
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			req := &adapters.RequestData{Method: "POST", Uri: server.URL}
+			httpInfo := bidder.doRequestImpl(ctx, req, func(msg string, args ...interface{}) {}, time.Now(), nil, test.timeout, bidder.Bidder)
+
+			if test.expectTimeout {
+				assert.IsType(t, &errortypes.Timeout{}, httpInfo.err)
+			} else {
+				assert.NoError(t, httpInfo.err)
+			}
+			assert.Equal(t, test.timeout.timeout, httpInfo.timeout)
+			assert.Equal(t, httpInfo.timeout.Milliseconds(), makeExt(httpInfo).TimeoutMS)
+			assert.Equal(t, test.expectLatencySamples, tracker.current.count, "latency samples")
+			assert.True(t, bidder.circuitBreaker.allow(server.URL), "a cut request isn't a bidder failure")
+		})
+	}
+}
+
+func TestDoRequestImplKeepsTimingOutBidderCut(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tracker := newLatencyTracker(config.AdaptiveBidderTimeouts{Enabled: true, Percentile: 0.9, MinSamples: 3, WindowSeconds: 60, SlowBidderBudgetRatio: 0.5, MinTimeout: 10})
+	tracker.clock = clock
+	tracker.windowStart = clock.now
+	for _, latency := range repeatLatency(150*time.Millisecond, 3) {
+		tracker.record(latency)
+	}
+	bidder := bidderAdapter{
+		me:             &metricsConfig.NilMetricsEngine{},
+		Client:         server.Client(),
+		config:         bidderAdapterConfig{DisableConnMetrics: true},
+		latencyTracker: tracker,
+	}
+
+	// the samples of the slow responses are rotated out after two windows, only the cut requests are left
+	for window := 0; window < 3; window++ {
+		clock.now = clock.now.Add(61 * time.Second)
+		for i := 0; i < 3; i++ {
+			timeout, cut := tracker.timeout(200 * time.Millisecond)
+			require.True(t, cut, "window %d, request %d", window, i)
+
+			req := &adapters.RequestData{Method: "POST", Uri: server.URL}
+			httpInfo := bidder.doRequestImpl(context.Background(), req, func(msg string, args ...interface{}) {}, time.Now(), nil, adaptiveTimeout{timeout: timeout, cut: cut}, bidder.Bidder)
+			require.IsType(t, &errortypes.Timeout{}, httpInfo.err)
+		}
+	}
+	assert.Equal(t, 3, tracker.current.buckets[latencyBucketCount], "cut requests are counted above their timeout")
+	assert.Equal(t, 3, tracker.previous.buckets[latencyBucketCount], "the slow responses have been rotated out")
+}
+
+func TestRequestBidWithAdaptiveTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tracker := newLatencyTracker(config.AdaptiveBidderTimeouts{Enabled: true, Percentile: 0.9, MinSamples: 1, WindowSeconds: 60, SlowBidderBudgetRatio: 0.1, MinTimeout: 10})
+	tracker.record(900 * time.Millisecond)
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{Method: "POST", Uri: server.URL},
+	}
+	bidder := AdaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.NilMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "").(*bidderAdapter)
+	bidder.latencyTracker = tracker
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	bidderReq := BidderRequest{
+		BidRequest: &openrtb2.BidRequest{ID: "request-id", TMax: 2000, Imp: []openrtb2.Imp{{ID: "imp-id"}}},
+		BidderName: openrtb_ext.BidderAppnexus,
+	}
+	bidReqOptions := bidRequestOptions{bidderRequestStartTime: time.Now()}
+	_, _, errs := bidder.requestBid(ctx, bidderReq, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidReqOptions, openrtb_ext.ExtAlternateBidderCodes{}, &hookexecution.EmptyHookExecutor{}, nil)
+
+	assert.Empty(t, errs)
+	assert.Greater(t, bidderImpl.bidRequest.TMax, int64(0))
+	assert.LessOrEqual(t, bidderImpl.bidRequest.TMax, int64(200), "tmax is cut to the adaptive timeout")
+}
+
+func TestRequestBidWithResponseCache(t *testing.T) {
+	var serverCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverCalls++
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id":"resp-id"}`))
+	}))
+	defer server.Close()
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    server.URL,
+			Body:   []byte(`{"id":"req-id"}`),
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{{Bid: &openrtb2.Bid{ID: "bid1", ImpID: "imp1", Price: 1}, BidType: openrtb_ext.BidTypeBanner}},
+		},
+	}
+	cfg := &config.Configuration{
+		BidderInfos: config.BidderInfos{
+			string(openrtb_ext.BidderAppnexus): config.BidderInfo{
+				ResponseCache: &config.ResponseCache{Enabled: true},
+			},
+		},
+	}
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordAdapterBidResponseCacheResult", openrtb_ext.BidderAppnexus, metrics.CacheMiss).Return().Once()
+	me.On("RecordAdapterBidResponseCacheResult", openrtb_ext.BidderAppnexus, metrics.CacheHit).Return().Once()
+	me.On("RecordOverheadTime", mock.Anything, mock.Anything).Return()
+	me.On("RecordBidderServerResponseTime", mock.Anything).Return()
+	me.On("RecordAdapterConnections", mock.Anything, mock.Anything, mock.Anything).Return()
+	me.On("RecordDNSTime", mock.Anything).Return().Maybe()
+	me.On("RecordTLSHandshakeTime", mock.Anything).Return().Maybe()
+	bidder := AdaptBidder(bidderImpl, server.Client(), cfg, me, openrtb_ext.BidderAppnexus, nil, "")
+	bidReqOptions := bidRequestOptions{
+		headerDebugAllowed: true,
+		bidAdjustments:     map[string]float64{"appnexus": 2},
+	}
+
+	makeBidderRequest := func(id string) BidderRequest {
+		return BidderRequest{
+			BidRequest: &openrtb2.BidRequest{ID: id, Imp: []openrtb2.Imp{{ID: "imp1"}}},
+			BidderName: openrtb_ext.BidderAppnexus,
+		}
+	}
+
+	seatBids, _, errs := bidder.requestBid(context.Background(), makeBidderRequest("req1"), currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidReqOptions, openrtb_ext.ExtAlternateBidderCodes{}, &hookexecution.EmptyHookExecutor{}, nil)
+	assert.Empty(t, errs)
+	if assert.Len(t, seatBids, 1) && assert.Len(t, seatBids[0].Bids, 1) && assert.Len(t, seatBids[0].HttpCalls, 1) {
+		assert.Equal(t, 2.0, seatBids[0].Bids[0].Bid.Price)
+		assert.False(t, seatBids[0].HttpCalls[0].Cached)
+	}
+
+	// an identical request with a different id reuses the bids of the first one
+	seatBids, _, errs = bidder.requestBid(context.Background(), makeBidderRequest("req2"), currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, &adscert.NilSigner{}, bidReqOptions, openrtb_ext.ExtAlternateBidderCodes{}, &hookexecution.EmptyHookExecutor{}, nil)
+	assert.Empty(t, errs)
+	if assert.Len(t, seatBids, 1) && assert.Len(t, seatBids[0].Bids, 1) && assert.Len(t, seatBids[0].HttpCalls, 1) {
+		assert.Equal(t, 2.0, seatBids[0].Bids[0].Bid.Price, "cached bids should not be adjusted twice")
+		assert.True(t, seatBids[0].HttpCalls[0].Cached)
+		assert.Equal(t, `{"id":"resp-id"}`, seatBids[0].HttpCalls[0].ResponseBody)
+	}
+	assert.Equal(t, 1, serverCalls)
+	me.AssertExpectations(t)
+}
@@ -0,0 +1,211 @@
+package exchange
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/metrics"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/timeutil"
+)
+
+const (
+	defaultCircuitBreakerWindow           = 10 * time.Second
+	defaultCircuitBreakerMinRequests      = 20
+	defaultCircuitBreakerErrorRate        = 0.5
+	defaultCircuitBreakerOpenDuration     = 30 * time.Second
+	defaultCircuitBreakerHalfOpenRequests = 3
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker short-circuits requests to a bidder, or to one of its endpoint hosts, after too many
+// of them failed. It is safe for concurrent use.
+type circuitBreaker struct {
+	bidderName       openrtb_ext.BidderName
+	perHost          bool
+	window           time.Duration
+	minRequests      int
+	errorRate        float64
+	openDuration     time.Duration
+	halfOpenRequests int
+
+	me    metrics.MetricsEngine
+	clock timeutil.Time
+
+	lock     sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state       circuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	// probes is the number of requests let through while half-open, succeeded the number of them which succeeded
+	probes    int
+	succeeded int
+}
+
+func newCircuitBreaker(cfg *config.CircuitBreaker, bidderName openrtb_ext.BidderName, me metrics.MetricsEngine) *circuitBreaker {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	cb := &circuitBreaker{
+		bidderName:       bidderName,
+		perHost:          cfg.PerHost,
+		window:           time.Duration(cfg.WindowSeconds) * time.Second,
+		minRequests:      cfg.MinRequests,
+		errorRate:        cfg.ErrorRate,
+		openDuration:     time.Duration(cfg.OpenSeconds) * time.Second,
+		halfOpenRequests: cfg.HalfOpenRequests,
+		me:               me,
+		clock:            &timeutil.RealTime{},
+		circuits:         make(map[string]*circuit),
+	}
+	if cb.window <= 0 {
+		cb.window = defaultCircuitBreakerWindow
+	}
+	if cb.minRequests <= 0 {
+		cb.minRequests = defaultCircuitBreakerMinRequests
+	}
+	if cb.errorRate <= 0 {
+		cb.errorRate = defaultCircuitBreakerErrorRate
+	}
+	if cb.openDuration <= 0 {
+		cb.openDuration = defaultCircuitBreakerOpenDuration
+	}
+	if cb.halfOpenRequests <= 0 {
+		cb.halfOpenRequests = defaultCircuitBreakerHalfOpenRequests
+	}
+	return cb
+}
+
+// circuitKey returns the key of the circuit tracking requests to the given uri.
+func (cb *circuitBreaker) circuitKey(uri string) string {
+	if !cb.perHost {
+		return ""
+	}
+	if u, err := url.Parse(uri); err == nil {
+		return u.Host
+	}
+	return uri
+}
+
+// allow reports whether a request may be sent to the given uri. Every allowed request must be followed
+// by a call to done once its outcome is known, or to release if it has none. A nil circuit breaker allows
+// every request.
+func (cb *circuitBreaker) allow(uri string) bool {
+	if cb == nil {
+		return true
+	}
+
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	c := cb.getCircuit(cb.circuitKey(uri))
+	now := cb.clock.Now()
+
+	if c.state == circuitOpen {
+		if now.Sub(c.openedAt) < cb.openDuration {
+			cb.me.RecordAdapterCircuitBreakerRejected(cb.bidderName)
+			return false
+		}
+		c.state = circuitHalfOpen
+		c.probes = 0
+		c.succeeded = 0
+	}
+
+	if c.state == circuitHalfOpen {
+		if c.probes >= cb.halfOpenRequests {
+			cb.me.RecordAdapterCircuitBreakerRejected(cb.bidderName)
+			return false
+		}
+		c.probes++
+	}
+	return true
+}
+
+// done records the outcome of a request to the given uri, opening or closing its circuit as needed.
+func (cb *circuitBreaker) done(uri string, failed bool) {
+	if cb == nil {
+		return
+	}
+
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	c := cb.getCircuit(cb.circuitKey(uri))
+	now := cb.clock.Now()
+
+	switch c.state {
+	case circuitClosed:
+		if now.Sub(c.windowStart) >= cb.window {
+			c.resetWindow(now)
+		}
+		c.requests++
+		if failed {
+			c.failures++
+		}
+		if c.requests >= cb.minRequests && float64(c.failures)/float64(c.requests) >= cb.errorRate {
+			cb.open(c, now)
+		}
+	case circuitHalfOpen:
+		if failed {
+			cb.open(c, now)
+			return
+		}
+		c.succeeded++
+		if c.succeeded >= cb.halfOpenRequests {
+			c.state = circuitClosed
+			c.resetWindow(now)
+		}
+	}
+}
+
+// release ends a request to the given uri without recording an outcome, e.g. when the auction was
+// canceled before the bidder could answer. It frees the probe slot the request took while half-open.
+func (cb *circuitBreaker) release(uri string) {
+	if cb == nil {
+		return
+	}
+
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	c := cb.getCircuit(cb.circuitKey(uri))
+	if c.state == circuitHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+}
+
+func (cb *circuitBreaker) open(c *circuit, now time.Time) {
+	c.state = circuitOpen
+	c.openedAt = now
+	cb.me.RecordAdapterCircuitBreakerOpened(cb.bidderName)
+}
+
+func (cb *circuitBreaker) getCircuit(key string) *circuit {
+	c, ok := cb.circuits[key]
+	if !ok {
+		c = &circuit{windowStart: cb.clock.Now()}
+		cb.circuits[key] = c
+	}
+	return c
+}
+
+func (c *circuit) resetWindow(now time.Time) {
+	c.windowStart = now
+	c.requests = 0
+	c.failures = 0
+}
@@ -0,0 +1,195 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/metrics"
+	metricsConfig "github.com/prebid/prebid-server/v2/metrics/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestNewCircuitBreaker(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      *config.CircuitBreaker
+		expected *circuitBreaker
+	}{
+		{
+			name:     "nil",
+			cfg:      nil,
+			expected: nil,
+		},
+		{
+			name:     "disabled",
+			cfg:      &config.CircuitBreaker{Enabled: false, MinRequests: 5},
+			expected: nil,
+		},
+		{
+			name: "defaults",
+			cfg:  &config.CircuitBreaker{Enabled: true},
+			expected: &circuitBreaker{
+				bidderName:       openrtb_ext.BidderAppnexus,
+				window:           defaultCircuitBreakerWindow,
+				minRequests:      defaultCircuitBreakerMinRequests,
+				errorRate:        defaultCircuitBreakerErrorRate,
+				openDuration:     defaultCircuitBreakerOpenDuration,
+				halfOpenRequests: defaultCircuitBreakerHalfOpenRequests,
+			},
+		},
+		{
+			name: "configured",
+			cfg:  &config.CircuitBreaker{Enabled: true, PerHost: true, WindowSeconds: 5, MinRequests: 10, ErrorRate: 0.8, OpenSeconds: 60, HalfOpenRequests: 1},
+			expected: &circuitBreaker{
+				bidderName:       openrtb_ext.BidderAppnexus,
+				perHost:          true,
+				window:           5 * time.Second,
+				minRequests:      10,
+				errorRate:        0.8,
+				openDuration:     60 * time.Second,
+				halfOpenRequests: 1,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cb := newCircuitBreaker(test.cfg, openrtb_ext.BidderAppnexus, &metricsConfig.NilMetricsEngine{})
+			if test.expected == nil {
+				assert.Nil(t, cb)
+				return
+			}
+			assert.Equal(t, test.expected.bidderName, cb.bidderName)
+			assert.Equal(t, test.expected.perHost, cb.perHost)
+			assert.Equal(t, test.expected.window, cb.window)
+			assert.Equal(t, test.expected.minRequests, cb.minRequests)
+			assert.Equal(t, test.expected.errorRate, cb.errorRate)
+			assert.Equal(t, test.expected.openDuration, cb.openDuration)
+			assert.Equal(t, test.expected.halfOpenRequests, cb.halfOpenRequests)
+		})
+	}
+}
+
+func TestCircuitBreakerNil(t *testing.T) {
+	var cb *circuitBreaker
+	assert.True(t, cb.allow("http://bidder.com"))
+	cb.done("http://bidder.com", true)
+	cb.release("http://bidder.com")
+}
+
+func TestCircuitBreakerRelease(t *testing.T) {
+	const uri = "http://bidder.com/openrtb"
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cb := newCircuitBreaker(&config.CircuitBreaker{Enabled: true, MinRequests: 1, OpenSeconds: 30, HalfOpenRequests: 1}, openrtb_ext.BidderAppnexus, &metricsConfig.NilMetricsEngine{})
+	cb.clock = clock
+
+	// released requests aren't counted while closed
+	assert.True(t, cb.allow(uri))
+	cb.release(uri)
+	assert.True(t, cb.allow(uri), "closed after released request")
+
+	cb.done(uri, true)
+	assert.False(t, cb.allow(uri), "open after failure")
+
+	// a released probe frees its slot without closing the circuit
+	clock.now = clock.now.Add(30 * time.Second)
+	assert.True(t, cb.allow(uri), "probe")
+	assert.False(t, cb.allow(uri), "probes exhausted")
+	cb.release(uri)
+	assert.True(t, cb.allow(uri), "probe slot released")
+	assert.False(t, cb.allow(uri), "still half-open")
+}
+
+func TestCircuitBreakerStateTransitions(t *testing.T) {
+	const uri = "http://bidder.com/openrtb"
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterCircuitBreakerOpened", openrtb_ext.BidderAppnexus).Return()
+	metricsMock.On("RecordAdapterCircuitBreakerRejected", openrtb_ext.BidderAppnexus).Return()
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cb := newCircuitBreaker(&config.CircuitBreaker{Enabled: true, WindowSeconds: 10, MinRequests: 4, ErrorRate: 0.5, OpenSeconds: 30, HalfOpenRequests: 2}, openrtb_ext.BidderAppnexus, metricsMock)
+	cb.clock = clock
+
+	// below the minimum number of requests the circuit stays closed
+	for i := 0; i < 3; i++ {
+		assert.True(t, cb.allow(uri))
+		cb.done(uri, true)
+	}
+	assert.True(t, cb.allow(uri), "closed with too few requests")
+
+	// failures of an expired window are forgotten
+	clock.now = clock.now.Add(11 * time.Second)
+	cb.done(uri, false)
+	assert.True(t, cb.allow(uri), "closed after window reset")
+
+	cb.done(uri, true)
+	cb.done(uri, false)
+	cb.done(uri, true)
+	assert.False(t, cb.allow(uri), "open at error rate")
+	metricsMock.AssertNumberOfCalls(t, "RecordAdapterCircuitBreakerOpened", 1)
+	metricsMock.AssertNumberOfCalls(t, "RecordAdapterCircuitBreakerRejected", 1)
+
+	// after the open duration a limited number of probes is let through
+	clock.now = clock.now.Add(30 * time.Second)
+	assert.True(t, cb.allow(uri), "first probe")
+	assert.True(t, cb.allow(uri), "second probe")
+	assert.False(t, cb.allow(uri), "probes exhausted")
+
+	// a failed probe opens the circuit again
+	cb.done(uri, true)
+	assert.False(t, cb.allow(uri), "reopened by failed probe")
+	metricsMock.AssertNumberOfCalls(t, "RecordAdapterCircuitBreakerOpened", 2)
+
+	// successful probes close the circuit
+	clock.now = clock.now.Add(30 * time.Second)
+	assert.True(t, cb.allow(uri))
+	assert.True(t, cb.allow(uri))
+	cb.done(uri, false)
+	cb.done(uri, false)
+	for i := 0; i < 5; i++ {
+		assert.True(t, cb.allow(uri), "closed by successful probes")
+	}
+}
+
+func TestCircuitBreakerPerHost(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		perHost              bool
+		expectOtherHostAllow bool
+	}{
+		{
+			name:                 "per-bidder",
+			perHost:              false,
+			expectOtherHostAllow: false,
+		},
+		{
+			name:                 "per-host",
+			perHost:              true,
+			expectOtherHostAllow: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cb := newCircuitBreaker(&config.CircuitBreaker{Enabled: true, PerHost: test.perHost, MinRequests: 1}, openrtb_ext.BidderAppnexus, &metricsConfig.NilMetricsEngine{})
+
+			assert.True(t, cb.allow("http://us.bidder.com/openrtb"))
+			cb.done("http://us.bidder.com/openrtb", true)
+
+			assert.False(t, cb.allow("http://us.bidder.com/openrtb"))
+			assert.Equal(t, test.expectOtherHostAllow, cb.allow("http://eu.bidder.com/openrtb"))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package exchange
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/currency"
+)
+
+// negotiateCurrency rewrites the bid floors and currencies of a bidder request to currencies the bidder accepts.
+// The first auction currency accepted by the bidder is kept, otherwise the first currency accepted by the bidder
+// is used. Floors which are not in an accepted currency are converted to it.
+func negotiateCurrency(bidderRequest *BidderRequest, accepted []string, conversions currency.Conversions) error {
+	if len(accepted) == 0 || conversions == nil {
+		return nil
+	}
+
+	defaultCurrency := "USD"
+	req := bidderRequest.BidRequest
+	auctionCur := req.Cur
+	if len(auctionCur) == 0 {
+		auctionCur = []string{defaultCurrency}
+	}
+
+	target := ""
+	for _, cur := range auctionCur {
+		if acceptsCurrency(accepted, cur) {
+			target = cur
+			break
+		}
+	}
+	if target == "" {
+		target = strings.ToUpper(accepted[0])
+	}
+
+	imps := make([]openrtb2.Imp, len(req.Imp))
+	copy(imps, req.Imp)
+	for i := range imps {
+		imp := &imps[i]
+		if imp.BidFloor > 0 {
+			floor, floorCur, err := convertFloor(imp.BidFloor, imp.BidFloorCur, target, accepted, conversions)
+			if err != nil {
+				return fmt.Errorf("unable to convert the bid floor of imp %s for bidder %s: %v", imp.ID, bidderRequest.BidderName, err)
+			}
+			imp.BidFloor, imp.BidFloorCur = floor, floorCur
+		}
+
+		if imp.PMP == nil || len(imp.PMP.Deals) == 0 {
+			continue
+		}
+		pmp := *imp.PMP
+		pmp.Deals = make([]openrtb2.Deal, len(imp.PMP.Deals))
+		copy(pmp.Deals, imp.PMP.Deals)
+		for j := range pmp.Deals {
+			deal := &pmp.Deals[j]
+			if deal.BidFloor <= 0 {
+				continue
+			}
+			floor, floorCur, err := convertFloor(deal.BidFloor, deal.BidFloorCur, target, accepted, conversions)
+			if err != nil {
+				return fmt.Errorf("unable to convert the floor of deal %s for bidder %s: %v", deal.ID, bidderRequest.BidderName, err)
+			}
+			deal.BidFloor, deal.BidFloorCur = floor, floorCur
+		}
+		imp.PMP = &pmp
+	}
+	req.Imp = imps
+
+	if !strings.EqualFold(target, auctionCur[0]) {
+		// bids are converted back to the auction currencies
+		bidderRequest.auctionCur = auctionCur
+		req.Cur = []string{target}
+	}
+	return nil
+}
+
+// convertFloor converts a floor to the target currency unless it's already in an accepted currency.
+func convertFloor(floor float64, floorCur, target string, accepted []string, conversions currency.Conversions) (float64, string, error) {
+	if floorCur == "" {
+		floorCur = "USD"
+	}
+	if acceptsCurrency(accepted, floorCur) {
+		return floor, floorCur, nil
+	}
+
+	rate, err := conversions.GetRate(floorCur, target)
+	if err != nil {
+		return 0, "", err
+	}
+	return math.Round(floor*rate*10000) / 10000, target, nil
+}
+
+func acceptsCurrency(accepted []string, cur string) bool {
+	for _, acceptedCur := range accepted {
+		if strings.EqualFold(acceptedCur, cur) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/currency"
+	"github.com/prebid/prebid-server/v2/experiment/adscert"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	metricsConfig "github.com/prebid/prebid-server/v2/metrics/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateCurrency(t *testing.T) {
+	conversions := currency.NewRates(map[string]map[string]float64{
+		"USD": {"EUR": 0.9, "JPY": 150},
+	})
+
+	testCases := []struct {
+		description        string
+		accepted           []string
+		req                *openrtb2.BidRequest
+		expectedReq        *openrtb2.BidRequest
+		expectedAuctionCur []string
+		expectedErr        string
+	}{
+		{
+			description: "no_accepted_currencies",
+			req:         &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp1", BidFloor: 1, BidFloorCur: "USD"}}},
+			expectedReq: &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp1", BidFloor: 1, BidFloorCur: "USD"}}},
+		},
+		{
+			description: "auction_currency_accepted",
+			accepted:    []string{"eur", "usd"},
+			req:         &openrtb2.BidRequest{Cur: []string{"USD"}, Imp: []openrtb2.Imp{{ID: "imp1", BidFloor: 1, BidFloorCur: "USD"}}},
+			expectedReq: &openrtb2.BidRequest{Cur: []string{"USD"}, Imp: []openrtb2.Imp{{ID: "imp1", BidFloor: 1, BidFloorCur: "USD"}}},
+		},
+		{
+			description: "floors_and_currency_converted",
+			accepted:    []string{"EUR"},
+			req: &openrtb2.BidRequest{
+				Imp: []openrtb2.Imp{
+					{ID: "imp1", BidFloor: 2, PMP: &openrtb2.PMP{Deals: []openrtb2.Deal{{ID: "deal1", BidFloor: 5, BidFloorCur: "USD"}, {ID: "deal2"}}}},
+					{ID: "imp2", BidFloor: 3, BidFloorCur: "EUR"},
+					{ID: "imp3"},
+				},
+			},
+			expectedReq: &openrtb2.BidRequest{
+				Cur: []string{"EUR"},
+				Imp: []openrtb2.Imp{
+					{ID: "imp1", BidFloor: 1.8, BidFloorCur: "EUR", PMP: &openrtb2.PMP{Deals: []openrtb2.Deal{{ID: "deal1", BidFloor: 4.5, BidFloorCur: "EUR"}, {ID: "deal2"}}}},
+					{ID: "imp2", BidFloor: 3, BidFloorCur: "EUR"},
+					{ID: "imp3"},
+				},
+			},
+			expectedAuctionCur: []string{"USD"},
+		},
+		{
+			description:        "second_auction_currency_accepted",
+			accepted:           []string{"JPY", "EUR"},
+			req:                &openrtb2.BidRequest{Cur: []string{"USD", "EUR"}, Imp: []openrtb2.Imp{{ID: "imp1", BidFloor: 1, BidFloorCur: "USD"}}},
+			expectedReq:        &openrtb2.BidRequest{Cur: []string{"EUR"}, Imp: []openrtb2.Imp{{ID: "imp1", BidFloor: 0.9, BidFloorCur: "EUR"}}},
+			expectedAuctionCur: []string{"USD", "EUR"},
+		},
+		{
+			description: "missing_rate",
+			accepted:    []string{"GBP"},
+			req:         &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp1", BidFloor: 1}}},
+			expectedReq: &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp1", BidFloor: 1}}},
+			expectedErr: "unable to convert the bid floor of imp imp1 for bidder appnexus: Currency conversion rate not found: 'USD' => 'GBP'",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			original := *test.req
+			bidderRequest := &BidderRequest{BidRequest: test.req, BidderName: openrtb_ext.BidderAppnexus}
+
+			err := negotiateCurrency(bidderRequest, test.accepted, conversions)
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedReq, bidderRequest.BidRequest)
+			assert.Equal(t, test.expectedAuctionCur, bidderRequest.auctionCur)
+			if len(original.Imp) > 0 && original.Imp[0].PMP != nil {
+				assert.Equal(t, 5.0, original.Imp[0].PMP.Deals[0].BidFloor, "deals of the auction request must not be updated")
+			}
+		})
+	}
+}
+
+func TestRequestBidWithNegotiatedCurrency(t *testing.T) {
+	server := httptest.NewServer(mockHandler(http.StatusOK, "getBody", "responseJson"))
+	defer server.Close()
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte("requestJson"),
+			Headers: http.Header{},
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids:     []*adapters.TypedBid{{Bid: &openrtb2.Bid{ID: "bid1", ImpID: "imp1", Price: 1.8}, BidType: openrtb_ext.BidTypeBanner}},
+			Currency: "EUR",
+		},
+	}
+	conversions := currency.NewRates(map[string]map[string]float64{
+		"USD": {"EUR": 0.9},
+	})
+
+	bidderRequest := BidderRequest{
+		BidRequest: &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp1", BidFloor: 2}}},
+		BidderName: openrtb_ext.BidderAppnexus,
+	}
+	require.NoError(t, negotiateCurrency(&bidderRequest, []string{"EUR"}, conversions))
+
+	bidder := AdaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.NilMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, "")
+	seatBids, _, errs := bidder.requestBid(
+		context.Background(),
+		bidderRequest,
+		conversions,
+		&adapters.ExtraRequestInfo{},
+		&adscert.NilSigner{},
+		bidRequestOptions{bidAdjustments: map[string]float64{}},
+		openrtb_ext.ExtAlternateBidderCodes{},
+		&hookexecution.EmptyHookExecutor{},
+		nil,
+	)
+
+	assert.Empty(t, errs)
+	require.Len(t, seatBids, 1)
+	assert.Equal(t, "USD", seatBids[0].Currency)
+	require.Len(t, seatBids[0].Bids, 1)
+	bid := seatBids[0].Bids[0]
+	assert.InDelta(t, 2.0, bid.Bid.Price, 0.0001)
+	assert.Equal(t, "EUR", bidderImpl.bidRequest.Cur[0])
+	if assert.NotNil(t, bid.BidCurrency) {
+		assert.Equal(t, "EUR", bid.BidCurrency.BidderCurrency)
+		assert.Equal(t, 1.8, bid.BidCurrency.BidderPrice)
+		assert.InDelta(t, 1/0.9, bid.BidCurrency.Rate, 0.0001)
+	}
+}
@@ -0,0 +1,136 @@
+package exchange
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+// earlyReturn decides whether the auction can return before all bidders responded, based on the responses
+// received so far. It is only used by the goroutine collecting the bidder responses.
+type earlyReturn struct {
+	pendingDealBidders map[openrtb_ext.BidderName]struct{}
+	hasDealBidders     bool
+	targetCPM          float64
+	impsBelowTarget    map[string]struct{}
+	// cancel releases the context of the bidders once all of them responded
+	cancel context.CancelFunc
+}
+
+// newEarlyReturn returns the early return tracker of the auction, or nil if the auction must wait for all bidders.
+func newEarlyReturn(cfg config.AccountAuctionEarlyReturn, bidderRequests []BidderRequest) *earlyReturn {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	er := &earlyReturn{
+		pendingDealBidders: make(map[openrtb_ext.BidderName]struct{}),
+		targetCPM:          cfg.TargetCPM,
+		impsBelowTarget:    make(map[string]struct{}),
+		cancel:             func() {},
+	}
+	for _, bidderRequest := range bidderRequests {
+		// shadow bidders don't compete, the auction neither waits for them nor returns on their bids
+		if bidderRequest.shadow {
+			continue
+		}
+		for _, dealBidder := range cfg.DealBidders {
+			if strings.EqualFold(dealBidder, bidderRequest.BidderName.String()) {
+				er.pendingDealBidders[bidderRequest.BidderName] = struct{}{}
+			}
+		}
+		if er.targetCPM > 0 {
+			for _, imp := range bidderRequest.BidRequest.Imp {
+				er.impsBelowTarget[imp.ID] = struct{}{}
+			}
+		}
+	}
+	er.hasDealBidders = len(er.pendingDealBidders) > 0
+
+	if !er.hasDealBidders && len(er.impsBelowTarget) == 0 {
+		return nil
+	}
+	return er
+}
+
+// update records the response of a bidder and reports whether the auction can return.
+func (er *earlyReturn) update(brw *bidResponseWrapper) bool {
+	if er == nil {
+		return false
+	}
+
+	delete(er.pendingDealBidders, brw.bidder)
+	for _, seatBid := range brw.adapterSeatBids {
+		if seatBid == nil {
+			continue
+		}
+		for _, bid := range seatBid.Bids {
+			if bid.Bid != nil && bid.Bid.Price >= er.targetCPM {
+				delete(er.impsBelowTarget, bid.Bid.ImpID)
+			}
+		}
+	}
+
+	dealBiddersResponded := er.hasDealBidders && len(er.pendingDealBidders) == 0
+	targetCPMReached := er.targetCPM > 0 && len(er.impsBelowTarget) == 0
+	return dealBiddersResponded || targetCPMReached
+}
+
+// collectLateBids gathers the responses of the bidders which did not respond before the auction returned.
+func (er *earlyReturn) collectLateBids(chBids <-chan *bidResponseWrapper, pending int) *LateBids {
+	lateBids := &LateBids{done: make(chan struct{})}
+	go func() {
+		defer close(lateBids.done)
+		defer er.cancel()
+		for i := 0; i < pending; i++ {
+			brw := <-chBids
+			lateBids.seatBids = appendSeatBids(lateBids.seatBids, brw.adapterSeatBids)
+		}
+	}()
+	return lateBids
+}
+
+// stop releases the context of the bidders when the auction did not return early.
+func (er *earlyReturn) stop() {
+	if er != nil {
+		er.cancel()
+	}
+}
+
+// LateBids holds the bids of the bidders which responded after the auction returned early.
+type LateBids struct {
+	done     chan struct{}
+	seatBids []openrtb2.SeatBid
+}
+
+// Wait blocks until all late bidders responded and returns their bids. A nil LateBids has no bids.
+func (lb *LateBids) Wait() []openrtb2.SeatBid {
+	if lb == nil {
+		return nil
+	}
+	<-lb.done
+	return lb.seatBids
+}
+
+// detachedContext carries the values of its parent but not its cancellation, so that bidders can keep
+// bidding in the background once the auction returned.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+func (c detachedContext) Value(key any) any         { return c.parent.Value(key) }
+
+// withoutCancel returns a context with the values and deadline of ctx which is not canceled along with it.
+func withoutCancel(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detachedContext{parent: ctx}, deadline)
+	}
+	return context.WithCancel(detachedContext{parent: ctx})
+}
@@ -0,0 +1,237 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/currency"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/experiment/adscert"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	metricsConf "github.com/prebid/prebid-server/v2/metrics/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEarlyReturnBidderRequest(bidder openrtb_ext.BidderName, impIDs ...string) BidderRequest {
+	req := BidderRequest{
+		BidderName:     bidder,
+		BidderCoreName: bidder,
+		BidRequest:     &openrtb2.BidRequest{ID: "req"},
+	}
+	for _, impID := range impIDs {
+		req.BidRequest.Imp = append(req.BidRequest.Imp, openrtb2.Imp{ID: impID})
+	}
+	return req
+}
+
+func newEarlyReturnResponse(bidder openrtb_ext.BidderName, bids ...openrtb2.Bid) *bidResponseWrapper {
+	seatBid := &entities.PbsOrtbSeatBid{Seat: string(bidder)}
+	for i := range bids {
+		seatBid.Bids = append(seatBid.Bids, &entities.PbsOrtbBid{Bid: &bids[i]})
+	}
+	return &bidResponseWrapper{bidder: bidder, adapterSeatBids: []*entities.PbsOrtbSeatBid{seatBid}}
+}
+
+func TestNewEarlyReturn(t *testing.T) {
+	bidderRequests := []BidderRequest{
+		newEarlyReturnBidderRequest("appnexus", "imp1"),
+		newEarlyReturnBidderRequest("rubicon", "imp1", "imp2"),
+	}
+
+	testCases := []struct {
+		name     string
+		cfg      config.AccountAuctionEarlyReturn
+		expected *earlyReturn
+	}{
+		{
+			name: "disabled",
+			cfg:  config.AccountAuctionEarlyReturn{Enabled: false, DealBidders: []string{"appnexus"}},
+		},
+		{
+			name: "no-condition",
+			cfg:  config.AccountAuctionEarlyReturn{Enabled: true},
+		},
+		{
+			name: "deal-bidders-not-called",
+			cfg:  config.AccountAuctionEarlyReturn{Enabled: true, DealBidders: []string{"pubmatic"}},
+		},
+		{
+			name: "deal-bidders",
+			cfg:  config.AccountAuctionEarlyReturn{Enabled: true, DealBidders: []string{"AppNexus", "pubmatic"}},
+			expected: &earlyReturn{
+				pendingDealBidders: map[openrtb_ext.BidderName]struct{}{"appnexus": {}},
+				hasDealBidders:     true,
+				impsBelowTarget:    map[string]struct{}{},
+			},
+		},
+		{
+			name: "target-cpm",
+			cfg:  config.AccountAuctionEarlyReturn{Enabled: true, TargetCPM: 5},
+			expected: &earlyReturn{
+				pendingDealBidders: map[openrtb_ext.BidderName]struct{}{},
+				targetCPM:          5,
+				impsBelowTarget:    map[string]struct{}{"imp1": {}, "imp2": {}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			er := newEarlyReturn(test.cfg, bidderRequests)
+			if test.expected == nil {
+				assert.Nil(t, er)
+				return
+			}
+			require.NotNil(t, er)
+			er.cancel = nil
+			assert.Equal(t, test.expected, er)
+		})
+	}
+}
+
+func TestEarlyReturnUpdate(t *testing.T) {
+	bidderRequests := []BidderRequest{
+		newEarlyReturnBidderRequest("appnexus", "imp1", "imp2"),
+		newEarlyReturnBidderRequest("rubicon", "imp1", "imp2"),
+		newEarlyReturnBidderRequest("pubmatic", "imp1", "imp2"),
+	}
+
+	testCases := []struct {
+		name      string
+		cfg       config.AccountAuctionEarlyReturn
+		responses []*bidResponseWrapper
+		expected  []bool
+	}{
+		{
+			name: "deal-bidders-responded",
+			cfg:  config.AccountAuctionEarlyReturn{Enabled: true, DealBidders: []string{"appnexus", "rubicon"}},
+			responses: []*bidResponseWrapper{
+				newEarlyReturnResponse("appnexus"),
+				newEarlyReturnResponse("pubmatic"),
+				newEarlyReturnResponse("rubicon"),
+			},
+			expected: []bool{false, false, true},
+		},
+		{
+			name: "target-cpm-reached-for-every-imp",
+			cfg:  config.AccountAuctionEarlyReturn{Enabled: true, TargetCPM: 5},
+			responses: []*bidResponseWrapper{
+				newEarlyReturnResponse("appnexus", openrtb2.Bid{ImpID: "imp1", Price: 6}, openrtb2.Bid{ImpID: "imp2", Price: 4}),
+				newEarlyReturnResponse("rubicon", openrtb2.Bid{ImpID: "imp1", Price: 7}),
+				newEarlyReturnResponse("pubmatic", openrtb2.Bid{ImpID: "imp2", Price: 5}),
+			},
+			expected: []bool{false, false, true},
+		},
+		{
+			name: "either-condition",
+			cfg:  config.AccountAuctionEarlyReturn{Enabled: true, DealBidders: []string{"pubmatic"}, TargetCPM: 5},
+			responses: []*bidResponseWrapper{
+				newEarlyReturnResponse("appnexus", openrtb2.Bid{ImpID: "imp1", Price: 6}, openrtb2.Bid{ImpID: "imp2", Price: 6}),
+			},
+			expected: []bool{true},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			er := newEarlyReturn(test.cfg, bidderRequests)
+			for i, response := range test.responses {
+				assert.Equal(t, test.expected[i], er.update(response), "response %d", i)
+			}
+		})
+	}
+
+	var nilEarlyReturn *earlyReturn
+	assert.False(t, nilEarlyReturn.update(newEarlyReturnResponse("appnexus")))
+	nilEarlyReturn.stop()
+}
+
+func TestEarlyReturnCollectLateBids(t *testing.T) {
+	canceled := false
+	er := &earlyReturn{cancel: func() { canceled = true }}
+
+	chBids := make(chan *bidResponseWrapper, 2)
+	lateBids := er.collectLateBids(chBids, 2)
+	chBids <- newEarlyReturnResponse("rubicon", openrtb2.Bid{ID: "bid1", ImpID: "imp1", Price: 1})
+	chBids <- newEarlyReturnResponse("pubmatic")
+
+	expected := []openrtb2.SeatBid{{Seat: "rubicon", Bid: []openrtb2.Bid{{ID: "bid1", ImpID: "imp1", Price: 1}}}}
+	assert.Equal(t, expected, lateBids.Wait())
+	assert.True(t, canceled, "bidders context should be released once all of them responded")
+
+	var nilLateBids *LateBids
+	assert.Nil(t, nilLateBids.Wait())
+}
+
+func TestWithoutCancel(t *testing.T) {
+	type key struct{}
+	deadline := time.Now().Add(time.Hour)
+	parent, cancelParent := context.WithDeadline(context.WithValue(context.Background(), key{}, "value"), deadline)
+
+	ctx, cancel := withoutCancel(parent)
+	defer cancel()
+	cancelParent()
+
+	assert.Error(t, parent.Err())
+	assert.NoError(t, ctx.Err(), "canceling the parent should not cancel the detached context")
+	assert.Equal(t, "value", ctx.Value(key{}))
+	ctxDeadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, deadline, ctxDeadline)
+
+	cancel()
+	assert.Error(t, ctx.Err())
+}
+
+// earlyReturnTestBidder bids once its release channel is closed, or right away if it has none
+type earlyReturnTestBidder struct {
+	seatBid *entities.PbsOrtbSeatBid
+	release chan struct{}
+}
+
+func (b *earlyReturnTestBidder) requestBid(ctx context.Context, bidderRequest BidderRequest, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, adsCertSigner adscert.Signer, bidRequestOptions bidRequestOptions, alternateBidderCodes openrtb_ext.ExtAlternateBidderCodes, hookExecutor hookexecution.StageExecutor, ruleToAdjustments openrtb_ext.AdjustmentsByDealID) ([]*entities.PbsOrtbSeatBid, extraBidderRespInfo, []error) {
+	if b.release != nil {
+		<-b.release
+	}
+	return []*entities.PbsOrtbSeatBid{b.seatBid}, extraBidderRespInfo{}, nil
+}
+
+func TestGetAllBidsWithEarlyReturn(t *testing.T) {
+	release := make(chan struct{})
+	e := exchange{
+		me: &metricsConf.NilMetricsEngine{},
+		adapterMap: map[openrtb_ext.BidderName]AdaptedBidder{
+			openrtb_ext.BidderAppnexus: &earlyReturnTestBidder{
+				seatBid: &entities.PbsOrtbSeatBid{Seat: "appnexus", Bids: []*entities.PbsOrtbBid{{Bid: &openrtb2.Bid{ID: "deal-bid", ImpID: "imp1", Price: 1}}}},
+			},
+			openrtb_ext.BidderRubicon: &earlyReturnTestBidder{
+				seatBid: &entities.PbsOrtbSeatBid{Seat: "rubicon", Bids: []*entities.PbsOrtbBid{{Bid: &openrtb2.Bid{ID: "late-bid", ImpID: "imp1", Price: 2}}}},
+				release: release,
+			},
+		},
+	}
+	bidderRequests := []BidderRequest{
+		newEarlyReturnBidderRequest("appnexus", "imp1"),
+		newEarlyReturnBidderRequest("rubicon", "imp1"),
+	}
+	er := newEarlyReturn(config.AccountAuctionEarlyReturn{Enabled: true, DealBidders: []string{"appnexus"}}, bidderRequests)
+
+	adapterBids, _, extraRespInfo := e.getAllBids(context.Background(), bidderRequests, nil, currency.NewConstantRates(), false, "", false, openrtb_ext.ExtAlternateBidderCodes{}, nil, &hookexecution.EmptyHookExecutor{}, time.Now(), nil, nil, false, er)
+
+	assert.Contains(t, adapterBids, openrtb_ext.BidderAppnexus)
+	assert.NotContains(t, adapterBids, openrtb_ext.BidderRubicon, "the auction should not wait for bidders other than deal bidders")
+	require.NotNil(t, extraRespInfo.lateBids)
+
+	close(release)
+	lateBids := extraRespInfo.lateBids.Wait()
+	if assert.Len(t, lateBids, 1) && assert.Len(t, lateBids[0].Bid, 1) {
+		assert.Equal(t, "rubicon", lateBids[0].Seat)
+		assert.Equal(t, "late-bid", lateBids[0].Bid[0].ID)
+	}
+}
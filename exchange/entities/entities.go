@@ -33,6 +33,8 @@ type PbsOrtbSeatBid struct {
 // PbsOrtbBid.BidVideo is optional but should be filled out by the Bidder if BidType is video.
 // PbsOrtbBid.BidEvents is set by exchange when event tracking is enabled
 // PbsOrtbBid.BidFloors is set by exchange when floors is enabled
+// PbsOrtbBid.BidPricing is set by exchange when the winning bid clears at a price other than its own price
+// PbsOrtbBid.BidCurrency is set by exchange when the bid was made in a currency negotiated with the bidder
 // PbsOrtbBid.DealPriority is optionally provided by adapters and used internally by the exchange to support deal targeted campaigns.
 // PbsOrtbBid.DealTierSatisfied is set to true by exchange.updateHbPbCatDur if deal tier satisfied otherwise it will be set to false
 // PbsOrtbBid.GeneratedBidID is unique Bid id generated by prebid server if generate Bid id option is enabled in config
@@ -44,6 +46,8 @@ type PbsOrtbBid struct {
 	BidVideo          *openrtb_ext.ExtBidPrebidVideo
 	BidEvents         *openrtb_ext.ExtBidPrebidEvents
 	BidFloors         *openrtb_ext.ExtBidPrebidFloors
+	BidPricing        *openrtb_ext.ExtBidPrebidPricing
+	BidCurrency       *openrtb_ext.ExtBidPrebidCurrency
 	DealPriority      int
 	DealTierSatisfied bool
 	GeneratedBidID    string
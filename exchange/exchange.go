@@ -19,6 +19,8 @@ import (
 	"github.com/prebid/prebid-server/v2/adapters"
 	"github.com/prebid/prebid-server/v2/adservertargeting"
 	"github.com/prebid/prebid-server/v2/bidadjustment"
+	"github.com/prebid/prebid-server/v2/bidvalidation"
+	"github.com/prebid/prebid-server/v2/capture"
 	"github.com/prebid/prebid-server/v2/config"
 	"github.com/prebid/prebid-server/v2/currency"
 	"github.com/prebid/prebid-server/v2/dsa"
@@ -80,10 +82,14 @@ type exchange struct {
 	adsCertSigner            adscert.Signer
 	server                   config.Server
 	bidValidationEnforcement config.Validations
+	bidValidators            *bidValidators
 	requestSplitter          requestSplitter
 	macroReplacer            macros.Replacer
 	priceFloorEnabled        bool
 	priceFloorFetcher        floors.FloorFetcher
+	trafficShaper            *trafficShaper
+	capturer                 *capture.Capturer
+	accountAdapters          *accountAdapters
 }
 
 // Container to pass out response ext data from the GetAllBids goroutines back into the main thread
@@ -94,6 +100,9 @@ type seatResponseExtra struct {
 	// httpCalls is the list of debugging info. It should only be populated if the request.test == 1.
 	// This will become response.ext.debug.httpcalls.{bidder} on the final Response.
 	HttpCalls []*openrtb_ext.ExtHttpCall
+	// StrippedFields lists the fields removed from the request by the data policies of the bidder.
+	// This will become response.ext.debug.strippedfields.{bidder} on the final Response.
+	StrippedFields []string
 }
 
 type bidResponseWrapper struct {
@@ -102,6 +111,7 @@ type bidResponseWrapper struct {
 	bidder                  openrtb_ext.BidderName
 	adapter                 openrtb_ext.BidderName
 	bidderResponseStartTime time.Time
+	seatNonBids             nonBids
 }
 
 type BidIDGenerator interface {
@@ -148,6 +158,7 @@ func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid
 		GDPR: cfg.GDPR,
 		LMT:  cfg.LMT,
 	}
+	trafficShaper := newTrafficShaper(cfg.TrafficShaping, metricsEngine)
 	requestSplitter := requestSplitter{
 		bidderToSyncerKey: bidderToSyncerKey,
 		me:                metricsEngine,
@@ -155,6 +166,7 @@ func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid
 		gdprPermsBuilder:  gdprPermsBuilder,
 		hostSChainNode:    cfg.HostSChainNode,
 		bidderInfo:        infos,
+		trafficShaper:     trafficShaper,
 	}
 
 	return &exchange{
@@ -175,10 +187,14 @@ func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid
 		adsCertSigner:            adsCertSigner,
 		server:                   config.Server{ExternalUrl: cfg.ExternalURL, GvlID: cfg.GDPR.HostVendorID, DataCenter: cfg.DataCenter},
 		bidValidationEnforcement: cfg.Validations,
+		bidValidators:            newBidValidators(bidvalidation.NewRegistry(), cfg.Validations),
 		requestSplitter:          requestSplitter,
 		macroReplacer:            macroReplacer,
 		priceFloorEnabled:        cfg.PriceFloors.Enabled,
 		priceFloorFetcher:        priceFloorFetcher,
+		trafficShaper:            trafficShaper,
+		capturer:                 capture.NewCapturer(cfg.AuctionCapture),
+		accountAdapters:          newAccountAdapters(newAdapterBuilders(), infos, config.Server{ExternalUrl: cfg.ExternalURL, GvlID: cfg.GDPR.HostVendorID, DataCenter: cfg.DataCenter}),
 	}
 }
 
@@ -217,6 +233,8 @@ type AuctionRequest struct {
 	QueryParams             url.Values
 	BidderResponseStartTime time.Time
 	TmaxAdjustments         *TmaxAdjustmentsPreprocessed
+	// Replay serves the http calls of a captured auction instead of calling the bidders
+	Replay *capture.Replay
 }
 
 // BidderRequest holds the bidder specific request and all other
@@ -229,6 +247,22 @@ type BidderRequest struct {
 	BidderStoredResponses map[string]json.RawMessage
 	IsRequestAlias        bool
 	ImpReplaceImpId       map[string]bool
+	// trafficSlice is the traffic slice the outcome of the request is recorded for, nil if traffic shaping is disabled
+	trafficSlice *trafficSlice
+	// auctionCur holds the currencies of the auction when the request was sent in a currency the bidder accepts
+	auctionCur []string
+	// recorder records the http calls of the bidder if the auction is captured
+	recorder *capture.Recorder
+	// replay serves the http calls of the bidder if the auction is replayed
+	replay *capture.Replay
+	// shadow is set for the bidders the account mirrors traffic to without letting them compete
+	shadow bool
+	// strippedFields lists the fields removed from the request by the data policies of the bidder
+	strippedFields []string
+	// adapter replaces the adapter of the bidder when the account points the bidder at its own endpoint
+	adapter adapters.Bidder
+	// adaptiveTimeout is the timeout of the http calls of the bidder if adaptive bidder timeouts are enabled
+	adaptiveTimeout adaptiveTimeout
 }
 
 func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog *DebugLog) (*AuctionResponse, error) {
@@ -236,6 +270,11 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 		return nil, nil
 	}
 
+	var recorder *capture.Recorder
+	if e.capturer != nil && r.Replay == nil {
+		recorder = e.capturer.Start(r.BidRequestWrapper, &r.Account, getPBSRates(e.currencyConverter), captureUIDs(e.bidderToSyncerKey, r.UserSyncs))
+	}
+
 	err := r.HookExecutor.ExecuteProcessedAuctionStage(r.BidRequestWrapper)
 	if err != nil {
 		return nil, err
@@ -337,8 +376,26 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 		Prebid: *requestExtPrebid,
 		SChain: requestExt.GetSChain(),
 	}
-	bidderRequests, privacyLabels, errs := e.requestSplitter.cleanOpenRTBRequests(ctx, *r, requestExtLegacy, gdprSignal, gdprEnforced, bidAdjustmentFactors)
+	bidderRequests, privacyLabels, seatNonBids, errs := e.requestSplitter.cleanOpenRTBRequests(ctx, *r, requestExtLegacy, gdprSignal, gdprEnforced, bidAdjustmentFactors, conversions)
 	errs = append(errs, floorErrs...)
+	allowedBidderRequests := bidderRequests[:0]
+	for i := range bidderRequests {
+		bidderRequests[i].recorder = recorder
+		bidderRequests[i].replay = r.Replay
+		bidderRequests[i].shadow = isShadowBidder(r.Account.ShadowBidders, bidderRequests[i].BidderName)
+		adapter, err := e.accountAdapters.get(&r.Account, bidderRequests[i])
+		if err != nil {
+			// the bidder isn't called on the host endpoint when the account expects it to use its own
+			errs = append(errs, err)
+			for _, imp := range bidderRequests[i].BidRequest.Imp {
+				seatNonBids.addImp(imp.ID, int(openrtb_ext.ErrorGeneral), string(bidderRequests[i].BidderName))
+			}
+			continue
+		}
+		bidderRequests[i].adapter = adapter
+		allowedBidderRequests = append(allowedBidderRequests, bidderRequests[i])
+	}
+	bidderRequests = allowedBidderRequests
 
 	mergedBidAdj, err := bidadjustment.Merge(r.BidRequestWrapper, r.Account.BidAdjustments)
 	if err != nil {
@@ -367,6 +424,8 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 		anyBidsReturned bool
 		// List of bidders we have requests for.
 		liveAdapters []openrtb_ext.BidderName
+		lateBids     *LateBids
+		shadowBids   *ShadowBids
 	)
 
 	if len(r.StoredAuctionResponses) > 0 {
@@ -387,37 +446,53 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 		} else if r.Account.AlternateBidderCodes != nil {
 			alternateBidderCodes = *r.Account.AlternateBidderCodes
 		}
+		bidderCtx := auctionCtx
+		earlyReturn := newEarlyReturn(r.Account.Auction.EarlyReturn, bidderRequests)
+		if earlyReturn != nil {
+			// bidders which did not respond when the auction returns early keep bidding in the background
+			bidderCtx, earlyReturn.cancel = withoutCancel(auctionCtx)
+		}
+
 		var extraRespInfo extraAuctionResponseInfo
-		adapterBids, adapterExtra, extraRespInfo = e.getAllBids(auctionCtx, bidderRequests, bidAdjustmentFactors, conversions, accountDebugAllow, r.GlobalPrivacyControlHeader, debugLog.DebugOverride, alternateBidderCodes, requestExtLegacy.Prebid.Experiment, r.HookExecutor, r.StartTime, bidAdjustmentRules, r.TmaxAdjustments, responseDebugAllow)
+		adapterBids, adapterExtra, extraRespInfo = e.getAllBids(bidderCtx, bidderRequests, bidAdjustmentFactors, conversions, accountDebugAllow, r.GlobalPrivacyControlHeader, debugLog.DebugOverride, alternateBidderCodes, requestExtLegacy.Prebid.Experiment, r.HookExecutor, r.StartTime, bidAdjustmentRules, r.TmaxAdjustments, responseDebugAllow, earlyReturn)
+		lateBids = extraRespInfo.lateBids
+		shadowBids = extraRespInfo.shadowBids
 		fledge = extraRespInfo.fledge
 		anyBidsReturned = extraRespInfo.bidsFound
 		r.BidderResponseStartTime = extraRespInfo.bidderResponseStartTime
+		seatNonBids.append(extraRespInfo.seatNonBids)
 	}
 
 	var (
 		auc            *auction
 		cacheErrs      []error
 		bidResponseExt *openrtb_ext.ExtBidResponse
-		seatNonBids    = nonBids{}
 	)
 
 	if anyBidsReturned {
+		if validators := e.bidValidators.get(&r.Account); len(validators) > 0 {
+			errs = append(errs, validateBids(validators, r.BidRequestWrapper.BidRequest, adapterBids, &seatNonBids)...)
+		}
+
 		if e.priceFloorEnabled {
 			var rejectedBids []*entities.PbsOrtbSeatBid
 			var enforceErrs []error
 
+			bidsBeforeEnforcement := collectBids(adapterBids)
 			adapterBids, enforceErrs, rejectedBids = floors.Enforce(r.BidRequestWrapper, adapterBids, r.Account, conversions)
 			errs = append(errs, enforceErrs...)
 			for _, rejectedBid := range rejectedBids {
 				errs = append(errs, &errortypes.Warning{
 					Message:     fmt.Sprintf("%s bid id %s rejected - bid price %.4f %s is less than bid floor %.4f %s for imp %s", rejectedBid.Seat, rejectedBid.Bids[0].Bid.ID, rejectedBid.Bids[0].Bid.Price, rejectedBid.Currency, rejectedBid.Bids[0].BidFloors.FloorValue, rejectedBid.Bids[0].BidFloors.FloorCurrency, rejectedBid.Bids[0].Bid.ImpID),
 					WarningCode: errortypes.FloorBidRejectionWarningCode})
-				rejectionReason := ResponseRejectedBelowFloor
+				rejectionReason := openrtb_ext.ResponseRejectedBelowFloor
 				if rejectedBid.Bids[0].Bid.DealID != "" {
-					rejectionReason = ResponseRejectedBelowDealFloor
+					rejectionReason = openrtb_ext.ResponseRejectedBelowDealFloor
 				}
 				seatNonBids.addBid(rejectedBid.Bids[0], int(rejectionReason), rejectedBid.Seat)
+				delete(bidsBeforeEnforcement, rejectedBid.Bids[0])
 			}
+			addDroppedBids(bidsBeforeEnforcement, adapterBids, openrtb_ext.ResponseRejectedGeneral, &seatNonBids)
 		}
 
 		var bidCategory map[string]string
@@ -433,6 +508,9 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 			}
 		}
 
+		preferDeals := requestExtPrebid.Targeting != nil && requestExtPrebid.Targeting.PreferDeals
+		assembleAdPods(r.BidRequestWrapper.Imp, adapterBids, preferDeals, &seatNonBids)
+
 		if e.bidIDGenerator.Enabled() {
 			for bidder, seatBid := range adapterBids {
 				for i := range seatBid.Bids {
@@ -455,7 +533,9 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 
 			// A non-nil auction is only needed if targeting is active. (It is used below this block to extract cache keys)
 			auc = newAuction(adapterBids, len(r.BidRequestWrapper.Imp), targData.preferDeals)
-			auc.validateAndUpdateMultiBid(adapterBids, targData.preferDeals, r.Account.DefaultBidLimit)
+			auc.validateAndUpdateMultiBid(adapterBids, targData.preferDeals, r.Account.DefaultBidLimit, &seatNonBids)
+			auc.applyMediaTypePriority(getMediaTypePriority(requestExtPrebid.Targeting, r.Account.Auction.MediaTypePriority), targData.preferDeals)
+			auc.applyPricing(r.Account.Auction.Pricing, r.BidRequestWrapper, conversions)
 			auc.setRoundedPrices(*targData)
 
 			if requestExtPrebid.SupportDeals {
@@ -481,6 +561,9 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 			if targData.includeWinners || targData.includeBidderKeys || targData.includeFormat {
 				targData.setTargeting(auc, r.BidRequestWrapper.BidRequest.App != nil, bidCategory, r.Account.TruncateTargetAttribute, multiBidMap)
 			}
+		} else {
+			// The clearing price doesn't depend on targeting, so the winning bids are priced without it too.
+			newAuction(adapterBids, len(r.BidRequestWrapper.Imp), preferDeals).applyPricing(r.Account.Auction.Pricing, r.BidRequestWrapper, conversions)
 		}
 		bidResponseExt = e.makeExtBidResponse(adapterBids, adapterExtra, *r, responseDebugAllow, requestExtPrebid.Passthrough, fledge, errs)
 	} else {
@@ -518,6 +601,9 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 
 	e.bidValidationEnforcement.SetBannerCreativeMaxSize(r.Account.Validations)
 
+	// bids rejected by the modules are reported along with the bids rejected by the exchange
+	seatNonBids.addSeatNonBids(r.HookExecutor.GetSeatNonBid())
+
 	// Build the response
 	bidResponse := e.buildBidResponse(ctx, liveAdapters, adapterBids, r.BidRequestWrapper, adapterExtra, auc, bidResponseExt, cacheInstructions.returnCreative, r.ImpExtInfoMap, r.PubID, errs, &seatNonBids)
 	bidResponse = adservertargeting.Apply(r.BidRequestWrapper, r.ResolvedBidRequest, bidResponse, r.QueryParams, bidResponseExt, r.Account.TruncateTargetAttribute)
@@ -527,10 +613,13 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 		return nil, err
 	}
 	bidResponseExt = setSeatNonBid(bidResponseExt, seatNonBids)
+	e.capturer.Finish(recorder, bidResponse)
 
 	return &AuctionResponse{
 		BidResponse:    bidResponse,
 		ExtBidResponse: bidResponseExt,
+		LateBids:       lateBids,
+		ShadowBids:     shadowBids,
 	}, nil
 }
 
@@ -698,7 +787,8 @@ func (e *exchange) getAllBids(
 	pbsRequestStartTime time.Time,
 	bidAdjustmentRules map[string][]openrtb_ext.Adjustment,
 	tmaxAdjustments *TmaxAdjustmentsPreprocessed,
-	responseDebugAllowed bool) (
+	responseDebugAllowed bool,
+	earlyReturn *earlyReturn) (
 	map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid,
 	map[openrtb_ext.BidderName]*seatResponseExtra,
 	extraAuctionResponseInfo) {
@@ -706,11 +796,27 @@ func (e *exchange) getAllBids(
 	adapterBids := make(map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, len(bidderRequests))
 	adapterExtra := make(map[openrtb_ext.BidderName]*seatResponseExtra, len(bidderRequests))
 	chBids := make(chan *bidResponseWrapper, len(bidderRequests))
+	chShadowBids := make(chan *bidResponseWrapper, len(bidderRequests))
 	extraRespInfo := extraAuctionResponseInfo{}
 
+	// shadow bidders don't compete, the auction neither waits for them nor cancels them when it returns
+	shadowCtx, shadowCancel := withoutCancel(ctx)
+	shadowBidders := 0
+	for _, bidderRequest := range bidderRequests {
+		if bidderRequest.shadow {
+			shadowBidders++
+		}
+	}
+	auctionBidders := len(bidderRequests) - shadowBidders
+
 	e.me.RecordOverheadTime(metrics.MakeBidderRequests, time.Since(pbsRequestStartTime))
 
 	for _, bidder := range bidderRequests {
+		bidderCtx, bidderChBids, bidderHookExecutor := ctx, chBids, hookExecutor
+		if bidder.shadow {
+			// shadow bidders outlive the response, so the outcomes of their hooks could never be part of it
+			bidderCtx, bidderChBids, bidderHookExecutor = shadowCtx, chShadowBids, &hookexecution.EmptyHookExecutor{}
+		}
 		// Here we actually call the adapters and collect the bids.
 		bidderRunner := e.recoverSafely(bidderRequests, func(bidderRequest BidderRequest, conversions currency.Conversions) {
 			// Passing in aName so a doesn't change out from under the go routine
@@ -723,7 +829,9 @@ func (e *exchange) getAllBids(
 			brw.adapter = bidderRequest.BidderCoreName
 			// Defer basic metrics to insure we capture them after all the values have been set
 			defer func() {
-				e.me.RecordAdapterRequest(bidderRequest.BidderLabels)
+				if !bidderRequest.shadow {
+					e.me.RecordAdapterRequest(bidderRequest.BidderLabels)
+				}
 			}()
 			start := time.Now()
 
@@ -740,8 +848,12 @@ func (e *exchange) getAllBids(
 				bidderRequestStartTime: start,
 				responseDebugAllowed:   responseDebugAllowed,
 			}
-			seatBids, extraBidderRespInfo, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(ctx, bidderRequest, conversions, &reqInfo, e.adsCertSigner, bidReqOptions, alternateBidderCodes, hookExecutor, bidAdjustmentRules)
+			seatBids, extraBidderRespInfo, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(bidderCtx, bidderRequest, conversions, &reqInfo, e.adsCertSigner, bidReqOptions, alternateBidderCodes, bidderHookExecutor, bidAdjustmentRules)
 			brw.bidderResponseStartTime = extraBidderRespInfo.respProcessingStartTime
+			brw.seatNonBids = extraBidderRespInfo.seatNonBids
+			if bidderRequest.trafficSlice != nil && !bidderRequest.shadow {
+				e.trafficShaper.record(*bidderRequest.trafficSlice, bidsToMetric(seatBids) == metrics.AdapterBidPresent)
+			}
 
 			// Add in time reporting
 			elapsed := time.Since(start)
@@ -752,14 +864,20 @@ func (e *exchange) getAllBids(
 			if len(seatBids) != 0 {
 				ae.HttpCalls = seatBids[0].HttpCalls
 			}
-			// Timing statistics
-			e.me.RecordAdapterTime(bidderRequest.BidderLabels, elapsed)
+			ae.StrippedFields = bidderRequest.strippedFields
 			bidderRequest.BidderLabels.AdapterBids = bidsToMetric(brw.adapterSeatBids)
 			bidderRequest.BidderLabels.AdapterErrors = errorsToMetric(err)
 			// Append any bid validation errors to the error list
 			ae.Errors = errsToBidderErrors(err)
 			ae.Warnings = errsToBidderWarnings(err)
 			brw.adapterExtra = ae
+			if bidderRequest.shadow {
+				e.recordShadowBids(bidderRequest.BidderName, seatBids)
+				chShadowBids <- brw
+				return
+			}
+			// Timing statistics
+			e.me.RecordAdapterTime(bidderRequest.BidderLabels, elapsed)
 			for _, seatBid := range seatBids {
 				if seatBid != nil {
 					for _, bid := range seatBid.Bids {
@@ -770,12 +888,13 @@ func (e *exchange) getAllBids(
 				}
 			}
 			chBids <- brw
-		}, chBids)
+		}, bidderChBids)
 		go bidderRunner(bidder, conversions)
 	}
+	extraRespInfo.shadowBids = collectShadowBids(chShadowBids, shadowBidders, shadowCancel)
 
 	// Wait for the bidders to do their thing
-	for i := 0; i < len(bidderRequests); i++ {
+	for i := 0; i < auctionBidders; i++ {
 		brw := <-chBids
 		if !brw.bidderResponseStartTime.IsZero() {
 			extraRespInfo.bidderResponseStartTime = brw.bidderResponseStartTime
@@ -798,7 +917,14 @@ func (e *exchange) getAllBids(
 		}
 		//but we need to add all bidders data to adapterExtra to have metrics and other metadata
 		adapterExtra[brw.bidder] = brw.adapterExtra
+		extraRespInfo.seatNonBids.append(brw.seatNonBids)
+
+		if pending := auctionBidders - i - 1; pending > 0 && earlyReturn.update(brw) {
+			extraRespInfo.lateBids = earlyReturn.collectLateBids(chBids, pending)
+			return adapterBids, adapterExtra, extraRespInfo
+		}
 	}
+	earlyReturn.stop()
 
 	return adapterBids, adapterExtra, extraRespInfo
 }
@@ -876,7 +1002,7 @@ func errorsToMetric(errs []error) map[metrics.AdapterError]struct{} {
 			ret[metrics.AdapterErrorBadInput] = s
 		case errortypes.BadServerResponseErrorCode:
 			ret[metrics.AdapterErrorBadServerResponse] = s
-		case errortypes.FailedToRequestBidsErrorCode:
+		case errortypes.FailedToRequestBidsErrorCode, errortypes.BidderCircuitOpenErrorCode:
 			ret[metrics.AdapterErrorFailedToRequestBids] = s
 		case errortypes.AlternateBidderCodeWarningCode:
 			ret[metrics.AdapterErrorValidation] = s
@@ -958,6 +1084,7 @@ func applyCategoryMapping(ctx context.Context, targeting openrtb_ext.ExtRequestT
 		bidIndex   int
 		bidID      string
 		bidPrice   string
+		bid        *entities.PbsOrtbBid
 	}
 
 	dedupe := make(map[string]bidDedupe)
@@ -1012,7 +1139,7 @@ func applyCategoryMapping(ctx context.Context, targeting openrtb_ext.ExtRequestT
 					//on receiving bids from adapters if no unique IAB category is returned  or if no ad server category is returned discard the bid
 					bidsToRemove = append(bidsToRemove, bidInd)
 					rejections = updateRejections(rejections, bidID, "Bid did not contain a category")
-					seatNonBids.addBid(bid, int(ResponseRejectedCategoryMappingInvalid), string(bidderName))
+					seatNonBids.addBid(bid, int(openrtb_ext.ResponseRejectedCategoryMappingInvalid), string(bidderName))
 					continue
 				}
 				if translateCategories {
@@ -1024,6 +1151,7 @@ func applyCategoryMapping(ctx context.Context, targeting openrtb_ext.ExtRequestT
 						bidsToRemove = append(bidsToRemove, bidInd)
 						reason := fmt.Sprintf("Category mapping file for primary ad server: '%s', publisher: '%s' not found", primaryAdServer, publisher)
 						rejections = updateRejections(rejections, bidID, reason)
+						seatNonBids.addBid(bid, int(openrtb_ext.ResponseRejectedCategoryMappingInvalid), string(bidderName))
 						continue
 					}
 				} else {
@@ -1040,6 +1168,7 @@ func applyCategoryMapping(ctx context.Context, targeting openrtb_ext.ExtRequestT
 			if err != nil {
 				bidsToRemove = append(bidsToRemove, bidInd)
 				rejections = updateRejections(rejections, bidID, err.Error())
+				seatNonBids.addBid(bid, int(openrtb_ext.ResponseRejectedGeneral), string(bidderName))
 				continue
 			}
 
@@ -1080,10 +1209,12 @@ func applyCategoryMapping(ctx context.Context, targeting openrtb_ext.ExtRequestT
 						// An older bid from the current bidder
 						bidsToRemove = append(bidsToRemove, dupe.bidIndex)
 						rejections = updateRejections(rejections, dupe.bidID, "Bid was deduplicated")
+						seatNonBids.addBid(dupe.bid, int(openrtb_ext.ResponseRejectedDuplicate), string(dupe.bidderName))
 					} else {
 						// An older bid from a different seatBid we've already finished with
 						oldSeatBid := (seatBids)[dupe.bidderName]
 						rejections = updateRejections(rejections, dupe.bidID, "Bid was deduplicated")
+						seatNonBids.addBid(dupe.bid, int(openrtb_ext.ResponseRejectedDuplicate), string(dupe.bidderName))
 						if len(oldSeatBid.Bids) == 1 {
 							seatBidsToRemove = append(seatBidsToRemove, dupe.bidderName)
 						} else {
@@ -1100,11 +1231,12 @@ func applyCategoryMapping(ctx context.Context, targeting openrtb_ext.ExtRequestT
 					// Remove this bid
 					bidsToRemove = append(bidsToRemove, bidInd)
 					rejections = updateRejections(rejections, bidID, "Bid was deduplicated")
+					seatNonBids.addBid(bid, int(openrtb_ext.ResponseRejectedDuplicate), string(bidderName))
 					continue
 				}
 			}
 			res[bidID] = categoryDuration
-			dedupe[dupeKey] = bidDedupe{bidderName: bidderName, bidIndex: bidInd, bidID: bidID, bidPrice: priceBucket}
+			dedupe[dupeKey] = bidDedupe{bidderName: bidderName, bidIndex: bidInd, bidID: bidID, bidPrice: priceBucket, bid: bid}
 		}
 
 		if len(bidsToRemove) > 0 {
@@ -1157,6 +1289,31 @@ func findDurationRange(dur int, durationRanges []int) (int, error) {
 	return newDur, err
 }
 
+// collectBids returns the seat of every bid present in the provided seat bids.
+func collectBids(seatBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid) map[*entities.PbsOrtbBid]string {
+	bids := make(map[*entities.PbsOrtbBid]string)
+	for bidderName, seatBid := range seatBids {
+		if seatBid == nil {
+			continue
+		}
+		for _, bid := range seatBid.Bids {
+			bids[bid] = bidderName.String()
+		}
+	}
+	return bids
+}
+
+// addDroppedBids records the bids collected before a processing step
+// that are no longer present in the seat bids as non-bids with the given reason.
+func addDroppedBids(bidsBefore map[*entities.PbsOrtbBid]string, seatBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, reason openrtb_ext.NonBidReason, seatNonBids *nonBids) {
+	for bid := range collectBids(seatBids) {
+		delete(bidsBefore, bid)
+	}
+	for bid, seat := range bidsBefore {
+		seatNonBids.addBid(bid, int(reason), seat)
+	}
+}
+
 func removeBidById(seatBid *entities.PbsOrtbSeatBid, bidID string) {
 	//Find index of bid to remove
 	dupeBidIndex := -1
@@ -1226,6 +1383,12 @@ func (e *exchange) makeExtBidResponse(adapterBids map[openrtb_ext.BidderName]*en
 		if debugInfo && len(responseExtra.HttpCalls) > 0 {
 			bidResponseExt.Debug.HttpCalls[bidderName] = responseExtra.HttpCalls
 		}
+		if debugInfo && len(responseExtra.StrippedFields) > 0 {
+			if bidResponseExt.Debug.StrippedFields == nil {
+				bidResponseExt.Debug.StrippedFields = make(map[openrtb_ext.BidderName][]string)
+			}
+			bidResponseExt.Debug.StrippedFields[bidderName] = responseExtra.StrippedFields
+		}
 		if len(responseExtra.Warnings) > 0 {
 			bidResponseExt.Warnings[bidderName] = responseExtra.Warnings
 		}
@@ -1276,12 +1439,12 @@ func (e *exchange) makeBid(bids []*entities.PbsOrtbBid, auc *auction, returnCrea
 			}
 			bidResponseExt.Warnings[adapter] = append(bidResponseExt.Warnings[adapter], dsaMessage)
 
-			seatNonBids.addBid(bid, int(ResponseRejectedGeneral), adapter.String())
+			seatNonBids.addBid(bid, int(openrtb_ext.ResponseRejectedGeneral), adapter.String())
 			continue // Don't add bid to result
 		}
 		if e.bidValidationEnforcement.BannerCreativeMaxSize == config.ValidationEnforce && bid.BidType == openrtb_ext.BidTypeBanner {
 			if !e.validateBannerCreativeSize(bid, bidResponseExt, adapter, pubID, e.bidValidationEnforcement.BannerCreativeMaxSize) {
-				seatNonBids.addBid(bid, int(ResponseRejectedCreativeSizeNotAllowed), adapter.String())
+				seatNonBids.addBid(bid, int(openrtb_ext.ResponseRejectedCreativeSizeNotAllowed), adapter.String())
 				continue // Don't add bid to result
 			}
 		} else if e.bidValidationEnforcement.BannerCreativeMaxSize == config.ValidationWarn && bid.BidType == openrtb_ext.BidTypeBanner {
@@ -1290,7 +1453,7 @@ func (e *exchange) makeBid(bids []*entities.PbsOrtbBid, auc *auction, returnCrea
 		if _, ok := impExtInfoMap[bid.Bid.ImpID]; ok {
 			if e.bidValidationEnforcement.SecureMarkup == config.ValidationEnforce && (bid.BidType == openrtb_ext.BidTypeBanner || bid.BidType == openrtb_ext.BidTypeVideo) {
 				if !e.validateBidAdM(bid, bidResponseExt, adapter, pubID, e.bidValidationEnforcement.SecureMarkup) {
-					seatNonBids.addBid(bid, int(ResponseRejectedCreativeNotSecure), adapter.String())
+					seatNonBids.addBid(bid, int(openrtb_ext.ResponseRejectedCreativeNotSecure), adapter.String())
 					continue // Don't add bid to result
 				}
 			} else if e.bidValidationEnforcement.SecureMarkup == config.ValidationWarn && (bid.BidType == openrtb_ext.BidTypeBanner || bid.BidType == openrtb_ext.BidTypeVideo) {
@@ -1304,11 +1467,13 @@ func (e *exchange) makeBid(bids []*entities.PbsOrtbBid, auc *auction, returnCrea
 			Events:            bid.BidEvents,
 			Targeting:         bid.BidTargets,
 			Floors:            bid.BidFloors,
+			Pricing:           bid.BidPricing,
 			Type:              bid.BidType,
 			Meta:              bid.BidMeta,
 			Video:             bid.BidVideo,
 			BidId:             bid.GeneratedBidID,
 			TargetBidderCode:  bid.TargetBidderCode,
+			Currency:          bid.BidCurrency,
 		}
 
 		if cacheInfo, found := e.getBidCacheInfo(bid, auc); found {
@@ -1604,3 +1769,14 @@ func setSeatNonBid(bidResponseExt *openrtb_ext.ExtBidResponse, seatNonBids nonBi
 	bidResponseExt.Prebid.SeatNonBid = seatNonBids.get()
 	return bidResponseExt
 }
+
+// getPBSRates returns the currency rates of the host, captured with an auction so it can be replayed with them.
+func getPBSRates(currencyConverter *currency.RateConverter) map[string]map[string]float64 {
+	if currencyConverter == nil {
+		return nil
+	}
+	if rates := currencyConverter.Rates().GetRates(); rates != nil {
+		return *rates
+	}
+	return nil
+}
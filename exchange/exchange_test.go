@@ -2826,6 +2826,7 @@ func TestCategoryDedupe(t *testing.T) {
 		dedupeGeneratorValue bool
 		expectedBids         []*entities.PbsOrtbBid
 		expectedCategories   map[string]string
+		expectedNonBids      map[string]int
 	}{
 		{
 			name:                 "bid_id5_selected_over_bid_id3",
@@ -2835,6 +2836,11 @@ func TestCategoryDedupe(t *testing.T) {
 				"bid_id2": "14.00_Sports_50s",
 				"bid_id5": "20.00_Electronics_30s",
 			},
+			expectedNonBids: map[string]int{
+				"imp_id1": int(openrtb_ext.ResponseRejectedDuplicate),
+				"imp_id3": int(openrtb_ext.ResponseRejectedDuplicate),
+				"imp_id4": int(openrtb_ext.ResponseRejectedCategoryMappingInvalid),
+			},
 		},
 		{
 			name:                 "bid_id3_selected_over_bid_id5",
@@ -2844,6 +2850,11 @@ func TestCategoryDedupe(t *testing.T) {
 				"bid_id2": "14.00_Sports_50s",
 				"bid_id3": "20.00_Electronics_30s",
 			},
+			expectedNonBids: map[string]int{
+				"imp_id1": int(openrtb_ext.ResponseRejectedDuplicate),
+				"imp_id4": int(openrtb_ext.ResponseRejectedCategoryMappingInvalid),
+				"imp_id5": int(openrtb_ext.ResponseRejectedDuplicate),
+			},
 		},
 	}
 
@@ -2862,12 +2873,19 @@ func TestCategoryDedupe(t *testing.T) {
 				},
 			}
 			deduplicateGenerator := fakeBooleanGenerator{value: tt.dedupeGeneratorValue}
-			bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, *requestExt.Prebid.Targeting, adapterBids, categoriesFetcher, targData, &deduplicateGenerator, &nonBids{})
+			seatNonBids := nonBids{}
+			bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, *requestExt.Prebid.Targeting, adapterBids, categoriesFetcher, targData, &deduplicateGenerator, &seatNonBids)
 
 			assert.Nil(t, err)
 			assert.Equal(t, 3, len(rejections))
 			assert.Equal(t, adapterBids[bidderName1].Bids, tt.expectedBids)
 			assert.Equal(t, bidCategory, tt.expectedCategories)
+
+			actualNonBids := make(map[string]int)
+			for _, nonBid := range seatNonBids.seatNonBidsMap[bidderName1.String()] {
+				actualNonBids[nonBid.ImpId] = nonBid.StatusCode
+			}
+			assert.Equal(t, tt.expectedNonBids, actualNonBids)
 		})
 	}
 }
@@ -5457,7 +5475,7 @@ func TestGetAllBids(t *testing.T) {
 
 			adapterBids, adapterExtra, extraRespInfo := e.getAllBids(context.Background(), test.in.bidderRequests, test.in.bidAdjustments,
 				test.in.conversions, test.in.accountDebugAllowed, test.in.globalPrivacyControlHeader, test.in.headerDebugAllowed, test.in.alternateBidderCodes, test.in.experiment,
-				test.in.hookExecutor, test.in.pbsRequestStartTime, test.in.bidAdjustmentRules, test.in.tmaxAdjustments, false)
+				test.in.hookExecutor, test.in.pbsRequestStartTime, test.in.bidAdjustmentRules, test.in.tmaxAdjustments, false, nil)
 
 			assert.Equalf(t, test.expected.extraRespInfo.bidsFound, extraRespInfo.bidsFound, "extraRespInfo.bidsFound mismatch")
 			assert.Equalf(t, test.expected.adapterBids, adapterBids, "adapterBids mismatch")
@@ -6332,3 +6350,32 @@ func TestBidsToUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestAddDroppedBids(t *testing.T) {
+	keptBid := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "kept", ImpID: "imp1", Price: 2}}
+	droppedBid := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "dropped", ImpID: "imp2", Price: 1}, OriginalBidCPM: 1.5, OriginalBidCur: "EUR"}
+
+	seatBids := map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+		"pubmatic": {Bids: []*entities.PbsOrtbBid{keptBid, droppedBid}},
+		"appnexus": nil,
+	}
+	bidsBefore := collectBids(seatBids)
+	assert.Equal(t, map[*entities.PbsOrtbBid]string{keptBid: "pubmatic", droppedBid: "pubmatic"}, bidsBefore)
+
+	seatBids["pubmatic"].Bids = []*entities.PbsOrtbBid{keptBid}
+	seatNonBids := nonBids{}
+	addDroppedBids(bidsBefore, seatBids, openrtb_ext.ResponseRejectedGeneral, &seatNonBids)
+
+	expectedNonBids := map[string][]openrtb_ext.NonBid{
+		"pubmatic": {
+			{
+				ImpId:      "imp2",
+				StatusCode: int(openrtb_ext.ResponseRejectedGeneral),
+				Ext: openrtb_ext.NonBidExt{
+					Prebid: openrtb_ext.ExtResponseNonBidPrebid{Bid: openrtb_ext.NonBidObject{Price: 1, OriginalBidCPM: 1.5, OriginalBidCur: "EUR"}},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expectedNonBids, seatNonBids.seatNonBidsMap)
+}
@@ -40,6 +40,40 @@ func (snb *nonBids) addBid(bid *entities.PbsOrtbBid, nonBidReason int, seat stri
 	snb.seatNonBidsMap[seat] = append(snb.seatNonBidsMap[seat], nonBid)
 }
 
+// addImp records a non bid for an imp the seat was not able to bid on. It is not thread safe.
+func (snb *nonBids) addImp(impID string, nonBidReason int, seat string) {
+	if snb.seatNonBidsMap == nil {
+		snb.seatNonBidsMap = make(map[string][]openrtb_ext.NonBid)
+	}
+	snb.seatNonBidsMap[seat] = append(snb.seatNonBidsMap[seat], openrtb_ext.NonBid{
+		ImpId:      impID,
+		StatusCode: nonBidReason,
+	})
+}
+
+// append adds all non bids of other to snb. It is not thread safe.
+func (snb *nonBids) append(other nonBids) {
+	for seat, nonBids := range other.seatNonBidsMap {
+		if snb.seatNonBidsMap == nil {
+			snb.seatNonBidsMap = make(map[string][]openrtb_ext.NonBid)
+		}
+		snb.seatNonBidsMap[seat] = append(snb.seatNonBidsMap[seat], nonBids...)
+	}
+}
+
+// addSeatNonBids adds the non bids reported outside of the exchange, such as by hooks. It is not thread safe.
+func (snb *nonBids) addSeatNonBids(seatNonBids []openrtb_ext.SeatNonBid) {
+	for _, seatNonBid := range seatNonBids {
+		if len(seatNonBid.NonBid) == 0 {
+			continue
+		}
+		if snb.seatNonBidsMap == nil {
+			snb.seatNonBidsMap = make(map[string][]openrtb_ext.NonBid)
+		}
+		snb.seatNonBidsMap[seatNonBid.Seat] = append(snb.seatNonBidsMap[seatNonBid.Seat], seatNonBid.NonBid...)
+	}
+}
+
 func (snb *nonBids) get() []openrtb_ext.SeatNonBid {
 	if snb == nil {
 		return nil
@@ -108,3 +108,69 @@ var sampleSeatBids = func(seat string, nonBidCount int) []openrtb_ext.SeatNonBid
 	seatNonBids = append(seatNonBids, seatNonBid)
 	return seatNonBids
 }
+
+func TestSeatNonBidsAddImp(t *testing.T) {
+	snb := &nonBids{}
+	snb.addImp("imp1", int(openrtb_ext.ErrorBidderUnreachable), "bidder1")
+	snb.addImp("imp2", int(openrtb_ext.ErrorBidderUnreachable), "bidder1")
+
+	expected := map[string][]openrtb_ext.NonBid{
+		"bidder1": {
+			{ImpId: "imp1", StatusCode: 103},
+			{ImpId: "imp2", StatusCode: 103},
+		},
+	}
+	assert.Equal(t, expected, snb.seatNonBidsMap)
+}
+
+func TestSeatNonBidsAppend(t *testing.T) {
+	tests := []struct {
+		name  string
+		snb   nonBids
+		other nonBids
+		want  map[string][]openrtb_ext.NonBid
+	}{
+		{
+			name:  "both-empty",
+			snb:   nonBids{},
+			other: nonBids{},
+			want:  nil,
+		},
+		{
+			name:  "empty-with-other",
+			snb:   nonBids{},
+			other: nonBids{seatNonBidsMap: sampleSeatNonBidMap("bidder1", 1)},
+			want:  sampleSeatNonBidMap("bidder1", 1),
+		},
+		{
+			name:  "same-seat",
+			snb:   nonBids{seatNonBidsMap: sampleSeatNonBidMap("bidder1", 1)},
+			other: nonBids{seatNonBidsMap: sampleSeatNonBidMap("bidder1", 2)},
+			want:  sampleSeatNonBidMap("bidder1", 3),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.snb.append(tt.other)
+			assert.Equal(t, tt.want, tt.snb.seatNonBidsMap)
+		})
+	}
+}
+
+func TestSeatNonBidsAddSeatNonBids(t *testing.T) {
+	snb := nonBids{seatNonBidsMap: sampleSeatNonBidMap("bidder1", 1)}
+	snb.addSeatNonBids([]openrtb_ext.SeatNonBid{
+		{Seat: "bidder1", NonBid: []openrtb_ext.NonBid{{ImpId: "imp2", StatusCode: 350}}},
+		{Seat: "bidder2", NonBid: []openrtb_ext.NonBid{{ImpId: "imp3", StatusCode: 350}}},
+		{Seat: "bidder3"},
+	})
+
+	expected := sampleSeatNonBidMap("bidder1", 1)
+	expected["bidder1"] = append(expected["bidder1"], openrtb_ext.NonBid{ImpId: "imp2", StatusCode: 350})
+	expected["bidder2"] = []openrtb_ext.NonBid{{ImpId: "imp3", StatusCode: 350}}
+	assert.Equal(t, expected, snb.seatNonBidsMap)
+
+	empty := nonBids{}
+	empty.addSeatNonBids(nil)
+	assert.Nil(t, empty.seatNonBidsMap)
+}
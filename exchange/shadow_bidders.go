@@ -0,0 +1,87 @@
+package exchange
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/metrics"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+// isShadowBidder reports whether the bidder is one of the shadow bidders of the account. Shadow bidders are called
+// like any other bidder, but their bids don't compete in the auction and are only reported to analytics and metrics.
+func isShadowBidder(shadowBidders []string, bidderName openrtb_ext.BidderName) bool {
+	for _, shadowBidder := range shadowBidders {
+		if strings.EqualFold(shadowBidder, bidderName.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordShadowBids records the outcome of a shadow bidder request. Shadow bidders are left out of the regular
+// adapter metrics, so that they don't skew the numbers of the bidders competing in the auction.
+func (e *exchange) recordShadowBids(bidderName openrtb_ext.BidderName, seatBids []*entities.PbsOrtbSeatBid) {
+	e.me.RecordAdapterShadowRequest(bidderName, bidsToMetric(seatBids) == metrics.AdapterBidPresent)
+	for _, seatBid := range seatBids {
+		if seatBid == nil {
+			continue
+		}
+		for _, bid := range seatBid.Bids {
+			e.me.RecordAdapterShadowPrice(bidderName, bid.Bid.Price*1000)
+		}
+	}
+}
+
+// collectShadowBids gathers the responses of the shadow bidders in the background, so that the auction doesn't wait
+// for them. cancel releases the context of the shadow bidders once all of them responded.
+func collectShadowBids(chShadowBids <-chan *bidResponseWrapper, shadowBidders int, cancel context.CancelFunc) *ShadowBids {
+	if shadowBidders == 0 {
+		cancel()
+		return nil
+	}
+	shadowBids := &ShadowBids{done: make(chan struct{})}
+	go func() {
+		defer close(shadowBids.done)
+		defer cancel()
+		for i := 0; i < shadowBidders; i++ {
+			brw := <-chShadowBids
+			shadowBids.seatBids = appendSeatBids(shadowBids.seatBids, brw.adapterSeatBids)
+		}
+	}()
+	return shadowBids
+}
+
+// ShadowBids holds the bids of the shadow bidders of the account, which did not compete in the auction.
+type ShadowBids struct {
+	done     chan struct{}
+	seatBids []openrtb2.SeatBid
+}
+
+// Wait blocks until all shadow bidders responded and returns their bids. A nil ShadowBids has no bids.
+func (sb *ShadowBids) Wait() []openrtb2.SeatBid {
+	if sb == nil {
+		return nil
+	}
+	<-sb.done
+	return sb.seatBids
+}
+
+// appendSeatBids appends the bids of the bidder seats as OpenRTB seat bids, skipping the seats without bids.
+func appendSeatBids(seatBids []openrtb2.SeatBid, adapterSeatBids []*entities.PbsOrtbSeatBid) []openrtb2.SeatBid {
+	for _, seatBid := range adapterSeatBids {
+		if seatBid == nil || len(seatBid.Bids) == 0 {
+			continue
+		}
+		bids := make([]openrtb2.Bid, 0, len(seatBid.Bids))
+		for _, bid := range seatBid.Bids {
+			if bid.Bid != nil {
+				bids = append(bids, *bid.Bid)
+			}
+		}
+		seatBids = append(seatBids, openrtb2.SeatBid{Seat: seatBid.Seat, Bid: bids})
+	}
+	return seatBids
+}
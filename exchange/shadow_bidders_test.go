@@ -0,0 +1,147 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/currency"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/experiment/adscert"
+	"github.com/prebid/prebid-server/v2/hooks"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v2/metrics"
+	metricsConf "github.com/prebid/prebid-server/v2/metrics/config"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsShadowBidder(t *testing.T) {
+	testCases := []struct {
+		name          string
+		shadowBidders []string
+		bidder        openrtb_ext.BidderName
+		expected      bool
+	}{
+		{
+			name:     "no-shadow-bidders",
+			bidder:   openrtb_ext.BidderAppnexus,
+			expected: false,
+		},
+		{
+			name:          "listed",
+			shadowBidders: []string{"rubicon", "appnexus"},
+			bidder:        openrtb_ext.BidderAppnexus,
+			expected:      true,
+		},
+		{
+			name:          "listed-case-insensitive",
+			shadowBidders: []string{"AppNexus"},
+			bidder:        openrtb_ext.BidderAppnexus,
+			expected:      true,
+		},
+		{
+			name:          "not-listed",
+			shadowBidders: []string{"rubicon"},
+			bidder:        openrtb_ext.BidderAppnexus,
+			expected:      false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, isShadowBidder(test.shadowBidders, test.bidder))
+		})
+	}
+}
+
+func TestAppendSeatBids(t *testing.T) {
+	adapterSeatBids := []*entities.PbsOrtbSeatBid{
+		nil,
+		{Seat: "appnexus"},
+		{Seat: "rubicon", Bids: []*entities.PbsOrtbBid{{Bid: &openrtb2.Bid{ID: "bid1", Price: 1}}, {}}},
+	}
+	seatBids := []openrtb2.SeatBid{{Seat: "pubmatic"}}
+
+	expected := []openrtb2.SeatBid{
+		{Seat: "pubmatic"},
+		{Seat: "rubicon", Bid: []openrtb2.Bid{{ID: "bid1", Price: 1}}},
+	}
+	assert.Equal(t, expected, appendSeatBids(seatBids, adapterSeatBids))
+}
+
+func TestNewEarlyReturnSkipsShadowBidders(t *testing.T) {
+	shadowRequest := newEarlyReturnBidderRequest("appnexus", "imp1")
+	shadowRequest.shadow = true
+
+	er := newEarlyReturn(config.AccountAuctionEarlyReturn{Enabled: true, DealBidders: []string{"appnexus"}, TargetCPM: 1}, []BidderRequest{shadowRequest})
+	assert.Nil(t, er, "the auction should neither wait for shadow bidders nor return on their bids")
+}
+
+// hookExecutorRecordingBidder records the hook executor the bidder is run with
+type hookExecutorRecordingBidder struct {
+	AdaptedBidder
+	hookExecutor hookexecution.StageExecutor
+}
+
+func (b *hookExecutorRecordingBidder) requestBid(ctx context.Context, bidderRequest BidderRequest, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, adsCertSigner adscert.Signer, bidRequestOptions bidRequestOptions, alternateBidderCodes openrtb_ext.ExtAlternateBidderCodes, hookExecutor hookexecution.StageExecutor, ruleToAdjustments openrtb_ext.AdjustmentsByDealID) ([]*entities.PbsOrtbSeatBid, extraBidderRespInfo, []error) {
+	b.hookExecutor = hookExecutor
+	return b.AdaptedBidder.requestBid(ctx, bidderRequest, conversions, reqInfo, adsCertSigner, bidRequestOptions, alternateBidderCodes, hookExecutor, ruleToAdjustments)
+}
+
+func TestGetAllBidsWithShadowBidders(t *testing.T) {
+	release := make(chan struct{})
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordOverheadTime", mock.Anything, mock.Anything).Return()
+	metricsMock.On("RecordAdapterRequest", mock.Anything).Return()
+	metricsMock.On("RecordAdapterTime", mock.Anything, mock.Anything).Return()
+	metricsMock.On("RecordAdapterPrice", mock.Anything, mock.Anything).Return()
+	metricsMock.On("RecordAdapterBidReceived", mock.Anything, mock.Anything, mock.Anything).Return()
+	metricsMock.On("RecordAdapterShadowRequest", openrtb_ext.BidderRubicon, true).Return()
+	metricsMock.On("RecordAdapterShadowPrice", openrtb_ext.BidderRubicon, float64(2000)).Return()
+
+	auctionBidder := &hookExecutorRecordingBidder{AdaptedBidder: &earlyReturnTestBidder{
+		seatBid: &entities.PbsOrtbSeatBid{Seat: "appnexus", Bids: []*entities.PbsOrtbBid{{Bid: &openrtb2.Bid{ID: "bid", ImpID: "imp1", Price: 1}}}},
+	}}
+	shadowBidder := &hookExecutorRecordingBidder{AdaptedBidder: &earlyReturnTestBidder{
+		seatBid: &entities.PbsOrtbSeatBid{Seat: "rubicon", Bids: []*entities.PbsOrtbBid{{Bid: &openrtb2.Bid{ID: "shadow-bid", ImpID: "imp1", Price: 2}}}},
+		release: release,
+	}}
+	e := exchange{
+		me: metricsMock,
+		adapterMap: map[openrtb_ext.BidderName]AdaptedBidder{
+			openrtb_ext.BidderAppnexus: auctionBidder,
+			openrtb_ext.BidderRubicon:  shadowBidder,
+		},
+	}
+	hookExecutor := hookexecution.NewHookExecutor(hooks.EmptyPlanBuilder{}, hookexecution.EndpointAuction, &metricsConf.NilMetricsEngine{})
+	shadowRequest := newEarlyReturnBidderRequest("rubicon", "imp1")
+	shadowRequest.shadow = true
+	bidderRequests := []BidderRequest{
+		newEarlyReturnBidderRequest("appnexus", "imp1"),
+		shadowRequest,
+	}
+
+	adapterBids, adapterExtra, extraRespInfo := e.getAllBids(context.Background(), bidderRequests, nil, currency.NewConstantRates(), false, "", false, openrtb_ext.ExtAlternateBidderCodes{}, nil, hookExecutor, time.Now(), nil, nil, false, nil)
+
+	assert.Contains(t, adapterBids, openrtb_ext.BidderAppnexus)
+	assert.NotContains(t, adapterBids, openrtb_ext.BidderRubicon, "shadow bids should not compete in the auction")
+	assert.NotContains(t, adapterExtra, openrtb_ext.BidderRubicon, "shadow bidders should not be part of the response")
+	require.NotNil(t, extraRespInfo.shadowBids, "the auction should not wait for shadow bidders")
+
+	close(release)
+	assert.Equal(t, []openrtb2.SeatBid{{Seat: "rubicon", Bid: []openrtb2.Bid{{ID: "shadow-bid", ImpID: "imp1", Price: 2}}}}, extraRespInfo.shadowBids.Wait())
+
+	metricsMock.AssertCalled(t, "RecordAdapterShadowRequest", openrtb_ext.BidderRubicon, true)
+	metricsMock.AssertCalled(t, "RecordAdapterShadowPrice", openrtb_ext.BidderRubicon, float64(2000))
+	metricsMock.AssertNumberOfCalls(t, "RecordAdapterPrice", 1)
+	metricsMock.AssertNumberOfCalls(t, "RecordAdapterRequest", 1)
+	metricsMock.AssertNumberOfCalls(t, "RecordAdapterTime", 1)
+	assert.Same(t, hookExecutor, auctionBidder.hookExecutor)
+	assert.IsType(t, &hookexecution.EmptyHookExecutor{}, shadowBidder.hookExecutor, "the hook outcomes of shadow bidders should not reach the request executor")
+}
@@ -53,6 +53,16 @@ func (b *bidderTmaxCtx) Until(t time.Time) time.Duration {
 	return time.Until(t)
 }
 
+// getBidderDeadline returns the deadline for a bidder to respond, leaving room for the overheads
+// accounted for by the tmax adjustments.
+func getBidderDeadline(deadline time.Time, tmaxAdjustments *TmaxAdjustmentsPreprocessed) time.Time {
+	if tmaxAdjustments == nil || !tmaxAdjustments.IsEnforced {
+		return deadline
+	}
+	overhead := time.Duration(tmaxAdjustments.BidderNetworkLatencyBuffer+tmaxAdjustments.PBSResponsePreparationDuration) * time.Millisecond
+	return deadline.Add(-overhead)
+}
+
 func getBidderTmax(ctx bidderTmaxContext, requestTmaxMS int64, tmaxAdjustments TmaxAdjustmentsPreprocessed) int64 {
 	if tmaxAdjustments.IsEnforced {
 		if deadline, ok := ctx.Deadline(); ok {
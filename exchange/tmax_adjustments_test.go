@@ -106,3 +106,34 @@ func TestProcessTMaxAdjustments(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBidderDeadline(t *testing.T) {
+	deadline := time.Date(2023, 5, 30, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		description      string
+		tmaxAdjustments  *TmaxAdjustmentsPreprocessed
+		expectedDeadline time.Time
+	}{
+		{
+			description:      "nil-tmax-adjustments",
+			tmaxAdjustments:  nil,
+			expectedDeadline: deadline,
+		},
+		{
+			description:      "not-enforced",
+			tmaxAdjustments:  &TmaxAdjustmentsPreprocessed{IsEnforced: false, BidderNetworkLatencyBuffer: 50},
+			expectedDeadline: deadline,
+		},
+		{
+			description:      "enforced",
+			tmaxAdjustments:  &TmaxAdjustmentsPreprocessed{IsEnforced: true, BidderNetworkLatencyBuffer: 50, PBSResponsePreparationDuration: 60},
+			expectedDeadline: deadline.Add(-110 * time.Millisecond),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expectedDeadline, getBidderDeadline(deadline, test.tmaxAdjustments))
+		})
+	}
+}
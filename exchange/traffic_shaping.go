@@ -0,0 +1,196 @@
+package exchange
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/metrics"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/timeutil"
+)
+
+// trafficSlice identifies the requests a bid rate is learned for.
+type trafficSlice struct {
+	bidder     openrtb_ext.BidderName
+	account    string
+	country    string
+	deviceType int64
+	formats    string
+}
+
+type trafficStats struct {
+	requests int
+	bids     int
+}
+
+// trafficShaper learns the bid rate of bidders per traffic slice to suppress calls to bidders which
+// are unlikely to bid. It is safe for concurrent use.
+type trafficShaper struct {
+	window      time.Duration
+	minRequests int
+	minBidRate  float64
+	minPassRate float64
+
+	me     metrics.MetricsEngine
+	clock  timeutil.Time
+	random func() float64
+
+	lock        sync.Mutex
+	windowStart time.Time
+	current     map[trafficSlice]*trafficStats
+	previous    map[trafficSlice]*trafficStats
+}
+
+func newTrafficShaper(cfg config.TrafficShaping, me metrics.MetricsEngine) *trafficShaper {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	clock := &timeutil.RealTime{}
+	return &trafficShaper{
+		window:      time.Duration(cfg.WindowSeconds) * time.Second,
+		minRequests: cfg.MinRequests,
+		minBidRate:  cfg.MinBidRate,
+		minPassRate: cfg.MinPassRate,
+		me:          me,
+		clock:       clock,
+		random:      rand.Float64,
+		windowStart: clock.Now(),
+		current:     make(map[trafficSlice]*trafficStats),
+		previous:    make(map[trafficSlice]*trafficStats),
+	}
+}
+
+// newTrafficSlice returns the traffic slice of a request sent to the given bidder.
+func newTrafficSlice(bidder openrtb_ext.BidderName, account string, req *openrtb2.BidRequest) trafficSlice {
+	slice := trafficSlice{
+		bidder:  bidder,
+		account: account,
+		formats: impFormats(req.Imp),
+	}
+	if req.Device != nil {
+		slice.deviceType = int64(req.Device.DeviceType)
+		if req.Device.Geo != nil {
+			slice.country = req.Device.Geo.Country
+		}
+	}
+	return slice
+}
+
+// impFormats returns the sorted list of media types requested by the imps.
+func impFormats(imps []openrtb2.Imp) string {
+	formats := make(map[openrtb_ext.BidType]struct{})
+	for _, imp := range imps {
+		if imp.Banner != nil {
+			formats[openrtb_ext.BidTypeBanner] = struct{}{}
+		}
+		if imp.Video != nil {
+			formats[openrtb_ext.BidTypeVideo] = struct{}{}
+		}
+		if imp.Audio != nil {
+			formats[openrtb_ext.BidTypeAudio] = struct{}{}
+		}
+		if imp.Native != nil {
+			formats[openrtb_ext.BidTypeNative] = struct{}{}
+		}
+	}
+
+	sorted := make([]string, 0, len(formats))
+	for format := range formats {
+		sorted = append(sorted, string(format))
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// allow reports whether a request of the given slice may be sent to its bidder. Requests are always
+// allowed until enough of them were recorded, and when the bid rate of the slice reaches the minimum
+// bid rate. Below it, requests are let through with a probability proportional to the bid rate.
+// A nil traffic shaper allows every request.
+func (ts *trafficShaper) allow(slice trafficSlice) bool {
+	if ts == nil {
+		return true
+	}
+
+	passRate, ok := ts.passRate(slice)
+	if !ok || ts.random() < passRate {
+		return true
+	}
+	ts.me.RecordAdapterTrafficShaped(slice.bidder)
+	return false
+}
+
+// passRate returns the share of requests of the given slice to let through, or false if every request
+// should be.
+func (ts *trafficShaper) passRate(slice trafficSlice) (float64, bool) {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	ts.rotate()
+	var requests, bids int
+	if stats, ok := ts.current[slice]; ok {
+		requests += stats.requests
+		bids += stats.bids
+	}
+	if stats, ok := ts.previous[slice]; ok {
+		requests += stats.requests
+		bids += stats.bids
+	}
+	if requests < ts.minRequests {
+		return 0, false
+	}
+
+	bidRate := float64(bids) / float64(requests)
+	if bidRate >= ts.minBidRate {
+		return 0, false
+	}
+	passRate := bidRate / ts.minBidRate
+	if passRate < ts.minPassRate {
+		passRate = ts.minPassRate
+	}
+	return passRate, true
+}
+
+// record adds the outcome of a request of the given slice sent to its bidder.
+func (ts *trafficShaper) record(slice trafficSlice, hasBids bool) {
+	if ts == nil {
+		return
+	}
+
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+
+	ts.rotate()
+	stats, ok := ts.current[slice]
+	if !ok {
+		stats = &trafficStats{}
+		ts.current[slice] = stats
+	}
+	stats.requests++
+	if hasBids {
+		stats.bids++
+	}
+}
+
+// rotate moves the current stats to the previous ones once the window elapsed. It must be called
+// with the lock held.
+func (ts *trafficShaper) rotate() {
+	now := ts.clock.Now()
+	elapsed := now.Sub(ts.windowStart)
+	if elapsed < ts.window {
+		return
+	}
+
+	if elapsed < 2*ts.window {
+		ts.previous = ts.current
+	} else {
+		ts.previous = make(map[trafficSlice]*trafficStats)
+	}
+	ts.current = make(map[trafficSlice]*trafficStats)
+	ts.windowStart = now
+}
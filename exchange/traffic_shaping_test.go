@@ -0,0 +1,173 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/metrics"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestTrafficShaper(clock *fakeClock, me metrics.MetricsEngine, random float64) *trafficShaper {
+	ts := newTrafficShaper(config.TrafficShaping{
+		Enabled:       true,
+		WindowSeconds: 3600,
+		MinRequests:   10,
+		MinBidRate:    0.2,
+		MinPassRate:   0.05,
+	}, me)
+	ts.clock = clock
+	ts.windowStart = clock.now
+	ts.random = func() float64 { return random }
+	return ts
+}
+
+func recordTraffic(ts *trafficShaper, slice trafficSlice, requests, bids int) {
+	for i := 0; i < requests; i++ {
+		ts.record(slice, i < bids)
+	}
+}
+
+func TestNewTrafficShaperDisabled(t *testing.T) {
+	ts := newTrafficShaper(config.TrafficShaping{Enabled: false}, &metrics.MetricsEngineMock{})
+	assert.Nil(t, ts)
+
+	slice := trafficSlice{bidder: "appnexus"}
+	ts.record(slice, false)
+	assert.True(t, ts.allow(slice))
+}
+
+func TestTrafficShaperPassRate(t *testing.T) {
+	testCases := []struct {
+		name             string
+		requests         int
+		bids             int
+		expectedPassRate float64
+		expectedOK       bool
+	}{
+		{
+			name:       "no-requests",
+			expectedOK: false,
+		},
+		{
+			name:       "too-few-requests",
+			requests:   9,
+			expectedOK: false,
+		},
+		{
+			name:       "bid-rate-above-minimum",
+			requests:   10,
+			bids:       2,
+			expectedOK: false,
+		},
+		{
+			name:             "bid-rate-below-minimum",
+			requests:         10,
+			bids:             1,
+			expectedPassRate: 0.5,
+			expectedOK:       true,
+		},
+		{
+			name:             "no-bids",
+			requests:         10,
+			expectedPassRate: 0.05,
+			expectedOK:       true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ts := newTestTrafficShaper(&fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, &metrics.MetricsEngineMock{}, 0)
+			slice := trafficSlice{bidder: "appnexus", account: "acct"}
+			recordTraffic(ts, slice, test.requests, test.bids)
+
+			passRate, ok := ts.passRate(slice)
+			assert.Equal(t, test.expectedOK, ok)
+			assert.InDelta(t, test.expectedPassRate, passRate, 0.0001)
+		})
+	}
+}
+
+func TestTrafficShaperAllow(t *testing.T) {
+	testCases := []struct {
+		name          string
+		random        float64
+		expectedAllow bool
+	}{
+		{
+			name:          "passed",
+			random:        0.4,
+			expectedAllow: true,
+		},
+		{
+			name:          "suppressed",
+			random:        0.6,
+			expectedAllow: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			me := &metrics.MetricsEngineMock{}
+			me.On("RecordAdapterTrafficShaped", mock.Anything).Return()
+			ts := newTestTrafficShaper(&fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, me, test.random)
+			slice := trafficSlice{bidder: "appnexus", account: "acct"}
+			recordTraffic(ts, slice, 10, 1)
+
+			assert.Equal(t, test.expectedAllow, ts.allow(slice))
+			assert.True(t, ts.allow(trafficSlice{bidder: "appnexus", account: "other"}), "other slices should not be shaped")
+			if test.expectedAllow {
+				me.AssertNotCalled(t, "RecordAdapterTrafficShaped", mock.Anything)
+			} else {
+				me.AssertCalled(t, "RecordAdapterTrafficShaped", openrtb_ext.BidderName("appnexus"))
+			}
+		})
+	}
+}
+
+func TestTrafficShaperWindows(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	ts := newTestTrafficShaper(clock, &metrics.MetricsEngineMock{}, 0)
+	slice := trafficSlice{bidder: "appnexus"}
+
+	recordTraffic(ts, slice, 10, 0)
+	_, ok := ts.passRate(slice)
+	assert.True(t, ok)
+
+	clock.now = clock.now.Add(time.Hour)
+	_, ok = ts.passRate(slice)
+	assert.True(t, ok, "previous window should still be used")
+
+	clock.now = clock.now.Add(time.Hour)
+	_, ok = ts.passRate(slice)
+	assert.False(t, ok, "expired windows should be dropped")
+}
+
+func TestNewTrafficSlice(t *testing.T) {
+	req := &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "imp1", Video: &openrtb2.Video{}},
+			{ID: "imp2", Banner: &openrtb2.Banner{}, Native: &openrtb2.Native{}},
+		},
+		Device: &openrtb2.Device{
+			DeviceType: adcom1.DeviceMobile,
+			Geo:        &openrtb2.Geo{Country: "USA"},
+		},
+	}
+
+	slice := newTrafficSlice("appnexus", "acct", req)
+	assert.Equal(t, trafficSlice{
+		bidder:     "appnexus",
+		account:    "acct",
+		country:    "USA",
+		deviceType: int64(adcom1.DeviceMobile),
+		formats:    "banner,native,video",
+	}, slice)
+
+	assert.Equal(t, trafficSlice{bidder: "appnexus"}, newTrafficSlice("appnexus", "", &openrtb2.BidRequest{}))
+}
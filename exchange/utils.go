@@ -17,6 +17,7 @@ import (
 	"github.com/prebid/openrtb/v20/openrtb2"
 
 	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/currency"
 	"github.com/prebid/prebid-server/v2/errortypes"
 	"github.com/prebid/prebid-server/v2/firstpartydata"
 	"github.com/prebid/prebid-server/v2/gdpr"
@@ -50,6 +51,7 @@ type requestSplitter struct {
 	gdprPermsBuilder  gdpr.PermissionsBuilder
 	hostSChainNode    *openrtb2.SupplyChainNode
 	bidderInfo        config.BidderInfos
+	trafficShaper     *trafficShaper
 }
 
 // cleanOpenRTBRequests splits the input request into requests which are sanitized for each bidder. Intended behavior is:
@@ -57,13 +59,16 @@ type requestSplitter struct {
 //  1. BidRequest.Imp[].Ext will only contain the "prebid" field and a "bidder" field which has the params for the intended Bidder.
 //  2. Every BidRequest.Imp[] requested Bids from the Bidder who keys it.
 //  3. BidRequest.User.BuyerUID will be set to that Bidder's ID.
+//
+// Calls skipped by traffic shaping are returned as seat non bids.
 func (rs *requestSplitter) cleanOpenRTBRequests(ctx context.Context,
 	auctionReq AuctionRequest,
 	requestExt *openrtb_ext.ExtRequest,
 	gdprSignal gdpr.Signal,
 	gdprEnforced bool,
 	bidAdjustmentFactors map[string]float64,
-) (allowedBidderRequests []BidderRequest, privacyLabels metrics.PrivacyLabels, errs []error) {
+	conversions currency.Conversions,
+) (allowedBidderRequests []BidderRequest, privacyLabels metrics.PrivacyLabels, seatNonBids nonBids, errs []error) {
 	req := auctionReq.BidRequestWrapper
 
 	requestAliases, requestAliasesGVLIDs, errs := getRequestAliases(req)
@@ -83,7 +88,7 @@ func (rs *requestSplitter) cleanOpenRTBRequests(ctx context.Context,
 
 	var allBidderRequests []BidderRequest
 	var allBidderRequestErrs []error
-	allBidderRequests, allBidderRequestErrs = getAuctionBidderRequests(auctionReq, requestExt, rs.bidderToSyncerKey, impsByBidder, requestAliases, rs.hostSChainNode)
+	allBidderRequests, seatNonBids, allBidderRequestErrs = getAuctionBidderRequests(auctionReq, requestExt, rs.bidderToSyncerKey, impsByBidder, requestAliases, rs.hostSChainNode, rs.trafficShaper)
 	if allBidderRequestErrs != nil {
 		errs = append(errs, allBidderRequestErrs...)
 	}
@@ -106,6 +111,13 @@ func (rs *requestSplitter) cleanOpenRTBRequests(ctx context.Context,
 		applyBidAdjustmentToFloor(allBidderRequests, bidAdjustmentFactors)
 	}
 
+	for i := range allBidderRequests {
+		bidderInfo := rs.bidderInfo[string(allBidderRequests[i].BidderCoreName)]
+		if err := negotiateCurrency(&allBidderRequests[i], bidderInfo.Currencies, conversions); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	consent, err := getConsent(req, gpp)
 	if err != nil {
 		errs = append(errs, err)
@@ -232,6 +244,14 @@ func (rs *requestSplitter) cleanOpenRTBRequests(ctx context.Context,
 		}
 		bidderRequest.BidRequest = reqWrapper.BidRequest
 
+		// data policies run last, so that they also apply to the fields set by FPD
+		bidderRequest.strippedFields, err = applyDataPolicies(bidderRequest, rs.bidderInfo, &auctionReq.Account)
+		if err != nil {
+			// the request isn't sent when the fields the bidder may receive can't be enforced
+			errs = append(errs, fmt.Errorf("%s: data policy: %v", bidderRequest.BidderName, err))
+			continue
+		}
+
 		allowedBidderRequests = append(allowedBidderRequests, bidderRequest)
 
 		// GPP downgrade: always downgrade unless we can confirm GPP is supported
@@ -244,6 +264,34 @@ func (rs *requestSplitter) cleanOpenRTBRequests(ctx context.Context,
 	return
 }
 
+// applyDataPolicies removes the fields of the request the bidder may not receive and returns their paths. The host
+// policy of the bidder is applied first, then the account policy, so that an account can only restrict a bidder further.
+func applyDataPolicies(bidderRequest BidderRequest, bidderInfos config.BidderInfos, account *config.Account) ([]string, error) {
+	var policies []*config.DataPolicy
+	if bidderInfo, ok := bidderInfos[bidderRequest.BidderName.String()]; ok {
+		policies = append(policies, bidderInfo.DataPolicy)
+	} else if bidderInfo, ok := bidderInfos[bidderRequest.BidderCoreName.String()]; ok {
+		policies = append(policies, bidderInfo.DataPolicy)
+	}
+	if account != nil {
+		if policy, ok := account.Privacy.DataPolicy[bidderRequest.BidderName.String()]; ok {
+			policies = append(policies, &policy)
+		} else if policy, ok := account.Privacy.DataPolicy[bidderRequest.BidderCoreName.String()]; ok {
+			policies = append(policies, &policy)
+		}
+	}
+
+	var strippedFields []string
+	for _, policy := range policies {
+		stripped, err := privacy.ApplyDataPolicy(bidderRequest.BidRequest, policy)
+		if err != nil {
+			return nil, err
+		}
+		strippedFields = append(strippedFields, stripped...)
+	}
+	return strippedFields, nil
+}
+
 func shouldSetLegacyPrivacy(bidderInfo config.BidderInfos, bidder string) bool {
 	binfo, defined := bidderInfo[bidder]
 
@@ -319,25 +367,30 @@ func getAuctionBidderRequests(auctionRequest AuctionRequest,
 	bidderToSyncerKey map[string]string,
 	impsByBidder map[string][]openrtb2.Imp,
 	requestAliases map[string]string,
-	hostSChainNode *openrtb2.SupplyChainNode) ([]BidderRequest, []error) {
+	hostSChainNode *openrtb2.SupplyChainNode,
+	trafficShaper *trafficShaper) ([]BidderRequest, nonBids, []error) {
 
 	bidderRequests := make([]BidderRequest, 0, len(impsByBidder))
+	seatNonBids := nonBids{}
 	req := auctionRequest.BidRequestWrapper
 	explicitBuyerUIDs, err := extractBuyerUIDs(req.BidRequest.User)
 	if err != nil {
-		return nil, []error{err}
+		return nil, seatNonBids, []error{err}
 	}
 
 	bidderParamsInReqExt, err := ExtractReqExtBidderParamsMap(req.BidRequest)
 	if err != nil {
-		return nil, []error{err}
+		return nil, seatNonBids, []error{err}
 	}
 
 	sChainWriter, err := schain.NewSChainWriter(requestExt, hostSChainNode)
 	if err != nil {
-		return nil, []error{err}
+		return nil, seatNonBids, []error{err}
 	}
 
+	// test requests are never shaped so that every bidder can be debugged
+	shapeTraffic := trafficShaper != nil && req.BidRequest.Test != 1
+
 	lowerCaseExplicitBuyerUIDs := make(map[string]string)
 	for bidder, uid := range explicitBuyerUIDs {
 		lowerKey := strings.ToLower(bidder)
@@ -353,9 +406,21 @@ func getAuctionBidderRequests(auctionRequest AuctionRequest,
 
 		sChainWriter.Write(&reqCopy, bidder)
 
+		var slice *trafficSlice
+		if shapeTraffic {
+			s := newTrafficSlice(openrtb_ext.BidderName(bidder), auctionRequest.LegacyLabels.PubID, &reqCopy)
+			if !trafficShaper.allow(s) {
+				for _, imp := range imps {
+					seatNonBids.addImp(imp.ID, int(openrtb_ext.RequestBlockedOptimized), bidder)
+				}
+				continue
+			}
+			slice = &s
+		}
+
 		reqCopy.Ext, err = buildRequestExtForBidder(bidder, req.BidRequest.Ext, requestExt, bidderParamsInReqExt, auctionRequest.Account.AlternateBidderCodes)
 		if err != nil {
-			return nil, []error{err}
+			return nil, seatNonBids, []error{err}
 		}
 
 		if err := removeUnpermissionedEids(&reqCopy, bidder, requestExt); err != nil {
@@ -376,6 +441,7 @@ func getAuctionBidderRequests(auctionRequest AuctionRequest,
 				CookieFlag:  auctionRequest.LegacyLabels.CookieFlag,
 				AdapterBids: metrics.AdapterBidPresent,
 			},
+			trafficSlice: slice,
 		}
 
 		syncerKey := bidderToSyncerKey[string(coreBidder)]
@@ -387,7 +453,7 @@ func getAuctionBidderRequests(auctionRequest AuctionRequest,
 
 		bidderRequests = append(bidderRequests, bidderRequest)
 	}
-	return bidderRequests, errs
+	return bidderRequests, seatNonBids, errs
 }
 
 func buildRequestExtForBidder(bidder string, requestExt json.RawMessage, requestExtParsed *openrtb_ext.ExtRequest, bidderParamsInReqExt map[string]json.RawMessage, cfgABC *openrtb_ext.ExtAlternateBidderCodes) (json.RawMessage, error) {
@@ -27,7 +27,7 @@ func Enforce(bidRequestWrapper *openrtb_ext.RequestWrapper, seatBids map[openrtb
 		return seatBids, nil, rejectedBids
 	}
 
-	if isSignalingSkipped(requestExt) || !isValidImpBidFloorPresent(bidRequestWrapper.BidRequest.Imp) {
+	if isSignalingSkipped(requestExt) || !(isValidImpBidFloorPresent(bidRequestWrapper.BidRequest.Imp) || isValidDealBidFloorPresent(bidRequestWrapper.BidRequest.Imp)) {
 		return seatBids, nil, rejectedBids
 	}
 
@@ -130,6 +130,36 @@ func enforceFloorToBids(bidRequestWrapper *openrtb_ext.RequestWrapper, seatBids
 					continue
 				}
 
+				if deal := getDeal(reqImp, bid.Bid.DealID); deal != nil {
+					dealFloorCur := deal.BidFloorCur
+					if dealFloorCur == "" {
+						dealFloorCur = defaultCurrency
+					}
+					rate, err := getCurrencyConversionRate(seatBid.Currency, dealFloorCur, conversions)
+					if err != nil {
+						errs = append(errs, fmt.Errorf("error in rate conversion from = %s to %s with bidder %s for impression id %s and bid id %s error = %v", seatBid.Currency, dealFloorCur, bidderName, bid.Bid.ImpID, bid.Bid.ID, err.Error()))
+						continue
+					}
+
+					// a deal bid is only checked against the floor of its deal
+					if (rate*bid.Bid.Price + floorPrecision) < deal.BidFloor {
+						bid.BidFloors = &openrtb_ext.ExtBidPrebidFloors{FloorValue: deal.BidFloor, FloorCurrency: dealFloorCur}
+						rejectedBids = append(rejectedBids, &entities.PbsOrtbSeatBid{
+							Currency: seatBid.Currency,
+							Seat:     seatBid.Seat,
+							Bids:     []*entities.PbsOrtbBid{bid},
+						})
+						continue
+					}
+					eligibleBids = append(eligibleBids, bid)
+					continue
+				}
+
+				if reqImp.BidFloor <= 0 {
+					eligibleBids = append(eligibleBids, bid)
+					continue
+				}
+
 				rate, err := getCurrencyConversionRate(seatBid.Currency, reqImp.BidFloorCur, conversions)
 				if err != nil {
 					errs = append(errs, fmt.Errorf("error in rate conversion from = %s to %s with bidder %s for impression id %s and bid id %s error = %v", seatBid.Currency, reqImp.BidFloorCur, bidderName, bid.Bid.ImpID, bid.Bid.ID, err.Error()))
@@ -206,6 +236,34 @@ func isValidImpBidFloorPresent(imp []openrtb2.Imp) bool {
 	return false
 }
 
+// isValidDealBidFloorPresent checks if non zero deal floor is present in imp.pmp.deals of the request
+func isValidDealBidFloorPresent(imp []openrtb2.Imp) bool {
+	for i := range imp {
+		if imp[i].PMP == nil {
+			continue
+		}
+		for _, deal := range imp[i].PMP.Deals {
+			if deal.BidFloor > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getDeal returns the deal of the imp matching the deal id if it has a floor
+func getDeal(imp *openrtb_ext.ImpWrapper, dealID string) *openrtb2.Deal {
+	if dealID == "" || imp.PMP == nil {
+		return nil
+	}
+	for i := range imp.PMP.Deals {
+		if imp.PMP.Deals[i].ID == dealID && imp.PMP.Deals[i].BidFloor > 0 {
+			return &imp.PMP.Deals[i]
+		}
+	}
+	return nil
+}
+
 // isSatisfiedByEnforceRate check enforcements should be done or not based on enforceRate in config and in request
 func isSatisfiedByEnforceRate(requestExt *openrtb_ext.RequestExt, configEnforceRate int, f func(int) int) bool {
 	requestEnforceRate := getEnforceRateRequest(requestExt)
@@ -406,6 +406,132 @@ func TestEnforceFloorToBids(t *testing.T) {
 	}
 }
 
+func TestEnforceFloorToBidsWithDealFloors(t *testing.T) {
+	newRequestWrapper := func() *openrtb_ext.RequestWrapper {
+		bw := openrtb_ext.RequestWrapper{
+			BidRequest: &openrtb2.BidRequest{
+				ID: "some-request-id",
+				Imp: []openrtb2.Imp{
+					{ID: "some-impression-id-1", BidFloor: 1.01, BidFloorCur: "USD", PMP: &openrtb2.PMP{Deals: []openrtb2.Deal{
+						{ID: "deal_1", BidFloor: 5, BidFloorCur: "USD"},
+						{ID: "deal_2", BidFloor: 310, BidFloorCur: "INR"},
+						{ID: "deal_3"},
+					}}},
+					{ID: "some-impression-id-2", PMP: &openrtb2.PMP{Deals: []openrtb2.Deal{{ID: "deal_4", BidFloor: 2}}}},
+				},
+			},
+		}
+		bw.RebuildRequest()
+		return &bw
+	}
+
+	tests := []struct {
+		name              string
+		bids              []*entities.PbsOrtbBid
+		enforceDealFloors bool
+		expEligibleBids   []*entities.PbsOrtbBid
+		expRejectedBids   []*entities.PbsOrtbSeatBid
+	}{
+		{
+			name: "Deal floors not enforced",
+			bids: []*entities.PbsOrtbBid{
+				{Bid: &openrtb2.Bid{ID: "some-bid-1", Price: 1.2, DealID: "deal_1", ImpID: "some-impression-id-1"}},
+			},
+			enforceDealFloors: false,
+			expEligibleBids: []*entities.PbsOrtbBid{
+				{Bid: &openrtb2.Bid{ID: "some-bid-1", Price: 1.2, DealID: "deal_1", ImpID: "some-impression-id-1"}},
+			},
+			expRejectedBids: []*entities.PbsOrtbSeatBid{},
+		},
+		{
+			name: "Deal bids checked against the floor of their deal",
+			bids: []*entities.PbsOrtbBid{
+				{Bid: &openrtb2.Bid{ID: "some-bid-1", Price: 4.5, DealID: "deal_1", ImpID: "some-impression-id-1"}},
+				{Bid: &openrtb2.Bid{ID: "some-bid-2", Price: 5.5, DealID: "deal_1", ImpID: "some-impression-id-1"}},
+				{Bid: &openrtb2.Bid{ID: "some-bid-3", Price: 3.9, DealID: "deal_2", ImpID: "some-impression-id-1"}},
+				{Bid: &openrtb2.Bid{ID: "some-bid-4", Price: 4.1, DealID: "deal_2", ImpID: "some-impression-id-1"}},
+				{Bid: &openrtb2.Bid{ID: "some-bid-5", Price: 1.5, DealID: "deal_3", ImpID: "some-impression-id-1"}},
+				{Bid: &openrtb2.Bid{ID: "some-bid-6", Price: 0.5, DealID: "deal_3", ImpID: "some-impression-id-1"}},
+				{Bid: &openrtb2.Bid{ID: "some-bid-7", Price: 1.9, DealID: "deal_4", ImpID: "some-impression-id-2"}},
+				{Bid: &openrtb2.Bid{ID: "some-bid-8", Price: 0.1, ImpID: "some-impression-id-2"}},
+			},
+			enforceDealFloors: true,
+			expEligibleBids: []*entities.PbsOrtbBid{
+				{Bid: &openrtb2.Bid{ID: "some-bid-2", Price: 5.5, DealID: "deal_1", ImpID: "some-impression-id-1"}},
+				{Bid: &openrtb2.Bid{ID: "some-bid-4", Price: 4.1, DealID: "deal_2", ImpID: "some-impression-id-1"}},
+				{Bid: &openrtb2.Bid{ID: "some-bid-5", Price: 1.5, DealID: "deal_3", ImpID: "some-impression-id-1"}},
+				{Bid: &openrtb2.Bid{ID: "some-bid-8", Price: 0.1, ImpID: "some-impression-id-2"}},
+			},
+			expRejectedBids: []*entities.PbsOrtbSeatBid{
+				{
+					Seat:     "pubmatic",
+					Currency: "USD",
+					Bids: []*entities.PbsOrtbBid{
+						{Bid: &openrtb2.Bid{ID: "some-bid-1", Price: 4.5, DealID: "deal_1", ImpID: "some-impression-id-1"}, BidFloors: &openrtb_ext.ExtBidPrebidFloors{FloorValue: 5, FloorCurrency: "USD"}},
+					},
+				},
+				{
+					Seat:     "pubmatic",
+					Currency: "USD",
+					Bids: []*entities.PbsOrtbBid{
+						{Bid: &openrtb2.Bid{ID: "some-bid-3", Price: 3.9, DealID: "deal_2", ImpID: "some-impression-id-1"}, BidFloors: &openrtb_ext.ExtBidPrebidFloors{FloorValue: 310, FloorCurrency: "INR"}},
+					},
+				},
+				{
+					Seat:     "pubmatic",
+					Currency: "USD",
+					Bids: []*entities.PbsOrtbBid{
+						{Bid: &openrtb2.Bid{ID: "some-bid-6", Price: 0.5, DealID: "deal_3", ImpID: "some-impression-id-1"}},
+					},
+				},
+				{
+					Seat:     "pubmatic",
+					Currency: "USD",
+					Bids: []*entities.PbsOrtbBid{
+						{Bid: &openrtb2.Bid{ID: "some-bid-7", Price: 1.9, DealID: "deal_4", ImpID: "some-impression-id-2"}, BidFloors: &openrtb_ext.ExtBidPrebidFloors{FloorValue: 2, FloorCurrency: "USD"}},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		seatBids := map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+			"pubmatic": {Bids: tt.bids, Seat: "pubmatic", Currency: "USD"},
+		}
+		seatbids, errs, rejBids := enforceFloorToBids(newRequestWrapper(), seatBids, currency.Conversions(convert{}), tt.enforceDealFloors)
+		assert.Equal(t, tt.expEligibleBids, seatbids["pubmatic"].Bids, tt.name)
+		assert.Equal(t, []error{}, errs, tt.name)
+		assert.Equal(t, tt.expRejectedBids, rejBids, tt.name)
+	}
+}
+
+func TestIsValidDealBidFloorPresent(t *testing.T) {
+	tests := []struct {
+		name string
+		imp  []openrtb2.Imp
+		want bool
+	}{
+		{
+			name: "No deals",
+			imp:  []openrtb2.Imp{{ID: "1234", BidFloor: 1}},
+			want: false,
+		},
+		{
+			name: "Deals without floor",
+			imp:  []openrtb2.Imp{{ID: "1234", PMP: &openrtb2.PMP{Deals: []openrtb2.Deal{{ID: "deal_1"}}}}},
+			want: false,
+		},
+		{
+			name: "Deal with floor",
+			imp:  []openrtb2.Imp{{ID: "1234"}, {ID: "5678", PMP: &openrtb2.PMP{Deals: []openrtb2.Deal{{ID: "deal_1"}, {ID: "deal_2", BidFloor: 2}}}}},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isValidDealBidFloorPresent(tt.imp), tt.name)
+	}
+}
+
 func TestEnforce(t *testing.T) {
 	type args struct {
 		bidRequestWrapper *openrtb_ext.RequestWrapper
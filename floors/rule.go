@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/golang/glog"
+	"github.com/prebid/openrtb/v20/adcom1"
 	"github.com/prebid/openrtb/v20/openrtb2"
 	"github.com/prebid/prebid-server/v2/currency"
 	"github.com/prebid/prebid-server/v2/openrtb_ext"
@@ -226,10 +227,22 @@ func createRuleKey(floorSchema openrtb_ext.PriceFloorSchema, request *openrtb_ex
 	return ruleKeys
 }
 
-// getDeviceType returns device type provided into request
+// getDeviceType returns device type provided into request, it is derived from the user agent
+// or from the device type when the request has no user agent
 func getDeviceType(request *openrtb_ext.RequestWrapper) string {
 	value := catchAll
-	if request.Device == nil || len(request.Device.UA) == 0 {
+	if request.Device == nil {
+		return value
+	}
+	if len(request.Device.UA) == 0 {
+		switch request.Device.DeviceType {
+		case adcom1.DevicePhone:
+			value = Phone
+		case adcom1.DeviceTablet:
+			value = Tablet
+		case adcom1.DevicePC:
+			value = Desktop
+		}
 		return value
 	}
 	if isMobileDevice(request.Device.UA) {
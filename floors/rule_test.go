@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/prebid/openrtb/v20/adcom1"
 	"github.com/prebid/openrtb/v20/openrtb2"
 	"github.com/prebid/prebid-server/v2/currency"
 	"github.com/prebid/prebid-server/v2/openrtb_ext"
@@ -918,6 +919,31 @@ func TestGetDeviceType(t *testing.T) {
 			request: &openrtb2.BidRequest{Device: &openrtb2.Device{}},
 			want:    "*",
 		},
+		{
+			name:    "empty user agent with phone device type",
+			request: &openrtb2.BidRequest{Device: &openrtb2.Device{DeviceType: adcom1.DevicePhone}},
+			want:    "phone",
+		},
+		{
+			name:    "empty user agent with tablet device type",
+			request: &openrtb2.BidRequest{Device: &openrtb2.Device{DeviceType: adcom1.DeviceTablet}},
+			want:    "tablet",
+		},
+		{
+			name:    "empty user agent with pc device type",
+			request: &openrtb2.BidRequest{Device: &openrtb2.Device{DeviceType: adcom1.DevicePC}},
+			want:    "desktop",
+		},
+		{
+			name:    "empty user agent with connected tv device type",
+			request: &openrtb2.BidRequest{Device: &openrtb2.Device{DeviceType: adcom1.DeviceTV}},
+			want:    "*",
+		},
+		{
+			name:    "user agent takes precedence over device type",
+			request: &openrtb2.BidRequest{Device: &openrtb2.Device{UA: "Safari(iPhone Apple Mobile)", DeviceType: adcom1.DevicePC}},
+			want:    "phone",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
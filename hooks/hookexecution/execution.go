@@ -71,7 +71,9 @@ func executeGroup[H any, P any](
 
 	for _, hook := range group.Hooks {
 		mCtx := executionCtx.getModuleContext(hook.Module)
-		newPayload := handleModuleActivities(hook.Code, executionCtx.activityControl, payload, executionCtx.account)
+		newPayload, preciseGeoRestricted := handleModuleActivities(hook.Code, executionCtx.activityControl, payload, executionCtx.account)
+		newPayload = isolatePayload(newPayload)
+		mCtx.PreciseGeoRestricted = preciseGeoRestricted
 		wg.Add(1)
 		go func(hw hooks.HookWrapper[H], moduleCtx hookstage.ModuleInvocationContext) {
 			defer wg.Done()
@@ -201,6 +203,7 @@ func handleHookResponse[P any](
 		rejectErr = handleHookReject(ctx, hr, &hookOutcome, metricEngine, labels)
 	} else {
 		payload = handleHookMutations(payload, hr, &hookOutcome, metricEngine, labels)
+		hookOutcome.SeatNonBid = hr.Result.SeatNonBid
 	}
 
 	return payload, hookOutcome, rejectErr
@@ -318,18 +321,31 @@ func handleHookMutations[P any](
 	return payload
 }
 
-func handleModuleActivities[P any](hookCode string, activityControl privacy.ActivityControl, payload P, account *config.Account) P {
+// isolatePayload copies the parts of the payload a hook could modify in place, such as the exitpoint headers,
+// so that a hook which keeps running after its timeout can't modify the payload the mutations are applied to.
+func isolatePayload[P any](payload P) P {
+	if exitpointPayload, ok := any(payload).(hookstage.ExitpointPayload); ok {
+		exitpointPayload.Headers = exitpointPayload.Headers.Clone()
+		return any(exitpointPayload).(P)
+	}
+	return payload
+}
+
+// handleModuleActivities scrubs the payload according to the activities the hook is allowed, and reports whether
+// the hook is denied the transmitPreciseGeo activity.
+func handleModuleActivities[P any](hookCode string, activityControl privacy.ActivityControl, payload P, account *config.Account) (P, bool) {
+	scopeGeneral := privacy.Component{Type: privacy.ComponentTypeGeneral, Name: hookCode}
+	transmitPreciseGeoActivityAllowed := activityControl.Allow(privacy.ActivityTransmitPreciseGeo, scopeGeneral, privacy.ActivityRequest{})
+
 	payloadData, ok := any(&payload).(hookstage.RequestUpdater)
 	if !ok {
-		return payload
+		return payload, !transmitPreciseGeoActivityAllowed
 	}
 
-	scopeGeneral := privacy.Component{Type: privacy.ComponentTypeGeneral, Name: hookCode}
 	transmitUserFPDActivityAllowed := activityControl.Allow(privacy.ActivityTransmitUserFPD, scopeGeneral, privacy.ActivityRequest{})
-	transmitPreciseGeoActivityAllowed := activityControl.Allow(privacy.ActivityTransmitPreciseGeo, scopeGeneral, privacy.ActivityRequest{})
 
 	if transmitUserFPDActivityAllowed && transmitPreciseGeoActivityAllowed {
-		return payload
+		return payload, false
 	}
 
 	// changes need to be applied to new payload and leave original payload unchanged
@@ -358,6 +374,6 @@ func handleModuleActivities[P any](hookCode string, activityControl privacy.Acti
 	var newPayload = payload
 	var np = any(&newPayload).(hookstage.RequestUpdater)
 	np.SetBidderRequestPayload(bidderReqCopy)
-	return newPayload
+	return newPayload, !transmitPreciseGeoActivityAllowed
 
 }
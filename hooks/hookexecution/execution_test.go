@@ -93,7 +93,7 @@ func TestHandleModuleActivitiesBidderRequestPayload(t *testing.T) {
 			//check input payload didn't change
 			origInPayloadData := test.inPayloadData
 			activityControl := privacy.NewActivityControl(test.privacyConfig)
-			newPayload := handleModuleActivities(test.hookCode, activityControl, test.inPayloadData, nil)
+			newPayload, _ := handleModuleActivities(test.hookCode, activityControl, test.inPayloadData, nil)
 			assert.Equal(t, test.expectedPayloadData.Request.BidRequest, newPayload.Request.BidRequest)
 			assert.Equal(t, origInPayloadData, test.inPayloadData)
 		})
@@ -108,6 +108,7 @@ func TestHandleModuleActivitiesProcessedAuctionRequestPayload(t *testing.T) {
 		privacyConfig       *config.AccountPrivacy
 		inPayloadData       hookstage.ProcessedAuctionRequestPayload
 		expectedPayloadData hookstage.ProcessedAuctionRequestPayload
+		expectedPreciseGeo  bool
 	}{
 		{
 			description: "payload should change when userFPD is blocked by activity",
@@ -154,6 +155,7 @@ func TestHandleModuleActivitiesProcessedAuctionRequestPayload(t *testing.T) {
 					Device: &openrtb2.Device{IPv6: testIPv6Scrubbed},
 				}},
 			},
+			expectedPreciseGeo: true,
 		},
 		{
 			description: "payload should not change when transmitPreciseGeo is not blocked by activity",
@@ -177,8 +179,9 @@ func TestHandleModuleActivitiesProcessedAuctionRequestPayload(t *testing.T) {
 			origInPayloadData := test.inPayloadData
 			activityControl := privacy.NewActivityControl(test.privacyConfig)
 			account := &config.Account{Privacy: config.AccountPrivacy{IPv6Config: config.IPv6{AnonKeepBits: testIPv6ScrubBytes}}}
-			newPayload := handleModuleActivities(test.hookCode, activityControl, test.inPayloadData, account)
+			newPayload, preciseGeoRestricted := handleModuleActivities(test.hookCode, activityControl, test.inPayloadData, account)
 			assert.Equal(t, test.expectedPayloadData.Request.BidRequest, newPayload.Request.BidRequest)
+			assert.Equal(t, test.expectedPreciseGeo, preciseGeoRestricted)
 			assert.Equal(t, origInPayloadData, test.inPayloadData)
 		})
 	}
@@ -192,6 +195,7 @@ func TestHandleModuleActivitiesNoBidderRequestPayload(t *testing.T) {
 		privacyConfig       *config.AccountPrivacy
 		inPayloadData       hookstage.RawAuctionRequestPayload
 		expectedPayloadData hookstage.RawAuctionRequestPayload
+		expectedPreciseGeo  bool
 	}{
 		{
 			description:         "payload should not change when userFPD is blocked by activity",
@@ -213,6 +217,7 @@ func TestHandleModuleActivitiesNoBidderRequestPayload(t *testing.T) {
 			inPayloadData:       hookstage.RawAuctionRequestPayload{},
 			privacyConfig:       getTransmitPreciseGeoActivityConfig("foo", false),
 			expectedPayloadData: hookstage.RawAuctionRequestPayload{},
+			expectedPreciseGeo:  true,
 		},
 		{
 			description:         "payload should not change when transmitPreciseGeo is not blocked by activity",
@@ -227,8 +232,9 @@ func TestHandleModuleActivitiesNoBidderRequestPayload(t *testing.T) {
 			//check input payload didn't change
 			origInPayloadData := test.inPayloadData
 			activityControl := privacy.NewActivityControl(test.privacyConfig)
-			newPayload := handleModuleActivities(test.hookCode, activityControl, test.inPayloadData, &config.Account{})
+			newPayload, preciseGeoRestricted := handleModuleActivities(test.hookCode, activityControl, test.inPayloadData, &config.Account{})
 			assert.Equal(t, test.expectedPayloadData, newPayload)
+			assert.Equal(t, test.expectedPreciseGeo, preciseGeoRestricted)
 			assert.Equal(t, origInPayloadData, test.inPayloadData)
 		})
 	}
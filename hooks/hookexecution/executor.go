@@ -19,6 +19,7 @@ import (
 const (
 	EndpointAuction = "/openrtb2/auction"
 	EndpointAmp     = "/openrtb2/amp"
+	EndpointVideo   = "/openrtb2/video"
 )
 
 // An entity specifies the type of object that was processed during the execution of the stage.
@@ -29,6 +30,7 @@ const (
 	entityAuctionRequest           entity = "auction-request"
 	entityAuctionResponse          entity = "auction_response"
 	entityAllProcessedBidResponses entity = "all_processed_bid_responses"
+	entityHttpResponse             entity = "http-response"
 )
 
 type StageExecutor interface {
@@ -39,6 +41,8 @@ type StageExecutor interface {
 	ExecuteRawBidderResponseStage(response *adapters.BidderResponse, bidder string) *RejectError
 	ExecuteAllProcessedBidResponsesStage(adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid)
 	ExecuteAuctionResponseStage(response *openrtb2.BidResponse)
+	ExecuteExitpointStage(response any, status int, w http.ResponseWriter) (any, int)
+	GetSeatNonBid() []openrtb_ext.SeatNonBid
 }
 
 type HookStageExecutor interface {
@@ -84,8 +88,31 @@ func (e *hookExecutor) SetActivityControl(activityControl privacy.ActivityContro
 	e.activityControl = activityControl
 }
 
+// GetOutcomes returns the outcomes of the stages executed so far. Bidders still running, such as the late
+// bidders of an early returned auction, may push outcomes afterwards which aren't part of the returned copy.
 func (e *hookExecutor) GetOutcomes() []StageOutcome {
-	return e.stageOutcomes
+	e.Lock()
+	defer e.Unlock()
+
+	outcomes := make([]StageOutcome, len(e.stageOutcomes))
+	copy(outcomes, e.stageOutcomes)
+	return outcomes
+}
+
+// GetSeatNonBid returns the bids rejected by the hooks which were executed successfully so far
+func (e *hookExecutor) GetSeatNonBid() []openrtb_ext.SeatNonBid {
+	e.Lock()
+	defer e.Unlock()
+
+	var seatNonBid []openrtb_ext.SeatNonBid
+	for _, stageOutcome := range e.stageOutcomes {
+		for _, groupOutcome := range stageOutcome.Groups {
+			for _, hookOutcome := range groupOutcome.InvocationResults {
+				seatNonBid = append(seatNonBid, hookOutcome.SeatNonBid...)
+			}
+		}
+	}
+	return seatNonBid
 }
 
 func (e *hookExecutor) ExecuteEntrypointStage(req *http.Request, body []byte) ([]byte, *RejectError) {
@@ -295,6 +322,62 @@ func (e *hookExecutor) ExecuteAuctionResponseStage(response *openrtb2.BidRespons
 	e.pushStageOutcome(outcome)
 }
 
+// ExecuteExitpointStage runs exitpoint hooks over the response and the HTTP status code about to be sent to the client,
+// error responses included. Hooks are given a copy of the headers of the provided http.ResponseWriter,
+// which are replaced by the modified headers once all hooks completed, so hooks which timed out can't
+// modify them while the response is written. Method returns the response that has to be written and the
+// HTTP status code to send.
+func (e *hookExecutor) ExecuteExitpointStage(response any, status int, w http.ResponseWriter) (any, int) {
+	plan := e.planBuilder.PlanForExitpointStage(e.endpoint, e.account)
+	if len(plan) == 0 {
+		return response, status
+	}
+
+	handler := func(
+		ctx context.Context,
+		moduleCtx hookstage.ModuleInvocationContext,
+		hook hookstage.Exitpoint,
+		payload hookstage.ExitpointPayload,
+	) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+		return hook.HandleExitpointHook(ctx, moduleCtx, payload)
+	}
+
+	stageName := hooks.StageExitpoint.String()
+	executionCtx := e.newContext(stageName)
+	payload := hookstage.ExitpointPayload{Response: response, Headers: w.Header().Clone(), StatusCode: status}
+
+	outcome, payload, contexts, _ := executeStage(executionCtx, plan, payload, handler, e.metricEngine)
+	outcome.Entity = entityHttpResponse
+	outcome.Stage = stageName
+
+	e.saveModuleContexts(contexts)
+	e.pushStageOutcome(outcome)
+
+	copyHeaders(w.Header(), payload.Headers)
+	if payload.StatusCode == 0 {
+		payload.StatusCode = status
+	}
+
+	return payload.Response, payload.StatusCode
+}
+
+// copyHeaders makes headers replaced by hook mutations visible in the response writer.
+func copyHeaders(dst, src http.Header) {
+	if src == nil {
+		return
+	}
+
+	for key := range dst {
+		if _, ok := src[key]; !ok {
+			dst.Del(key)
+		}
+	}
+
+	for key, values := range src {
+		dst[key] = values
+	}
+}
+
 func (e *hookExecutor) newContext(stage string) executionContext {
 	return executionContext{
 		account:         e.account,
@@ -354,3 +437,11 @@ func (executor EmptyHookExecutor) ExecuteAllProcessedBidResponsesStage(_ map[ope
 }
 
 func (executor EmptyHookExecutor) ExecuteAuctionResponseStage(_ *openrtb2.BidResponse) {}
+
+func (executor EmptyHookExecutor) ExecuteExitpointStage(response any, status int, _ http.ResponseWriter) (any, int) {
+	return response, status
+}
+
+func (executor EmptyHookExecutor) GetSeatNonBid() []openrtb_ext.SeatNonBid {
+	return nil
+}
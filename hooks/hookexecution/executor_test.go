@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
@@ -40,10 +42,12 @@ func TestEmptyHookExecutor(t *testing.T) {
 	processedAuctionRejectErr := executor.ExecuteProcessedAuctionStage(&openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{}})
 	bidderRequestRejectErr := executor.ExecuteBidderRequestStage(&openrtb_ext.RequestWrapper{BidRequest: bidderRequest}, "bidder-name")
 	executor.ExecuteAuctionResponseStage(&openrtb2.BidResponse{})
+	exitpointResponse, exitpointStatus := executor.ExecuteExitpointStage(body, http.StatusOK, httptest.NewRecorder())
 
 	outcomes := executor.GetOutcomes()
 	assert.Equal(t, EmptyHookExecutor{}, executor, "EmptyHookExecutor shouldn't be changed.")
 	assert.Empty(t, outcomes, "EmptyHookExecutor shouldn't return stage outcomes.")
+	assert.Empty(t, executor.GetSeatNonBid(), "EmptyHookExecutor shouldn't return seat non bids.")
 
 	assert.Nil(t, entrypointRejectErr, "EmptyHookExecutor shouldn't return reject error at entrypoint stage.")
 	assert.Equal(t, body, entrypointBody, "EmptyHookExecutor shouldn't change body at entrypoint stage.")
@@ -54,6 +58,9 @@ func TestEmptyHookExecutor(t *testing.T) {
 	assert.Nil(t, processedAuctionRejectErr, "EmptyHookExecutor shouldn't return reject error at processed-auction stage.")
 	assert.Nil(t, bidderRequestRejectErr, "EmptyHookExecutor shouldn't return reject error at bidder-request stage.")
 	assert.Equal(t, expectedBidderRequest, bidderRequest, "EmptyHookExecutor shouldn't change payload at bidder-request stage.")
+
+	assert.Equal(t, body, exitpointResponse, "EmptyHookExecutor shouldn't change response at exitpoint stage.")
+	assert.Equal(t, http.StatusOK, exitpointStatus, "EmptyHookExecutor shouldn't change status at exitpoint stage.")
 }
 
 func TestExecuteEntrypointStage(t *testing.T) {
@@ -907,6 +914,40 @@ func TestExecuteProcessedAuctionStage(t *testing.T) {
 	}
 }
 
+func TestExecuteProcessedAuctionStagePreciseGeoRestricted(t *testing.T) {
+	exec := NewHookExecutor(TestPreciseGeoRestrictedBuilder{}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+	exec.SetActivityControl(privacy.NewActivityControl(getTransmitPreciseGeoActivityConfig("foo", false)))
+
+	err := exec.ExecuteProcessedAuctionStage(&openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "some-id"}})
+	assert.NoError(t, err)
+
+	expectedModuleContexts := &moduleContexts{ctxs: map[string]hookstage.ModuleContext{
+		"module-1": {"precise-geo-restricted": true},
+		"module-2": {"precise-geo-restricted": false},
+	}}
+	assert.Equal(t, expectedModuleContexts, exec.moduleContexts, "Hooks must be told whether the activity controls restrict precise geo.")
+}
+
+func TestRaceGetOutcomesWhileBidderStagesRun(t *testing.T) {
+	exec := NewHookExecutor(TestApplyHookMutationsBuilder{}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(bidder string) {
+			defer wg.Done()
+			exec.ExecuteBidderRequestStage(&openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{ID: "some-id", User: &openrtb2.User{ID: "user-id"}}}, bidder)
+		}(fmt.Sprintf("bidder-%d", i))
+	}
+
+	outcomes := exec.GetOutcomes()
+	length := len(outcomes)
+	wg.Wait()
+
+	assert.Len(t, outcomes, length, "the outcomes returned should not change when bidder stages complete")
+	assert.Len(t, exec.GetOutcomes(), 10)
+}
+
 func TestExecuteBidderRequestStage(t *testing.T) {
 	bidderName := "the-bidder"
 	foobarModuleCtx := &moduleContexts{ctxs: map[string]hookstage.ModuleContext{"foobar": nil}}
@@ -1216,6 +1257,40 @@ func buildDefaultActivityConfig(componentName string, allow bool) config.Activit
 	}
 }
 
+func TestGetSeatNonBid(t *testing.T) {
+	nonBid := func(impID string) openrtb_ext.NonBid {
+		return openrtb_ext.NonBid{ImpId: impID, StatusCode: 350}
+	}
+	rejectedBids := []openrtb_ext.SeatNonBid{{Seat: "bidderA", NonBid: []openrtb_ext.NonBid{nonBid("imp1")}}}
+	metricEngine := &metricsConfig.NilMetricsEngine{}
+	executionCtx := executionContext{stage: hooks.StageRawBidderResponse.String()}
+	payload := hookstage.RawBidderResponsePayload{Bidder: "bidderA"}
+
+	_, successOutcome, _ := handleHookResponse(executionCtx, payload, hookResponse[hookstage.RawBidderResponsePayload]{
+		HookID: HookID{ModuleCode: "foobar", HookImplCode: "foo"},
+		Result: hookstage.HookResult[hookstage.RawBidderResponsePayload]{SeatNonBid: rejectedBids},
+	}, metricEngine)
+	assert.Equal(t, rejectedBids, successOutcome.SeatNonBid, "Seat non bids of successful hooks should be kept.")
+
+	_, failureOutcome, _ := handleHookResponse(executionCtx, payload, hookResponse[hookstage.RawBidderResponsePayload]{
+		HookID: HookID{ModuleCode: "foobar", HookImplCode: "foo"},
+		Err:    FailureError{Message: "failed"},
+		Result: hookstage.HookResult[hookstage.RawBidderResponsePayload]{SeatNonBid: rejectedBids},
+	}, metricEngine)
+	assert.Empty(t, failureOutcome.SeatNonBid, "Seat non bids of failed hooks should be ignored.")
+
+	exec := NewHookExecutor(hooks.EmptyPlanBuilder{}, EndpointAuction, metricEngine)
+	exec.pushStageOutcome(StageOutcome{Groups: []GroupOutcome{{InvocationResults: []HookOutcome{successOutcome, failureOutcome}}}})
+	exec.pushStageOutcome(StageOutcome{Groups: []GroupOutcome{
+		{InvocationResults: []HookOutcome{{SeatNonBid: []openrtb_ext.SeatNonBid{{Seat: "bidderB", NonBid: []openrtb_ext.NonBid{nonBid("imp2")}}}}}},
+	}})
+
+	assert.Equal(t, []openrtb_ext.SeatNonBid{
+		{Seat: "bidderA", NonBid: []openrtb_ext.NonBid{nonBid("imp1")}},
+		{Seat: "bidderB", NonBid: []openrtb_ext.NonBid{nonBid("imp2")}},
+	}, exec.GetSeatNonBid())
+}
+
 func TestExecuteRawBidderResponseStage(t *testing.T) {
 	foobarModuleCtx := &moduleContexts{ctxs: map[string]hookstage.ModuleContext{"foobar": nil}}
 	resp := adapters.BidderResponse{Bids: []*adapters.TypedBid{{DealPriority: 1}}}
@@ -1952,6 +2027,141 @@ func TestExecuteAuctionResponseStage(t *testing.T) {
 	}
 }
 
+func TestExecuteExitpointStage(t *testing.T) {
+	resp := &openrtb2.BidResponse{ID: "some-id"}
+
+	testCases := []struct {
+		description           string
+		givenPlanBuilder      hooks.ExecutionPlanBuilder
+		expectedResponse      any
+		expectedStatus        int
+		expectedContentType   string
+		expectedStageOutcomes []StageOutcome
+	}{
+		{
+			description:           "Payload not changed if hook execution plan empty",
+			givenPlanBuilder:      hooks.EmptyPlanBuilder{},
+			expectedResponse:      resp,
+			expectedStatus:        http.StatusOK,
+			expectedContentType:   "application/json",
+			expectedStageOutcomes: []StageOutcome{},
+		},
+		{
+			description:         "Response, headers and status changed if hooks return mutations",
+			givenPlanBuilder:    TestExitpointPlanBuilder{hook: mockUpdateExitpointHook{}},
+			expectedResponse:    []byte("<VAST></VAST>"),
+			expectedStatus:      http.StatusCreated,
+			expectedContentType: "application/xml",
+			expectedStageOutcomes: []StageOutcome{
+				{
+					Entity: entityHttpResponse,
+					Stage:  hooks.StageExitpoint.String(),
+					Groups: []GroupOutcome{
+						{
+							InvocationResults: []HookOutcome{
+								{
+									AnalyticsTags: hookanalytics.Analytics{},
+									HookID:        HookID{ModuleCode: "foobar", HookImplCode: "foo"},
+									Status:        StatusSuccess,
+									Action:        ActionUpdate,
+									Message:       "",
+									DebugMessages: []string{
+										fmt.Sprintf("Hook mutation successfully applied, affected key: exitpoint.response, mutation type: %s", hookstage.MutationUpdate),
+									},
+									Errors:   nil,
+									Warnings: nil,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			description:         "Stage execution can't be rejected - stage doesn't support rejection",
+			givenPlanBuilder:    TestExitpointPlanBuilder{hook: mockRejectHook{}},
+			expectedResponse:    resp,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedStageOutcomes: []StageOutcome{
+				{
+					Entity: entityHttpResponse,
+					Stage:  hooks.StageExitpoint.String(),
+					Groups: []GroupOutcome{
+						{
+							InvocationResults: []HookOutcome{
+								{
+									AnalyticsTags: hookanalytics.Analytics{},
+									HookID:        HookID{ModuleCode: "foobar", HookImplCode: "foo"},
+									Status:        StatusExecutionFailure,
+									Action:        "",
+									Message:       "",
+									DebugMessages: nil,
+									Errors: []string{
+										fmt.Sprintf("Module (name: foobar, hook code: foo) tried to reject request on the %s stage that does not support rejection", hooks.StageExitpoint),
+									},
+									Warnings: nil,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			exec := NewHookExecutor(test.givenPlanBuilder, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+			w := httptest.NewRecorder()
+			w.Header().Set("Content-Type", "application/json")
+
+			response, status := exec.ExecuteExitpointStage(resp, http.StatusOK, w)
+
+			assert.Equal(t, test.expectedResponse, response, "Incorrect response update.")
+			assert.Equal(t, test.expectedStatus, status, "Incorrect status code.")
+			assert.Equal(t, test.expectedContentType, w.Header().Get("Content-Type"), "Incorrect response headers.")
+
+			stageOutcomes := exec.GetOutcomes()
+			if len(test.expectedStageOutcomes) == 0 {
+				assert.Empty(t, stageOutcomes, "Incorrect stage outcomes.")
+			} else {
+				assertEqualStageOutcomes(t, test.expectedStageOutcomes[0], stageOutcomes[0])
+			}
+		})
+	}
+}
+
+func TestRaceExecuteExitpointStageTimedOutHook(t *testing.T) {
+	exec := NewHookExecutor(TestExitpointPlanBuilder{hook: mockTimeoutExitpointHook{}}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Type", "application/json")
+
+	_, status := exec.ExecuteExitpointStage(&openrtb2.BidResponse{ID: "some-id"}, http.StatusOK, w)
+	assert.Equal(t, http.StatusOK, status)
+
+	// the response is written while the hook which timed out still runs
+	for i := 0; i < 5; i++ {
+		assert.Empty(t, w.Header().Get("X-Late"), "a hook which timed out should not modify the response headers")
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+}
+
+func TestExecuteExitpointStageErrorStatus(t *testing.T) {
+	body := []byte("Invalid request: some error\n")
+
+	exec := NewHookExecutor(hooks.EmptyPlanBuilder{}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+	response, status := exec.ExecuteExitpointStage(body, http.StatusBadRequest, httptest.NewRecorder())
+	assert.Equal(t, body, response, "Incorrect response.")
+	assert.Equal(t, http.StatusBadRequest, status, "The error status should be kept without hooks.")
+
+	exec = NewHookExecutor(TestExitpointPlanBuilder{hook: mockRejectHook{}}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+	response, status = exec.ExecuteExitpointStage(body, http.StatusBadRequest, httptest.NewRecorder())
+	assert.Equal(t, body, response, "Incorrect response.")
+	assert.Equal(t, http.StatusBadRequest, status, "The error status should be kept when hooks don't change it.")
+	assert.Len(t, exec.GetOutcomes(), 1, "The exitpoint hooks should run for error responses.")
+}
 func TestInterStageContextCommunication(t *testing.T) {
 	body := []byte(`{"foo": "bar"}`)
 	reader := bytes.NewReader(body)
@@ -2169,6 +2379,22 @@ func (e TestApplyHookMutationsBuilder) PlanForAuctionResponseStage(_ string, _ *
 	}
 }
 
+type TestExitpointPlanBuilder struct {
+	hooks.EmptyPlanBuilder
+	hook hookstage.Exitpoint
+}
+
+func (e TestExitpointPlanBuilder) PlanForExitpointStage(_ string, _ *config.Account) hooks.Plan[hookstage.Exitpoint] {
+	return hooks.Plan[hookstage.Exitpoint]{
+		hooks.Group[hookstage.Exitpoint]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.Exitpoint]{
+				{Module: "foobar", Code: "foo", Hook: e.hook},
+			},
+		},
+	}
+}
+
 type TestRejectPlanBuilder struct {
 	hooks.EmptyPlanBuilder
 }
@@ -2585,6 +2811,22 @@ func (e TestWithModuleContextsPlanBuilder) PlanForAuctionResponseStage(_ string,
 	}
 }
 
+type TestPreciseGeoRestrictedBuilder struct {
+	hooks.EmptyPlanBuilder
+}
+
+func (e TestPreciseGeoRestrictedBuilder) PlanForProcessedAuctionStage(_ string, _ *config.Account) hooks.Plan[hookstage.ProcessedAuctionRequest] {
+	return hooks.Plan[hookstage.ProcessedAuctionRequest]{
+		hooks.Group[hookstage.ProcessedAuctionRequest]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.ProcessedAuctionRequest]{
+				{Module: "module-1", Code: "foo", Hook: mockPreciseGeoRestrictedHook{}},
+				{Module: "module-2", Code: "bar", Hook: mockPreciseGeoRestrictedHook{}},
+			},
+		},
+	}
+}
+
 type TestAllHookResultsBuilder struct {
 	hooks.EmptyPlanBuilder
 }
@@ -3,6 +3,7 @@ package hookexecution
 import (
 	"context"
 	"errors"
+	"net/http"
 	"time"
 
 	"github.com/prebid/prebid-server/v2/hooks/hookstage"
@@ -102,6 +103,10 @@ func (e mockRejectHook) HandleAuctionResponseHook(_ context.Context, _ hookstage
 	return hookstage.HookResult[hookstage.AuctionResponsePayload]{Reject: true}, nil
 }
 
+func (e mockRejectHook) HandleExitpointHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.ExitpointPayload) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+	return hookstage.HookResult[hookstage.ExitpointPayload]{Reject: true}, nil
+}
+
 type mockTimeoutHook struct{}
 
 func (e mockTimeoutHook) HandleEntrypointHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.EntrypointPayload) (hookstage.HookResult[hookstage.EntrypointPayload], error) {
@@ -222,6 +227,12 @@ func (e mockModuleContextHook) HandleAuctionResponseHook(_ context.Context, miCt
 	return hookstage.HookResult[hookstage.AuctionResponsePayload]{ModuleContext: miCtx.ModuleContext}, nil
 }
 
+type mockPreciseGeoRestrictedHook struct{}
+
+func (e mockPreciseGeoRestrictedHook) HandleProcessedAuctionHook(_ context.Context, miCtx hookstage.ModuleInvocationContext, _ hookstage.ProcessedAuctionRequestPayload) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	return hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{ModuleContext: map[string]interface{}{"precise-geo-restricted": miCtx.PreciseGeoRestricted}}, nil
+}
+
 type mockFailureHook struct{}
 
 func (h mockFailureHook) HandleEntrypointHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.EntrypointPayload) (hookstage.HookResult[hookstage.EntrypointPayload], error) {
@@ -388,3 +399,27 @@ func (e mockUpdateBidResponseHook) HandleAuctionResponseHook(_ context.Context,
 
 	return hookstage.HookResult[hookstage.AuctionResponsePayload]{ChangeSet: c}, nil
 }
+
+type mockTimeoutExitpointHook struct{}
+
+func (e mockTimeoutExitpointHook) HandleExitpointHook(_ context.Context, _ hookstage.ModuleInvocationContext, payload hookstage.ExitpointPayload) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+	time.Sleep(20 * time.Millisecond)
+	payload.Headers.Set("X-Late", "true")
+	return hookstage.HookResult[hookstage.ExitpointPayload]{}, nil
+}
+
+type mockUpdateExitpointHook struct{}
+
+func (e mockUpdateExitpointHook) HandleExitpointHook(_ context.Context, _ hookstage.ModuleInvocationContext, _ hookstage.ExitpointPayload) (hookstage.HookResult[hookstage.ExitpointPayload], error) {
+	c := hookstage.ChangeSet[hookstage.ExitpointPayload]{}
+	c.AddMutation(
+		func(payload hookstage.ExitpointPayload) (hookstage.ExitpointPayload, error) {
+			payload.Headers.Set("Content-Type", "application/xml")
+			payload.StatusCode = http.StatusCreated
+			payload.Response = []byte("<VAST></VAST>")
+			return payload, nil
+		}, hookstage.MutationUpdate, "exitpoint", "response",
+	)
+
+	return hookstage.HookResult[hookstage.ExitpointPayload]{ChangeSet: c}, nil
+}
@@ -0,0 +1,40 @@
+package hookstage
+
+import (
+	"context"
+	"net/http"
+)
+
+// Exitpoint hooks are invoked at the very end of request processing,
+// right before the response is serialized and sent to the client.
+// The hooks are invoked even if the request was rejected at earlier stages.
+//
+// At this stage, account config is available,
+// so it can be configured at the account-level execution plan,
+// the account-level module config is passed to hooks.
+//
+// Rejection has no effect and is completely ignored at this stage.
+type Exitpoint interface {
+	HandleExitpointHook(
+		context.Context,
+		ModuleInvocationContext,
+		ExitpointPayload,
+	) (HookResult[ExitpointPayload], error)
+}
+
+// ExitpointPayload consists of the endpoint-specific response object,
+// the response headers and the HTTP status code that will be sent back to the requester.
+//
+// The Response holds *openrtb2.BidResponse for "/openrtb2/auction" endpoint,
+// AmpResponse for "/openrtb2/amp" endpoint and *openrtb_ext.BidResponseVideo for "/openrtb2/video" endpoint.
+// Hooks are allowed to replace the Response with any other value using mutations.
+// A []byte value is written to the client as is, any other value is serialized to JSON.
+//
+// Hooks are allowed to modify the Headers and the StatusCode using mutations
+// to wrap the response in a custom envelope (ex. VAST XML).
+// Each hook gets its own copy of the Headers, changes made outside of mutations are discarded.
+type ExitpointPayload struct {
+	Response   any
+	Headers    http.Header
+	StatusCode int
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/prebid/prebid-server/v2/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
 )
 
 // HookResult represents the result of execution the concrete hook instance.
@@ -16,7 +17,8 @@ type HookResult[T any] struct {
 	Warnings      []string
 	DebugMessages []string
 	AnalyticsTags hookanalytics.Analytics
-	ModuleContext ModuleContext // holds values that the module wants to pass to itself at later stages
+	ModuleContext ModuleContext            // holds values that the module wants to pass to itself at later stages
+	SeatNonBid    []openrtb_ext.SeatNonBid // bids rejected by the hook, reported in the seatnonbid of the response
 }
 
 // ModuleInvocationContext holds data passed to the module hook during invocation.
@@ -27,6 +29,9 @@ type ModuleInvocationContext struct {
 	Endpoint string
 	// ModuleContext holds values that the module passes to itself from the previous stages.
 	ModuleContext ModuleContext
+	// PreciseGeoRestricted is true when the activity controls deny the hook the transmitPreciseGeo activity,
+	// the device ip and the geo of the bid request passed to the hook are then already scrubbed.
+	PreciseGeoRestricted bool
 }
 
 // ModuleContext holds arbitrary data passed between module hooks at different stages.
@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"net/http"
+	"os"
 	"path/filepath"
 	"runtime"
 	"time"
@@ -20,6 +21,8 @@ import (
 	"github.com/spf13/viper"
 )
 
+var replayFile = flag.String("replay", "", "replay the auctions captured in the given file and write the responses to stdout instead of serving requests")
+
 func init() {
 	jsoniter.RegisterExtension(&jsonutil.RawMessageExtension{})
 }
@@ -49,6 +52,13 @@ func main() {
 	garbageCollectionThreshold := make([]byte, cfg.GarbageCollectorThreshold)
 	defer runtime.KeepAlive(garbageCollectionThreshold)
 
+	if *replayFile != "" {
+		if err := replay(cfg, *replayFile, os.Stdout); err != nil {
+			glog.Exitf("Unable to replay the captured auctions: %v", err)
+		}
+		return
+	}
+
 	err = serve(cfg)
 	if err != nil {
 		glog.Exitf("prebid-server failed: %v", err)
@@ -280,6 +280,55 @@ func (me *MultiMetricsEngine) RecordAdapterGDPRRequestBlocked(adapter openrtb_ex
 	}
 }
 
+// RecordAdapterCircuitBreakerOpened across all engines
+func (me *MultiMetricsEngine) RecordAdapterCircuitBreakerOpened(adapter openrtb_ext.BidderName) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterCircuitBreakerOpened(adapter)
+	}
+}
+
+// RecordAdapterCircuitBreakerRejected across all engines
+func (me *MultiMetricsEngine) RecordAdapterCircuitBreakerRejected(adapter openrtb_ext.BidderName) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterCircuitBreakerRejected(adapter)
+	}
+}
+
+// RecordAdapterTrafficShaped across all engines
+func (me *MultiMetricsEngine) RecordAdapterTrafficShaped(adapter openrtb_ext.BidderName) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterTrafficShaped(adapter)
+	}
+}
+
+// RecordAdapterBidResponseCacheResult across all engines
+func (me *MultiMetricsEngine) RecordAdapterBidResponseCacheResult(adapter openrtb_ext.BidderName, cacheResult metrics.CacheResult) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterBidResponseCacheResult(adapter, cacheResult)
+	}
+}
+
+// RecordAdapterOpenConnections across all engines
+func (me *MultiMetricsEngine) RecordAdapterOpenConnections(adapter openrtb_ext.BidderName, connections int) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterOpenConnections(adapter, connections)
+	}
+}
+
+// RecordAdapterShadowRequest across all engines
+func (me *MultiMetricsEngine) RecordAdapterShadowRequest(adapter openrtb_ext.BidderName, hasBids bool) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterShadowRequest(adapter, hasBids)
+	}
+}
+
+// RecordAdapterShadowPrice across all engines
+func (me *MultiMetricsEngine) RecordAdapterShadowPrice(adapter openrtb_ext.BidderName, cpm float64) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterShadowPrice(adapter, cpm)
+	}
+}
+
 // RecordDebugRequest across all engines
 func (me *MultiMetricsEngine) RecordDebugRequest(debugEnabled bool, pubId string) {
 	for _, thisME := range *me {
@@ -499,6 +548,34 @@ func (me *NilMetricsEngine) RecordAdapterBuyerUIDScrubbed(adapter openrtb_ext.Bi
 func (me *NilMetricsEngine) RecordAdapterGDPRRequestBlocked(adapter openrtb_ext.BidderName) {
 }
 
+// RecordAdapterCircuitBreakerOpened as a noop
+func (me *NilMetricsEngine) RecordAdapterCircuitBreakerOpened(adapter openrtb_ext.BidderName) {
+}
+
+// RecordAdapterCircuitBreakerRejected as a noop
+func (me *NilMetricsEngine) RecordAdapterCircuitBreakerRejected(adapter openrtb_ext.BidderName) {
+}
+
+// RecordAdapterTrafficShaped as a noop
+func (me *NilMetricsEngine) RecordAdapterTrafficShaped(adapter openrtb_ext.BidderName) {
+}
+
+// RecordAdapterBidResponseCacheResult as a noop
+func (me *NilMetricsEngine) RecordAdapterBidResponseCacheResult(adapter openrtb_ext.BidderName, cacheResult metrics.CacheResult) {
+}
+
+// RecordAdapterOpenConnections as a noop
+func (me *NilMetricsEngine) RecordAdapterOpenConnections(adapter openrtb_ext.BidderName, connections int) {
+}
+
+// RecordAdapterShadowRequest as a noop
+func (me *NilMetricsEngine) RecordAdapterShadowRequest(adapter openrtb_ext.BidderName, hasBids bool) {
+}
+
+// RecordAdapterShadowPrice as a noop
+func (me *NilMetricsEngine) RecordAdapterShadowPrice(adapter openrtb_ext.BidderName, cpm float64) {
+}
+
 // RecordDebugRequest as a noop
 func (me *NilMetricsEngine) RecordDebugRequest(debugEnabled bool, pubId string) {
 }
@@ -102,6 +102,20 @@ type AdapterMetrics struct {
 	BuyerUIDScrubbed   metrics.Meter
 	GDPRRequestBlocked metrics.Meter
 
+	CircuitBreakerOpenedMeter   metrics.Meter
+	CircuitBreakerRejectedMeter metrics.Meter
+
+	TrafficShapedMeter metrics.Meter
+
+	BidResponseCacheHitMeter  metrics.Meter
+	BidResponseCacheMissMeter metrics.Meter
+
+	OpenConnectionsGauge metrics.Gauge
+
+	ShadowNoBidMeter     metrics.Meter
+	ShadowGotBidsMeter   metrics.Meter
+	ShadowPriceHistogram metrics.Histogram
+
 	BidValidationCreativeSizeErrorMeter metrics.Meter
 	BidValidationCreativeSizeWarnMeter  metrics.Meter
 
@@ -401,6 +415,20 @@ func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMet
 		BidsReceivedMeter: blankMeter,
 		PanicMeter:        blankMeter,
 		MarkupMetrics:     makeBlankBidMarkupMetrics(),
+
+		CircuitBreakerOpenedMeter:   blankMeter,
+		CircuitBreakerRejectedMeter: blankMeter,
+
+		TrafficShapedMeter: blankMeter,
+
+		BidResponseCacheHitMeter:  blankMeter,
+		BidResponseCacheMissMeter: blankMeter,
+
+		OpenConnectionsGauge: &metrics.NilGauge{},
+
+		ShadowNoBidMeter:     blankMeter,
+		ShadowGotBidsMeter:   blankMeter,
+		ShadowPriceHistogram: &metrics.NilHistogram{},
 	}
 	if !disabledMetrics.AdapterConnectionMetrics {
 		newAdapter.ConnCreated = metrics.NilCounter{}
@@ -490,6 +518,15 @@ func registerAdapterMetrics(registry metrics.Registry, adapterOrAccount string,
 	am.PanicMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.requests.panic", adapterOrAccount, exchange), registry)
 	am.BuyerUIDScrubbed = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.buyeruid_scrubbed", adapterOrAccount, exchange), registry)
 	am.GDPRRequestBlocked = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.gdpr_request_blocked", adapterOrAccount, exchange), registry)
+	am.CircuitBreakerOpenedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.circuit_breaker.opened", adapterOrAccount, exchange), registry)
+	am.CircuitBreakerRejectedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.circuit_breaker.rejected", adapterOrAccount, exchange), registry)
+	am.TrafficShapedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.requests.traffic_shaped", adapterOrAccount, exchange), registry)
+	am.BidResponseCacheHitMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.response_cache.hit", adapterOrAccount, exchange), registry)
+	am.BidResponseCacheMissMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.response_cache.miss", adapterOrAccount, exchange), registry)
+	am.OpenConnectionsGauge = metrics.GetOrRegisterGauge(fmt.Sprintf("%[1]s.%[2]s.connections_open", adapterOrAccount, exchange), registry)
+	am.ShadowNoBidMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.shadow.requests.nobid", adapterOrAccount, exchange), registry)
+	am.ShadowGotBidsMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.shadow.requests.gotbids", adapterOrAccount, exchange), registry)
+	am.ShadowPriceHistogram = metrics.GetOrRegisterHistogram(fmt.Sprintf("%[1]s.%[2]s.shadow.prices", adapterOrAccount, exchange), registry, metrics.NewExpDecaySample(1028, 0.015))
 
 	am.BidValidationCreativeSizeErrorMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.response.validation.size.err", adapterOrAccount, exchange), registry)
 	am.BidValidationCreativeSizeWarnMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.response.validation.size.warn", adapterOrAccount, exchange), registry)
@@ -603,6 +640,11 @@ func (me *Metrics) RecordRequest(labels Labels) {
 	// Handle the account metrics now.
 	am := me.getAccountMetrics(labels.PubID)
 	am.requestMeter.Mark(1)
+
+	// experiment arms are dynamic per account, so their meters are registered on first use
+	if labels.Experiment != "" && labels.PubID != PublisherUnknown {
+		metrics.GetOrRegisterMeter(fmt.Sprintf("account.%s.experiment.%s.%s.requests.%s", labels.PubID, labels.Experiment, labels.ExperimentArm, labels.RequestStatus), me.MetricsRegistry).Mark(1)
+	}
 }
 
 func (me *Metrics) RecordDebugRequest(debugEnabled bool, pubID string) {
@@ -961,6 +1003,91 @@ func (me *Metrics) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.Bidde
 	am.GDPRRequestBlocked.Mark(1)
 }
 
+func (me *Metrics) RecordAdapterCircuitBreakerOpened(adapterName openrtb_ext.BidderName) {
+	adapterStr := string(adapterName)
+	am, ok := me.AdapterMetrics[strings.ToLower(adapterStr)]
+	if !ok {
+		glog.Errorf("Trying to log adapter circuit breaker opened metric for %s: adapter not found", adapterStr)
+		return
+	}
+
+	am.CircuitBreakerOpenedMeter.Mark(1)
+}
+
+func (me *Metrics) RecordAdapterCircuitBreakerRejected(adapterName openrtb_ext.BidderName) {
+	adapterStr := string(adapterName)
+	am, ok := me.AdapterMetrics[strings.ToLower(adapterStr)]
+	if !ok {
+		glog.Errorf("Trying to log adapter circuit breaker rejected metric for %s: adapter not found", adapterStr)
+		return
+	}
+
+	am.CircuitBreakerRejectedMeter.Mark(1)
+}
+
+func (me *Metrics) RecordAdapterTrafficShaped(adapterName openrtb_ext.BidderName) {
+	adapterStr := string(adapterName)
+	am, ok := me.AdapterMetrics[strings.ToLower(adapterStr)]
+	if !ok {
+		glog.Errorf("Trying to log adapter traffic shaped metric for %s: adapter not found", adapterStr)
+		return
+	}
+
+	am.TrafficShapedMeter.Mark(1)
+}
+
+func (me *Metrics) RecordAdapterBidResponseCacheResult(adapterName openrtb_ext.BidderName, cacheResult CacheResult) {
+	adapterStr := string(adapterName)
+	am, ok := me.AdapterMetrics[strings.ToLower(adapterStr)]
+	if !ok {
+		glog.Errorf("Trying to log adapter bid response cache metric for %s: adapter not found", adapterStr)
+		return
+	}
+
+	if cacheResult == CacheHit {
+		am.BidResponseCacheHitMeter.Mark(1)
+	} else {
+		am.BidResponseCacheMissMeter.Mark(1)
+	}
+}
+
+func (me *Metrics) RecordAdapterOpenConnections(adapterName openrtb_ext.BidderName, connections int) {
+	adapterStr := string(adapterName)
+	am, ok := me.AdapterMetrics[strings.ToLower(adapterStr)]
+	if !ok {
+		glog.Errorf("Trying to log adapter open connections metric for %s: adapter not found", adapterStr)
+		return
+	}
+
+	am.OpenConnectionsGauge.Update(int64(connections))
+}
+
+func (me *Metrics) RecordAdapterShadowRequest(adapterName openrtb_ext.BidderName, hasBids bool) {
+	adapterStr := string(adapterName)
+	am, ok := me.AdapterMetrics[strings.ToLower(adapterStr)]
+	if !ok {
+		glog.Errorf("Trying to log adapter shadow request metric for %s: adapter not found", adapterStr)
+		return
+	}
+
+	if hasBids {
+		am.ShadowGotBidsMeter.Mark(1)
+	} else {
+		am.ShadowNoBidMeter.Mark(1)
+	}
+}
+
+func (me *Metrics) RecordAdapterShadowPrice(adapterName openrtb_ext.BidderName, cpm float64) {
+	adapterStr := string(adapterName)
+	am, ok := me.AdapterMetrics[strings.ToLower(adapterStr)]
+	if !ok {
+		glog.Errorf("Trying to log adapter shadow price metric for %s: adapter not found", adapterStr)
+		return
+	}
+
+	am.ShadowPriceHistogram.Update(int64(cpm))
+}
+
 func (me *Metrics) RecordAdsCertReq(success bool) {
 	if success {
 		me.AdsCertRequestsSuccess.Mark(1)
@@ -289,6 +289,20 @@ func TestRecordDebugRequest(t *testing.T) {
 	}
 }
 
+func TestRecordRequestWithExperiment(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordRequest(Labels{RType: ReqTypeORTB2Web, RequestStatus: RequestStatusOK, PubID: "pub", Experiment: "floors", ExperimentArm: "test"})
+	m.RecordRequest(Labels{RType: ReqTypeORTB2Web, RequestStatus: RequestStatusOK, PubID: PublisherUnknown, Experiment: "floors", ExperimentArm: "test"})
+
+	meter, ok := registry.Get("account.pub.experiment.floors.test.requests.ok").(metrics.Meter)
+	if assert.True(t, ok, "the experiment arm meter should be registered") {
+		assert.Equal(t, int64(1), meter.Count())
+	}
+	assert.Nil(t, registry.Get("account.unknown.experiment.floors.test.requests.ok"))
+}
+
 func TestRecordBidValidationCreativeSize(t *testing.T) {
 	testCases := []struct {
 		description          string
@@ -874,6 +888,102 @@ func TestRecordAdapterGDPRRequestBlocked(t *testing.T) {
 	}
 }
 
+func TestRecordAdapterCircuitBreaker(t *testing.T) {
+	var fakeBidder openrtb_ext.BidderName = "fooAdvertising"
+	adapter := "AnyName"
+	lowerCaseAdapterName := "anyname"
+
+	tests := []struct {
+		name            string
+		adapterName     openrtb_ext.BidderName
+		expectedOpened  int64
+		expectedRejects int64
+	}{
+		{
+			name:            "bidder_found",
+			adapterName:     openrtb_ext.BidderName(adapter),
+			expectedOpened:  1,
+			expectedRejects: 1,
+		},
+		{
+			name:            "bidder_not_found",
+			adapterName:     fakeBidder,
+			expectedOpened:  0,
+			expectedRejects: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := metrics.NewRegistry()
+			m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName(adapter)}, config.DisabledMetrics{}, nil, nil)
+
+			m.RecordAdapterCircuitBreakerOpened(tt.adapterName)
+			m.RecordAdapterCircuitBreakerRejected(tt.adapterName)
+
+			assert.Equal(t, tt.expectedOpened, m.AdapterMetrics[lowerCaseAdapterName].CircuitBreakerOpenedMeter.Count())
+			assert.Equal(t, tt.expectedRejects, m.AdapterMetrics[lowerCaseAdapterName].CircuitBreakerRejectedMeter.Count())
+		})
+	}
+}
+
+func TestRecordAdapterTrafficShaped(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("AnyName")}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordAdapterTrafficShaped(openrtb_ext.BidderName("AnyName"))
+	m.RecordAdapterTrafficShaped(openrtb_ext.BidderName("fooAdvertising"))
+
+	assert.Equal(t, int64(1), m.AdapterMetrics["anyname"].TrafficShapedMeter.Count())
+}
+
+func TestRecordAdapterBidResponseCacheResult(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("AnyName")}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordAdapterBidResponseCacheResult(openrtb_ext.BidderName("AnyName"), CacheHit)
+	m.RecordAdapterBidResponseCacheResult(openrtb_ext.BidderName("AnyName"), CacheMiss)
+	m.RecordAdapterBidResponseCacheResult(openrtb_ext.BidderName("AnyName"), CacheMiss)
+	m.RecordAdapterBidResponseCacheResult(openrtb_ext.BidderName("fooAdvertising"), CacheHit)
+
+	assert.Equal(t, int64(1), m.AdapterMetrics["anyname"].BidResponseCacheHitMeter.Count())
+	assert.Equal(t, int64(2), m.AdapterMetrics["anyname"].BidResponseCacheMissMeter.Count())
+}
+
+func TestRecordAdapterOpenConnections(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("AnyName")}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordAdapterOpenConnections(openrtb_ext.BidderName("AnyName"), 3)
+	m.RecordAdapterOpenConnections(openrtb_ext.BidderName("AnyName"), 2)
+	m.RecordAdapterOpenConnections(openrtb_ext.BidderName("fooAdvertising"), 1)
+
+	assert.Equal(t, int64(2), m.AdapterMetrics["anyname"].OpenConnectionsGauge.Value())
+}
+
+func TestRecordAdapterShadowRequest(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("AnyName")}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordAdapterShadowRequest(openrtb_ext.BidderName("AnyName"), true)
+	m.RecordAdapterShadowRequest(openrtb_ext.BidderName("AnyName"), true)
+	m.RecordAdapterShadowRequest(openrtb_ext.BidderName("AnyName"), false)
+	m.RecordAdapterShadowRequest(openrtb_ext.BidderName("fooAdvertising"), true)
+
+	assert.Equal(t, int64(2), m.AdapterMetrics["anyname"].ShadowGotBidsMeter.Count())
+	assert.Equal(t, int64(1), m.AdapterMetrics["anyname"].ShadowNoBidMeter.Count())
+}
+
+func TestRecordAdapterShadowPrice(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("AnyName")}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordAdapterShadowPrice(openrtb_ext.BidderName("AnyName"), 42)
+	m.RecordAdapterShadowPrice(openrtb_ext.BidderName("fooAdvertising"), 10)
+
+	assert.Equal(t, int64(1), m.AdapterMetrics["anyname"].ShadowPriceHistogram.Count())
+	assert.Equal(t, int64(42), m.AdapterMetrics["anyname"].ShadowPriceHistogram.Sum())
+}
+
 func TestRecordCookieSync(t *testing.T) {
 	registry := metrics.NewRegistry()
 	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("Foo"), openrtb_ext.BidderName("Bar")}, config.DisabledMetrics{}, nil, nil)
@@ -13,6 +13,9 @@ type Labels struct {
 	PubID         string // exchange specific ID, so we cannot compile in values
 	CookieFlag    CookieFlag
 	RequestStatus RequestStatus
+	// Experiment and ExperimentArm identify the arm of the account experiment the request was assigned to, if any
+	Experiment    string
+	ExperimentArm string
 }
 
 // AdapterLabels defines the labels that can be attached to the adapter metrics.
@@ -457,6 +460,13 @@ type MetricsEngine interface {
 	RecordRequestPrivacy(privacy PrivacyLabels)
 	RecordAdapterBuyerUIDScrubbed(adapterName openrtb_ext.BidderName)
 	RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.BidderName)
+	RecordAdapterCircuitBreakerOpened(adapterName openrtb_ext.BidderName)
+	RecordAdapterCircuitBreakerRejected(adapterName openrtb_ext.BidderName)
+	RecordAdapterTrafficShaped(adapterName openrtb_ext.BidderName)
+	RecordAdapterBidResponseCacheResult(adapterName openrtb_ext.BidderName, cacheResult CacheResult)
+	RecordAdapterOpenConnections(adapterName openrtb_ext.BidderName, connections int)
+	RecordAdapterShadowRequest(adapterName openrtb_ext.BidderName, hasBids bool)
+	RecordAdapterShadowPrice(adapterName openrtb_ext.BidderName, cpm float64)
 	RecordDebugRequest(debugEnabled bool, pubId string)
 	RecordStoredResponse(pubId string)
 	RecordAdsCertReq(success bool)
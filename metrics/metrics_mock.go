@@ -166,6 +166,41 @@ func (me *MetricsEngineMock) RecordAdapterGDPRRequestBlocked(adapterName openrtb
 	me.Called(adapterName)
 }
 
+// RecordAdapterCircuitBreakerOpened mock
+func (me *MetricsEngineMock) RecordAdapterCircuitBreakerOpened(adapterName openrtb_ext.BidderName) {
+	me.Called(adapterName)
+}
+
+// RecordAdapterCircuitBreakerRejected mock
+func (me *MetricsEngineMock) RecordAdapterCircuitBreakerRejected(adapterName openrtb_ext.BidderName) {
+	me.Called(adapterName)
+}
+
+// RecordAdapterTrafficShaped mock
+func (me *MetricsEngineMock) RecordAdapterTrafficShaped(adapterName openrtb_ext.BidderName) {
+	me.Called(adapterName)
+}
+
+// RecordAdapterBidResponseCacheResult mock
+func (me *MetricsEngineMock) RecordAdapterBidResponseCacheResult(adapterName openrtb_ext.BidderName, cacheResult CacheResult) {
+	me.Called(adapterName, cacheResult)
+}
+
+// RecordAdapterOpenConnections mock
+func (me *MetricsEngineMock) RecordAdapterOpenConnections(adapterName openrtb_ext.BidderName, connections int) {
+	me.Called(adapterName, connections)
+}
+
+// RecordAdapterShadowRequest mock
+func (me *MetricsEngineMock) RecordAdapterShadowRequest(adapterName openrtb_ext.BidderName, hasBids bool) {
+	me.Called(adapterName, hasBids)
+}
+
+// RecordAdapterShadowPrice mock
+func (me *MetricsEngineMock) RecordAdapterShadowPrice(adapterName openrtb_ext.BidderName, cpm float64) {
+	me.Called(adapterName, cpm)
+}
+
 // RecordDebugRequest mock
 func (me *MetricsEngineMock) RecordDebugRequest(debugEnabled bool, pubId string) {
 	me.Called(debugEnabled, pubId)
@@ -72,6 +72,14 @@ type Metrics struct {
 	adapterConnectionWaitTime             *prometheus.HistogramVec
 	adapterScrubbedBuyerUIDs              *prometheus.CounterVec
 	adapterGDPRBlockedRequests            *prometheus.CounterVec
+	adapterCircuitBreakerOpened           *prometheus.CounterVec
+	adapterCircuitBreakerRejected         *prometheus.CounterVec
+	adapterTrafficShaped                  *prometheus.CounterVec
+	adapterBidResponseCache               *prometheus.CounterVec
+	adapterOpenConnections                *prometheus.GaugeVec
+	adapterShadowRequests                 *prometheus.CounterVec
+	experimentRequests                    *prometheus.CounterVec
+	adapterShadowPrices                   *prometheus.HistogramVec
 	adapterBidResponseValidationSizeError *prometheus.CounterVec
 	adapterBidResponseValidationSizeWarn  *prometheus.CounterVec
 	adapterBidResponseSecureMarkupError   *prometheus.CounterVec
@@ -112,6 +120,8 @@ const (
 	cacheResultLabel     = "cache_result"
 	connectionErrorLabel = "connection_error"
 	cookieLabel          = "cookie"
+	experimentArmLabel   = "experiment_arm"
+	experimentLabel      = "experiment"
 	hasBidsLabel         = "has_bids"
 	isAudioLabel         = "audio"
 	isBannerLabel        = "banner"
@@ -348,6 +358,53 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 			[]string{adapterLabel})
 	}
 
+	// circuit breaker metrics are not preloaded as only a few bidders are expected to enable it
+	metrics.adapterCircuitBreakerOpened = newCounter(cfg, reg,
+		"adapter_circuit_breaker_opened",
+		"Count of times the circuit breaker of a bidder opened due to failed requests",
+		[]string{adapterLabel})
+
+	metrics.adapterCircuitBreakerRejected = newCounter(cfg, reg,
+		"adapter_circuit_breaker_rejected",
+		"Count of total bidder requests not sent because the circuit breaker of the bidder was open",
+		[]string{adapterLabel})
+
+	// not preloaded as it is only recorded when traffic shaping is enabled
+	metrics.adapterTrafficShaped = newCounter(cfg, reg,
+		"adapter_traffic_shaped",
+		"Count of total bidder requests not sent because the bidder was unlikely to bid",
+		[]string{adapterLabel})
+
+	// not preloaded as only a few bidders are expected to enable their response cache
+	metrics.adapterBidResponseCache = newCounter(cfg, reg,
+		"adapter_bid_response_cache",
+		"Count of bidder requests looked up in the bidder response cache labeled by cache result",
+		[]string{adapterLabel, cacheResultLabel})
+
+	// not preloaded as only bidders with a dedicated http client report their open connections
+	metrics.adapterOpenConnections = newGaugeVec(cfg, reg,
+		"adapter_open_connections",
+		"Count of open connections in the dedicated connection pool of a bidder",
+		[]string{adapterLabel})
+
+	// not preloaded as only the bidders listed by accounts as shadow bidders are recorded
+	metrics.adapterShadowRequests = newCounter(cfg, reg,
+		"adapter_shadow_requests",
+		"Count of requests to shadow bidders labeled by adapter and if it resulted in bids.",
+		[]string{adapterLabel, hasBidsLabel})
+
+	metrics.adapterShadowPrices = newHistogramVec(cfg, reg,
+		"adapter_shadow_prices",
+		"Monetary value of the bids of shadow bidders labeled by adapter.",
+		[]string{adapterLabel},
+		priceBuckets)
+
+	// not preloaded as experiments and their arms are defined by the accounts
+	metrics.experimentRequests = newCounter(cfg, reg,
+		"experiment_requests",
+		"Count of requests assigned to an arm of an account experiment labeled by account, experiment, arm and status.",
+		[]string{accountLabel, experimentLabel, experimentArmLabel, requestStatusLabel})
+
 	metrics.storedResponsesFetchTimer = newHistogramVec(cfg, reg,
 		"stored_response_fetch_time_seconds",
 		"Seconds to fetch stored responses labeled by fetch type",
@@ -605,6 +662,18 @@ func newCounterWithoutLabels(cfg config.PrometheusMetrics, registry *prometheus.
 	return counter
 }
 
+func newGaugeVec(cfg config.PrometheusMetrics, registry *prometheus.Registry, name, help string, labels []string) *prometheus.GaugeVec {
+	opts := prometheus.GaugeOpts{
+		Namespace: cfg.Namespace,
+		Subsystem: cfg.Subsystem,
+		Name:      name,
+		Help:      help,
+	}
+	gauge := prometheus.NewGaugeVec(opts, labels)
+	registry.MustRegister(gauge)
+	return gauge
+}
+
 func newHistogramVec(cfg config.PrometheusMetrics, registry *prometheus.Registry, name, help string, labels []string, buckets []float64) *prometheus.HistogramVec {
 	opts := prometheus.HistogramOpts{
 		Namespace: cfg.Namespace,
@@ -671,6 +740,15 @@ func (m *Metrics) RecordRequest(labels metrics.Labels) {
 		m.accountRequests.With(prometheus.Labels{
 			accountLabel: labels.PubID,
 		}).Inc()
+
+		if labels.Experiment != "" {
+			m.experimentRequests.With(prometheus.Labels{
+				accountLabel:       labels.PubID,
+				experimentLabel:    labels.Experiment,
+				experimentArmLabel: labels.ExperimentArm,
+				requestStatusLabel: string(labels.RequestStatus),
+			}).Inc()
+		}
 	}
 }
 
@@ -979,6 +1057,50 @@ func (m *Metrics) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.Bidder
 	}).Inc()
 }
 
+func (m *Metrics) RecordAdapterCircuitBreakerOpened(adapterName openrtb_ext.BidderName) {
+	m.adapterCircuitBreakerOpened.With(prometheus.Labels{
+		adapterLabel: strings.ToLower(string(adapterName)),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterCircuitBreakerRejected(adapterName openrtb_ext.BidderName) {
+	m.adapterCircuitBreakerRejected.With(prometheus.Labels{
+		adapterLabel: strings.ToLower(string(adapterName)),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterTrafficShaped(adapterName openrtb_ext.BidderName) {
+	m.adapterTrafficShaped.With(prometheus.Labels{
+		adapterLabel: strings.ToLower(string(adapterName)),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterBidResponseCacheResult(adapterName openrtb_ext.BidderName, cacheResult metrics.CacheResult) {
+	m.adapterBidResponseCache.With(prometheus.Labels{
+		adapterLabel:     strings.ToLower(string(adapterName)),
+		cacheResultLabel: string(cacheResult),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterOpenConnections(adapterName openrtb_ext.BidderName, connections int) {
+	m.adapterOpenConnections.With(prometheus.Labels{
+		adapterLabel: strings.ToLower(string(adapterName)),
+	}).Set(float64(connections))
+}
+
+func (m *Metrics) RecordAdapterShadowRequest(adapterName openrtb_ext.BidderName, hasBids bool) {
+	m.adapterShadowRequests.With(prometheus.Labels{
+		adapterLabel: strings.ToLower(string(adapterName)),
+		hasBidsLabel: strconv.FormatBool(hasBids),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterShadowPrice(adapterName openrtb_ext.BidderName, cpm float64) {
+	m.adapterShadowPrices.With(prometheus.Labels{
+		adapterLabel: strings.ToLower(string(adapterName)),
+	}).Observe(cpm)
+}
+
 func (m *Metrics) RecordAdsCertReq(success bool) {
 	if success {
 		m.adsCertRequests.With(prometheus.Labels{
@@ -157,6 +157,27 @@ func TestRequestMetric(t *testing.T) {
 		})
 }
 
+func TestRequestMetricWithExperiment(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordRequest(metrics.Labels{
+		RType:         metrics.ReqTypeORTB2Web,
+		RequestStatus: metrics.RequestStatusOK,
+		PubID:         "pub",
+		Experiment:    "floors",
+		ExperimentArm: "test",
+	})
+
+	assertCounterVecValue(t, "", "experimentRequests", m.experimentRequests,
+		float64(1),
+		prometheus.Labels{
+			accountLabel:       "pub",
+			experimentLabel:    "floors",
+			experimentArmLabel: "test",
+			requestStatusLabel: string(metrics.RequestStatusOK),
+		})
+}
+
 func TestDebugRequestMetric(t *testing.T) {
 	testCases := []struct {
 		description                      string
@@ -2028,3 +2049,99 @@ func TestRecordModuleMetrics(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordAdapterCircuitBreaker(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := openrtb_ext.BidderName("AnyName")
+	lowerCasedAdapterName := "anyname"
+	m.RecordAdapterCircuitBreakerOpened(adapterName)
+	m.RecordAdapterCircuitBreakerRejected(adapterName)
+	m.RecordAdapterCircuitBreakerRejected(adapterName)
+
+	assertCounterVecValue(t,
+		"Increment adapter circuit breaker opened counter",
+		"adapter_circuit_breaker_opened",
+		m.adapterCircuitBreakerOpened,
+		1,
+		prometheus.Labels{
+			adapterLabel: lowerCasedAdapterName,
+		})
+	assertCounterVecValue(t,
+		"Increment adapter circuit breaker rejected counter",
+		"adapter_circuit_breaker_rejected",
+		m.adapterCircuitBreakerRejected,
+		2,
+		prometheus.Labels{
+			adapterLabel: lowerCasedAdapterName,
+		})
+}
+
+func TestRecordAdapterTrafficShaped(t *testing.T) {
+	m := createMetricsForTesting()
+	m.RecordAdapterTrafficShaped(openrtb_ext.BidderName("AnyName"))
+
+	assertCounterVecValue(t,
+		"Increment adapter traffic shaped counter",
+		"adapter_traffic_shaped",
+		m.adapterTrafficShaped,
+		1,
+		prometheus.Labels{
+			adapterLabel: "anyname",
+		})
+}
+
+func TestRecordAdapterBidResponseCacheResult(t *testing.T) {
+	m := createMetricsForTesting()
+	m.RecordAdapterBidResponseCacheResult(openrtb_ext.BidderName("AnyName"), metrics.CacheHit)
+	m.RecordAdapterBidResponseCacheResult(openrtb_ext.BidderName("AnyName"), metrics.CacheMiss)
+	m.RecordAdapterBidResponseCacheResult(openrtb_ext.BidderName("AnyName"), metrics.CacheMiss)
+
+	assertCounterVecValue(t,
+		"Increment adapter bid response cache hit counter",
+		"adapter_bid_response_cache:hit",
+		m.adapterBidResponseCache,
+		1,
+		prometheus.Labels{
+			adapterLabel:     "anyname",
+			cacheResultLabel: string(metrics.CacheHit),
+		})
+	assertCounterVecValue(t,
+		"Increment adapter bid response cache miss counter",
+		"adapter_bid_response_cache:miss",
+		m.adapterBidResponseCache,
+		2,
+		prometheus.Labels{
+			adapterLabel:     "anyname",
+			cacheResultLabel: string(metrics.CacheMiss),
+		})
+}
+
+func TestRecordAdapterOpenConnections(t *testing.T) {
+	m := createMetricsForTesting()
+	m.RecordAdapterOpenConnections(openrtb_ext.BidderName("AnyName"), 3)
+	m.RecordAdapterOpenConnections(openrtb_ext.BidderName("AnyName"), 2)
+
+	metric := dto.Metric{}
+	m.adapterOpenConnections.With(prometheus.Labels{adapterLabel: "anyname"}).Write(&metric)
+	assert.Equal(t, float64(2), metric.GetGauge().GetValue())
+}
+
+func TestRecordAdapterShadowRequest(t *testing.T) {
+	m := createMetricsForTesting()
+	m.RecordAdapterShadowRequest(openrtb_ext.BidderName("AnyName"), true)
+	m.RecordAdapterShadowRequest(openrtb_ext.BidderName("AnyName"), true)
+	m.RecordAdapterShadowRequest(openrtb_ext.BidderName("AnyName"), false)
+
+	assertCounterVecValue(t, "", "adapterShadowRequests:gotbids", m.adapterShadowRequests, 2,
+		prometheus.Labels{adapterLabel: "anyname", hasBidsLabel: "true"})
+	assertCounterVecValue(t, "", "adapterShadowRequests:nobid", m.adapterShadowRequests, 1,
+		prometheus.Labels{adapterLabel: "anyname", hasBidsLabel: "false"})
+}
+
+func TestRecordAdapterShadowPrice(t *testing.T) {
+	m := createMetricsForTesting()
+	m.RecordAdapterShadowPrice(openrtb_ext.BidderName("AnyName"), 42)
+
+	result := getHistogramFromHistogramVec(m.adapterShadowPrices, adapterLabel, "anyname")
+	assertHistogram(t, "adapterShadowPrices", result, 1, 42)
+}
@@ -1,7 +1,11 @@
 package modules
 
 import (
+	prebidDevicedetection "github.com/prebid/prebid-server/v2/modules/prebid/devicedetection"
+	prebidIpgeolocation "github.com/prebid/prebid-server/v2/modules/prebid/ipgeolocation"
 	prebidOrtb2blocking "github.com/prebid/prebid-server/v2/modules/prebid/ortb2blocking"
+	prebidResponsecorrection "github.com/prebid/prebid-server/v2/modules/prebid/responsecorrection"
+	prebidRichmediafilter "github.com/prebid/prebid-server/v2/modules/prebid/richmediafilter"
 )
 
 // builders returns mapping between module name and its builder
@@ -9,7 +13,11 @@ import (
 func builders() ModuleBuilders {
 	return ModuleBuilders{
 		"prebid": {
-			"ortb2blocking": prebidOrtb2blocking.Builder,
+			"devicedetection":    prebidDevicedetection.Builder,
+			"ipgeolocation":      prebidIpgeolocation.Builder,
+			"ortb2blocking":      prebidOrtb2blocking.Builder,
+			"responsecorrection": prebidResponsecorrection.Builder,
+			"richmediafilter":    prebidRichmediafilter.Builder,
 		},
 	}
 }
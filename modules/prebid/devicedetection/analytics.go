@@ -0,0 +1,40 @@
+package devicedetection
+
+import (
+	"github.com/prebid/prebid-server/v2/hooks/hookanalytics"
+)
+
+const detectDeviceTag = "detect_device"
+
+const (
+	ruleAnalyticKey       = "rule"
+	confidenceAnalyticKey = "confidence"
+	fieldsAnalyticKey     = "fields"
+)
+
+// devicedetection module has only 1 activity: `detect_device`, its result holds the confidence of the detection,
+// which is 0 when no rule matches the device
+func newDetectDeviceTags(d detection, matched bool, filledFields []string) hookanalytics.Analytics {
+	result := hookanalytics.Result{
+		Status:    hookanalytics.ResultStatusAllow,
+		Values:    map[string]interface{}{confidenceAnalyticKey: d.confidence},
+		AppliedTo: hookanalytics.AppliedTo{Request: true},
+	}
+	if matched {
+		result.Values[ruleAnalyticKey] = d.rule
+	}
+	if len(filledFields) > 0 {
+		result.Status = hookanalytics.ResultStatusModify
+		result.Values[fieldsAnalyticKey] = filledFields
+	}
+
+	return hookanalytics.Analytics{
+		Activities: []hookanalytics.Activity{
+			{
+				Name:    detectDeviceTag,
+				Status:  hookanalytics.ActivityStatusSuccess,
+				Results: []hookanalytics.Result{result},
+			},
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package devicedetection
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+)
+
+const defaultReloadIntervalSeconds = 60
+
+func newConfig(data json.RawMessage) (config, error) {
+	cfg := config{ReloadIntervalSeconds: defaultReloadIntervalSeconds}
+	if len(data) == 0 {
+		return cfg, errors.New("rules_file is required")
+	}
+	if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+	if cfg.RulesFile == "" {
+		return cfg, errors.New("rules_file is required")
+	}
+	if cfg.ReloadIntervalSeconds < 0 {
+		return cfg, fmt.Errorf("reload_interval_seconds must be positive or zero to disable reloads, got %d", cfg.ReloadIntervalSeconds)
+	}
+	return cfg, nil
+}
+
+type config struct {
+	// RulesFile is the path of the local json file holding the detection rules
+	RulesFile string `json:"rules_file"`
+	// ReloadIntervalSeconds is how often the rules file is checked for changes, 0 disables reloads
+	ReloadIntervalSeconds int `json:"reload_interval_seconds"`
+}
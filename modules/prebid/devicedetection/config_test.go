@@ -0,0 +1,59 @@
+package devicedetection
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig(t *testing.T) {
+	testCases := []struct {
+		description    string
+		data           json.RawMessage
+		expectedConfig config
+		expectedError  string
+	}{
+		{
+			description:    "default-reload-interval",
+			data:           json.RawMessage(`{"enabled": true, "rules_file": "rules.json"}`),
+			expectedConfig: config{RulesFile: "rules.json", ReloadIntervalSeconds: defaultReloadIntervalSeconds},
+		},
+		{
+			description:    "reloads-disabled",
+			data:           json.RawMessage(`{"rules_file": "rules.json", "reload_interval_seconds": 0}`),
+			expectedConfig: config{RulesFile: "rules.json"},
+		},
+		{
+			description:   "no-config",
+			expectedError: "rules_file is required",
+		},
+		{
+			description:   "no-rules-file",
+			data:          json.RawMessage(`{"enabled": true}`),
+			expectedError: "rules_file is required",
+		},
+		{
+			description:   "negative-reload-interval",
+			data:          json.RawMessage(`{"rules_file": "rules.json", "reload_interval_seconds": -1}`),
+			expectedError: "reload_interval_seconds must be positive or zero to disable reloads, got -1",
+		},
+		{
+			description:   "invalid-config",
+			data:          json.RawMessage(`{"rules_file": 1}`),
+			expectedError: "failed to parse config",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			cfg, err := newConfig(test.data)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedConfig, cfg)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package devicedetection
+
+import (
+	"strings"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/util/ptrutil"
+)
+
+func handleProcessedAuctionHook(
+	rules *rules,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (result hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], err error) {
+	if payload.Request == nil || payload.Request.BidRequest == nil {
+		return result, hookexecution.NewFailure("payload contains a nil bid request")
+	}
+
+	device := payload.Request.Device
+	if device == nil || (device.UA == "" && device.SUA == nil) {
+		return result, nil
+	}
+
+	d, matched := rules.detect(device)
+	if !matched {
+		result.AnalyticsTags = newDetectDeviceTags(d, false, nil)
+		return result, nil
+	}
+
+	_, filledFields := fillDevice(*device, d.device)
+	result.AnalyticsTags = newDetectDeviceTags(d, true, filledFields)
+	if len(filledFields) == 0 {
+		return result, nil
+	}
+
+	// the hook may be given a copy of the request scrubbed by the activity controls,
+	// so the detected values are filled into the device of the request being mutated
+	changeSet := hookstage.ChangeSet[hookstage.ProcessedAuctionRequestPayload]{}
+	changeSet.AddMutation(func(p hookstage.ProcessedAuctionRequestPayload) (hookstage.ProcessedAuctionRequestPayload, error) {
+		if p.Request.Device == nil {
+			return p, nil
+		}
+		detectedDevice, _ := fillDevice(*p.Request.Device, d.device)
+		p.Request.Device = &detectedDevice
+		return p, nil
+	}, hookstage.MutationUpdate, "bidrequest", "device")
+	result.ChangeSet = changeSet
+
+	return result, nil
+}
+
+// fillDevice fills the fields missing from the device with the detected values, the values set by the publisher
+// are never overwritten. It returns the updated copy of the device and the paths of the filled fields.
+func fillDevice(device openrtb2.Device, detected ruleDevice) (openrtb2.Device, []string) {
+	var filled []string
+	fillString := func(field *string, value, path string) {
+		if *field == "" && value != "" {
+			*field = value
+			filled = append(filled, path)
+		}
+	}
+
+	if device.DeviceType == 0 && detected.DeviceType != 0 {
+		device.DeviceType = detected.DeviceType
+		filled = append(filled, "device.devicetype")
+	}
+	fillString(&device.Make, detected.Make, "device.make")
+	fillString(&device.Model, detected.Model, "device.model")
+	fillString(&device.OS, detected.OS, "device.os")
+	fillString(&device.OSV, detected.OSV, "device.osv")
+	fillString(&device.HWV, detected.HWV, "device.hwv")
+
+	// the sua detected from the user agent is marked as parsed, a sua sent by the publisher keeps its source
+	var sua openrtb2.UserAgent
+	if device.SUA != nil {
+		sua = *device.SUA
+	} else {
+		sua.Source = adcom1.UASourceParsed
+	}
+	suaFilled := len(filled)
+	if sua.Platform == nil && device.OS != "" {
+		sua.Platform = &openrtb2.BrandVersion{Brand: device.OS, Version: splitVersion(device.OSV)}
+		filled = append(filled, "device.sua.platform")
+	}
+	if len(sua.Browsers) == 0 && detected.Browser != "" {
+		sua.Browsers = []openrtb2.BrandVersion{{Brand: detected.Browser, Version: splitVersion(detected.BrowserVersion)}}
+		filled = append(filled, "device.sua.browsers")
+	}
+	if sua.Mobile == nil && device.DeviceType != 0 {
+		sua.Mobile = ptrutil.ToPtr(isMobile(device.DeviceType))
+		filled = append(filled, "device.sua.mobile")
+	}
+	fillString(&sua.Model, device.Model, "device.sua.model")
+	if len(filled) > suaFilled {
+		device.SUA = &sua
+	}
+
+	return device, filled
+}
+
+func splitVersion(version string) []string {
+	if version == "" {
+		return nil
+	}
+	return strings.Split(version, ".")
+}
+
+func isMobile(deviceType adcom1.DeviceType) int8 {
+	switch deviceType {
+	case adcom1.DeviceMobile, adcom1.DevicePhone, adcom1.DeviceTablet:
+		return 1
+	}
+	return 0
+}
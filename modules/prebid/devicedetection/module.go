@@ -0,0 +1,43 @@
+package devicedetection
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/modules/moduledeps"
+	"github.com/prebid/prebid-server/v2/util/task"
+)
+
+// Builder loads the rules file set in the host config and schedules its reloads
+func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	loader, err := newRulesLoader(cfg.RulesFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ReloadIntervalSeconds > 0 {
+		task.NewTickerTask(time.Duration(cfg.ReloadIntervalSeconds)*time.Second, loader).Start()
+	}
+
+	return Module{rules: loader}, nil
+}
+
+type Module struct {
+	rules *rulesLoader
+}
+
+// HandleProcessedAuctionHook fills the device fields missing from the request with the values of the first
+// detection rule matching the user agent or the client hints of the device.
+func (m Module) HandleProcessedAuctionHook(
+	_ context.Context,
+	_ hookstage.ModuleInvocationContext,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	return handleProcessedAuctionHook(m.rules.get(), payload)
+}
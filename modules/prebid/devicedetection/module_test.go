@@ -0,0 +1,220 @@
+package devicedetection
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/modules/moduledeps"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	writeRulesFile(t, path, testRules, time.Now())
+
+	module, err := Builder(json.RawMessage(`{"enabled": true, "rules_file": "`+path+`", "reload_interval_seconds": 0}`), moduledeps.ModuleDeps{})
+	require.NoError(t, err)
+	assert.Equal(t, "1", module.(Module).rules.get().version)
+
+	_, err = Builder(json.RawMessage(`{"enabled": true}`), moduledeps.ModuleDeps{})
+	assert.EqualError(t, err, "rules_file is required")
+
+	_, err = Builder(json.RawMessage(`{"enabled": true, "rules_file": "`+filepath.Join(t.TempDir(), "missing.json")+`"}`), moduledeps.ModuleDeps{})
+	assert.Error(t, err)
+}
+
+func TestHandleProcessedAuctionHook(t *testing.T) {
+	rules, err := parseRules([]byte(testRules))
+	require.NoError(t, err)
+	module := Module{rules: &rulesLoader{}}
+	module.rules.rules.Store(rules)
+
+	rokuUA := "Roku/DVP-12.5 (12.5.0.4178)"
+
+	testCases := []struct {
+		description        string
+		device             *openrtb2.Device
+		expectedDevice     *openrtb2.Device
+		expectedHookResult hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]
+	}{
+		{
+			description: "Missing device fields and sua filled",
+			device:      &openrtb2.Device{UA: rokuUA},
+			expectedDevice: &openrtb2.Device{
+				UA:         rokuUA,
+				DeviceType: adcom1.DeviceSetTopBox,
+				Make:       "Roku",
+				OS:         "Roku OS",
+				OSV:        "12.5",
+				SUA: &openrtb2.UserAgent{
+					Platform: &openrtb2.BrandVersion{Brand: "Roku OS", Version: []string{"12", "5"}},
+					Mobile:   ptrutil.ToPtr[int8](0),
+					Source:   adcom1.UASourceParsed,
+				},
+			},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   detectDeviceTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{{
+						Status: hookanalytics.ResultStatusModify,
+						Values: map[string]interface{}{
+							ruleAnalyticKey:       "roku",
+							confidenceAnalyticKey: 0.95,
+							fieldsAnalyticKey:     []string{"device.devicetype", "device.make", "device.os", "device.osv", "device.sua.platform", "device.sua.mobile"},
+						},
+						AppliedTo: hookanalytics.AppliedTo{Request: true},
+					}},
+				}}},
+			},
+		},
+		{
+			description: "Publisher values not overwritten",
+			device: &openrtb2.Device{
+				UA:         rokuUA,
+				DeviceType: adcom1.DeviceTV,
+				OS:         "RokuOS",
+				SUA:        &openrtb2.UserAgent{Platform: &openrtb2.BrandVersion{Brand: "RokuOS"}, Source: adcom1.UASourceHighEntropy},
+			},
+			expectedDevice: &openrtb2.Device{
+				UA:         rokuUA,
+				DeviceType: adcom1.DeviceTV,
+				Make:       "Roku",
+				OS:         "RokuOS",
+				OSV:        "12.5",
+				SUA:        &openrtb2.UserAgent{Platform: &openrtb2.BrandVersion{Brand: "RokuOS"}, Mobile: ptrutil.ToPtr[int8](0), Source: adcom1.UASourceHighEntropy},
+			},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   detectDeviceTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{{
+						Status: hookanalytics.ResultStatusModify,
+						Values: map[string]interface{}{
+							ruleAnalyticKey:       "roku",
+							confidenceAnalyticKey: 0.95,
+							fieldsAnalyticKey:     []string{"device.make", "device.osv", "device.sua.mobile"},
+						},
+						AppliedTo: hookanalytics.AppliedTo{Request: true},
+					}},
+				}}},
+			},
+		},
+		{
+			description: "Nothing to fill",
+			device: &openrtb2.Device{
+				UA:         rokuUA,
+				DeviceType: adcom1.DeviceTV,
+				Make:       "Roku",
+				OS:         "Roku OS",
+				OSV:        "12.5",
+				SUA:        &openrtb2.UserAgent{Platform: &openrtb2.BrandVersion{Brand: "Roku OS"}, Mobile: ptrutil.ToPtr[int8](0)},
+			},
+			expectedDevice: &openrtb2.Device{
+				UA:         rokuUA,
+				DeviceType: adcom1.DeviceTV,
+				Make:       "Roku",
+				OS:         "Roku OS",
+				OSV:        "12.5",
+				SUA:        &openrtb2.UserAgent{Platform: &openrtb2.BrandVersion{Brand: "Roku OS"}, Mobile: ptrutil.ToPtr[int8](0)},
+			},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   detectDeviceTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{{
+						Status:    hookanalytics.ResultStatusAllow,
+						Values:    map[string]interface{}{ruleAnalyticKey: "roku", confidenceAnalyticKey: 0.95},
+						AppliedTo: hookanalytics.AppliedTo{Request: true},
+					}},
+				}}},
+			},
+		},
+		{
+			description:    "No rule matching device",
+			device:         &openrtb2.Device{UA: "Mozilla/5.0 (Windows NT 10.0; Win64; x64)"},
+			expectedDevice: &openrtb2.Device{UA: "Mozilla/5.0 (Windows NT 10.0; Win64; x64)"},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   detectDeviceTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{{
+						Status:    hookanalytics.ResultStatusAllow,
+						Values:    map[string]interface{}{confidenceAnalyticKey: 0.0},
+						AppliedTo: hookanalytics.AppliedTo{Request: true},
+					}},
+				}}},
+			},
+		},
+		{
+			description:        "No user agent nor client hints",
+			device:             &openrtb2.Device{IP: "1.2.3.4"},
+			expectedDevice:     &openrtb2.Device{IP: "1.2.3.4"},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			originalDevice := *test.device
+			payload := hookstage.ProcessedAuctionRequestPayload{Request: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Device: test.device}}}
+
+			hookResult, err := module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{Endpoint: hookexecution.EndpointAuction}, payload)
+			assert.NoError(t, err)
+			assert.Equal(t, originalDevice, *test.device, "the device of the payload must not be changed in place")
+
+			// test mutations separately
+			for _, mut := range hookResult.ChangeSet.Mutations() {
+				newPayload, err := mut.Apply(payload)
+				assert.NoError(t, err)
+				payload = newPayload
+			}
+			assert.Equal(t, test.expectedDevice, payload.Request.Device, "Invalid device after executing ProcessedAuctionRequest hook.")
+
+			// reset ChangeSet not to break hookResult assertion, we validated ChangeSet separately
+			hookResult.ChangeSet = hookstage.ChangeSet[hookstage.ProcessedAuctionRequestPayload]{}
+			assert.Equal(t, test.expectedHookResult, hookResult, "Invalid hook execution result.")
+		})
+	}
+
+	_, err = module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{}, hookstage.ProcessedAuctionRequestPayload{})
+	assert.Equal(t, hookexecution.NewFailure("payload contains a nil bid request"), err)
+}
+
+func TestHandleProcessedAuctionHookScrubbedPayload(t *testing.T) {
+	rules, err := parseRules([]byte(testRules))
+	require.NoError(t, err)
+	module := Module{rules: &rulesLoader{}}
+	module.rules.rules.Store(rules)
+
+	rokuUA := "Roku/DVP-12.5 (12.5.0.4178)"
+	device := &openrtb2.Device{UA: rokuUA, IP: "1.2.3.4", IFA: "test-ifa"}
+	payload := hookstage.ProcessedAuctionRequestPayload{Request: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Device: device}}}
+
+	// the activity controls give the hook a copy of the request with the ip and the device ids scrubbed
+	scrubbedPayload := hookstage.ProcessedAuctionRequestPayload{Request: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+		Device: &openrtb2.Device{UA: rokuUA, IP: "1.2.3.0"},
+	}}}
+
+	hookResult, err := module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{}, scrubbedPayload)
+	require.NoError(t, err)
+
+	for _, mut := range hookResult.ChangeSet.Mutations() {
+		payload, err = mut.Apply(payload)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "1.2.3.4", payload.Request.Device.IP, "the scrubbed ip must not be written to the request")
+	assert.Equal(t, "test-ifa", payload.Request.Device.IFA, "the scrubbed device ids must not be written to the request")
+	assert.Equal(t, "Roku", payload.Request.Device.Make)
+}
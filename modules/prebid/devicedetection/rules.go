@@ -0,0 +1,211 @@
+package devicedetection
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+)
+
+// rulesFile is the format of the rules file. The rules are tried in order and the first matching rule is applied,
+// so the most specific rules must come first.
+type rulesFile struct {
+	Version string     `json:"version"`
+	Rules   []ruleJSON `json:"rules"`
+}
+
+type ruleJSON struct {
+	Name string `json:"name"`
+	// UA is a regular expression matched against device.ua, its groups can be used in the device fields as $1, $2...
+	UA string `json:"ua"`
+	// SUAPlatform is a regular expression matched against the brand of device.sua.platform
+	SUAPlatform string `json:"sua_platform"`
+	// SUAModel is a regular expression matched against device.sua.model
+	SUAModel   string     `json:"sua_model"`
+	Confidence float64    `json:"confidence"`
+	Device     ruleDevice `json:"device"`
+}
+
+// ruleDevice holds the values filled in the device, the string values can refer to the groups of the ua expression
+type ruleDevice struct {
+	DeviceType     adcom1.DeviceType `json:"devicetype"`
+	Make           string            `json:"make"`
+	Model          string            `json:"model"`
+	OS             string            `json:"os"`
+	OSV            string            `json:"osv"`
+	HWV            string            `json:"hwv"`
+	Browser        string            `json:"browser"`
+	BrowserVersion string            `json:"browser_version"`
+}
+
+type rule struct {
+	name        string
+	ua          *regexp.Regexp
+	suaPlatform *regexp.Regexp
+	suaModel    *regexp.Regexp
+	confidence  float64
+	device      ruleDevice
+}
+
+type rules struct {
+	version string
+	rules   []rule
+}
+
+// detection is the result of matching a device against the rules
+type detection struct {
+	rule       string
+	confidence float64
+	device     ruleDevice
+}
+
+func parseRules(data []byte) (*rules, error) {
+	var file rulesFile
+	if err := jsonutil.UnmarshalValid(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %s", err)
+	}
+
+	parsed := &rules{version: file.Version, rules: make([]rule, 0, len(file.Rules))}
+	for i, r := range file.Rules {
+		if r.UA == "" && r.SUAPlatform == "" && r.SUAModel == "" {
+			return nil, fmt.Errorf("rules[%d]: at least one of ua, sua_platform or sua_model must be set", i)
+		}
+		if r.Confidence < 0 || r.Confidence > 1 {
+			return nil, fmt.Errorf("rules[%d]: confidence must be between 0 and 1, got %g", i, r.Confidence)
+		}
+
+		compiled := rule{name: r.Name, confidence: r.Confidence, device: r.Device}
+		var err error
+		if compiled.ua, err = compileRuleExpression(r.UA); err != nil {
+			return nil, fmt.Errorf("rules[%d].ua: %s", i, err)
+		}
+		if compiled.suaPlatform, err = compileRuleExpression(r.SUAPlatform); err != nil {
+			return nil, fmt.Errorf("rules[%d].sua_platform: %s", i, err)
+		}
+		if compiled.suaModel, err = compileRuleExpression(r.SUAModel); err != nil {
+			return nil, fmt.Errorf("rules[%d].sua_model: %s", i, err)
+		}
+		if compiled.name == "" {
+			compiled.name = fmt.Sprintf("rules[%d]", i)
+		}
+		parsed.rules = append(parsed.rules, compiled)
+	}
+	return parsed, nil
+}
+
+func compileRuleExpression(expression string) (*regexp.Regexp, error) {
+	if expression == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expression)
+}
+
+// detect returns the detection of the first rule matching the device, or false if none matches
+func (r *rules) detect(device *openrtb2.Device) (detection, bool) {
+	for _, rule := range r.rules {
+		if d, ok := rule.match(device); ok {
+			return d, true
+		}
+	}
+	return detection{}, false
+}
+
+func (r rule) match(device *openrtb2.Device) (detection, bool) {
+	var uaMatch []int
+	if r.ua != nil {
+		if uaMatch = r.ua.FindStringSubmatchIndex(device.UA); uaMatch == nil {
+			return detection{}, false
+		}
+	}
+	if r.suaPlatform != nil && (device.SUA == nil || device.SUA.Platform == nil || !r.suaPlatform.MatchString(device.SUA.Platform.Brand)) {
+		return detection{}, false
+	}
+	if r.suaModel != nil && (device.SUA == nil || !r.suaModel.MatchString(device.SUA.Model)) {
+		return detection{}, false
+	}
+
+	d := detection{rule: r.name, confidence: r.confidence, device: r.device}
+	if uaMatch != nil {
+		expand := func(template string) string {
+			if !strings.Contains(template, "$") {
+				return template
+			}
+			return string(r.ua.ExpandString(nil, template, device.UA, uaMatch))
+		}
+		d.device.Make = expand(d.device.Make)
+		d.device.Model = expand(d.device.Model)
+		d.device.OS = expand(d.device.OS)
+		d.device.OSV = expand(d.device.OSV)
+		d.device.HWV = expand(d.device.HWV)
+		d.device.Browser = expand(d.device.Browser)
+		d.device.BrowserVersion = expand(d.device.BrowserVersion)
+	}
+	return d, true
+}
+
+// rulesLoader holds the rules read from the rules file and reloads them when the file changes.
+// A file which can't be read or parsed on reload is logged and the current rules are kept.
+type rulesLoader struct {
+	path    string
+	rules   atomic.Pointer[rules]
+	mutex   sync.Mutex
+	modTime time.Time
+}
+
+func newRulesLoader(path string) (*rulesLoader, error) {
+	loader := &rulesLoader{path: path}
+	if _, err := loader.load(); err != nil {
+		return nil, err
+	}
+	return loader, nil
+}
+
+func (l *rulesLoader) get() *rules {
+	return l.rules.Load()
+}
+
+// Run reloads the rules if the file changed since it was last read
+func (l *rulesLoader) Run() error {
+	reloaded, err := l.load()
+	if err != nil {
+		glog.Warningf("Unable to reload device detection rules from %s, keeping the current rules: %v", l.path, err)
+		return err
+	}
+	if reloaded {
+		glog.Infof("Device detection rules reloaded from %s, version: %s", l.path, l.get().version)
+	}
+	return nil
+}
+
+func (l *rulesLoader) load() (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return false, err
+	}
+	if l.rules.Load() != nil && info.ModTime().Equal(l.modTime) {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return false, err
+	}
+	parsed, err := parseRules(data)
+	if err != nil {
+		return false, err
+	}
+	l.rules.Store(parsed)
+	l.modTime = info.ModTime()
+	return true, nil
+}
@@ -0,0 +1,171 @@
+package devicedetection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `{
+  "version": "1",
+  "rules": [
+    {
+      "name": "roku",
+      "ua": "Roku/DVP-(\\d+)\\.(\\d+)",
+      "confidence": 0.95,
+      "device": {"devicetype": 7, "make": "Roku", "os": "Roku OS", "osv": "$1.$2"}
+    },
+    {
+      "name": "pixel",
+      "sua_platform": "^Android$",
+      "sua_model": "^Pixel",
+      "confidence": 0.8,
+      "device": {"devicetype": 4, "make": "Google", "os": "Android"}
+    },
+    {
+      "ua": "Android",
+      "confidence": 0.5,
+      "device": {"devicetype": 1, "os": "Android"}
+    }
+  ]
+}`
+
+func writeRulesFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	testCases := []struct {
+		description   string
+		data          string
+		expectedError string
+	}{
+		{
+			description:   "invalid-json",
+			data:          `{"rules": {}}`,
+			expectedError: "failed to parse rules",
+		},
+		{
+			description:   "no-expression",
+			data:          `{"rules": [{"name": "any", "confidence": 1}]}`,
+			expectedError: "rules[0]: at least one of ua, sua_platform or sua_model must be set",
+		},
+		{
+			description:   "invalid-confidence",
+			data:          `{"rules": [{"ua": "Roku", "confidence": 2}]}`,
+			expectedError: "rules[0]: confidence must be between 0 and 1, got 2",
+		},
+		{
+			description:   "invalid-expression",
+			data:          `{"rules": [{"ua": "Roku", "confidence": 1}, {"sua_model": "(", "confidence": 1}]}`,
+			expectedError: "rules[1].sua_model: error parsing regexp: missing closing ): `(`",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			_, err := parseRules([]byte(test.data))
+			assert.ErrorContains(t, err, test.expectedError)
+		})
+	}
+}
+
+func TestRulesDetect(t *testing.T) {
+	rules, err := parseRules([]byte(testRules))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		description       string
+		device            *openrtb2.Device
+		expectedDetection detection
+		expectedMatch     bool
+	}{
+		{
+			description: "ua-groups-expanded",
+			device:      &openrtb2.Device{UA: "Roku/DVP-12.5 (12.5.0.4178)"},
+			expectedDetection: detection{
+				rule:       "roku",
+				confidence: 0.95,
+				device:     ruleDevice{DeviceType: adcom1.DeviceSetTopBox, Make: "Roku", OS: "Roku OS", OSV: "12.5"},
+			},
+			expectedMatch: true,
+		},
+		{
+			description: "client-hints",
+			device:      &openrtb2.Device{SUA: &openrtb2.UserAgent{Platform: &openrtb2.BrandVersion{Brand: "Android"}, Model: "Pixel 8"}},
+			expectedDetection: detection{
+				rule:       "pixel",
+				confidence: 0.8,
+				device:     ruleDevice{DeviceType: adcom1.DevicePhone, Make: "Google", OS: "Android"},
+			},
+			expectedMatch: true,
+		},
+		{
+			description: "first-matching-rule-applied",
+			device:      &openrtb2.Device{UA: "Mozilla/5.0 (Linux; Android 14)", SUA: &openrtb2.UserAgent{Platform: &openrtb2.BrandVersion{Brand: "Android"}, Model: "SM-S918B"}},
+			expectedDetection: detection{
+				rule:       "rules[2]",
+				confidence: 0.5,
+				device:     ruleDevice{DeviceType: adcom1.DeviceMobile, OS: "Android"},
+			},
+			expectedMatch: true,
+		},
+		{
+			description: "no-match",
+			device:      &openrtb2.Device{UA: "Mozilla/5.0 (Windows NT 10.0; Win64; x64)"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			d, matched := rules.detect(test.device)
+			assert.Equal(t, test.expectedMatch, matched)
+			assert.Equal(t, test.expectedDetection, d)
+		})
+	}
+}
+
+func TestRulesLoaderReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeRulesFile(t, path, testRules, modTime)
+
+	loader, err := newRulesLoader(path)
+	require.NoError(t, err)
+	assert.Equal(t, "1", loader.get().version)
+
+	// the file isn't parsed again until it changes
+	current := loader.get()
+	assert.NoError(t, loader.Run())
+	assert.Same(t, current, loader.get())
+
+	writeRulesFile(t, path, `{"version": "2", "rules": [{"ua": "Roku", "confidence": 1}]}`, modTime.Add(time.Minute))
+	assert.NoError(t, loader.Run())
+	assert.Equal(t, "2", loader.get().version)
+
+	writeRulesFile(t, path, `{"version": "3", "rules": [{"ua": "(", "confidence": 1}]}`, modTime.Add(2*time.Minute))
+	assert.Error(t, loader.Run())
+	assert.Equal(t, "2", loader.get().version, "the current rules should be kept when the file is invalid")
+
+	require.NoError(t, os.Remove(path))
+	assert.Error(t, loader.Run())
+	assert.Equal(t, "2", loader.get().version, "the current rules should be kept when the file is missing")
+}
+
+func TestNewRulesLoaderErrors(t *testing.T) {
+	_, err := newRulesLoader(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	writeRulesFile(t, path, `{"rules": [{"confidence": 1}]}`, time.Now())
+	_, err = newRulesLoader(path)
+	assert.EqualError(t, err, "rules[0]: at least one of ua, sua_platform or sua_model must be set")
+}
@@ -0,0 +1,36 @@
+package ipgeolocation
+
+import (
+	"github.com/prebid/prebid-server/v2/hooks/hookanalytics"
+)
+
+const enrichGeoTag = "enrich_geo"
+
+const (
+	precisionAnalyticKey = "precision"
+	fieldsAnalyticKey    = "fields"
+)
+
+// ipgeolocation module has only 1 activity: `enrich_geo`, its result holds the precision cap applied to the request
+// and the geo fields filled in from the database
+func newEnrichGeoTags(p precision, filledFields []string) hookanalytics.Analytics {
+	result := hookanalytics.Result{
+		Status:    hookanalytics.ResultStatusAllow,
+		Values:    map[string]interface{}{precisionAnalyticKey: p.String()},
+		AppliedTo: hookanalytics.AppliedTo{Request: true},
+	}
+	if len(filledFields) > 0 {
+		result.Status = hookanalytics.ResultStatusModify
+		result.Values[fieldsAnalyticKey] = filledFields
+	}
+
+	return hookanalytics.Analytics{
+		Activities: []hookanalytics.Activity{
+			{
+				Name:    enrichGeoTag,
+				Status:  hookanalytics.ActivityStatusSuccess,
+				Results: []hookanalytics.Result{result},
+			},
+		},
+	}
+}
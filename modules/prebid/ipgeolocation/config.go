@@ -0,0 +1,118 @@
+package ipgeolocation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+)
+
+const defaultReloadIntervalSeconds = 60
+
+// precision is the most precise level of the geo fields filled in by the module
+type precision int
+
+const (
+	precisionNone precision = iota
+	precisionCountry
+	precisionRegion
+	precisionMetro
+)
+
+var precisionNames = []string{"none", "country", "region", "metro"}
+
+func (p precision) String() string {
+	return precisionNames[p]
+}
+
+func parsePrecision(name, field string) (precision, error) {
+	for i, precisionName := range precisionNames {
+		if name == precisionName {
+			return precision(i), nil
+		}
+	}
+	return precisionNone, fmt.Errorf("%s must be one of none, country, region or metro, got %q", field, name)
+}
+
+func newConfig(data json.RawMessage) (config, error) {
+	cfg := config{
+		ReloadIntervalSeconds: defaultReloadIntervalSeconds,
+		precisionConfig:       precisionConfig{MaxPrecision: "metro", RestrictedPrecision: "country"},
+	}
+	if len(data) == 0 {
+		return cfg, errors.New("database_file is required")
+	}
+	if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+	if cfg.DatabaseFile == "" {
+		return cfg, errors.New("database_file is required")
+	}
+	if cfg.ReloadIntervalSeconds < 0 {
+		return cfg, fmt.Errorf("reload_interval_seconds must be positive or zero to disable reloads, got %d", cfg.ReloadIntervalSeconds)
+	}
+	if _, _, err := cfg.precisions(precisionConfig{}); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+type config struct {
+	// DatabaseFile is the path of the local MaxMind DB file of a GeoIP2 or GeoLite2 City database
+	DatabaseFile string `json:"database_file"`
+	// ReloadIntervalSeconds is how often the database file is checked for changes, 0 disables reloads
+	ReloadIntervalSeconds int `json:"reload_interval_seconds"`
+	precisionConfig
+}
+
+// precisionConfig holds the precision caps, the account config can only lower the caps set by the host
+type precisionConfig struct {
+	// MaxPrecision caps the geo fields filled in for the requests
+	MaxPrecision string `json:"max_precision"`
+	// RestrictedPrecision caps the geo fields filled in when the activity controls deny transmitPreciseGeo to the module
+	RestrictedPrecision string `json:"restricted_precision"`
+}
+
+// precisions returns the max and restricted precisions of the host config lowered by the account config
+func (cfg config) precisions(account precisionConfig) (maxPrecision, restrictedPrecision precision, err error) {
+	if maxPrecision, err = parsePrecision(cfg.MaxPrecision, "max_precision"); err != nil {
+		return
+	}
+	if restrictedPrecision, err = parsePrecision(cfg.RestrictedPrecision, "restricted_precision"); err != nil {
+		return
+	}
+	if account.MaxPrecision != "" {
+		var p precision
+		if p, err = parsePrecision(account.MaxPrecision, "max_precision"); err != nil {
+			return
+		}
+		maxPrecision = lowerPrecision(maxPrecision, p)
+	}
+	if account.RestrictedPrecision != "" {
+		var p precision
+		if p, err = parsePrecision(account.RestrictedPrecision, "restricted_precision"); err != nil {
+			return
+		}
+		restrictedPrecision = lowerPrecision(restrictedPrecision, p)
+	}
+	return maxPrecision, lowerPrecision(restrictedPrecision, maxPrecision), nil
+}
+
+func newAccountConfig(data json.RawMessage) (precisionConfig, error) {
+	var cfg precisionConfig
+	if len(data) == 0 {
+		return cfg, nil
+	}
+	if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse account config: %s", err)
+	}
+	return cfg, nil
+}
+
+func lowerPrecision(a, b precision) precision {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,131 @@
+package ipgeolocation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig(t *testing.T) {
+	defaultPrecisions := precisionConfig{MaxPrecision: "metro", RestrictedPrecision: "country"}
+
+	testCases := []struct {
+		description    string
+		data           json.RawMessage
+		expectedConfig config
+		expectedError  string
+	}{
+		{
+			description:    "defaults",
+			data:           json.RawMessage(`{"enabled": true, "database_file": "city.mmdb"}`),
+			expectedConfig: config{DatabaseFile: "city.mmdb", ReloadIntervalSeconds: defaultReloadIntervalSeconds, precisionConfig: defaultPrecisions},
+		},
+		{
+			description: "precisions-and-reloads-disabled",
+			data:        json.RawMessage(`{"database_file": "city.mmdb", "reload_interval_seconds": 0, "max_precision": "region", "restricted_precision": "none"}`),
+			expectedConfig: config{
+				DatabaseFile:    "city.mmdb",
+				precisionConfig: precisionConfig{MaxPrecision: "region", RestrictedPrecision: "none"},
+			},
+		},
+		{
+			description:   "no-config",
+			expectedError: "database_file is required",
+		},
+		{
+			description:   "no-database-file",
+			data:          json.RawMessage(`{"enabled": true}`),
+			expectedError: "database_file is required",
+		},
+		{
+			description:   "negative-reload-interval",
+			data:          json.RawMessage(`{"database_file": "city.mmdb", "reload_interval_seconds": -1}`),
+			expectedError: "reload_interval_seconds must be positive or zero to disable reloads, got -1",
+		},
+		{
+			description:   "invalid-max-precision",
+			data:          json.RawMessage(`{"database_file": "city.mmdb", "max_precision": "city"}`),
+			expectedError: `max_precision must be one of none, country, region or metro, got "city"`,
+		},
+		{
+			description:   "invalid-restricted-precision",
+			data:          json.RawMessage(`{"database_file": "city.mmdb", "restricted_precision": ""}`),
+			expectedError: `restricted_precision must be one of none, country, region or metro, got ""`,
+		},
+		{
+			description:   "invalid-config",
+			data:          json.RawMessage(`{"database_file": 1}`),
+			expectedError: "failed to parse config",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			cfg, err := newConfig(test.data)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedConfig, cfg)
+		})
+	}
+}
+
+func TestPrecisions(t *testing.T) {
+	cfg := config{precisionConfig: precisionConfig{MaxPrecision: "region", RestrictedPrecision: "country"}}
+
+	testCases := []struct {
+		description                 string
+		account                     precisionConfig
+		expectedMaxPrecision        precision
+		expectedRestrictedPrecision precision
+		expectedError               string
+	}{
+		{
+			description:                 "host-precisions",
+			expectedMaxPrecision:        precisionRegion,
+			expectedRestrictedPrecision: precisionCountry,
+		},
+		{
+			description:                 "account-lowers-precisions",
+			account:                     precisionConfig{MaxPrecision: "country", RestrictedPrecision: "none"},
+			expectedMaxPrecision:        precisionCountry,
+			expectedRestrictedPrecision: precisionNone,
+		},
+		{
+			description:                 "account-cannot-raise-precisions",
+			account:                     precisionConfig{MaxPrecision: "metro", RestrictedPrecision: "metro"},
+			expectedMaxPrecision:        precisionRegion,
+			expectedRestrictedPrecision: precisionCountry,
+		},
+		{
+			description:                 "restricted-precision-capped-by-max-precision",
+			account:                     precisionConfig{MaxPrecision: "none"},
+			expectedMaxPrecision:        precisionNone,
+			expectedRestrictedPrecision: precisionNone,
+		},
+		{
+			description:   "invalid-account-precision",
+			account:       precisionConfig{RestrictedPrecision: "city"},
+			expectedError: `restricted_precision must be one of none, country, region or metro, got "city"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			maxPrecision, restrictedPrecision, err := cfg.precisions(test.account)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedMaxPrecision, maxPrecision)
+			assert.Equal(t, test.expectedRestrictedPrecision, restrictedPrecision)
+		})
+	}
+
+	_, err := newAccountConfig(json.RawMessage(`{"max_precision": 1}`))
+	assert.ErrorContains(t, err, "failed to parse account config")
+}
@@ -0,0 +1,121 @@
+package ipgeolocation
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// location holds the fields of a GeoIP2 or GeoLite2 City record used to enrich the request
+type location struct {
+	// country is the ISO-3166-1 alpha-2 code of the country
+	country string
+	// region is the ISO-3166-2 subdivision code without the country prefix
+	region   string
+	metro    string
+	timeZone string
+}
+
+// geoRecord holds the fields of a City database record read to build the location
+type geoRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	// Subdivisions are ordered from the largest to the smallest
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+	Location struct {
+		MetroCode uint   `maxminddb:"metro_code"`
+		TimeZone  string `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+}
+
+func lookupLocation(db *maxminddb.Reader, ip net.IP) (location, bool, error) {
+	// ipv6 addresses aren't in ipv4 databases
+	if db.Metadata.IPVersion == 4 && ip.To4() == nil {
+		return location{}, false, nil
+	}
+	var record geoRecord
+	if err := db.Lookup(ip, &record); err != nil {
+		return location{}, false, err
+	}
+
+	loc := location{
+		country:  record.Country.ISOCode,
+		timeZone: record.Location.TimeZone,
+	}
+	if len(record.Subdivisions) > 0 {
+		loc.region = record.Subdivisions[0].ISOCode
+	}
+	if record.Location.MetroCode > 0 {
+		loc.metro = strconv.FormatUint(uint64(record.Location.MetroCode), 10)
+	}
+
+	return loc, loc != location{}, nil
+}
+
+// databaseLoader holds the database read from the database file and reloads it when the file changes.
+// A file which can't be read or parsed on reload is logged and the current database is kept.
+type databaseLoader struct {
+	path     string
+	database atomic.Pointer[maxminddb.Reader]
+	mutex    sync.Mutex
+	modTime  time.Time
+}
+
+func newDatabaseLoader(path string) (*databaseLoader, error) {
+	loader := &databaseLoader{path: path}
+	if _, err := loader.load(); err != nil {
+		return nil, err
+	}
+	return loader, nil
+}
+
+func (l *databaseLoader) get() *maxminddb.Reader {
+	return l.database.Load()
+}
+
+// Run reloads the database if the file changed since it was last read
+func (l *databaseLoader) Run() error {
+	reloaded, err := l.load()
+	if err != nil {
+		glog.Warningf("Unable to reload the ip geolocation database from %s, keeping the current database: %v", l.path, err)
+		return err
+	}
+	if reloaded {
+		glog.Infof("IP geolocation database reloaded from %s, type: %s, build epoch: %d", l.path, l.get().Metadata.DatabaseType, l.get().Metadata.BuildEpoch)
+	}
+	return nil
+}
+
+func (l *databaseLoader) load() (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return false, err
+	}
+	if l.database.Load() != nil && info.ModTime().Equal(l.modTime) {
+		return false, nil
+	}
+
+	content, err := os.ReadFile(l.path)
+	if err != nil {
+		return false, err
+	}
+	parsed, err := maxminddb.FromBytes(content)
+	if err != nil {
+		return false, err
+	}
+	l.database.Store(parsed)
+	l.modTime = info.ModTime()
+	return true, nil
+}
@@ -0,0 +1,133 @@
+package ipgeolocation
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testNetworks are the networks of the test City database
+var testNetworks = []testNetwork{
+	{cidr: "216.160.83.0/24", record: cityRecord("US", "WA", 819, "America/Los_Angeles")},
+	{cidr: "81.2.69.0/24", record: cityRecord("GB", "ENG", 0, "Europe/London")},
+	{cidr: "2001:218::/32", record: cityRecord("JP", "13", 0, "Asia/Tokyo")},
+	{cidr: "89.160.20.0/24", record: map[string]interface{}{"continent": map[string]interface{}{"code": "EU"}}},
+}
+
+func cityRecord(country, region string, metroCode uint16, timeZone string) map[string]interface{} {
+	loc := map[string]interface{}{"time_zone": timeZone, "latitude": 47.2513, "longitude": -122.3149}
+	if metroCode > 0 {
+		loc["metro_code"] = metroCode
+	}
+	return map[string]interface{}{
+		"country":      map[string]interface{}{"iso_code": country, "names": map[string]interface{}{"en": country}},
+		"subdivisions": []interface{}{map[string]interface{}{"iso_code": region}},
+		"location":     loc,
+	}
+}
+
+func writeDatabaseFile(t *testing.T, path string, networks []testNetwork, modTime time.Time) {
+	require.NoError(t, os.WriteFile(path, buildTestMMDB(t, 6, 28, networks), 0644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestLookupLocation(t *testing.T) {
+	db, err := maxminddb.FromBytes(buildTestMMDB(t, 6, 24, testNetworks))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		ip               string
+		expectedLocation location
+		expectedFound    bool
+	}{
+		{
+			ip:               "216.160.83.56",
+			expectedLocation: location{country: "US", region: "WA", metro: "819", timeZone: "America/Los_Angeles"},
+			expectedFound:    true,
+		},
+		{
+			ip:               "81.2.69.142",
+			expectedLocation: location{country: "GB", region: "ENG", timeZone: "Europe/London"},
+			expectedFound:    true,
+		},
+		{
+			ip:               "2001:218:1::1",
+			expectedLocation: location{country: "JP", region: "13", timeZone: "Asia/Tokyo"},
+			expectedFound:    true,
+		},
+		{
+			ip: "89.160.20.112",
+		},
+		{
+			ip: "10.0.0.1",
+		},
+	}
+
+	for _, test := range testCases {
+		loc, found, err := lookupLocation(db, net.ParseIP(test.ip))
+		assert.NoError(t, err, test.ip)
+		assert.Equal(t, test.expectedFound, found, test.ip)
+		assert.Equal(t, test.expectedLocation, loc, test.ip)
+	}
+}
+
+func TestLookupLocationIPv4Database(t *testing.T) {
+	db, err := maxminddb.FromBytes(buildTestMMDB(t, 4, 28, testNetworks[:2]))
+	require.NoError(t, err)
+
+	loc, found, err := lookupLocation(db, net.ParseIP("81.2.69.142"))
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, location{country: "GB", region: "ENG", timeZone: "Europe/London"}, loc)
+
+	_, found, err = lookupLocation(db, net.ParseIP("2001:218:1::1"))
+	assert.NoError(t, err)
+	assert.False(t, found, "ipv6 addresses are not in ipv4 databases")
+}
+
+func TestDatabaseLoaderReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	modTime := time.Now().Add(-time.Hour)
+	writeDatabaseFile(t, path, testNetworks[:1], modTime)
+
+	loader, err := newDatabaseLoader(path)
+	require.NoError(t, err)
+	_, found, _ := lookupLocation(loader.get(), net.ParseIP("81.2.69.142"))
+	assert.False(t, found)
+
+	// the file isn't parsed again until it changes
+	current := loader.get()
+	assert.NoError(t, loader.Run())
+	assert.Same(t, current, loader.get())
+
+	writeDatabaseFile(t, path, testNetworks, modTime.Add(time.Minute))
+	assert.NoError(t, loader.Run())
+	_, found, _ = lookupLocation(loader.get(), net.ParseIP("81.2.69.142"))
+	assert.True(t, found)
+
+	current = loader.get()
+	require.NoError(t, os.WriteFile(path, []byte("invalid"), 0644))
+	require.NoError(t, os.Chtimes(path, modTime.Add(2*time.Minute), modTime.Add(2*time.Minute)))
+	assert.Error(t, loader.Run())
+	assert.Same(t, current, loader.get(), "the current database should be kept when the file is invalid")
+
+	require.NoError(t, os.Remove(path))
+	assert.Error(t, loader.Run())
+	assert.Same(t, current, loader.get(), "the current database should be kept when the file is missing")
+}
+
+func TestNewDatabaseLoaderErrors(t *testing.T) {
+	_, err := newDatabaseLoader(filepath.Join(t.TempDir(), "missing.mmdb"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	require.NoError(t, os.WriteFile(path, []byte("invalid"), 0644))
+	_, err = newDatabaseLoader(path)
+	assert.ErrorContains(t, err, "invalid MaxMind DB file")
+}
@@ -0,0 +1,157 @@
+package ipgeolocation
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+)
+
+// geoValues holds the values of the device location allowed by the precision cap
+type geoValues struct {
+	// countryAlpha2 is only used to compare the country with the one sent by the publisher
+	countryAlpha2 string
+	country       string
+	region        string
+	metro         string
+	utcOffset     int64
+}
+
+func (m Module) handleProcessedAuctionHook(
+	p precision,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (result hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], err error) {
+	if payload.Request == nil || payload.Request.BidRequest == nil {
+		return result, hookexecution.NewFailure("payload contains a nil bid request")
+	}
+
+	device := payload.Request.Device
+	if device == nil || (device.IP == "" && device.IPv6 == "") || p == precisionNone {
+		return result, nil
+	}
+
+	loc, found, err := lookupDevice(m.database.get(), device)
+	if err != nil {
+		return result, hookexecution.NewFailure("failed to look up the device ip: %s", err)
+	}
+	if !found {
+		result.AnalyticsTags = newEnrichGeoTags(p, nil)
+		return result, nil
+	}
+
+	values := m.newGeoValues(loc, p)
+	_, filledFields := fillGeo(device.Geo, values)
+	result.AnalyticsTags = newEnrichGeoTags(p, filledFields)
+	if len(filledFields) == 0 {
+		return result, nil
+	}
+
+	// the hook may be given a copy of the request scrubbed by the activity controls,
+	// so the values are filled into the geo of the request being mutated
+	changeSet := hookstage.ChangeSet[hookstage.ProcessedAuctionRequestPayload]{}
+	changeSet.AddMutation(func(p hookstage.ProcessedAuctionRequestPayload) (hookstage.ProcessedAuctionRequestPayload, error) {
+		if p.Request.Device == nil {
+			return p, nil
+		}
+		geo, _ := fillGeo(p.Request.Device.Geo, values)
+		device := *p.Request.Device
+		device.Geo = &geo
+		p.Request.Device = &device
+		return p, nil
+	}, hookstage.MutationUpdate, "bidrequest", "device", "geo")
+	result.ChangeSet = changeSet
+
+	return result, nil
+}
+
+// lookupDevice looks up device.ip and then device.ipv6 when the ipv4 address is not in the database
+func lookupDevice(db *maxminddb.Reader, device *openrtb2.Device) (location, bool, error) {
+	for _, address := range []string{device.IP, device.IPv6} {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			continue
+		}
+		loc, found, err := lookupLocation(db, ip)
+		if err != nil || found {
+			return loc, found, err
+		}
+	}
+	return location{}, false, nil
+}
+
+// newGeoValues converts the location to the OpenRTB values and drops the values more precise than the cap.
+// The utc offset reveals the region of the countries spanning several time zones so it is capped as a region.
+func (m Module) newGeoValues(loc location, p precision) geoValues {
+	var values geoValues
+	values.countryAlpha2 = loc.country
+	values.country = countryAlpha3[loc.country]
+	if p >= precisionRegion && loc.region != "" && loc.country != "" {
+		// OpenRTB expects the 2-letter state code for the USA and the ISO-3166-2 code elsewhere
+		values.region = loc.region
+		if loc.country != "US" {
+			values.region = loc.country + "-" + loc.region
+		}
+	}
+	if p >= precisionRegion && loc.timeZone != "" {
+		values.utcOffset = m.utcOffset(loc.timeZone)
+	}
+	if p >= precisionMetro {
+		values.metro = loc.metro
+	}
+	return values
+}
+
+// utcOffset returns the current offset of the time zone in minutes, 0 when the time zone is unknown
+func (m Module) utcOffset(timeZone string) int64 {
+	cached, ok := m.timeZones.Load(timeZone)
+	if !ok {
+		tz, err := time.LoadLocation(timeZone)
+		if err != nil {
+			tz = nil
+		}
+		cached, _ = m.timeZones.LoadOrStore(timeZone, tz)
+	}
+	tz := cached.(*time.Location)
+	if tz == nil {
+		return 0
+	}
+	_, offset := m.now().In(tz).Zone()
+	return int64(offset / 60)
+}
+
+// fillGeo fills the fields missing from the geo with the values of the database, the values set by the publisher
+// are never overwritten. The values are dropped when the publisher set a different country, so the filled fields
+// can't contradict the request. It returns the updated copy of the geo and the paths of the filled fields.
+func fillGeo(geo *openrtb2.Geo, values geoValues) (openrtb2.Geo, []string) {
+	var filled []string
+	if geo == nil {
+		// the geo is built from the ip address only
+		geo = &openrtb2.Geo{Type: adcom1.LocationIP, IPService: adcom1.LocationServiceMaxMind}
+	}
+	result := *geo
+
+	if result.Country != "" && !strings.EqualFold(result.Country, values.country) && !strings.EqualFold(result.Country, values.countryAlpha2) {
+		return result, nil
+	}
+
+	fillString := func(field *string, value, path string) {
+		if *field == "" && value != "" {
+			*field = value
+			filled = append(filled, path)
+		}
+	}
+	fillString(&result.Country, values.country, "device.geo.country")
+	fillString(&result.Region, values.region, "device.geo.region")
+	fillString(&result.Metro, values.metro, "device.geo.metro")
+	if result.UTCOffset == 0 && values.utcOffset != 0 {
+		result.UTCOffset = values.utcOffset
+		filled = append(filled, "device.geo.utcoffset")
+	}
+
+	return result, filled
+}
@@ -0,0 +1,231 @@
+package ipgeolocation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mmdbMetadataMarker starts the metadata section located at the end of a MaxMind DB file
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// mmdbDataSectionSeparator is the size of the zeroed block between the search tree and the data section
+const mmdbDataSectionSeparator = 16
+
+// data field types of the MaxMind DB format written by the test encoder
+const (
+	mmdbString = 2
+	mmdbDouble = 3
+	mmdbUint16 = 5
+	mmdbUint32 = 6
+	mmdbMap    = 7
+	mmdbInt32  = 8
+	mmdbUint64 = 9
+	mmdbArray  = 11
+	mmdbBool   = 14
+)
+
+type testNetwork struct {
+	cidr   string
+	record map[string]interface{}
+}
+
+type testNode struct {
+	children [2]*testNode
+	// data is the offset of the record of the leaf nodes in the data section, -1 for the inner nodes
+	data int
+}
+
+// buildTestMMDB writes a MaxMind DB holding the networks, the repeated strings of the records are written as pointers
+func buildTestMMDB(t *testing.T, ipVersion, recordSize uint, networks []testNetwork) []byte {
+	var data bytes.Buffer
+	encoder := newTestEncoder(&data, true)
+
+	root := &testNode{data: -1}
+	for _, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network.cidr)
+		require.NoError(t, err)
+		ones, bits := ipNet.Mask.Size()
+		ip := ipNet.IP
+		if ipVersion == 6 && bits == 32 {
+			ip, ones = ip.To16(), ones+96
+			// ipv4 networks are stored as ::a.b.c.d
+			ip = append(make(net.IP, 12), ip[12:]...)
+		}
+
+		offset := data.Len()
+		encoder.encode(network.record)
+
+		node := root
+		for i := 0; i < ones; i++ {
+			bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+			if i == ones-1 {
+				node.children[bit] = &testNode{data: offset}
+				break
+			}
+			if node.children[bit] == nil {
+				node.children[bit] = &testNode{data: -1}
+			}
+			node = node.children[bit]
+		}
+	}
+
+	// number the inner nodes breadth first, the root being 0
+	var nodes []*testNode
+	numbers := map[*testNode]uint{}
+	for queue := []*testNode{root}; len(queue) > 0; queue = queue[1:] {
+		numbers[queue[0]] = uint(len(nodes))
+		nodes = append(nodes, queue[0])
+		for _, child := range queue[0].children {
+			if child != nil && child.data < 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+	nodeCount := uint(len(nodes))
+
+	var content bytes.Buffer
+	for _, node := range nodes {
+		var records [2]uint
+		for i, child := range node.children {
+			switch {
+			case child == nil:
+				records[i] = nodeCount
+			case child.data >= 0:
+				records[i] = nodeCount + mmdbDataSectionSeparator + uint(child.data)
+			default:
+				records[i] = numbers[child]
+			}
+		}
+		switch recordSize {
+		case 24:
+			content.Write([]byte{byte(records[0] >> 16), byte(records[0] >> 8), byte(records[0])})
+			content.Write([]byte{byte(records[1] >> 16), byte(records[1] >> 8), byte(records[1])})
+		case 28:
+			content.Write([]byte{byte(records[0] >> 16), byte(records[0] >> 8), byte(records[0])})
+			content.WriteByte(byte((records[0]>>24)&0x0f)<<4 | byte((records[1]>>24)&0x0f))
+			content.Write([]byte{byte(records[1] >> 16), byte(records[1] >> 8), byte(records[1])})
+		case 32:
+			content.Write(binary.BigEndian.AppendUint32(nil, uint32(records[0])))
+			content.Write(binary.BigEndian.AppendUint32(nil, uint32(records[1])))
+		}
+	}
+	content.Write(make([]byte, mmdbDataSectionSeparator))
+	content.Write(data.Bytes())
+
+	content.Write(mmdbMetadataMarker)
+	newTestEncoder(&content, false).encode(map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1717200000),
+		"database_type":               "Test-City",
+		"ip_version":                  uint16(ipVersion),
+		"languages":                   []interface{}{"en"},
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(recordSize),
+	})
+	return content.Bytes()
+}
+
+// testEncoder writes values in the format of the MaxMind DB data section
+type testEncoder struct {
+	buf *bytes.Buffer
+	// strings holds the offsets of the strings already written, nil when pointers aren't used
+	strings map[string]int
+}
+
+func newTestEncoder(buf *bytes.Buffer, usePointers bool) testEncoder {
+	encoder := testEncoder{buf: buf}
+	if usePointers {
+		encoder.strings = map[string]int{}
+	}
+	return encoder
+}
+
+func (e testEncoder) encode(value interface{}) {
+	switch v := value.(type) {
+	case string:
+		if offset, ok := e.strings[v]; ok {
+			e.buf.Write([]byte{0x20 | byte(offset>>8)&0x07, byte(offset)})
+			return
+		}
+		if e.strings != nil && len(v) > 2 {
+			e.strings[v] = e.buf.Len()
+		}
+		e.writeControl(mmdbString, len(v))
+		e.buf.WriteString(v)
+	case float64:
+		e.writeControl(mmdbDouble, 8)
+		e.buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		e.writeControl(mmdbBool, size)
+	case int32:
+		e.writeControl(mmdbInt32, 4)
+		e.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+	case uint16:
+		e.writeUint(mmdbUint16, uint64(v))
+	case uint32:
+		e.writeUint(mmdbUint32, uint64(v))
+	case uint64:
+		e.writeUint(mmdbUint64, v)
+	case []interface{}:
+		e.writeControl(mmdbArray, len(v))
+		for _, item := range v {
+			e.encode(item)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		e.writeControl(mmdbMap, len(v))
+		for _, key := range keys {
+			e.encode(key)
+			e.encode(v[key])
+		}
+	default:
+		panic("unsupported test value")
+	}
+}
+
+func (e testEncoder) writeUint(fieldType int, value uint64) {
+	b := binary.BigEndian.AppendUint64(nil, value)
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+	e.writeControl(fieldType, len(b))
+	e.buf.Write(b)
+}
+
+func (e testEncoder) writeControl(fieldType, size int) {
+	var control byte
+	if fieldType <= mmdbMap {
+		control = byte(fieldType) << 5
+	}
+	var extra []byte
+	switch {
+	case size < 29:
+		control |= byte(size)
+	case size < 285:
+		control |= 29
+		extra = []byte{byte(size - 29)}
+	default:
+		control |= 30
+		extra = []byte{byte((size - 285) >> 8), byte(size - 285)}
+	}
+	e.buf.WriteByte(control)
+	if fieldType > mmdbMap {
+		e.buf.WriteByte(byte(fieldType - 7))
+	}
+	e.buf.Write(extra)
+}
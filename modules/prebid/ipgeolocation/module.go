@@ -0,0 +1,61 @@
+package ipgeolocation
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/modules/moduledeps"
+	"github.com/prebid/prebid-server/v2/util/task"
+)
+
+// Builder loads the database file set in the host config and schedules its reloads
+func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	loader, err := newDatabaseLoader(cfg.DatabaseFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ReloadIntervalSeconds > 0 {
+		task.NewTickerTask(time.Duration(cfg.ReloadIntervalSeconds)*time.Second, loader).Start()
+	}
+
+	return Module{cfg: cfg, database: loader, timeZones: &sync.Map{}, now: time.Now}, nil
+}
+
+type Module struct {
+	cfg      config
+	database *databaseLoader
+	// timeZones caches the locations loaded for the time zones of the database
+	timeZones *sync.Map
+	now       func() time.Time
+}
+
+// HandleProcessedAuctionHook fills the device.geo fields missing from the request with the location of the device ip,
+// up to the precision allowed by the config and the activity controls.
+func (m Module) HandleProcessedAuctionHook(
+	_ context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	result := hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{}
+	accountCfg, err := newAccountConfig(miCtx.AccountConfig)
+	if err != nil {
+		return result, err
+	}
+	maxPrecision, restrictedPrecision, err := m.cfg.precisions(accountCfg)
+	if err != nil {
+		return result, err
+	}
+
+	if miCtx.PreciseGeoRestricted {
+		return m.handleProcessedAuctionHook(restrictedPrecision, payload)
+	}
+	return m.handleProcessedAuctionHook(maxPrecision, payload)
+}
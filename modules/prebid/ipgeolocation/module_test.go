@@ -0,0 +1,275 @@
+package ipgeolocation
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/modules/moduledeps"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/prebid/prebid-server/v2/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	writeDatabaseFile(t, path, testNetworks, time.Now())
+
+	module, err := Builder(json.RawMessage(`{"enabled": true, "database_file": "`+path+`", "reload_interval_seconds": 0, "max_precision": "region"}`), moduledeps.ModuleDeps{})
+	require.NoError(t, err)
+	assert.Equal(t, "region", module.(Module).cfg.MaxPrecision)
+	assert.Equal(t, "Test-City", module.(Module).database.get().Metadata.DatabaseType)
+
+	_, err = Builder(json.RawMessage(`{"enabled": true}`), moduledeps.ModuleDeps{})
+	assert.EqualError(t, err, "database_file is required")
+
+	_, err = Builder(json.RawMessage(`{"enabled": true, "database_file": "`+filepath.Join(t.TempDir(), "missing.mmdb")+`"}`), moduledeps.ModuleDeps{})
+	assert.Error(t, err)
+}
+
+func TestHandleProcessedAuctionHook(t *testing.T) {
+	db, err := maxminddb.FromBytes(buildTestMMDB(t, 6, 24, testNetworks))
+	require.NoError(t, err)
+	module := Module{
+		cfg:       config{precisionConfig: precisionConfig{MaxPrecision: "metro", RestrictedPrecision: "country"}},
+		database:  &databaseLoader{},
+		timeZones: &sync.Map{},
+		now:       func() time.Time { return time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC) },
+	}
+	module.database.database.Store(db)
+
+	usIP := "216.160.83.56"
+
+	testCases := []struct {
+		description        string
+		accountConfig      json.RawMessage
+		restricted         bool
+		device             *openrtb2.Device
+		expectedDevice     *openrtb2.Device
+		expectedHookResult hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]
+	}{
+		{
+			description: "Missing geo built from the ip",
+			device:      &openrtb2.Device{IP: usIP},
+			expectedDevice: &openrtb2.Device{IP: usIP, Geo: &openrtb2.Geo{
+				Type:      adcom1.LocationIP,
+				IPService: adcom1.LocationServiceMaxMind,
+				Country:   "USA",
+				Region:    "WA",
+				Metro:     "819",
+				UTCOffset: -480,
+			}},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   enrichGeoTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{{
+						Status: hookanalytics.ResultStatusModify,
+						Values: map[string]interface{}{
+							precisionAnalyticKey: "metro",
+							fieldsAnalyticKey:    []string{"device.geo.country", "device.geo.region", "device.geo.metro", "device.geo.utcoffset"},
+						},
+						AppliedTo: hookanalytics.AppliedTo{Request: true},
+					}},
+				}}},
+			},
+		},
+		{
+			description: "Missing geo fields filled without overwriting the publisher values",
+			device:      &openrtb2.Device{IP: usIP, Geo: &openrtb2.Geo{Type: adcom1.LocationGPS, Lat: ptrutil.ToPtr(47.25), Region: "OR"}},
+			expectedDevice: &openrtb2.Device{IP: usIP, Geo: &openrtb2.Geo{
+				Type:      adcom1.LocationGPS,
+				Lat:       ptrutil.ToPtr(47.25),
+				Country:   "USA",
+				Region:    "OR",
+				Metro:     "819",
+				UTCOffset: -480,
+			}},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: newEnrichGeoTags(precisionMetro, []string{"device.geo.country", "device.geo.metro", "device.geo.utcoffset"}),
+			},
+		},
+		{
+			description:    "Geo not filled when the publisher set another country",
+			device:         &openrtb2.Device{IP: usIP, Geo: &openrtb2.Geo{Country: "CAN"}},
+			expectedDevice: &openrtb2.Device{IP: usIP, Geo: &openrtb2.Geo{Country: "CAN"}},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: newEnrichGeoTags(precisionMetro, nil),
+			},
+		},
+		{
+			description:    "Geo filled when the publisher set the same alpha-2 country",
+			device:         &openrtb2.Device{IP: usIP, Geo: &openrtb2.Geo{Country: "us"}},
+			expectedDevice: &openrtb2.Device{IP: usIP, Geo: &openrtb2.Geo{Country: "us", Region: "WA", Metro: "819", UTCOffset: -480}},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: newEnrichGeoTags(precisionMetro, []string{"device.geo.region", "device.geo.metro", "device.geo.utcoffset"}),
+			},
+		},
+		{
+			description: "ISO-3166-2 region outside of the USA and no utcoffset for UTC",
+			device:      &openrtb2.Device{IP: "81.2.69.142"},
+			expectedDevice: &openrtb2.Device{IP: "81.2.69.142", Geo: &openrtb2.Geo{
+				Type:      adcom1.LocationIP,
+				IPService: adcom1.LocationServiceMaxMind,
+				Country:   "GBR",
+				Region:    "GB-ENG",
+			}},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: newEnrichGeoTags(precisionMetro, []string{"device.geo.country", "device.geo.region"}),
+			},
+		},
+		{
+			description: "IPv6 looked up when the ipv4 address is not in the database",
+			device:      &openrtb2.Device{IP: "10.0.0.1", IPv6: "2001:218:1::1"},
+			expectedDevice: &openrtb2.Device{IP: "10.0.0.1", IPv6: "2001:218:1::1", Geo: &openrtb2.Geo{
+				Type:      adcom1.LocationIP,
+				IPService: adcom1.LocationServiceMaxMind,
+				Country:   "JPN",
+				Region:    "JP-13",
+				UTCOffset: 540,
+			}},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: newEnrichGeoTags(precisionMetro, []string{"device.geo.country", "device.geo.region", "device.geo.utcoffset"}),
+			},
+		},
+		{
+			description:   "Account lowers the precision",
+			accountConfig: json.RawMessage(`{"max_precision": "region"}`),
+			device:        &openrtb2.Device{IP: usIP},
+			expectedDevice: &openrtb2.Device{IP: usIP, Geo: &openrtb2.Geo{
+				Type:      adcom1.LocationIP,
+				IPService: adcom1.LocationServiceMaxMind,
+				Country:   "USA",
+				Region:    "WA",
+				UTCOffset: -480,
+			}},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: newEnrichGeoTags(precisionRegion, []string{"device.geo.country", "device.geo.region", "device.geo.utcoffset"}),
+			},
+		},
+		{
+			description: "Restricted precision applied when precise geo is denied by the activity controls",
+			restricted:  true,
+			device:      &openrtb2.Device{IP: usIP},
+			expectedDevice: &openrtb2.Device{IP: usIP, Geo: &openrtb2.Geo{
+				Type:      adcom1.LocationIP,
+				IPService: adcom1.LocationServiceMaxMind,
+				Country:   "USA",
+			}},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: newEnrichGeoTags(precisionCountry, []string{"device.geo.country"}),
+			},
+		},
+		{
+			description:        "Nothing filled when the precision is none",
+			accountConfig:      json.RawMessage(`{"restricted_precision": "none"}`),
+			restricted:         true,
+			device:             &openrtb2.Device{IP: usIP},
+			expectedDevice:     &openrtb2.Device{IP: usIP},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{},
+		},
+		{
+			description:    "IP not in the database",
+			device:         &openrtb2.Device{IP: "10.0.0.1"},
+			expectedDevice: &openrtb2.Device{IP: "10.0.0.1"},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   enrichGeoTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{{
+						Status:    hookanalytics.ResultStatusAllow,
+						Values:    map[string]interface{}{precisionAnalyticKey: "metro"},
+						AppliedTo: hookanalytics.AppliedTo{Request: true},
+					}},
+				}}},
+			},
+		},
+		{
+			description:        "No ip",
+			device:             &openrtb2.Device{UA: "Mozilla/5.0"},
+			expectedDevice:     &openrtb2.Device{UA: "Mozilla/5.0"},
+			expectedHookResult: hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload]{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			originalDevice := *test.device
+			payload := hookstage.ProcessedAuctionRequestPayload{Request: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Device: test.device}}}
+			miCtx := hookstage.ModuleInvocationContext{Endpoint: hookexecution.EndpointAuction, AccountConfig: test.accountConfig, PreciseGeoRestricted: test.restricted}
+
+			hookResult, err := module.HandleProcessedAuctionHook(context.Background(), miCtx, payload)
+			assert.NoError(t, err)
+			assert.Equal(t, originalDevice, *test.device, "the device of the payload must not be changed in place")
+
+			// test mutations separately
+			for _, mut := range hookResult.ChangeSet.Mutations() {
+				newPayload, err := mut.Apply(payload)
+				assert.NoError(t, err)
+				payload = newPayload
+			}
+			assert.Equal(t, test.expectedDevice, payload.Request.Device, "Invalid device after executing ProcessedAuctionRequest hook.")
+
+			// reset ChangeSet not to break hookResult assertion, we validated ChangeSet separately
+			hookResult.ChangeSet = hookstage.ChangeSet[hookstage.ProcessedAuctionRequestPayload]{}
+			assert.Equal(t, test.expectedHookResult, hookResult, "Invalid hook execution result.")
+		})
+	}
+
+	_, err = module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{}, hookstage.ProcessedAuctionRequestPayload{})
+	assert.Equal(t, hookexecution.NewFailure("payload contains a nil bid request"), err)
+
+	_, err = module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{AccountConfig: json.RawMessage(`{"max_precision": "city"}`)}, hookstage.ProcessedAuctionRequestPayload{})
+	assert.EqualError(t, err, `max_precision must be one of none, country, region or metro, got "city"`)
+}
+
+func TestHandleProcessedAuctionHookScrubbedPayload(t *testing.T) {
+	db, err := maxminddb.FromBytes(buildTestMMDB(t, 6, 24, testNetworks))
+	require.NoError(t, err)
+	module := Module{
+		cfg:       config{precisionConfig: precisionConfig{MaxPrecision: "metro", RestrictedPrecision: "country"}},
+		database:  &databaseLoader{},
+		timeZones: &sync.Map{},
+		now:       time.Now,
+	}
+	module.database.database.Store(db)
+
+	device := &openrtb2.Device{IP: "216.160.83.56", Geo: &openrtb2.Geo{Lat: ptrutil.ToPtr(47.2513)}}
+	payload := hookstage.ProcessedAuctionRequestPayload{Request: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Device: device}}}
+
+	// the activity controls give the hook a copy of the request with the ip and the geo scrubbed
+	scrubbedPayload := hookstage.ProcessedAuctionRequestPayload{Request: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+		Device: &openrtb2.Device{IP: "216.160.83.0", Geo: &openrtb2.Geo{Lat: ptrutil.ToPtr(47.25)}},
+	}}}
+
+	hookResult, err := module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{PreciseGeoRestricted: true}, scrubbedPayload)
+	require.NoError(t, err)
+
+	for _, mut := range hookResult.ChangeSet.Mutations() {
+		payload, err = mut.Apply(payload)
+		require.NoError(t, err)
+	}
+	expectedDevice := &openrtb2.Device{IP: "216.160.83.56", Geo: &openrtb2.Geo{Lat: ptrutil.ToPtr(47.2513), Country: "USA"}}
+	assert.Equal(t, expectedDevice, payload.Request.Device, "the scrubbed ip and geo must not be written to the request")
+}
+
+func TestUTCOffset(t *testing.T) {
+	module := Module{timeZones: &sync.Map{}, now: func() time.Time { return time.Date(2024, 7, 15, 12, 0, 0, 0, time.UTC) }}
+
+	assert.Equal(t, int64(-420), module.utcOffset("America/Los_Angeles"), "the offset should follow daylight saving time")
+	assert.Equal(t, int64(330), module.utcOffset("Asia/Kolkata"))
+	assert.Equal(t, int64(0), module.utcOffset("Invalid/Zone"))
+
+	_, cached := module.timeZones.Load("Invalid/Zone")
+	assert.True(t, cached, "the unknown time zones should be cached")
+}
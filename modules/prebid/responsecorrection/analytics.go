@@ -0,0 +1,52 @@
+package responsecorrection
+
+import (
+	"github.com/prebid/prebid-server/v2/hooks/hookanalytics"
+)
+
+const correctBidsTag = "correct_bids"
+
+const (
+	correctionBidType   = "bid_type"
+	correctionMType     = "mtype"
+	correctionMediaType = "media_type"
+)
+
+// responsecorrection module has only 1 activity: `correct_bids`, each correction is reported as a result of it
+func newCorrectBidsTags() hookanalytics.Analytics {
+	return hookanalytics.Analytics{
+		Activities: []hookanalytics.Activity{
+			{
+				Name:   correctBidsTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+			},
+		},
+	}
+}
+
+// addCorrectionTag reports a corrected bid, the status is block when the bid was stripped and modify otherwise
+func addCorrectionTag(
+	analytics *hookanalytics.Analytics,
+	status hookanalytics.ResultStatus,
+	bidder, bidID, impID string,
+	correction string,
+	from, to interface{},
+) {
+	values := map[string]interface{}{
+		"correction": correction,
+		"from":       from,
+	}
+	if to != nil {
+		values["to"] = to
+	}
+
+	analytics.Activities[0].Results = append(analytics.Activities[0].Results, hookanalytics.Result{
+		Status: status,
+		Values: values,
+		AppliedTo: hookanalytics.AppliedTo{
+			Bidder: bidder,
+			BidIds: []string{bidID},
+			ImpIds: []string{impID},
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package responsecorrection
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+)
+
+// newConfig applies the given config on top of the defaults,
+// so that the account config only has to list the corrections it changes
+func newConfig(data json.RawMessage, defaults config) (config, error) {
+	cfg := defaults
+	if len(data) == 0 {
+		return cfg, nil
+	}
+	if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+	return cfg, nil
+}
+
+func defaultConfig() config {
+	return config{
+		RetypeVASTBids:            true,
+		FixMType:                  true,
+		StripDisallowedMediaTypes: true,
+	}
+}
+
+type config struct {
+	// RetypeVASTBids turns banner and native bids with VAST markup into video bids
+	RetypeVASTBids bool `json:"retype_vast_bids"`
+	// FixMType overwrites the bid mtype when it doesn't match the bid type
+	FixMType bool `json:"fix_mtype"`
+	// StripDisallowedMediaTypes removes bids of a media type the imp doesn't offer
+	StripDisallowedMediaTypes bool `json:"strip_disallowed_media_types"`
+	// ExcludedBidders lists the bidders whose bids are never corrected
+	ExcludedBidders []string `json:"excluded_bidders"`
+}
+
+func (cfg config) isExcluded(bidder string) bool {
+	for _, excluded := range cfg.ExcludedBidders {
+		if excluded == bidder {
+			return true
+		}
+	}
+	return false
+}
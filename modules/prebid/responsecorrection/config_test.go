@@ -0,0 +1,52 @@
+package responsecorrection
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig(t *testing.T) {
+	testCases := []struct {
+		description    string
+		data           json.RawMessage
+		defaults       config
+		expectedConfig config
+		expectedError  string
+	}{
+		{
+			description:    "empty-config-keeps-defaults",
+			defaults:       defaultConfig(),
+			expectedConfig: defaultConfig(),
+		},
+		{
+			description: "config-overrides-defaults",
+			data:        json.RawMessage(`{"fix_mtype": false, "excluded_bidders": ["bidderA"]}`),
+			defaults:    defaultConfig(),
+			expectedConfig: config{
+				RetypeVASTBids:            true,
+				StripDisallowedMediaTypes: true,
+				ExcludedBidders:           []string{"bidderA"},
+			},
+		},
+		{
+			description:   "invalid-config",
+			data:          json.RawMessage(`{"fix_mtype": "yes"}`),
+			defaults:      defaultConfig(),
+			expectedError: "failed to parse config",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			cfg, err := newConfig(test.data, test.defaults)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedConfig, cfg)
+		})
+	}
+}
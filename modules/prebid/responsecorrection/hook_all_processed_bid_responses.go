@@ -0,0 +1,62 @@
+package responsecorrection
+
+import (
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+func handleAllProcessedBidResponsesHook(
+	cfg config,
+	payload hookstage.AllProcessedBidResponsesPayload,
+	moduleCtx hookstage.ModuleContext,
+) (result hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload], err error) {
+	if !cfg.StripDisallowedMediaTypes {
+		return result, nil
+	}
+
+	mediaTypes, ok := moduleCtx[impMediaTypesKey].(impMediaTypes)
+	if !ok {
+		result.Warnings = append(result.Warnings, "imp media types are missing from the module context, the processed auction request hook must run to strip bids")
+		return result, nil
+	}
+
+	result.AnalyticsTags = newCorrectBidsTags()
+
+	allowedBids := make(map[openrtb_ext.BidderName][]*entities.PbsOrtbBid)
+	for bidderName, seatBid := range payload.Responses {
+		if seatBid == nil || cfg.isExcluded(string(bidderName)) {
+			continue
+		}
+
+		allowed := make([]*entities.PbsOrtbBid, 0, len(seatBid.Bids))
+		for _, bid := range seatBid.Bids {
+			if bid == nil || bid.Bid == nil || mediaTypes.allows(bid.Bid.ImpID, bid.BidType) {
+				allowed = append(allowed, bid)
+				continue
+			}
+			addCorrectionTag(&result.AnalyticsTags, hookanalytics.ResultStatusBlock, string(bidderName), bid.Bid.ID, bid.Bid.ImpID, correctionMediaType, bid.BidType, nil)
+		}
+
+		if len(allowed) != len(seatBid.Bids) {
+			allowedBids[bidderName] = allowed
+		}
+	}
+
+	if len(allowedBids) > 0 {
+		changeSet := hookstage.ChangeSet[hookstage.AllProcessedBidResponsesPayload]{}
+		// the seat bids are shared with the exchange, so the bids are replaced on them rather than on a copy of the map
+		changeSet.AddMutation(func(p hookstage.AllProcessedBidResponsesPayload) (hookstage.AllProcessedBidResponsesPayload, error) {
+			for bidderName, bids := range allowedBids {
+				if seatBid, ok := p.Responses[bidderName]; ok && seatBid != nil {
+					seatBid.Bids = bids
+				}
+			}
+			return p, nil
+		}, hookstage.MutationDelete, "bids")
+		result.ChangeSet = changeSet
+	}
+
+	return result, nil
+}
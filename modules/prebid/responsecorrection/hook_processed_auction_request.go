@@ -0,0 +1,17 @@
+package responsecorrection
+
+import (
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+)
+
+func handleProcessedAuctionHook(
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (result hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], err error) {
+	if payload.Request == nil || payload.Request.BidRequest == nil {
+		return result, hookexecution.NewFailure("payload contains a nil bid request")
+	}
+
+	result.ModuleContext = hookstage.ModuleContext{impMediaTypesKey: newImpMediaTypes(payload.Request.Imp)}
+	return result, nil
+}
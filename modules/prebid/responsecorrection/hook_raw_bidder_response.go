@@ -0,0 +1,72 @@
+package responsecorrection
+
+import (
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+func handleRawBidderResponseHook(
+	cfg config,
+	payload hookstage.RawBidderResponsePayload,
+) (result hookstage.HookResult[hookstage.RawBidderResponsePayload], err error) {
+	if cfg.isExcluded(payload.Bidder) || (!cfg.RetypeVASTBids && !cfg.FixMType) {
+		return result, nil
+	}
+
+	result.AnalyticsTags = newCorrectBidsTags()
+
+	// the bids are copied before being corrected, the payload is only changed through the change set
+	bids := make([]*adapters.TypedBid, 0, len(payload.Bids))
+	corrected := false
+	for _, typedBid := range payload.Bids {
+		if typedBid == nil || typedBid.Bid == nil {
+			bids = append(bids, typedBid)
+			continue
+		}
+
+		bidType := typedBid.BidType
+		if cfg.RetypeVASTBids && isRetypedToVideo(typedBid) {
+			bidType = openrtb_ext.BidTypeVideo
+			addCorrectionTag(&result.AnalyticsTags, hookanalytics.ResultStatusModify, payload.Bidder, typedBid.Bid.ID, typedBid.Bid.ImpID, correctionBidType, typedBid.BidType, bidType)
+		}
+
+		mType := typedBid.Bid.MType
+		if expected, ok := markupTypes[bidType]; cfg.FixMType && ok && mType != 0 && mType != expected {
+			mType = expected
+			addCorrectionTag(&result.AnalyticsTags, hookanalytics.ResultStatusModify, payload.Bidder, typedBid.Bid.ID, typedBid.Bid.ImpID, correctionMType, typedBid.Bid.MType, mType)
+		}
+
+		if bidType == typedBid.BidType && mType == typedBid.Bid.MType {
+			bids = append(bids, typedBid)
+			continue
+		}
+
+		bid := *typedBid.Bid
+		bid.MType = mType
+		correctedBid := *typedBid
+		correctedBid.Bid = &bid
+		correctedBid.BidType = bidType
+		bids = append(bids, &correctedBid)
+		corrected = true
+	}
+
+	if corrected {
+		changeSet := hookstage.ChangeSet[hookstage.RawBidderResponsePayload]{}
+		changeSet.RawBidderResponse().Bids().Update(bids)
+		result.ChangeSet = changeSet
+	}
+
+	return result, nil
+}
+
+// isRetypedToVideo reports whether a bid carries VAST markup under a type that can't render it,
+// audio bids are VAST documents too and are left as they are
+func isRetypedToVideo(typedBid *adapters.TypedBid) bool {
+	switch typedBid.BidType {
+	case openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeAudio:
+		return false
+	}
+	return isVAST(typedBid.Bid.AdM)
+}
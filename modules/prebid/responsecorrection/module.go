@@ -0,0 +1,62 @@
+package responsecorrection
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/modules/moduledeps"
+)
+
+// Builder builds the module, the host config holds the default corrections the account config can override
+func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newConfig(rawConfig, defaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	return Module{defaults: cfg}, nil
+}
+
+type Module struct {
+	defaults config
+}
+
+// HandleProcessedAuctionHook stores the media types offered by each imp in the module context,
+// so that the bids can be checked against them at the response stages.
+func (m Module) HandleProcessedAuctionHook(
+	_ context.Context,
+	_ hookstage.ModuleInvocationContext,
+	payload hookstage.ProcessedAuctionRequestPayload,
+) (hookstage.HookResult[hookstage.ProcessedAuctionRequestPayload], error) {
+	return handleProcessedAuctionHook(payload)
+}
+
+// HandleRawBidderResponseHook re-types bids with VAST markup and fixes the mtype of the bids returned by a bidder.
+func (m Module) HandleRawBidderResponseHook(
+	_ context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.RawBidderResponsePayload,
+) (hookstage.HookResult[hookstage.RawBidderResponsePayload], error) {
+	result := hookstage.HookResult[hookstage.RawBidderResponsePayload]{}
+	cfg, err := newConfig(miCtx.AccountConfig, m.defaults)
+	if err != nil {
+		return result, err
+	}
+
+	return handleRawBidderResponseHook(cfg, payload)
+}
+
+// HandleAllProcessedBidResponsesHook strips the bids whose media type isn't offered by their imp.
+func (m Module) HandleAllProcessedBidResponsesHook(
+	_ context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.AllProcessedBidResponsesPayload,
+) (hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload], error) {
+	result := hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload]{}
+	cfg, err := newConfig(miCtx.AccountConfig, m.defaults)
+	if err != nil {
+		return result, err
+	}
+
+	return handleAllProcessedBidResponsesHook(cfg, payload, miCtx.ModuleContext)
+}
@@ -0,0 +1,257 @@
+package responsecorrection
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/exchange/entities"
+	"github.com/prebid/prebid-server/v2/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/modules/moduledeps"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const vastAdm = `<?xml version="1.0" encoding="UTF-8"?><VAST version="4.0"><Ad id="1"></Ad></VAST>`
+
+func buildModule(t *testing.T, hostConfig json.RawMessage) Module {
+	result, err := Builder(hostConfig, moduledeps.ModuleDeps{})
+	require.NoError(t, err, "Failed to build module.")
+
+	module, ok := result.(Module)
+	require.True(t, ok, "Failed to cast module type.")
+	return module
+}
+
+func TestBuilder(t *testing.T) {
+	_, err := Builder(json.RawMessage(`{"fix_mtype": 1}`), moduledeps.ModuleDeps{})
+	assert.ErrorContains(t, err, "failed to parse config")
+
+	module := buildModule(t, json.RawMessage(`{"retype_vast_bids": false}`))
+	assert.Equal(t, config{FixMType: true, StripDisallowedMediaTypes: true}, module.defaults)
+}
+
+func TestHandleProcessedAuctionHook(t *testing.T) {
+	module := buildModule(t, nil)
+
+	_, err := module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{}, hookstage.ProcessedAuctionRequestPayload{})
+	assert.Equal(t, hookexecution.NewFailure("payload contains a nil bid request"), err)
+
+	payload := hookstage.ProcessedAuctionRequestPayload{Request: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "banner", Banner: &openrtb2.Banner{}},
+			{ID: "multi", Banner: &openrtb2.Banner{}, Video: &openrtb2.Video{}, Audio: &openrtb2.Audio{}, Native: &openrtb2.Native{}},
+		},
+	}}}
+	result, err := module.HandleProcessedAuctionHook(context.Background(), hookstage.ModuleInvocationContext{}, payload)
+	require.NoError(t, err)
+	assert.Equal(t, hookstage.ModuleContext{impMediaTypesKey: impMediaTypes{
+		"banner": {openrtb_ext.BidTypeBanner: {}},
+		"multi":  {openrtb_ext.BidTypeBanner: {}, openrtb_ext.BidTypeVideo: {}, openrtb_ext.BidTypeAudio: {}, openrtb_ext.BidTypeNative: {}},
+	}}, result.ModuleContext)
+}
+
+func TestHandleRawBidderResponseHook(t *testing.T) {
+	vastBannerBid := &adapters.TypedBid{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp1", AdM: vastAdm, MType: openrtb2.MarkupBanner}, BidType: openrtb_ext.BidTypeBanner}
+	mismatchedBid := &adapters.TypedBid{Bid: &openrtb2.Bid{ID: "2", ImpID: "imp2", AdM: "<div></div>", MType: openrtb2.MarkupVideo}, BidType: openrtb_ext.BidTypeBanner}
+	audioBid := &adapters.TypedBid{Bid: &openrtb2.Bid{ID: "3", ImpID: "imp3", AdM: vastAdm, MType: openrtb2.MarkupAudio}, BidType: openrtb_ext.BidTypeAudio}
+	noMTypeBid := &adapters.TypedBid{Bid: &openrtb2.Bid{ID: "4", ImpID: "imp4", AdM: "<div></div>"}, BidType: openrtb_ext.BidTypeBanner}
+
+	testCases := []struct {
+		description        string
+		config             json.RawMessage
+		payload            hookstage.RawBidderResponsePayload
+		expectedBids       []*adapters.TypedBid
+		expectedHookResult hookstage.HookResult[hookstage.RawBidderResponsePayload]
+	}{
+		{
+			description: "bids-corrected",
+			payload:     hookstage.RawBidderResponsePayload{Bidder: "appnexus", Bids: []*adapters.TypedBid{vastBannerBid, mismatchedBid, audioBid, noMTypeBid}},
+			expectedBids: []*adapters.TypedBid{
+				{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp1", AdM: vastAdm, MType: openrtb2.MarkupVideo}, BidType: openrtb_ext.BidTypeVideo},
+				{Bid: &openrtb2.Bid{ID: "2", ImpID: "imp2", AdM: "<div></div>", MType: openrtb2.MarkupBanner}, BidType: openrtb_ext.BidTypeBanner},
+				audioBid,
+				noMTypeBid,
+			},
+			expectedHookResult: hookstage.HookResult[hookstage.RawBidderResponsePayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   correctBidsTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{
+						{
+							Status:    hookanalytics.ResultStatusModify,
+							Values:    map[string]interface{}{"correction": correctionBidType, "from": openrtb_ext.BidTypeBanner, "to": openrtb_ext.BidTypeVideo},
+							AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"1"}, ImpIds: []string{"imp1"}},
+						},
+						{
+							Status:    hookanalytics.ResultStatusModify,
+							Values:    map[string]interface{}{"correction": correctionMType, "from": openrtb2.MarkupBanner, "to": openrtb2.MarkupVideo},
+							AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"1"}, ImpIds: []string{"imp1"}},
+						},
+						{
+							Status:    hookanalytics.ResultStatusModify,
+							Values:    map[string]interface{}{"correction": correctionMType, "from": openrtb2.MarkupVideo, "to": openrtb2.MarkupBanner},
+							AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"2"}, ImpIds: []string{"imp2"}},
+						},
+					},
+				}}},
+			},
+		},
+		{
+			description:  "vast-retyping-disabled-by-account",
+			config:       json.RawMessage(`{"retype_vast_bids": false}`),
+			payload:      hookstage.RawBidderResponsePayload{Bidder: "appnexus", Bids: []*adapters.TypedBid{vastBannerBid}},
+			expectedBids: []*adapters.TypedBid{vastBannerBid},
+			expectedHookResult: hookstage.HookResult[hookstage.RawBidderResponsePayload]{
+				AnalyticsTags: newCorrectBidsTags(),
+			},
+		},
+		{
+			description:        "bidder-excluded",
+			config:             json.RawMessage(`{"excluded_bidders": ["appnexus"]}`),
+			payload:            hookstage.RawBidderResponsePayload{Bidder: "appnexus", Bids: []*adapters.TypedBid{vastBannerBid, mismatchedBid}},
+			expectedBids:       []*adapters.TypedBid{vastBannerBid, mismatchedBid},
+			expectedHookResult: hookstage.HookResult[hookstage.RawBidderResponsePayload]{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			module := buildModule(t, nil)
+
+			hookResult, err := module.HandleRawBidderResponseHook(
+				context.Background(),
+				hookstage.ModuleInvocationContext{AccountConfig: test.config, Endpoint: hookexecution.EndpointAuction},
+				test.payload,
+			)
+			assert.NoError(t, err)
+
+			// test mutations separately
+			for _, mut := range hookResult.ChangeSet.Mutations() {
+				newPayload, err := mut.Apply(test.payload)
+				assert.NoError(t, err)
+				test.payload = newPayload
+			}
+			assert.Equal(t, test.expectedBids, test.payload.Bids, "Invalid Bids returned after executing RawBidderResponse hook.")
+
+			// reset ChangeSet not to break hookResult assertion, we validated ChangeSet separately
+			hookResult.ChangeSet = hookstage.ChangeSet[hookstage.RawBidderResponsePayload]{}
+			assert.Equal(t, test.expectedHookResult, hookResult, "Invalid hook execution result.")
+		})
+	}
+
+	// the bids of the payload must not be changed in place
+	assert.Equal(t, openrtb2.MarkupBanner, vastBannerBid.Bid.MType)
+	assert.Equal(t, openrtb_ext.BidTypeBanner, vastBannerBid.BidType)
+}
+
+func TestHandleAllProcessedBidResponsesHook(t *testing.T) {
+	moduleCtx := hookstage.ModuleContext{impMediaTypesKey: impMediaTypes{
+		"banner": {openrtb_ext.BidTypeBanner: {}},
+		"video":  {openrtb_ext.BidTypeVideo: {}},
+	}}
+	newResponses := func() map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid {
+		return map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+			"appnexus": {Bids: []*entities.PbsOrtbBid{
+				{Bid: &openrtb2.Bid{ID: "1", ImpID: "banner"}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb2.Bid{ID: "2", ImpID: "banner"}, BidType: openrtb_ext.BidTypeVideo},
+				{Bid: &openrtb2.Bid{ID: "3", ImpID: "unknown"}, BidType: openrtb_ext.BidTypeNative},
+			}},
+			"rubicon": {Bids: []*entities.PbsOrtbBid{
+				{Bid: &openrtb2.Bid{ID: "4", ImpID: "video"}, BidType: openrtb_ext.BidTypeVideo},
+			}},
+		}
+	}
+
+	testCases := []struct {
+		description        string
+		config             json.RawMessage
+		moduleCtx          hookstage.ModuleContext
+		expectedBidIDs     map[openrtb_ext.BidderName][]string
+		expectedHookResult hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload]
+	}{
+		{
+			description:    "disallowed-bids-stripped",
+			moduleCtx:      moduleCtx,
+			expectedBidIDs: map[openrtb_ext.BidderName][]string{"appnexus": {"1", "3"}, "rubicon": {"4"}},
+			expectedHookResult: hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   correctBidsTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{{
+						Status:    hookanalytics.ResultStatusBlock,
+						Values:    map[string]interface{}{"correction": correctionMediaType, "from": openrtb_ext.BidTypeVideo},
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"2"}, ImpIds: []string{"banner"}},
+					}},
+				}}},
+			},
+		},
+		{
+			description:    "bidder-excluded",
+			config:         json.RawMessage(`{"excluded_bidders": ["appnexus"]}`),
+			moduleCtx:      moduleCtx,
+			expectedBidIDs: map[openrtb_ext.BidderName][]string{"appnexus": {"1", "2", "3"}, "rubicon": {"4"}},
+			expectedHookResult: hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload]{
+				AnalyticsTags: newCorrectBidsTags(),
+			},
+		},
+		{
+			description:        "stripping-disabled",
+			config:             json.RawMessage(`{"strip_disallowed_media_types": false}`),
+			moduleCtx:          moduleCtx,
+			expectedBidIDs:     map[openrtb_ext.BidderName][]string{"appnexus": {"1", "2", "3"}, "rubicon": {"4"}},
+			expectedHookResult: hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload]{},
+		},
+		{
+			description:    "imp-media-types-missing",
+			expectedBidIDs: map[openrtb_ext.BidderName][]string{"appnexus": {"1", "2", "3"}, "rubicon": {"4"}},
+			expectedHookResult: hookstage.HookResult[hookstage.AllProcessedBidResponsesPayload]{
+				Warnings: []string{"imp media types are missing from the module context, the processed auction request hook must run to strip bids"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			module := buildModule(t, nil)
+			payload := hookstage.AllProcessedBidResponsesPayload{Responses: newResponses()}
+
+			hookResult, err := module.HandleAllProcessedBidResponsesHook(
+				context.Background(),
+				hookstage.ModuleInvocationContext{AccountConfig: test.config, Endpoint: hookexecution.EndpointAuction, ModuleContext: test.moduleCtx},
+				payload,
+			)
+			assert.NoError(t, err)
+
+			// test mutations separately
+			for _, mut := range hookResult.ChangeSet.Mutations() {
+				_, err := mut.Apply(payload)
+				assert.NoError(t, err)
+			}
+			bidIDs := make(map[openrtb_ext.BidderName][]string)
+			for bidderName, seatBid := range payload.Responses {
+				for _, bid := range seatBid.Bids {
+					bidIDs[bidderName] = append(bidIDs[bidderName], bid.Bid.ID)
+				}
+			}
+			assert.Equal(t, test.expectedBidIDs, bidIDs, "Invalid Bids left after executing AllProcessedBidResponses hook.")
+
+			// reset ChangeSet not to break hookResult assertion, we validated ChangeSet separately
+			hookResult.ChangeSet = hookstage.ChangeSet[hookstage.AllProcessedBidResponsesPayload]{}
+			assert.Equal(t, test.expectedHookResult, hookResult, "Invalid hook execution result.")
+		})
+	}
+}
+
+func TestIsVAST(t *testing.T) {
+	assert.True(t, isVAST(vastAdm))
+	assert.True(t, isVAST("\ufeff  <vast version=\"3.0\"></vast>"))
+	assert.False(t, isVAST("<div><VAST></VAST></div>"))
+	assert.False(t, isVAST(`<?xml version="1.0"`))
+	assert.False(t, isVAST(""))
+}
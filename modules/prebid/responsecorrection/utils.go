@@ -0,0 +1,65 @@
+package responsecorrection
+
+import (
+	"strings"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+// impMediaTypesKey is the key of the media types offered by each imp in the module context
+const impMediaTypesKey = "imp_media_types"
+
+// impMediaTypes maps the imp ids to the media types the imps offer
+type impMediaTypes map[string]map[openrtb_ext.BidType]struct{}
+
+func newImpMediaTypes(imps []openrtb2.Imp) impMediaTypes {
+	mediaTypes := make(impMediaTypes, len(imps))
+	for _, imp := range imps {
+		types := make(map[openrtb_ext.BidType]struct{})
+		if imp.Banner != nil {
+			types[openrtb_ext.BidTypeBanner] = struct{}{}
+		}
+		if imp.Video != nil {
+			types[openrtb_ext.BidTypeVideo] = struct{}{}
+		}
+		if imp.Audio != nil {
+			types[openrtb_ext.BidTypeAudio] = struct{}{}
+		}
+		if imp.Native != nil {
+			types[openrtb_ext.BidTypeNative] = struct{}{}
+		}
+		mediaTypes[imp.ID] = types
+	}
+	return mediaTypes
+}
+
+// allows reports whether the imp offers the media type, bids for unknown imps are left to the exchange
+func (mt impMediaTypes) allows(impID string, bidType openrtb_ext.BidType) bool {
+	types, ok := mt[impID]
+	if !ok {
+		return true
+	}
+	_, ok = types[bidType]
+	return ok
+}
+
+var markupTypes = map[openrtb_ext.BidType]openrtb2.MarkupType{
+	openrtb_ext.BidTypeBanner: openrtb2.MarkupBanner,
+	openrtb_ext.BidTypeVideo:  openrtb2.MarkupVideo,
+	openrtb_ext.BidTypeAudio:  openrtb2.MarkupAudio,
+	openrtb_ext.BidTypeNative: openrtb2.MarkupNative,
+}
+
+// isVAST reports whether the markup is a VAST document, possibly preceded by an xml declaration
+func isVAST(adm string) bool {
+	adm = strings.TrimLeft(adm, "\ufeff \t\r\n")
+	if strings.HasPrefix(adm, "<?xml") {
+		end := strings.Index(adm, "?>")
+		if end == -1 {
+			return false
+		}
+		adm = strings.TrimLeft(adm[end+2:], " \t\r\n")
+	}
+	return len(adm) >= 5 && strings.EqualFold(adm[:5], "<vast")
+}
@@ -0,0 +1,68 @@
+package richmediafilter
+
+import (
+	"github.com/prebid/prebid-server/v2/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+)
+
+const enforceBlockingTag = "enforce_blocking"
+
+const (
+	substringsAnalyticKey = "substrings"
+	patternsAnalyticKey   = "patterns"
+)
+
+// richmediafilter module has only 1 activity: `enforce_blocking` which will be used in further result processing
+func newEnforceBlockingTags() hookanalytics.Analytics {
+	return hookanalytics.Analytics{
+		Activities: []hookanalytics.Activity{
+			{
+				Name:   enforceBlockingTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+			},
+		},
+	}
+}
+
+func addFailedStatusTag(result *hookstage.HookResult[hookstage.RawBidderResponsePayload]) {
+	result.AnalyticsTags.Activities[0].Status = hookanalytics.ActivityStatusError
+}
+
+func addAllowedAnalyticTag(result *hookstage.HookResult[hookstage.RawBidderResponsePayload], bidder, bidID, impID string) {
+	newAllowedResult := hookanalytics.Result{
+		Status: hookanalytics.ResultStatusAllow,
+		AppliedTo: hookanalytics.AppliedTo{
+			Bidder: bidder,
+			BidIds: []string{bidID},
+			ImpIds: []string{impID},
+		},
+	}
+
+	result.AnalyticsTags.Activities[0].Results = append(result.AnalyticsTags.Activities[0].Results, newAllowedResult)
+}
+
+func addBlockedAnalyticTag(
+	result *hookstage.HookResult[hookstage.RawBidderResponsePayload],
+	bidder, bidID, impID string,
+	matches creativeMatches,
+) {
+	values := make(map[string]interface{})
+	if len(matches.substrings) > 0 {
+		values[substringsAnalyticKey] = matches.substrings
+	}
+	if len(matches.patterns) > 0 {
+		values[patternsAnalyticKey] = matches.patterns
+	}
+
+	newBlockedResult := hookanalytics.Result{
+		Status: hookanalytics.ResultStatusBlock,
+		Values: values,
+		AppliedTo: hookanalytics.AppliedTo{
+			Bidder: bidder,
+			BidIds: []string{bidID},
+			ImpIds: []string{impID},
+		},
+	}
+
+	result.AnalyticsTags.Activities[0].Results = append(result.AnalyticsTags.Activities[0].Results, newBlockedResult)
+}
@@ -0,0 +1,52 @@
+package richmediafilter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+)
+
+func newConfig(data json.RawMessage) (config, error) {
+	var cfg config
+	if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+	for _, action := range cfg.ActionOverrides.EnforceBlocks {
+		if err := validateCondition(action.Conditions); err != nil {
+			return cfg, fmt.Errorf("invalid action_overrides.enforce_blocks: %s", err)
+		}
+	}
+	return cfg, nil
+}
+
+type config struct {
+	// BlockedSubstrings are matched against the adm case-insensitively
+	BlockedSubstrings []string `json:"blocked_substrings"`
+	// BlockedPatterns are regular expressions matched against the adm
+	BlockedPatterns []string        `json:"blocked_patterns"`
+	EnforceBlocks   bool            `json:"enforce_blocks"`
+	ActionOverrides ActionOverrides `json:"action_overrides"`
+}
+
+type ActionOverrides struct {
+	EnforceBlocks []ActionOverride `json:"enforce_blocks"`
+}
+
+type ActionOverride struct {
+	Conditions Conditions `json:"conditions"`
+	Override   bool       `json:"override"`
+}
+
+type Conditions struct {
+	Bidders    []string `json:"bidders"`
+	MediaTypes []string `json:"media_types"`
+}
+
+func validateCondition(conditions Conditions) error {
+	if conditions.Bidders == nil && conditions.MediaTypes == nil {
+		return errors.New("conditions must have at least one of bidders or media_types")
+	}
+	return nil
+}
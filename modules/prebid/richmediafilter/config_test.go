@@ -0,0 +1,34 @@
+package richmediafilter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfig(t *testing.T) {
+	cfg, err := newConfig(json.RawMessage(`{
+  "enforce_blocks": true,
+  "blocked_substrings": ["mraid-loader"],
+  "blocked_patterns": ["top\\.location\\s*="],
+  "action_overrides": {
+    "enforce_blocks": [{"conditions": {"bidders": ["bidderA"], "media_types": ["video"]}, "override": false}]
+  }
+}`))
+	require.NoError(t, err)
+
+	assert.True(t, cfg.EnforceBlocks, "enforce_blocks")
+	assert.Equal(t, []string{"mraid-loader"}, cfg.BlockedSubstrings, "blocked_substrings")
+	assert.Equal(t, []string{`top\.location\s*=`}, cfg.BlockedPatterns, "blocked_patterns")
+	assert.Equal(t, []ActionOverride{{Conditions: Conditions{Bidders: []string{"bidderA"}, MediaTypes: []string{"video"}}, Override: false}}, cfg.ActionOverrides.EnforceBlocks, "action_overrides.enforce_blocks")
+}
+
+func TestNewConfigErrors(t *testing.T) {
+	_, err := newConfig(json.RawMessage(`{"enforce_blocks": "true"}`))
+	assert.ErrorContains(t, err, "failed to parse config")
+
+	_, err = newConfig(json.RawMessage(`{"action_overrides": {"enforce_blocks": [{"conditions": {}, "override": true}]}}`))
+	assert.EqualError(t, err, "invalid action_overrides.enforce_blocks: conditions must have at least one of bidders or media_types")
+}
@@ -0,0 +1,185 @@
+package richmediafilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+)
+
+type patternCompiler func(expressions []string) ([]*regexp.Regexp, error)
+
+// creativeMatches holds the blocked substrings and patterns found in a creative
+type creativeMatches struct {
+	substrings []string
+	patterns   []string
+}
+
+func (m creativeMatches) String() string {
+	return strings.Join(append(append([]string{}, m.substrings...), m.patterns...), ", ")
+}
+
+func handleRawBidderResponseHook(
+	cfg config,
+	payload hookstage.RawBidderResponsePayload,
+	compilePatterns patternCompiler,
+) (result hookstage.HookResult[hookstage.RawBidderResponsePayload], err error) {
+	if len(cfg.BlockedSubstrings) == 0 && len(cfg.BlockedPatterns) == 0 {
+		return result, nil
+	}
+
+	result.AnalyticsTags = newEnforceBlockingTags()
+
+	patterns, err := compilePatterns(cfg.BlockedPatterns)
+	if err != nil {
+		addFailedStatusTag(&result)
+		return result, hookexecution.NewFailure("failed to compile blocked patterns: %s", err)
+	}
+	substrings := make([]string, len(cfg.BlockedSubstrings))
+	for i, substring := range cfg.BlockedSubstrings {
+		substrings[i] = strings.ToLower(substring)
+	}
+
+	bidder := payload.Bidder
+	seatNonBids := make(map[string][]openrtb_ext.NonBid)
+	var seats []string
+
+	// allowedBids will store all bids that have passed the creative check
+	allowedBids := make([]*adapters.TypedBid, 0, len(payload.Bids))
+	for _, bid := range payload.Bids {
+		if bid == nil || bid.Bid == nil {
+			allowedBids = append(allowedBids, bid)
+			continue
+		}
+
+		enforceBlocks, message := enforceBlocksOverride(cfg, bidder, bid.BidType)
+		result.Warnings = mergeStrings(result.Warnings, message)
+		if !enforceBlocks {
+			addAllowedAnalyticTag(&result, bidder, bid.Bid.ID, bid.Bid.ImpID)
+			allowedBids = append(allowedBids, bid)
+			continue
+		}
+
+		matches := matchCreative(bid.Bid.AdM, cfg.BlockedSubstrings, substrings, patterns)
+		if len(matches.substrings) == 0 && len(matches.patterns) == 0 {
+			addAllowedAnalyticTag(&result, bidder, bid.Bid.ID, bid.Bid.ImpID)
+			allowedBids = append(allowedBids, bid)
+			continue
+		}
+
+		addBlockedAnalyticTag(&result, bidder, bid.Bid.ID, bid.Bid.ImpID, matches)
+		addDebugMessage(&result, bid.Bid, bidder, matches)
+
+		seat := bidder
+		if bid.Seat != "" {
+			seat = string(bid.Seat)
+		}
+		if _, ok := seatNonBids[seat]; !ok {
+			seats = append(seats, seat)
+		}
+		seatNonBids[seat] = append(seatNonBids[seat], newNonBid(bid.Bid))
+	}
+
+	for _, seat := range seats {
+		result.SeatNonBid = append(result.SeatNonBid, openrtb_ext.SeatNonBid{Seat: seat, NonBid: seatNonBids[seat]})
+	}
+
+	changeSet := hookstage.ChangeSet[hookstage.RawBidderResponsePayload]{}
+	if len(payload.Bids) != len(allowedBids) {
+		changeSet.RawBidderResponse().Bids().Update(allowedBids)
+		result.ChangeSet = changeSet
+	}
+
+	return result, nil
+}
+
+// enforceBlocksOverride returns whether the blocks are enforced for the bid, bidder specific overrides take
+// precedence over the overrides applying to all bidders
+func enforceBlocksOverride(cfg config, bidder string, bidType openrtb_ext.BidType) (enforceBlocks bool, message string) {
+	var allOverrides []bool
+	var specificOverrides []bool
+
+	for _, action := range cfg.ActionOverrides.EnforceBlocks {
+		matchAllBidders := action.Conditions.Bidders == nil
+		matchesBidder := matchAllBidders || hasMatches(action.Conditions.Bidders, bidder)
+		matchesMedia := action.Conditions.MediaTypes == nil || hasMatches(action.Conditions.MediaTypes, string(bidType))
+
+		if matchesBidder && matchesMedia {
+			if matchAllBidders {
+				allOverrides = append(allOverrides, action.Override)
+			} else {
+				specificOverrides = append(specificOverrides, action.Override)
+			}
+		}
+	}
+
+	if len(specificOverrides)+len(allOverrides) > 1 {
+		message = fmt.Sprintf("More than one condition matches bid. Bidder: %s, bid media type: %s", bidder, bidType)
+	}
+
+	if len(specificOverrides) > 0 {
+		return specificOverrides[0], message
+	} else if len(allOverrides) > 0 {
+		return allOverrides[0], message
+	}
+	return cfg.EnforceBlocks, message
+}
+
+// matchCreative returns the configured substrings and patterns found in the adm,
+// the substrings are given both as configured and lower cased for the case-insensitive search
+func matchCreative(adm string, configuredSubstrings, substrings []string, patterns []*regexp.Regexp) creativeMatches {
+	var matches creativeMatches
+	if adm == "" {
+		return matches
+	}
+
+	lowerAdm := strings.ToLower(adm)
+	for i, substring := range substrings {
+		if substring != "" && strings.Contains(lowerAdm, substring) {
+			matches.substrings = append(matches.substrings, configuredSubstrings[i])
+		}
+	}
+	for _, pattern := range patterns {
+		if pattern.MatchString(adm) {
+			matches.patterns = append(matches.patterns, pattern.String())
+		}
+	}
+	return matches
+}
+
+func newNonBid(bid *openrtb2.Bid) openrtb_ext.NonBid {
+	return openrtb_ext.NonBid{
+		ImpId:      bid.ImpID,
+		StatusCode: int(openrtb_ext.ResponseRejectedInvalidCreative),
+		Ext: openrtb_ext.NonBidExt{
+			Prebid: openrtb_ext.ExtResponseNonBidPrebid{Bid: openrtb_ext.NonBidObject{
+				Price:   bid.Price,
+				ADomain: bid.ADomain,
+				CatTax:  bid.CatTax,
+				Cat:     bid.Cat,
+				DealID:  bid.DealID,
+				W:       bid.W,
+				H:       bid.H,
+				Dur:     bid.Dur,
+				MType:   bid.MType,
+			}},
+		},
+	}
+}
+
+func addDebugMessage(
+	result *hookstage.HookResult[hookstage.RawBidderResponsePayload],
+	bid *openrtb2.Bid,
+	bidder string,
+	matches creativeMatches,
+) {
+	result.DebugMessages = append(
+		result.DebugMessages,
+		fmt.Sprintf("Bid %s from bidder %s has been rejected, creative matches: %s", bid.ID, bidder, matches),
+	)
+}
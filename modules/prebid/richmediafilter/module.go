@@ -0,0 +1,57 @@
+package richmediafilter
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sync"
+
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/modules/moduledeps"
+)
+
+func Builder(_ json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	return Module{patterns: &sync.Map{}}, nil
+}
+
+type Module struct {
+	// patterns caches the compiled blocked patterns of the accounts by their expression
+	patterns *sync.Map
+}
+
+// HandleRawBidderResponseHook rejects the bids of a bidder whose creative matches a blocked substring or pattern.
+func (m Module) HandleRawBidderResponseHook(
+	_ context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.RawBidderResponsePayload,
+) (hookstage.HookResult[hookstage.RawBidderResponsePayload], error) {
+	result := hookstage.HookResult[hookstage.RawBidderResponsePayload]{}
+	if len(miCtx.AccountConfig) == 0 {
+		return result, nil
+	}
+
+	cfg, err := newConfig(miCtx.AccountConfig)
+	if err != nil {
+		return result, err
+	}
+
+	return handleRawBidderResponseHook(cfg, payload, m.compilePatterns)
+}
+
+func (m Module) compilePatterns(expressions []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(expressions))
+	for _, expression := range expressions {
+		if pattern, ok := m.patterns.Load(expression); ok {
+			patterns = append(patterns, pattern.(*regexp.Regexp))
+			continue
+		}
+
+		pattern, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, err
+		}
+		m.patterns.Store(expression, pattern)
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
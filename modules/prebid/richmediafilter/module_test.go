@@ -0,0 +1,215 @@
+package richmediafilter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/adapters"
+	"github.com/prebid/prebid-server/v2/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v2/hooks/hookstage"
+	"github.com/prebid/prebid-server/v2/modules/moduledeps"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = json.RawMessage(`
+{
+  "enforce_blocks": true,
+  "blocked_substrings": ["MRAID-Loader.example.com"],
+  "blocked_patterns": ["top\\.location(\\.href)?\\s*="],
+  "action_overrides": {
+    "enforce_blocks": [
+      {
+        "conditions": {
+          "bidders": ["rubicon"]
+        },
+        "override": false
+      }
+    ]
+  }
+}`)
+
+func TestHandleRawBidderResponseHook(t *testing.T) {
+	cleanBid := &adapters.TypedBid{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp1", AdM: "<div>ad</div>"}, BidType: openrtb_ext.BidTypeBanner}
+	loaderBid := &adapters.TypedBid{
+		Bid:     &openrtb2.Bid{ID: "2", ImpID: "imp2", Price: 1.5, ADomain: []string{"a.com"}, AdM: `<script src="https://mraid-loader.example.com/l.js"></script>`},
+		BidType: openrtb_ext.BidTypeBanner,
+	}
+	redirectBid := &adapters.TypedBid{
+		Bid:     &openrtb2.Bid{ID: "3", ImpID: "imp3", AdM: `<script>window.top.location.href = "https://redirect.example.com"</script>`, W: 300, H: 250},
+		BidType: openrtb_ext.BidTypeBanner,
+		Seat:    "altseat",
+	}
+
+	testCases := []struct {
+		description        string
+		config             json.RawMessage
+		payload            hookstage.RawBidderResponsePayload
+		expectedBids       []*adapters.TypedBid
+		expectedHookResult hookstage.HookResult[hookstage.RawBidderResponsePayload]
+		expectedError      error
+	}{
+		{
+			description:        "Payload not changed when module config not defined",
+			payload:            hookstage.RawBidderResponsePayload{Bidder: "appnexus", Bids: []*adapters.TypedBid{loaderBid}},
+			expectedBids:       []*adapters.TypedBid{loaderBid},
+			expectedHookResult: hookstage.HookResult[hookstage.RawBidderResponsePayload]{},
+		},
+		{
+			description:  "Bids with blocked substrings and patterns rejected",
+			config:       testConfig,
+			payload:      hookstage.RawBidderResponsePayload{Bidder: "appnexus", Bids: []*adapters.TypedBid{cleanBid, loaderBid, redirectBid}},
+			expectedBids: []*adapters.TypedBid{cleanBid},
+			expectedHookResult: hookstage.HookResult[hookstage.RawBidderResponsePayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   enforceBlockingTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{
+						{
+							Status:    hookanalytics.ResultStatusAllow,
+							AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"1"}, ImpIds: []string{"imp1"}},
+						},
+						{
+							Status:    hookanalytics.ResultStatusBlock,
+							Values:    map[string]interface{}{substringsAnalyticKey: []string{"MRAID-Loader.example.com"}},
+							AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"2"}, ImpIds: []string{"imp2"}},
+						},
+						{
+							Status:    hookanalytics.ResultStatusBlock,
+							Values:    map[string]interface{}{patternsAnalyticKey: []string{`top\.location(\.href)?\s*=`}},
+							AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"3"}, ImpIds: []string{"imp3"}},
+						},
+					},
+				}}},
+				DebugMessages: []string{
+					"Bid 2 from bidder appnexus has been rejected, creative matches: MRAID-Loader.example.com",
+					`Bid 3 from bidder appnexus has been rejected, creative matches: top\.location(\.href)?\s*=`,
+				},
+				SeatNonBid: []openrtb_ext.SeatNonBid{
+					{
+						Seat: "appnexus",
+						NonBid: []openrtb_ext.NonBid{{
+							ImpId:      "imp2",
+							StatusCode: int(openrtb_ext.ResponseRejectedInvalidCreative),
+							Ext:        openrtb_ext.NonBidExt{Prebid: openrtb_ext.ExtResponseNonBidPrebid{Bid: openrtb_ext.NonBidObject{Price: 1.5, ADomain: []string{"a.com"}}}},
+						}},
+					},
+					{
+						Seat: "altseat",
+						NonBid: []openrtb_ext.NonBid{{
+							ImpId:      "imp3",
+							StatusCode: int(openrtb_ext.ResponseRejectedInvalidCreative),
+							Ext:        openrtb_ext.NonBidExt{Prebid: openrtb_ext.ExtResponseNonBidPrebid{Bid: openrtb_ext.NonBidObject{W: 300, H: 250}}},
+						}},
+					},
+				},
+			},
+		},
+		{
+			description:  "Bids allowed when blocks are not enforced for bidder",
+			config:       testConfig,
+			payload:      hookstage.RawBidderResponsePayload{Bidder: "rubicon", Bids: []*adapters.TypedBid{loaderBid}},
+			expectedBids: []*adapters.TypedBid{loaderBid},
+			expectedHookResult: hookstage.HookResult[hookstage.RawBidderResponsePayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   enforceBlockingTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{{
+						Status:    hookanalytics.ResultStatusAllow,
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "rubicon", BidIds: []string{"2"}, ImpIds: []string{"imp2"}},
+					}},
+				}}},
+			},
+		},
+		{
+			description: "Warning added when several overrides match bid",
+			config: json.RawMessage(`{"enforce_blocks": true, "blocked_substrings": ["mraid-loader"], "action_overrides": {"enforce_blocks": [
+  {"conditions": {"media_types": ["banner"]}, "override": false},
+  {"conditions": {"bidders": ["appnexus"]}, "override": true}
+]}}`),
+			payload:      hookstage.RawBidderResponsePayload{Bidder: "appnexus", Bids: []*adapters.TypedBid{cleanBid}},
+			expectedBids: []*adapters.TypedBid{cleanBid},
+			expectedHookResult: hookstage.HookResult[hookstage.RawBidderResponsePayload]{
+				Warnings: []string{"More than one condition matches bid. Bidder: appnexus, bid media type: banner"},
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   enforceBlockingTag,
+					Status: hookanalytics.ActivityStatusSuccess,
+					Results: []hookanalytics.Result{{
+						Status:    hookanalytics.ResultStatusAllow,
+						AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", BidIds: []string{"1"}, ImpIds: []string{"imp1"}},
+					}},
+				}}},
+			},
+		},
+		{
+			description:  "Expect error if blocked pattern is invalid",
+			config:       json.RawMessage(`{"enforce_blocks": true, "blocked_patterns": ["top.location("]}`),
+			payload:      hookstage.RawBidderResponsePayload{Bidder: "appnexus", Bids: []*adapters.TypedBid{loaderBid}},
+			expectedBids: []*adapters.TypedBid{loaderBid},
+			expectedHookResult: hookstage.HookResult[hookstage.RawBidderResponsePayload]{
+				AnalyticsTags: hookanalytics.Analytics{Activities: []hookanalytics.Activity{{
+					Name:   enforceBlockingTag,
+					Status: hookanalytics.ActivityStatusError,
+				}}},
+			},
+			expectedError: hookexecution.NewFailure("failed to compile blocked patterns: error parsing regexp: missing closing ): `top.location(`"),
+		},
+		{
+			description:        "Expect error if config is invalid",
+			config:             json.RawMessage(`{"blocked_patterns": "top"}`),
+			payload:            hookstage.RawBidderResponsePayload{Bidder: "appnexus", Bids: []*adapters.TypedBid{loaderBid}},
+			expectedBids:       []*adapters.TypedBid{loaderBid},
+			expectedHookResult: hookstage.HookResult[hookstage.RawBidderResponsePayload]{},
+			expectedError:      errors.New("failed to parse config: cannot unmarshal richmediafilter.config.BlockedPatterns: decode slice: expect [ or n, but found \""),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			result, err := Builder(nil, moduledeps.ModuleDeps{})
+			require.NoError(t, err, "Failed to build module.")
+
+			module, ok := result.(Module)
+			require.True(t, ok, "Failed to cast module type.")
+
+			hookResult, err := module.HandleRawBidderResponseHook(
+				context.Background(),
+				hookstage.ModuleInvocationContext{
+					AccountConfig: test.config,
+					Endpoint:      hookexecution.EndpointAuction,
+				},
+				test.payload,
+			)
+			assert.Equal(t, test.expectedError, err, "Invalid hook execution error.")
+
+			// test mutations separately
+			for _, mut := range hookResult.ChangeSet.Mutations() {
+				newPayload, err := mut.Apply(test.payload)
+				assert.NoError(t, err)
+				test.payload = newPayload
+			}
+			assert.Equal(t, test.expectedBids, test.payload.Bids, "Invalid Bids returned after executing RawBidderResponse hook.")
+
+			// reset ChangeSet not to break hookResult assertion, we validated ChangeSet separately
+			hookResult.ChangeSet = hookstage.ChangeSet[hookstage.RawBidderResponsePayload]{}
+			assert.Equal(t, test.expectedHookResult, hookResult, "Invalid hook execution result.")
+		})
+	}
+}
+
+func TestCompilePatternsCached(t *testing.T) {
+	result, err := Builder(nil, moduledeps.ModuleDeps{})
+	require.NoError(t, err)
+	module := result.(Module)
+
+	first, err := module.compilePatterns([]string{"a+b"})
+	require.NoError(t, err)
+	second, err := module.compilePatterns([]string{"a+b"})
+	require.NoError(t, err)
+	assert.Same(t, first[0], second[0])
+}
@@ -0,0 +1,25 @@
+package richmediafilter
+
+import (
+	"strings"
+)
+
+// mergeStrings appends the new non-empty messages which aren't in the list yet
+func mergeStrings(messages []string, newMessages ...string) []string {
+	for _, msg := range newMessages {
+		if msg == "" || hasMatches(messages, msg) {
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func hasMatches(list []string, s string) bool {
+	for _, val := range list {
+		if strings.EqualFold(val, s) {
+			return true
+		}
+	}
+	return false
+}
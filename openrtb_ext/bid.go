@@ -15,18 +15,36 @@ type ExtBid struct {
 // DealPriority represents priority of deal bid. If its non deal bid then value will be 0
 // DealTierSatisfied true represents corresponding bid has satisfied the deal tier
 type ExtBidPrebid struct {
-	Cache             *ExtBidPrebidCache  `json:"cache,omitempty"`
-	DealPriority      int                 `json:"dealpriority,omitempty"`
-	DealTierSatisfied bool                `json:"dealtiersatisfied,omitempty"`
-	Meta              *ExtBidPrebidMeta   `json:"meta,omitempty"`
-	Targeting         map[string]string   `json:"targeting,omitempty"`
-	TargetBidderCode  string              `json:"targetbiddercode,omitempty"`
-	Type              BidType             `json:"type,omitempty"`
-	Video             *ExtBidPrebidVideo  `json:"video,omitempty"`
-	Events            *ExtBidPrebidEvents `json:"events,omitempty"`
-	BidId             string              `json:"bidid,omitempty"`
-	Passthrough       json.RawMessage     `json:"passthrough,omitempty"`
-	Floors            *ExtBidPrebidFloors `json:"floors,omitempty"`
+	Cache             *ExtBidPrebidCache    `json:"cache,omitempty"`
+	DealPriority      int                   `json:"dealpriority,omitempty"`
+	DealTierSatisfied bool                  `json:"dealtiersatisfied,omitempty"`
+	Meta              *ExtBidPrebidMeta     `json:"meta,omitempty"`
+	Targeting         map[string]string     `json:"targeting,omitempty"`
+	TargetBidderCode  string                `json:"targetbiddercode,omitempty"`
+	Type              BidType               `json:"type,omitempty"`
+	Video             *ExtBidPrebidVideo    `json:"video,omitempty"`
+	Events            *ExtBidPrebidEvents   `json:"events,omitempty"`
+	BidId             string                `json:"bidid,omitempty"`
+	Passthrough       json.RawMessage       `json:"passthrough,omitempty"`
+	Floors            *ExtBidPrebidFloors   `json:"floors,omitempty"`
+	Pricing           *ExtBidPrebidPricing  `json:"pricing,omitempty"`
+	Currency          *ExtBidPrebidCurrency `json:"currency,omitempty"`
+}
+
+// ExtBidPrebidPricing defines the contract for bidresponse.seatbid.bid[i].ext.prebid.pricing
+// It's set when the winning bid clears at a price different from its own price.
+type ExtBidPrebidPricing struct {
+	Mode          string  `json:"mode"`
+	BidPrice      float64 `json:"bidprice"`
+	ClearingPrice float64 `json:"clearingprice"`
+}
+
+// ExtBidPrebidCurrency defines the contract for bidresponse.seatbid.bid[i].ext.prebid.currency
+// It's set when the bidder was sent the request in a currency it accepts instead of the auction currency.
+type ExtBidPrebidCurrency struct {
+	BidderCurrency string  `json:"biddercur"`
+	BidderPrice    float64 `json:"bidderprice"`
+	Rate           float64 `json:"rate"`
 }
 
 // ExtBidPrebidFloors defines the contract for bidresponse.seatbid.bid[i].ext.prebid.floors
@@ -106,6 +106,7 @@ var coreBidderNames []BidderName = []BidderName{
 	BidderFRVRAdNetwork,
 	BidderGamma,
 	BidderGamoshi,
+	BidderGenericORTB,
 	BidderGlobalsun,
 	BidderGothamads,
 	BidderGrid,
@@ -394,6 +395,7 @@ const (
 	BidderFRVRAdNetwork     BidderName = "frvradn"
 	BidderGamma             BidderName = "gamma"
 	BidderGamoshi           BidderName = "gamoshi"
+	BidderGenericORTB       BidderName = "genericortb"
 	BidderGlobalsun         BidderName = "globalsun"
 	BidderGothamads         BidderName = "gothamads"
 	BidderGrid              BidderName = "grid"
@@ -648,8 +650,11 @@ func NewBidderParamsValidator(schemaDirectory string) (BidderParamValidator, err
 		schemaContents[BidderName(bidderName)] = string(fileBytes)
 	}
 
-	// set alias bidder params schema to its parent
+	// set alias bidder params schema to its parent, unless the alias defines its own
 	for alias, parent := range aliasBidderToParent {
+		if _, ok := schemas[alias]; ok {
+			continue
+		}
 		parentSchema := schemas[parent]
 		schemas[alias] = parentSchema
 
@@ -282,3 +282,23 @@ func TestNewBidderParamsValidator(t *testing.T) {
 		})
 	}
 }
+
+func TestNewBidderParamsValidatorAliasOwnSchema(t *testing.T) {
+	aliasBidderToParent = map[BidderName]BidderName{"rubicon": "appnexus"}
+	defer func() { aliasBidderToParent = map[BidderName]BidderName{} }()
+	paramsValidator = &mockParamsHelper{
+		fs: fstest.MapFS{
+			"test/appnexus.json": {
+				Data: []byte(`{"parent":true}`),
+			},
+			"test/rubicon.json": {
+				Data: []byte(`{"alias":true}`),
+			},
+		},
+	}
+
+	bidderValidator, err := NewBidderParamsValidator("test")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"parent":true}`, bidderValidator.Schema("appnexus"))
+	assert.Equal(t, `{"alias":true}`, bidderValidator.Schema("rubicon"), "an alias with its own schema should not use the schema of its parent")
+}
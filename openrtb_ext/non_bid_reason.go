@@ -1,4 +1,4 @@
-package exchange
+package openrtb_ext
 
 // SeatNonBid list the reasons why bid was not resulted in positive bid
 // reason could be either No bid, Error, Request rejection or Response rejection
@@ -6,13 +6,22 @@ package exchange
 type NonBidReason int
 
 const (
-	NoBidUnknownError                      NonBidReason = 0 // No Bid - General
+	NoBidUnknownError                      NonBidReason = 0   // No Bid - General
+	ErrorGeneral                           NonBidReason = 100 // Error - General
+	ErrorBidderUnreachable                 NonBidReason = 103 // Error - Bidder Unreachable
+	RequestBlockedOptimized                NonBidReason = 203 // Request Blocked - Optimized
 	ResponseRejectedGeneral                NonBidReason = 300
 	ResponseRejectedBelowFloor             NonBidReason = 301 // Response Rejected - Below Floor
 	ResponseRejectedCategoryMappingInvalid NonBidReason = 303 // Response Rejected - Category Mapping Invalid
 	ResponseRejectedBelowDealFloor         NonBidReason = 304 // Response Rejected - Bid was Below Deal Floor
+	ResponseRejectedInvalidCreative        NonBidReason = 350 // Response Rejected - Invalid Creative
 	ResponseRejectedCreativeSizeNotAllowed NonBidReason = 351 // Response Rejected - Invalid Creative (Size Not Allowed)
 	ResponseRejectedCreativeNotSecure      NonBidReason = 352 // Response Rejected - Invalid Creative (Not Secure)
+	ResponseRejectedAdvertiserBlocked      NonBidReason = 356 // Response Rejected - Advertiser Blocked
+	ResponseRejectedAdPodNotFitting        NonBidReason = 500 // Response Rejected - Bid doesn't fit the Ad Pod (exchange specific)
+	ResponseRejectedDuplicate              NonBidReason = 501 // Response Rejected - Bid was Deduplicated (exchange specific)
+	ResponseRejectedMultiBidLimitExceeded  NonBidReason = 502 // Response Rejected - Exceeded Bid Limit per Imp (exchange specific)
+	ResponseRejectedCompetitiveSeparation  NonBidReason = 503 // Response Rejected - Bid shares a category or advertiser domain with the Ad Pod (exchange specific)
 )
 
 // Ptr returns pointer to own value.
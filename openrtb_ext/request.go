@@ -101,7 +101,14 @@ type AdServerTarget struct {
 
 // Experiment defines if experimental features are available for the request
 type Experiment struct {
-	AdsCert *AdsCert `json:"adscert,omitempty"`
+	AdsCert *AdsCert       `json:"adscert,omitempty"`
+	Arm     *ExperimentArm `json:"arm,omitempty"`
+}
+
+// ExperimentArm identifies the arm of the account experiment the request was assigned to
+type ExperimentArm struct {
+	Experiment string `json:"experiment,omitempty"`
+	Name       string `json:"name,omitempty"`
 }
 
 // AdsCert defines if Call Sign feature is enabled for request
@@ -201,6 +208,16 @@ type ExtRequestTargeting struct {
 	PreferDeals               bool                      `json:"preferdeals,omitempty"`
 	AppendBidderNames         bool                      `json:"appendbiddernames,omitempty"`
 	AlwaysIncludeDeals        bool                      `json:"alwaysincludedeals,omitempty"`
+	MediaTypePriority         *ExtMediaTypePriority     `json:"mediatypepriority,omitempty"`
+}
+
+// ExtMediaTypePriority defines the contract for bidrequest.ext.prebid.targeting.mediatypepriority
+// It favours the media types listed first when selecting the winning bid of multi-format imps. A less
+// favoured media type wins when its price is more than bias times the price of the favoured one, or
+// never when bias is zero.
+type ExtMediaTypePriority struct {
+	Priority []BidType `json:"priority"`
+	Bias     float64   `json:"bias,omitempty"`
 }
 
 type ExtIncludeBrandCategory struct {
@@ -444,6 +461,9 @@ func (erp *ExtRequestPrebid) Clone() *ExtRequestPrebid {
 		if erp.Experiment.AdsCert != nil {
 			clone.Experiment.AdsCert = ptrutil.ToPtr(*erp.Experiment.AdsCert)
 		}
+		if erp.Experiment.Arm != nil {
+			clone.Experiment.Arm = ptrutil.ToPtr(*erp.Experiment.Arm)
+		}
 	}
 
 	if erp.MultiBid != nil {
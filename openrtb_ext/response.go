@@ -31,6 +31,9 @@ type ExtResponseDebug struct {
 	HttpCalls map[BidderName][]*ExtHttpCall `json:"httpcalls,omitempty"`
 	// Request after resolution of stored requests and debug overrides
 	ResolvedRequest json.RawMessage `json:"resolvedrequest,omitempty"`
+	// StrippedFields defines the contract for bidresponse.ext.debug.strippedfields, the fields removed from the
+	// request of each bidder by its data policies
+	StrippedFields map[BidderName][]string `json:"strippedfields,omitempty"`
 }
 
 // ExtResponseSyncData defines the contract for bidresponse.ext.usersync.{bidder}
@@ -83,6 +86,10 @@ type ExtHttpCall struct {
 	RequestHeaders map[string][]string `json:"requestheaders"`
 	ResponseBody   string              `json:"responsebody"`
 	Status         int                 `json:"status"`
+	// TimeoutMS is the timeout chosen for the request when adaptive bidder timeouts are enabled
+	TimeoutMS int64 `json:"timeoutms,omitempty"`
+	// Cached is true when the response was reused from the bidder response cache instead of calling the bidder
+	Cached bool `json:"cached,omitempty"`
 }
 
 // CookieStatus describes the allowed values for bidresponse.ext.usersync.{bidder}.status
@@ -0,0 +1,239 @@
+package privacy
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+)
+
+// ApplyDataPolicy removes from the request the fields the policy doesn't let through and returns the paths of the
+// removed fields. The request objects are replaced instead of being modified, so that objects shared with the
+// requests of other bidders are left untouched.
+func ApplyDataPolicy(req *openrtb2.BidRequest, policy *config.DataPolicy) ([]string, error) {
+	if req == nil || policy.IsEmpty() {
+		return nil, nil
+	}
+
+	pruner := &dataPolicyPruner{allow: policy.Allow, deny: policy.Deny}
+	if req.User != nil {
+		req.User = pruner.filterUser(req.User, policy.EIDSources, policy.SegTaxes)
+	}
+
+	var err error
+	if req.App, err = pruneSection(req.App, "app", pruner); err != nil {
+		return nil, err
+	}
+	if req.Device, err = pruneSection(req.Device, "device", pruner); err != nil {
+		return nil, err
+	}
+	if req.DOOH, err = pruneSection(req.DOOH, "dooh", pruner); err != nil {
+		return nil, err
+	}
+	if req.Site, err = pruneSection(req.Site, "site", pruner); err != nil {
+		return nil, err
+	}
+	if req.User, err = pruneSection(req.User, "user", pruner); err != nil {
+		return nil, err
+	}
+
+	return pruner.stripped, nil
+}
+
+type dataPolicyPruner struct {
+	allow    []string
+	deny     []string
+	stripped []string
+}
+
+// filterUser keeps the eids of the allowed sources and the data of the allowed segment taxonomies
+func (p *dataPolicyPruner) filterUser(user *openrtb2.User, eidSources []string, segTaxes []int) *openrtb2.User {
+	userCopy := *user
+
+	if len(eidSources) > 0 && len(user.EIDs) > 0 {
+		userCopy.EIDs = make([]openrtb2.EID, 0, len(user.EIDs))
+		for _, eid := range user.EIDs {
+			if containsFold(eidSources, eid.Source) {
+				userCopy.EIDs = append(userCopy.EIDs, eid)
+				continue
+			}
+			p.strip(fmt.Sprintf("user.eids[source=%s]", eid.Source))
+		}
+	}
+
+	if len(segTaxes) > 0 && len(user.Data) > 0 {
+		userCopy.Data = make([]openrtb2.Data, 0, len(user.Data))
+		for _, data := range user.Data {
+			segTax, err := jsonparser.GetInt(data.Ext, "segtax")
+			if err == nil && containsInt(segTaxes, int(segTax)) {
+				userCopy.Data = append(userCopy.Data, data)
+				continue
+			}
+			if err != nil {
+				p.strip("user.data[segtax=none]")
+			} else {
+				p.strip("user.data[segtax=" + strconv.FormatInt(segTax, 10) + "]")
+			}
+		}
+	}
+
+	return &userCopy
+}
+
+// dataPolicyKeptPaths are the privacy signals found in the policy sections. Every bidder needs them, so they are
+// kept whatever the policy.
+var dataPolicyKeptPaths = []string{"user.consent", "user.ext.consent"}
+
+// pruneSection returns the section object without the fields the policy doesn't let through, or nil if the whole
+// section is removed. The object is returned as is when none of its fields are removed.
+func pruneSection[T any](section *T, name string, p *dataPolicyPruner) (*T, error) {
+	if section == nil {
+		return nil, nil
+	}
+
+	denied := containsPath(p.deny, name)
+	allowed := !denied && (len(p.allow) == 0 || containsPath(p.allow, name))
+	if allowed && !hasChildPath(p.deny, name) {
+		return section, nil
+	}
+
+	sectionJSON, err := jsonutil.Marshal(section)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed && !p.keepsChild(sectionJSON, name, denied) {
+		p.strip(name)
+		return nil, nil
+	}
+	prunedJSON, changed, err := p.pruneObject(sectionJSON, name, allowed, denied)
+	if err != nil || !changed {
+		return section, err
+	}
+
+	prunedSection := new(T)
+	if err := jsonutil.Unmarshal(prunedJSON, prunedSection); err != nil {
+		return nil, err
+	}
+	return prunedSection, nil
+}
+
+// pruneObject removes the fields of the object the policy doesn't let through. Allowed is set when the object and
+// all its children are allowed, in which case only denied fields are removed. Denied is set when the object is
+// denied, in which case only the privacy signals are kept.
+func (p *dataPolicyPruner) pruneObject(data json.RawMessage, path string, allowed, denied bool) (json.RawMessage, bool, error) {
+	var fields map[string]json.RawMessage
+	if err := jsonutil.Unmarshal(data, &fields); err != nil {
+		return nil, false, err
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changed := false
+	for _, key := range keys {
+		fieldPath := path + "." + key
+		if containsPath(dataPolicyKeptPaths, fieldPath) {
+			continue
+		}
+		fieldDenied := denied || containsPath(p.deny, fieldPath)
+		fieldAllowed := !fieldDenied && (allowed || containsPath(p.allow, fieldPath))
+
+		// fields which are only partially allowed can't be pruned further when they aren't objects
+		isObject := len(fields[key]) > 0 && fields[key][0] == '{'
+		if !fieldAllowed && (!isObject || !p.keepsChild(fields[key], fieldPath, fieldDenied)) {
+			delete(fields, key)
+			p.strip(fieldPath)
+			changed = true
+			continue
+		}
+		if fieldAllowed && (!isObject || !hasChildPath(p.deny, fieldPath)) {
+			continue
+		}
+
+		prunedField, fieldChanged, err := p.pruneObject(fields[key], fieldPath, fieldAllowed, fieldDenied)
+		if err != nil {
+			return nil, false, err
+		}
+		if fieldChanged {
+			fields[key] = prunedField
+			changed = true
+		}
+	}
+
+	if !changed {
+		return data, false, nil
+	}
+	prunedData, err := jsonutil.Marshal(fields)
+	return prunedData, true, err
+}
+
+// keepsChild reports whether an object which isn't allowed as a whole is pruned instead of removed, because one of
+// its children is allowed or is a privacy signal present in the object
+func (p *dataPolicyPruner) keepsChild(data json.RawMessage, path string, denied bool) bool {
+	if !denied && hasChildPath(p.allow, path) {
+		return true
+	}
+	for _, keptPath := range dataPolicyKeptPaths {
+		if !strings.HasPrefix(keptPath, path+".") {
+			continue
+		}
+		if _, _, _, err := jsonparser.Get(data, strings.Split(strings.TrimPrefix(keptPath, path+"."), ".")...); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *dataPolicyPruner) strip(path string) {
+	for _, stripped := range p.stripped {
+		if stripped == path {
+			return
+		}
+	}
+	p.stripped = append(p.stripped, path)
+}
+
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+func hasChildPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package privacy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDataPolicy(t *testing.T) {
+	newRequest := func() *openrtb2.BidRequest {
+		return &openrtb2.BidRequest{
+			ID:     "req1",
+			Imp:    []openrtb2.Imp{{ID: "imp1"}},
+			Site:   &openrtb2.Site{Page: "https://example.com", Content: &openrtb2.Content{ID: "content1", Title: "title"}},
+			Device: &openrtb2.Device{UA: "ua", IP: "1.2.3.4", SUA: &openrtb2.UserAgent{Model: "model"}, Geo: &openrtb2.Geo{Country: "USA", City: "Boston"}},
+			User: &openrtb2.User{
+				ID:   "user1",
+				EIDs: []openrtb2.EID{{Source: "liveramp.com"}, {Source: "id5-sync.com"}},
+				Data: []openrtb2.Data{
+					{ID: "data1", Ext: json.RawMessage(`{"segtax":4}`)},
+					{ID: "data2", Ext: json.RawMessage(`{"segtax":600}`)},
+					{ID: "data3"},
+				},
+			},
+			Regs: &openrtb2.Regs{COPPA: 1},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		policy           *config.DataPolicy
+		expectedRequest  func(*openrtb2.BidRequest)
+		expectedStripped []string
+	}{
+		{
+			name:            "nil",
+			policy:          nil,
+			expectedRequest: func(req *openrtb2.BidRequest) {},
+		},
+		{
+			name:   "deny",
+			policy: &config.DataPolicy{Deny: []string{"device.sua", "site.content", "device.geo.city", "app"}},
+			expectedRequest: func(req *openrtb2.BidRequest) {
+				req.Device.SUA = nil
+				req.Device.Geo.City = ""
+				req.Site.Content = nil
+			},
+			expectedStripped: []string{"device.geo.city", "device.sua", "site.content"},
+		},
+		{
+			name:   "deny-section",
+			policy: &config.DataPolicy{Deny: []string{"user"}},
+			expectedRequest: func(req *openrtb2.BidRequest) {
+				req.User = nil
+			},
+			expectedStripped: []string{"user"},
+		},
+		{
+			name:   "allow",
+			policy: &config.DataPolicy{Allow: []string{"site", "device.ua", "device.geo.country", "user.eids"}},
+			expectedRequest: func(req *openrtb2.BidRequest) {
+				req.Device = &openrtb2.Device{UA: "ua", Geo: &openrtb2.Geo{Country: "USA"}}
+				req.User = &openrtb2.User{EIDs: req.User.EIDs}
+			},
+			expectedStripped: []string{"device.geo.city", "device.ip", "device.sua", "user.data", "user.id"},
+		},
+		{
+			name:   "allow-and-deny",
+			policy: &config.DataPolicy{Allow: []string{"site", "device"}, Deny: []string{"site.content.title"}},
+			expectedRequest: func(req *openrtb2.BidRequest) {
+				req.Site.Content.Title = ""
+				req.User = nil
+			},
+			expectedStripped: []string{"site.content.title", "user"},
+		},
+		{
+			name:   "eid-sources-and-segtaxes",
+			policy: &config.DataPolicy{EIDSources: []string{"LiveRamp.com"}, SegTaxes: []int{4}},
+			expectedRequest: func(req *openrtb2.BidRequest) {
+				req.User.EIDs = req.User.EIDs[:1]
+				req.User.Data = req.User.Data[:1]
+			},
+			expectedStripped: []string{"user.eids[source=id5-sync.com]", "user.data[segtax=600]", "user.data[segtax=none]"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			req := newRequest()
+			original := newRequest()
+			user, device, site := req.User, req.Device, req.Site
+
+			stripped, err := ApplyDataPolicy(req, test.policy)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedStripped, stripped)
+
+			expectedRequest := newRequest()
+			test.expectedRequest(expectedRequest)
+			assert.Equal(t, expectedRequest, req)
+
+			assert.Equal(t, original.User, user, "the user shared with other bidders should not be modified")
+			assert.Equal(t, original.Device, device, "the device shared with other bidders should not be modified")
+			assert.Equal(t, original.Site, site, "the site shared with other bidders should not be modified")
+		})
+	}
+}
+
+func TestApplyDataPolicyKeepsConsent(t *testing.T) {
+	newUser := func() *openrtb2.User {
+		return &openrtb2.User{ID: "user1", Consent: "consent", Ext: json.RawMessage(`{"consent":"consent","other":1}`)}
+	}
+
+	testCases := []struct {
+		name             string
+		policy           *config.DataPolicy
+		user             *openrtb2.User
+		expectedUser     *openrtb2.User
+		expectedStripped []string
+	}{
+		{
+			name:             "deny-consent",
+			policy:           &config.DataPolicy{Deny: []string{"user.consent", "user.ext.consent"}},
+			user:             newUser(),
+			expectedUser:     newUser(),
+			expectedStripped: nil,
+		},
+		{
+			name:             "deny-section",
+			policy:           &config.DataPolicy{Deny: []string{"user"}},
+			user:             newUser(),
+			expectedUser:     &openrtb2.User{Consent: "consent", Ext: json.RawMessage(`{"consent":"consent"}`)},
+			expectedStripped: []string{"user.ext.other", "user.id"},
+		},
+		{
+			name:             "deny-section-without-consent",
+			policy:           &config.DataPolicy{Deny: []string{"user"}},
+			user:             &openrtb2.User{ID: "user1"},
+			expectedUser:     nil,
+			expectedStripped: []string{"user"},
+		},
+		{
+			name:             "allow-other-fields",
+			policy:           &config.DataPolicy{Allow: []string{"user.id"}},
+			user:             newUser(),
+			expectedUser:     &openrtb2.User{ID: "user1", Consent: "consent", Ext: json.RawMessage(`{"consent":"consent"}`)},
+			expectedStripped: []string{"user.ext.other"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			req := &openrtb2.BidRequest{ID: "req1", User: test.user}
+
+			stripped, err := ApplyDataPolicy(req, test.policy)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedStripped, stripped)
+			assert.Equal(t, test.expectedUser, req.User)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/capture"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/currency"
+	"github.com/prebid/prebid-server/v2/errortypes"
+	"github.com/prebid/prebid-server/v2/exchange"
+	"github.com/prebid/prebid-server/v2/experiment/adscert"
+	"github.com/prebid/prebid-server/v2/floors"
+	"github.com/prebid/prebid-server/v2/gdpr"
+	"github.com/prebid/prebid-server/v2/hooks"
+	"github.com/prebid/prebid-server/v2/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v2/macros"
+	metricsConf "github.com/prebid/prebid-server/v2/metrics/config"
+	"github.com/prebid/prebid-server/v2/modules"
+	"github.com/prebid/prebid-server/v2/modules/moduledeps"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	pbc "github.com/prebid/prebid-server/v2/prebid_cache_client"
+	"github.com/prebid/prebid-server/v2/privacy"
+	storedRequestsConf "github.com/prebid/prebid-server/v2/stored_requests/config"
+	"github.com/prebid/prebid-server/v2/usersync"
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+)
+
+// replayResult is written for every replayed auction so the captured and replayed responses can be diffed. The
+// responses are identical if they only differ in their timings.
+type replayResult struct {
+	ID        string          `json:"id"`
+	Identical bool            `json:"identical"`
+	Error     string          `json:"error,omitempty"`
+	Captured  json.RawMessage `json:"captured,omitempty"`
+	Replayed  json.RawMessage `json:"replayed,omitempty"`
+}
+
+// replay feeds the auctions captured in the file at path back through the exchange and writes the results to out,
+// one JSON object per line. Bidders aren't called, their responses are served from the captured http calls, and
+// bids aren't cached.
+func replay(cfg *config.Configuration, path string, out io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	auctions, err := capture.Read(file)
+	if err != nil {
+		return err
+	}
+
+	// replayed auctions must not be captured again
+	cfg.AuctionCapture.Enabled = false
+
+	rates := &replayRates{}
+	currencyConverter := currency.NewRateConverter(rates, "replay", 0)
+	replayer, err := newReplayer(cfg, currencyConverter)
+	if err != nil {
+		return err
+	}
+	defer replayer.shutdown()
+
+	encoder := json.NewEncoder(out)
+	for _, auction := range auctions {
+		rates.set(auction.Rates)
+		currencyConverter.Run()
+
+		result := replayResult{ID: auction.ID, Captured: auction.Response}
+		response, err := replayer.replayAuction(context.Background(), auction)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Replayed, err = jsonutil.Marshal(response)
+			if err != nil {
+				return err
+			}
+			result.Identical = jsonpatch.Equal(withoutTimings(auction.Response), withoutTimings(result.Replayed))
+		}
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// responseTimings are the paths of the response fields which depend on when and how fast the auction was held
+var responseTimings = [][]string{
+	{"ext", "responsetimemillis"},
+	{"ext", "prebid", "auctiontimestamp"},
+}
+
+// withoutTimings returns a copy of the response without the fields which differ between a captured auction and its
+// replay whatever the bids.
+func withoutTimings(response json.RawMessage) json.RawMessage {
+	response = append(json.RawMessage(nil), response...)
+	for _, path := range responseTimings {
+		response = jsonparser.Delete(response, path...)
+	}
+	return response
+}
+
+type replayer struct {
+	cfg             *config.Configuration
+	exchange        exchange.Exchange
+	planBuilder     hooks.ExecutionPlanBuilder
+	tmaxAdjustments *exchange.TmaxAdjustmentsPreprocessed
+	shutdown        func()
+}
+
+// newReplayer builds an exchange the same way the server does, except for the cache client and metrics.
+func newReplayer(cfg *config.Configuration, currencyConverter *currency.RateConverter) (*replayer, error) {
+	metricsEngine := &metricsConf.NilMetricsEngine{}
+	httpClient := &http.Client{}
+
+	adapters, adaptersErrs := exchange.BuildAdapters(httpClient, cfg, cfg.BidderInfos, metricsEngine)
+	if len(adaptersErrs) > 0 {
+		return nil, errortypes.NewAggregateError("Failed to initialize adapters", adaptersErrs)
+	}
+	syncersByBidder, errs := usersync.BuildSyncers(cfg, cfg.BidderInfos)
+	if len(errs) > 0 {
+		return nil, errortypes.NewAggregateError("Failed to initialize user syncers", errs)
+	}
+
+	repo, _, err := modules.NewBuilder().Build(cfg.Hooks.Modules, moduledeps.ModuleDeps{HTTPClient: httpClient, RateConvertor: currencyConverter})
+	if err != nil {
+		return nil, err
+	}
+	shutdown, _, _, _, categoriesFetcher, _, _ := storedRequestsConf.NewStoredRequests(cfg, metricsEngine, httpClient, httprouter.New())
+
+	vendorListFetcher := gdpr.NewVendorListFetcher(context.Background(), cfg.GDPR, httpClient, gdpr.VendorListURLMaker)
+	gdprPermsBuilder := gdpr.NewPermissionsBuilder(cfg.GDPR, cfg.BidderInfos.ToGVLVendorIDMap(), vendorListFetcher)
+	adsCertSigner, err := adscert.NewAdCertsSigner(cfg.Experiment.AdCerts)
+	if err != nil {
+		shutdown()
+		return nil, err
+	}
+	priceFloorFetcher := floors.NewPriceFloorFetcher(cfg.PriceFloors, httpClient, metricsEngine)
+	cacheClient := &replayCacheClient{Client: pbc.NewClient(httpClient, &cfg.CacheURL, &cfg.ExtCacheURL, metricsEngine)}
+
+	return &replayer{
+		cfg:             cfg,
+		exchange:        exchange.NewExchange(adapters, cacheClient, cfg, syncersByBidder, metricsEngine, cfg.BidderInfos, gdprPermsBuilder, currencyConverter, categoriesFetcher, adsCertSigner, macros.NewStringIndexBasedReplacer(), priceFloorFetcher),
+		planBuilder:     hooks.NewExecutionPlanBuilder(cfg.Hooks, repo),
+		tmaxAdjustments: exchange.ProcessTMaxAdjustments(cfg.TmaxAdjustments),
+		shutdown:        shutdown,
+	}, nil
+}
+
+// replayAuction holds the captured auction again, serving the bidder http calls from the capture.
+func (r *replayer) replayAuction(ctx context.Context, auction *capture.Auction) (*openrtb2.BidResponse, error) {
+	request := &openrtb2.BidRequest{}
+	if err := jsonutil.UnmarshalValid(auction.Request, request); err != nil {
+		return nil, err
+	}
+	account := config.Account{}
+	if err := jsonutil.UnmarshalValid(auction.Account, &account); err != nil {
+		return nil, err
+	}
+	return r.holdAuction(ctx, request, account, auction.UIDs, capture.NewReplay(auction))
+}
+
+// holdAuction holds the auction the way the auction endpoint does, calling the bidders unless replay is set.
+func (r *replayer) holdAuction(ctx context.Context, request *openrtb2.BidRequest, account config.Account, uids capture.UIDs, replay *capture.Replay) (*openrtb2.BidResponse, error) {
+	activityControl := privacy.NewActivityControl(&account.Privacy)
+	hookExecutor := hookexecution.NewHookExecutor(r.planBuilder, hookexecution.EndpointAuction, &metricsConf.NilMetricsEngine{})
+	hookExecutor.SetAccount(&account)
+	hookExecutor.SetActivityControl(activityControl)
+
+	auctionRequest := &exchange.AuctionRequest{
+		BidRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: request},
+		Account:           account,
+		UserSyncs:         uids,
+		StartTime:         time.Now(),
+		PubID:             account.ID,
+		HookExecutor:      hookExecutor,
+		TCF2Config:        gdpr.NewTCF2Config(r.cfg.GDPR.TCF2, account.GDPR),
+		Activities:        activityControl,
+		TmaxAdjustments:   r.tmaxAdjustments,
+		Replay:            replay,
+	}
+	auctionResponse, err := r.exchange.HoldAuction(ctx, auctionRequest, nil)
+	if err != nil {
+		return nil, err
+	}
+	return auctionResponse.BidResponse, nil
+}
+
+// replayRates serves the currency rates captured with an auction to the currency converter.
+type replayRates struct {
+	mu    sync.Mutex
+	rates []byte
+}
+
+func (r *replayRates) set(conversions map[string]map[string]float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rates, _ = jsonutil.Marshal(currency.NewRates(conversions))
+}
+
+func (r *replayRates) Do(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewReader(r.rates)),
+	}, nil
+}
+
+// replayCacheClient doesn't cache bids, replayed auctions must not have side effects.
+type replayCacheClient struct {
+	pbc.Client
+}
+
+func (c *replayCacheClient) PutJson(ctx context.Context, values []pbc.Cacheable) ([]string, []error) {
+	return make([]string, len(values)), nil
+}
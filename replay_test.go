@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v2/capture"
+	"github.com/prebid/prebid-server/v2/config"
+	"github.com/prebid/prebid-server/v2/currency"
+	"github.com/prebid/prebid-server/v2/exchange"
+	"github.com/prebid/prebid-server/v2/hooks"
+	"github.com/prebid/prebid-server/v2/openrtb_ext"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type replayExchange struct {
+	auctionRequest *exchange.AuctionRequest
+}
+
+func (e *replayExchange) HoldAuction(ctx context.Context, r *exchange.AuctionRequest, debugLog *exchange.DebugLog) (*exchange.AuctionResponse, error) {
+	e.auctionRequest = r
+	return &exchange.AuctionResponse{BidResponse: &openrtb2.BidResponse{ID: r.BidRequestWrapper.ID}}, nil
+}
+
+func TestReplayAuction(t *testing.T) {
+	ex := &replayExchange{}
+	r := &replayer{cfg: &config.Configuration{}, exchange: ex, planBuilder: hooks.EmptyPlanBuilder{}}
+	auction := &capture.Auction{
+		ID:      "req1",
+		Request: json.RawMessage(`{"id":"req1","imp":[{"id":"imp1"}]}`),
+		Account: json.RawMessage(`{"id":"acct","debug_allow":true}`),
+		UIDs:    capture.UIDs{"adnxs": "uid1"},
+		Calls:   map[string][]capture.HttpCall{"appnexus": {{Method: "POST", Uri: "https://appnexus.com", StatusCode: 204}}},
+	}
+
+	response, err := r.replayAuction(context.Background(), auction)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &openrtb2.BidResponse{ID: "req1"}, response)
+	if assert.NotNil(t, ex.auctionRequest) {
+		assert.Equal(t, "acct", ex.auctionRequest.Account.ID)
+		assert.True(t, ex.auctionRequest.Account.DebugAllow)
+		assert.Equal(t, "acct", ex.auctionRequest.PubID)
+		assert.Equal(t, auction.UIDs, ex.auctionRequest.UserSyncs)
+		assert.NotNil(t, ex.auctionRequest.HookExecutor)
+		if assert.NotNil(t, ex.auctionRequest.Replay) {
+			call, ok := ex.auctionRequest.Replay.Call("appnexus", "POST", "https://appnexus.com", nil)
+			assert.True(t, ok)
+			assert.Equal(t, 204, call.StatusCode)
+		}
+	}
+}
+
+func TestReplayAuctionInvalidRequest(t *testing.T) {
+	r := &replayer{cfg: &config.Configuration{}, exchange: &replayExchange{}, planBuilder: hooks.EmptyPlanBuilder{}}
+
+	_, err := r.replayAuction(context.Background(), &capture.Auction{Request: json.RawMessage(`{"id":`), Account: json.RawMessage(`{}`)})
+
+	assert.Error(t, err)
+}
+
+func TestReplayRates(t *testing.T) {
+	rates := &replayRates{}
+	rates.set(map[string]map[string]float64{"USD": {"EUR": 0.9}})
+
+	resp, err := rates.Do(&http.Request{})
+	assert.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"conversions":{"USD":{"EUR":0.9}}}`, string(body))
+
+	currencyConverter := currency.NewRateConverter(rates, "replay", 0)
+	assert.NoError(t, currencyConverter.Run())
+	rate, err := currencyConverter.Rates().GetRate("USD", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.9, rate)
+}
+
+func TestReplayCapturedAuction(t *testing.T) {
+	var bidderCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&bidderCalls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"req1","seatbid":[{"seat":"958","bid":[{"id":"bid1","impid":"imp1","price":1.5,"adm":"<div></div>","crid":"cr1","w":300,"h":250,"ext":{"appnexus":{"bid_ad_type":0}}}]}],"cur":"USD"}`))
+	}))
+	defer server.Close()
+
+	allBidderInfos, err := config.LoadBidderInfoFromDisk(infoDirectory)
+	require.NoError(t, err)
+	appnexusInfo := allBidderInfos["appnexus"]
+	appnexusInfo.Endpoint = server.URL
+	bidderInfos := config.BidderInfos{"appnexus": appnexusInfo}
+
+	capturePath := filepath.Join(t.TempDir(), "capture.jsonl")
+	v := viper.New()
+	config.SetupViper(v, "", bidderInfos)
+	v.Set("gdpr.default_value", "0")
+	v.Set("auction_capture.enabled", true)
+	v.Set("auction_capture.sampling_rate", 1)
+	v.Set("auction_capture.file", capturePath)
+	cfg, err := config.New(v, bidderInfos, openrtb_ext.NormalizeBidderName)
+	require.NoError(t, err)
+
+	// the auction is held by the exchange built by the replay, capture enabled, against the bidder server
+	capturing, err := newReplayer(cfg, currency.NewRateConverter(&replayRates{}, "replay", 0))
+	require.NoError(t, err)
+	defer capturing.shutdown()
+	request := &openrtb2.BidRequest{
+		ID:     "req1",
+		Imp:    []openrtb2.Imp{{ID: "imp1", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}, Ext: json.RawMessage(`{"prebid":{"bidder":{"appnexus":{"placementId":12345}}}}`)}},
+		Site:   &openrtb2.Site{Page: "https://publisher.com/page", Publisher: &openrtb2.Publisher{ID: "acct"}},
+		Device: &openrtb2.Device{UA: "Mozilla/5.0", IP: "192.0.2.1"},
+		TMax:   500,
+	}
+	captured, err := capturing.holdAuction(context.Background(), request, config.Account{ID: "acct"}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, captured.SeatBid, 1)
+
+	// captured auctions are written in the background
+	var auctions []*capture.Auction
+	require.Eventually(t, func() bool {
+		file, err := os.Open(capturePath)
+		if err != nil {
+			return false
+		}
+		defer file.Close()
+		auctions, err = capture.Read(file)
+		return err == nil && len(auctions) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, auctions[0].Calls["appnexus"], 1)
+	ip, _ := jsonparser.GetString(auctions[0].Request, "device", "ip")
+	assert.Equal(t, "192.0.2.1", ip, "the request is captured before the privacy policies are enforced")
+
+	var out bytes.Buffer
+	require.NoError(t, replay(cfg, capturePath, &out))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&bidderCalls), "replayed auctions must not call the bidders")
+	var result replayResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, "req1", result.ID)
+	assert.Empty(t, result.Error)
+	bidID, _ := jsonparser.GetString(result.Replayed, "seatbid", "[0]", "bid", "[0]", "id")
+	assert.Equal(t, "bid1", bidID)
+	price, _ := jsonparser.GetFloat(result.Replayed, "seatbid", "[0]", "bid", "[0]", "price")
+	assert.Equal(t, 1.5, price)
+	assert.True(t, result.Identical, "the replayed response differs from the captured one:\n%s\n%s", result.Captured, result.Replayed)
+}
+
+func TestWithoutTimings(t *testing.T) {
+	response := json.RawMessage(`{"id":"req1","ext":{"responsetimemillis":{"appnexus":2},"tmaxrequest":500,"prebid":{"auctiontimestamp":1}}}`)
+
+	assert.JSONEq(t, `{"id":"req1","ext":{"tmaxrequest":500,"prebid":{}}}`, string(withoutTimings(response)))
+	assert.JSONEq(t, `{"id":"req1","ext":{"responsetimemillis":{"appnexus":2},"tmaxrequest":500,"prebid":{"auctiontimestamp":1}}}`, string(response), "the response must not be modified")
+}
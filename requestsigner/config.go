@@ -0,0 +1,30 @@
+package requestsigner
+
+// Request signer types available to the bidders
+const (
+	// TypeHMAC signs the timestamp and the body of the request with HMAC-SHA256
+	TypeHMAC = "hmac-sha256"
+	// TypeJWT sends a JWT bearer token signed with HMAC-SHA256
+	TypeJWT = "jwt"
+)
+
+// Config specifies how the requests sent to a bidder are signed. The key file holds the shared key, optionally
+// prefixed with a key id and a colon. It is checked for changes at every reload interval, so that keys can be rotated
+// without a restart. Zero values fall back to the defaults documented on each field.
+type Config struct {
+	// Type is the name of the request signer, hmac-sha256, jwt or a signer registered by the host
+	Type    string `yaml:"type" mapstructure:"type"`
+	KeyFile string `yaml:"keyFile" mapstructure:"keyFile"`
+	// KeyReloadSeconds is how often the key file is checked for a new key. Defaults to 60.
+	KeyReloadSeconds int `yaml:"keyReloadSeconds" mapstructure:"keyReloadSeconds"`
+	// Header is the header the signature is sent in. Defaults to X-Prebid-Signature for hmac-sha256 and to
+	// Authorization for jwt.
+	Header string `yaml:"header" mapstructure:"header"`
+	// Issuer and Audience are the iss and aud claims of the jwt tokens
+	Issuer   string `yaml:"issuer" mapstructure:"issuer"`
+	Audience string `yaml:"audience" mapstructure:"audience"`
+	// TokenTTLSeconds is the lifetime of the jwt tokens. Defaults to 60.
+	TokenTTLSeconds int `yaml:"tokenTTLSeconds" mapstructure:"tokenTTLSeconds"`
+	// Params configures the request signers registered by the host
+	Params map[string]string `yaml:"params" mapstructure:"params"`
+}
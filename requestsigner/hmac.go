@@ -0,0 +1,54 @@
+package requestsigner
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prebid/prebid-server/v2/util/timeutil"
+)
+
+const defaultHMACHeader = "X-Prebid-Signature"
+
+// hmacSigner signs the timestamp and the body of the request with HMAC-SHA256. The header holds the timestamp in
+// unix seconds, the id of the key when the key file names it and the hex encoded signature of "<timestamp>.<body>",
+// e.g. "t=1700000000,kid=2024-01,v1=f6ad34ed8d6cd89478d71c1a26706a8ba91b0424c6e3aeadda207ecda62a97ce".
+type hmacSigner struct {
+	header  string
+	keyFile *KeyFile
+	clock   timeutil.Time
+}
+
+// NewHMACSigner builds the hmac-sha256 request signer
+func NewHMACSigner(cfg Config, clock timeutil.Time) (RequestSigner, error) {
+	keyFile, err := NewKeyFile(cfg.KeyFile, cfg.KeyReloadSeconds, clock)
+	if err != nil {
+		return nil, err
+	}
+	header := cfg.Header
+	if header == "" {
+		header = defaultHMACHeader
+	}
+	return &hmacSigner{header: header, keyFile: keyFile, clock: clock}, nil
+}
+
+func (s *hmacSigner) Sign(req *http.Request, body []byte) error {
+	key := s.keyFile.Get()
+	timestamp := strconv.FormatInt(s.clock.Now().Unix(), 10)
+
+	mac := hmac.New(sha256.New, key.Secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	parts := []string{"t=" + timestamp}
+	if key.ID != "" {
+		parts = append(parts, "kid="+key.ID)
+	}
+	parts = append(parts, "v1="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set(s.header, strings.Join(parts, ","))
+	return nil
+}
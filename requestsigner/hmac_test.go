@@ -0,0 +1,48 @@
+package requestsigner
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHMACSignerSign(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+
+	testCases := []struct {
+		name           string
+		key            string
+		header         string
+		expectedHeader string
+		expectedValue  string
+	}{
+		{
+			name:           "default-header",
+			key:            "secret",
+			expectedHeader: "X-Prebid-Signature",
+			expectedValue:  "t=1700000000,v1=f6ad34ed8d6cd89478d71c1a26706a8ba91b0424c6e3aeadda207ecda62a97ce",
+		},
+		{
+			name:           "key-id-and-custom-header",
+			key:            "2024-01:secret",
+			header:         "X-Partner-Signature",
+			expectedHeader: "X-Partner-Signature",
+			expectedValue:  "t=1700000000,kid=2024-01,v1=f6ad34ed8d6cd89478d71c1a26706a8ba91b0424c6e3aeadda207ecda62a97ce",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			signer, err := NewHMACSigner(Config{KeyFile: writeKeyFile(t, test.key), Header: test.header}, clock)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "https://bidder.example.com", nil)
+			require.NoError(t, err)
+			require.NoError(t, signer.Sign(req, []byte(`{"id":"req1"}`)))
+			assert.Equal(t, test.expectedValue, req.Header.Get(test.expectedHeader))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package requestsigner
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/prebid/prebid-server/v2/util/jsonutil"
+	"github.com/prebid/prebid-server/v2/util/timeutil"
+)
+
+const (
+	defaultJWTHeader          = "Authorization"
+	defaultJWTTokenTTLSeconds = 60
+)
+
+// jwtSigner sends a JWT bearer token signed with HMAC-SHA256 (HS256) using the shared key. The kid of the token is
+// the id of the key when the key file names it.
+type jwtSigner struct {
+	header   string
+	issuer   string
+	audience string
+	ttl      time.Duration
+	keyFile  *KeyFile
+	clock    timeutil.Time
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+	Kid string `json:"kid,omitempty"`
+}
+
+type jwtClaims struct {
+	Iss string `json:"iss,omitempty"`
+	Aud string `json:"aud,omitempty"`
+	Iat int64  `json:"iat"`
+	Exp int64  `json:"exp"`
+}
+
+// NewJWTSigner builds the jwt request signer
+func NewJWTSigner(cfg Config, clock timeutil.Time) (RequestSigner, error) {
+	keyFile, err := NewKeyFile(cfg.KeyFile, cfg.KeyReloadSeconds, clock)
+	if err != nil {
+		return nil, err
+	}
+	header := cfg.Header
+	if header == "" {
+		header = defaultJWTHeader
+	}
+	ttlSeconds := cfg.TokenTTLSeconds
+	if ttlSeconds == 0 {
+		ttlSeconds = defaultJWTTokenTTLSeconds
+	}
+	return &jwtSigner{
+		header:   header,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		ttl:      time.Duration(ttlSeconds) * time.Second,
+		keyFile:  keyFile,
+		clock:    clock,
+	}, nil
+}
+
+func (s *jwtSigner) Sign(req *http.Request, body []byte) error {
+	key := s.keyFile.Get()
+	now := s.clock.Now()
+
+	header, err := jsonutil.Marshal(jwtHeader{Alg: "HS256", Typ: "JWT", Kid: key.ID})
+	if err != nil {
+		return err
+	}
+	claims, err := jsonutil.Marshal(jwtClaims{Iss: s.issuer, Aud: s.audience, Iat: now.Unix(), Exp: now.Add(s.ttl).Unix()})
+	if err != nil {
+		return err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	mac := hmac.New(sha256.New, key.Secret)
+	mac.Write([]byte(token))
+	token += "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	if http.CanonicalHeaderKey(s.header) == defaultJWTHeader {
+		token = "Bearer " + token
+	}
+	req.Header.Set(s.header, token)
+	return nil
+}
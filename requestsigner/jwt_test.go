@@ -0,0 +1,55 @@
+package requestsigner
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTSignerSign(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	cfg := Config{KeyFile: writeKeyFile(t, "2024-01:secret"), Issuer: "prebid-server", Audience: "bidder"}
+	signer, err := NewJWTSigner(cfg, clock)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "https://bidder.example.com", nil)
+	require.NoError(t, err)
+	require.NoError(t, signer.Sign(req, []byte(`{"id":"req1"}`)))
+
+	authorization := req.Header.Get("Authorization")
+	require.True(t, strings.HasPrefix(authorization, "Bearer "))
+	parts := strings.Split(strings.TrimPrefix(authorization, "Bearer "), ".")
+	require.Len(t, parts, 3)
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"alg":"HS256","typ":"JWT","kid":"2024-01"}`, string(header))
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"iss":"prebid-server","aud":"bidder","iat":1700000000,"exp":1700000060}`, string(claims))
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), parts[2])
+}
+
+func TestJWTSignerSignCustomHeader(t *testing.T) {
+	cfg := Config{KeyFile: writeKeyFile(t, "secret"), Header: "X-Auth-Token", TokenTTLSeconds: 300}
+	signer, err := NewJWTSigner(cfg, &fakeClock{now: time.Unix(1700000000, 0)})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "https://bidder.example.com", nil)
+	require.NoError(t, err)
+	require.NoError(t, signer.Sign(req, nil))
+
+	token := req.Header.Get("X-Auth-Token")
+	assert.Len(t, strings.Split(token, "."), 3, "the token should be sent without the bearer scheme")
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
@@ -0,0 +1,111 @@
+package requestsigner
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v2/util/timeutil"
+)
+
+const defaultKeyReloadSeconds = 60
+
+// Key is a shared signing key and its id, which is empty when the key file doesn't name the key
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// KeyFile holds the key read from a local file. The file is checked for changes at most once per reload interval,
+// so that a rotated key is picked up without a restart. The previous key is kept if the new file can't be read.
+type KeyFile struct {
+	path           string
+	reloadInterval time.Duration
+	clock          timeutil.Time
+
+	mutex     sync.Mutex
+	key       Key
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewKeyFile reads the key from the file at the given path, which must hold the key, optionally prefixed with a key
+// id and a colon. A zero reload interval falls back to the default.
+func NewKeyFile(path string, reloadSeconds int, clock timeutil.Time) (*KeyFile, error) {
+	if reloadSeconds == 0 {
+		reloadSeconds = defaultKeyReloadSeconds
+	}
+	keyFile := &KeyFile{
+		path:           path,
+		reloadInterval: time.Duration(reloadSeconds) * time.Second,
+		clock:          clock,
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if keyFile.key, err = readKey(path); err != nil {
+		return nil, err
+	}
+	keyFile.modTime = info.ModTime()
+	keyFile.lastCheck = clock.Now()
+	return keyFile, nil
+}
+
+// Get returns the current key, reloading the file first if it changed since the last check
+func (kf *KeyFile) Get() Key {
+	kf.mutex.Lock()
+	defer kf.mutex.Unlock()
+
+	now := kf.clock.Now()
+	if now.Sub(kf.lastCheck) < kf.reloadInterval {
+		return kf.key
+	}
+	kf.lastCheck = now
+
+	info, err := os.Stat(kf.path)
+	if err != nil {
+		glog.Warningf("Unable to check request signing key file %s, keeping the current key: %v", kf.path, err)
+		return kf.key
+	}
+	if info.ModTime().Equal(kf.modTime) {
+		return kf.key
+	}
+	key, err := readKey(kf.path)
+	if err != nil {
+		glog.Warningf("Unable to reload request signing key file %s, keeping the current key: %v", kf.path, err)
+		return kf.key
+	}
+	kf.key = key
+	kf.modTime = info.ModTime()
+	return kf.key
+}
+
+func readKey(path string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, err
+	}
+
+	// the key is the first line of the file, so that trailing new lines or comments are ignored
+	line, _, _ := bufio.NewReader(bytes.NewReader(data)).ReadLine()
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return Key{}, errors.New("empty key file")
+	}
+
+	key := Key{Secret: line}
+	if id, secret, found := bytes.Cut(line, []byte(":")); found {
+		if len(id) == 0 || len(secret) == 0 {
+			return Key{}, fmt.Errorf("malformed key in %s, expected <key id>:<key>", path)
+		}
+		key = Key{ID: string(id), Secret: secret}
+	}
+	return key, nil
+}
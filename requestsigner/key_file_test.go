@@ -0,0 +1,73 @@
+package requestsigner
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKeyFile(t *testing.T) {
+	testCases := []struct {
+		name              string
+		content           string
+		expectedKey       Key
+		expectedErrSubstr string
+	}{
+		{
+			name:        "secret-only",
+			content:     "secret\n",
+			expectedKey: Key{Secret: []byte("secret")},
+		},
+		{
+			name:        "key-id",
+			content:     "2024-01:secret\nignored",
+			expectedKey: Key{ID: "2024-01", Secret: []byte("secret")},
+		},
+		{
+			name:              "empty",
+			content:           "\n",
+			expectedErrSubstr: "empty key file",
+		},
+		{
+			name:              "missing-secret",
+			content:           "2024-01:",
+			expectedErrSubstr: "expected <key id>:<key>",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			keyFile, err := NewKeyFile(writeKeyFile(t, test.content), 0, &fakeClock{})
+			if test.expectedErrSubstr != "" {
+				assert.ErrorContains(t, err, test.expectedErrSubstr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedKey, keyFile.Get())
+		})
+	}
+}
+
+func TestKeyFileRotation(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	path := writeKeyFile(t, "2024-01:old")
+	keyFile, err := NewKeyFile(path, 10, clock)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("2024-02:new"), 0600))
+	require.NoError(t, os.Chtimes(path, clock.now.Add(time.Minute), clock.now.Add(time.Minute)))
+
+	clock.now = clock.now.Add(5 * time.Second)
+	assert.Equal(t, "2024-01", keyFile.Get().ID, "the file should not be checked before the reload interval")
+
+	clock.now = clock.now.Add(5 * time.Second)
+	assert.Equal(t, Key{ID: "2024-02", Secret: []byte("new")}, keyFile.Get())
+
+	require.NoError(t, os.WriteFile(path, []byte(""), 0600))
+	require.NoError(t, os.Chtimes(path, clock.now.Add(time.Hour), clock.now.Add(time.Hour)))
+	clock.now = clock.now.Add(10 * time.Second)
+	assert.Equal(t, "2024-02", keyFile.Get().ID, "the current key should be kept when the new file is invalid")
+}
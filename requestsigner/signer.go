@@ -0,0 +1,48 @@
+package requestsigner
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/prebid/prebid-server/v2/util/timeutil"
+)
+
+// RequestSigner signs the http requests sent to a bidder. The body is the body sent on the wire, after compression.
+type RequestSigner interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// Builder builds a request signer from the request signing config of a bidder
+type Builder func(cfg Config, clock timeutil.Time) (RequestSigner, error)
+
+var (
+	buildersMutex sync.RWMutex
+	builders      = map[string]Builder{
+		TypeHMAC: NewHMACSigner,
+		TypeJWT:  NewJWTSigner,
+	}
+)
+
+// Register makes a request signer available to the bidders under the given type. It is meant to be called by hosts
+// before the bidders are built, and replaces any signer already registered under the same type.
+func Register(signerType string, builder Builder) {
+	buildersMutex.Lock()
+	defer buildersMutex.Unlock()
+	builders[signerType] = builder
+}
+
+// NewRequestSigner builds the request signer selected by the config, or returns nil if requests aren't signed
+func NewRequestSigner(cfg *Config) (RequestSigner, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	buildersMutex.RLock()
+	builder, ok := builders[cfg.Type]
+	buildersMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown request signer type: %s", cfg.Type)
+	}
+	return builder(*cfg, &timeutil.RealTime{})
+}
@@ -0,0 +1,95 @@
+package requestsigner
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v2/util/timeutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func writeKeyFile(t *testing.T, key string) string {
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte(key), 0600))
+	return path
+}
+
+type headerSigner struct {
+	value string
+}
+
+func (s *headerSigner) Sign(req *http.Request, body []byte) error {
+	req.Header.Set("X-Custom-Signature", s.value)
+	return nil
+}
+
+func TestNewRequestSigner(t *testing.T) {
+	Register("custom", func(cfg Config, clock timeutil.Time) (RequestSigner, error) {
+		return &headerSigner{value: cfg.Params["value"]}, nil
+	})
+	keyFile := writeKeyFile(t, "secret")
+
+	testCases := []struct {
+		name              string
+		cfg               *Config
+		expectedType      RequestSigner
+		expectedErrSubstr string
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name:         "hmac",
+			cfg:          &Config{Type: TypeHMAC, KeyFile: keyFile},
+			expectedType: &hmacSigner{},
+		},
+		{
+			name:         "jwt",
+			cfg:          &Config{Type: TypeJWT, KeyFile: keyFile},
+			expectedType: &jwtSigner{},
+		},
+		{
+			name:         "registered",
+			cfg:          &Config{Type: "custom", Params: map[string]string{"value": "signed"}},
+			expectedType: &headerSigner{},
+		},
+		{
+			name:              "unknown-type",
+			cfg:               &Config{Type: "rsa", KeyFile: keyFile},
+			expectedErrSubstr: "unknown request signer type: rsa",
+		},
+		{
+			name:              "missing-key-file",
+			cfg:               &Config{Type: TypeHMAC, KeyFile: filepath.Join(t.TempDir(), "missing")},
+			expectedErrSubstr: "no such file or directory",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			signer, err := NewRequestSigner(test.cfg)
+			if test.expectedErrSubstr != "" {
+				assert.ErrorContains(t, err, test.expectedErrSubstr)
+				return
+			}
+			assert.NoError(t, err)
+			if test.expectedType == nil {
+				assert.Nil(t, signer)
+			} else {
+				assert.IsType(t, test.expectedType, signer)
+			}
+		})
+	}
+}